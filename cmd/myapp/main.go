@@ -2,14 +2,31 @@ package main
 
 import (
 	"log"
+	"os"
 	"salle_parfume/internal/app"
 )
 
 func main() {
-	// 1. сборка приложения
-	myApp, err := app.New()
+	// restore <файл> - восстановить базу из копии вместо запуска бота
+	if len(os.Args) > 1 && os.Args[1] == "restore" {
+		if err := app.Restore(os.Args[2:]); err != nil {
+			log.Fatalf("ошибка восстановления: %v", err)
+		}
+		return
+	}
+
+	// config print - показать итоговые настройки (секреты скрыты) и проверить их
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		if err := app.PrintConfig(os.Args[2:]); err != nil {
+			log.Fatalf("ошибка в настройках: %v", err)
+		}
+		return
+	}
+
+	// 1. сборка приложения (остальные аргументы - флаги настроек, например -config config.yaml)
+	myApp, err := app.New(os.Args[1:])
 	if err != nil {
-		log.Fatal("ошибка сборки приложения: %w", err)
+		log.Fatalf("ошибка сборки приложения: %v", err)
 	}
 
 	// 1. запуск приложения
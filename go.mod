@@ -7,4 +7,6 @@ require (
 	github.com/joho/godotenv v1.5.1
 )
 
-require github.com/mattn/go-sqlite3 v1.14.32 // indirect
+require github.com/mattn/go-sqlite3 v1.14.32
+
+require gopkg.in/yaml.v3 v3.0.1
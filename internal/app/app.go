@@ -2,37 +2,55 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
+	"os"
+	"salle_parfume/internal/backup"
 	"salle_parfume/internal/config"
+	httpDelivery "salle_parfume/internal/delivery/http"
 	"salle_parfume/internal/delivery/telegram"
 	"salle_parfume/internal/delivery/telegram/keyboards"
+	"salle_parfume/internal/delivery/web"
+	"salle_parfume/internal/domain"
+	"salle_parfume/internal/i18n"
 	"salle_parfume/internal/logger"
 	tgLogger "salle_parfume/internal/logger/telegram"
+	"salle_parfume/internal/metrics"
 	"salle_parfume/internal/repository"
 	"salle_parfume/internal/repository/sqlite"
+	"salle_parfume/internal/scheduler"
 	"salle_parfume/internal/service"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 // App - зависимости
 type App struct {
-	bot       *telegram.Bot     // telegram бот
-	logWriter *logger.LogWriter // логгер
+	bot       *telegram.Bot        // telegram бот
+	cfg       *config.Config       // настройки (режим получения обновлений)
+	api       *httpDelivery.Server // HTTP API для сайта (nil, если выключен)
+	metrics   *httpDelivery.Server // метрики и проверки здоровья (nil, если выключены)
+	scheduler *scheduler.Scheduler // фоновые задачи по расписанию
+	logWriter *logger.LogWriter    // логгер
 }
 
 // New эта сборки. Конструктор
-// тут все проверки ошибок
-func New() (*App, error) {
-	// 1. Грузим env
-	cfg, err := config.LoadConfig()
+// тут все проверки ошибок. args - флаги командной строки (см. config.Load)
+func New(args []string) (*App, error) {
+	// 1. Грузим настройки: файл, env, флаги
+	cfg, err := config.LoadValid(args)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка загрузки конфига: %w", err)
 	}
+	domain.DefaultCurrency = cfg.Currency
 
 	// 2. логирование
-	logWriter, err := logger.NewLogWriter("telegram", "telegramUsersUse.txt")
+	logWriter, err := logger.NewLogWriter(cfg.LogDir, "telegram", "telegramUsersUse.txt")
 	if err != nil {
 		log.Fatal("ошибка в системе логирования", err)
 	}
@@ -45,16 +63,35 @@ func New() (*App, error) {
 		return nil, fmt.Errorf("ошибка инициализации API бота: %w", err)
 	}
 
+	// ошибки из журнала и падения бота уходят в чат админов (ALERTS_CHAT_ID), журнал пишется как раньше
+	alertService := service.NewAlertService(cfg.AlertsChatID)
+	// уровень LOG_LEVEL касается только вывода: ошибки в чат админов уходят всегда
+	log.SetOutput(io.MultiWriter(logger.NewLevelWriter(os.Stderr, logger.ParseLevel(cfg.LogLevel)), alertService))
+
+	// метрики считаем всегда (это счетчики в памяти), отдаем - если задан METRICS_ADDR
+	appMetrics := metrics.New()
+	// неудачные запросы к Telegram API видны в метриках
+	botAPI.Client = appMetrics.WrapTelegramClient(botAPI.Client)
+
+	// загружаем переводы всех текстов бота
+	translations, err := i18n.Load()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка загрузки переводов: %w", err)
+	}
+
 	// создаем сервис исходных сообщений
-	messageService := service.NewMessageService()
+	messageService := service.NewMessageService(translations)
 
 	// создаем сервис клавиатур
-	keyboardsService := keyboards.NewService()
+	// выключенные части магазина (FEATURE_*) не показываются в меню и командах
+	features := domain.Features{Referrals: cfg.Features.Referrals, Loyalty: cfg.Features.Loyalty}
+	keyboardsService := keyboards.NewService(messageService, cfg.WebAppURL, features)
 
 	// 4. Инициализация db
 	db, err := sqlite.NewSqliteDB(sqlite.Config{
-		DriverName: "sqlite3",
-		Path:       "./assets/storage.db",
+		DriverName: cfg.DBDriver,
+		Path:       cfg.DBDSN,
+		Observer:   appMetrics,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("ошибка инициализации DB: %w", err)
@@ -63,19 +100,154 @@ func New() (*App, error) {
 	// инициализируем репозитории
 	authRepo := sqlite.NewAuthSqlite(db)
 	prodRepo := sqlite.NewProductSqlite(db)
+	orderRepo := sqlite.NewOrderSqlite(db)
+	reviewRepo := sqlite.NewReviewSqlite(db)
+	cartRepo := sqlite.NewCartSqlite(db)
+	promoRepo := sqlite.NewPromoSqlite(db)
+	textRepo := sqlite.NewTextSqlite(db)
+	auditRepo := sqlite.NewAuditSqlite(db)
+	deliveryRepo := sqlite.NewDeliverySqlite(db)
+	jobRepo := sqlite.NewJobSqlite(db)
+	subscriptionRepo := sqlite.NewSubscriptionSqlite(db)
+	referralRepo := sqlite.NewReferralSqlite(db)
+	loyaltyRepo := sqlite.NewLoyaltySqlite(db)
+	dialogRepo := sqlite.NewDialogSqlite(db)
+	abuseRepo := sqlite.NewAbuseSqlite(db)
+	// суммы в базе должны быть в валюте магазина, иначе расчет корзины упадет на сложении разных валют
+	if err := sqlite.CheckCurrency(db, cfg.Currency); err != nil {
+		return nil, fmt.Errorf("ошибка проверки валюты: %w", err)
+	}
 
 	// собиаем все в один контейнер репозиториев
-	repo := repository.NewRepository(authRepo, prodRepo)
+	repo := repository.NewRepository(authRepo, prodRepo, orderRepo, reviewRepo, cartRepo, promoRepo, textRepo, auditRepo, deliveryRepo, jobRepo, subscriptionRepo, referralRepo, loyaltyRepo, dialogRepo, abuseRepo)
+
+	// сервис расчета цен со скидками
+	pricingService := service.NewPricingService(promoRepo)
+	// сервис импорта каталога: фото загружаем через чат админа, чтобы получить file_id
+	catalogService := service.NewCatalogService(prodRepo, telegram.NewPhotoUploader(botAPI, cfg.AdminID))
+
+	// бонусная программа: кешбэк баллами, оплата ими части заказа, сгорание
+	// выключенная программа не начисляет и не принимает баллы, накопленные остаются в базе
+	earnPercent, maxSpendPercent := cfg.LoyaltyEarnPercent, cfg.LoyaltyMaxSpendPercent
+	if !cfg.Features.Loyalty {
+		earnPercent, maxSpendPercent = 0, 0
+	}
+	loyaltyService := service.NewLoyaltyService(loyaltyRepo, earnPercent, maxSpendPercent, cfg.LoyaltyPointsTTL)
+
+	// оформление заказов (общее для бота и API)
+	orderService := service.NewOrderService(orderRepo, deliveryRepo, cartRepo, promoRepo, pricingService, loyaltyService)
+
+	// реферальная программа: приглашения по ссылке и промокоды за друзей
+	referralService := service.NewReferralService(referralRepo, authRepo, orderRepo, promoRepo, cfg.ReferralRewardPercent, cfg.ReferralRewardTTL)
+
+	// тексты магазина, которые админ меняет из бота
+	textService := service.NewTextService(textRepo, translations)
+
+	// защита от флуда с растущими паузами и блокировки пользователей админом
+	abuseService := service.NewAbuseService(abuseRepo, cfg.RateLimit, time.Minute, cfg.FloodCooldown, cfg.FloodCooldownMax)
+
+	// копии базы: по расписанию и по команде админа /backup
+	backups := backup.New(db, backup.Config{
+		Dir:      cfg.Backup.Dir,
+		Keep:     cfg.Backup.Keep,
+		Gzip:     cfg.Backup.Gzip,
+		Password: cfg.Backup.Password,
+	})
 
 	// Создаем Handler (он принимает API и Сервис сообщений)
-	handler := telegram.NewHandler(botAPI, messageService, activityLogger, keyboardsService, repo, cfg.AdminID)
+	handler := telegram.NewHandler(botAPI, messageService, activityLogger, keyboardsService, pricingService, catalogService, textService, orderService, referralService, loyaltyService, abuseService, backups, repo, cfg.AdminID, cfg.OrdersChatID, cfg.PaymentToken, features)
+	// общая обработка всех обновлений, по порядку: паника не роняет бота, журнал действий, метрики,
+	// заблокированные и флуд отсекаются до всего остального, пользователь в базе, язык, права на команды и кнопки админа
+	handler.Use(
+		handler.Recover(alertService),
+		handler.Logging(),
+		handler.Metrics(appMetrics),
+		handler.BanCheck(abuseService),
+		handler.AntiFlood(abuseService),
+		handler.UserUpsert(),
+		handler.Locale(),
+		handler.Auth(),
+	)
 
 	// Создаем самого бота (принимает API, Handler)
 	bot := telegram.NewBot(botAPI, handler)
+	// о новых заказах (из бота, API и Mini App) сообщает бот
+	orderService.SetNotifier(bot)
+	alertService.SetSender(bot)
+	orderService.SetMetrics(appMetrics)
+	appMetrics.SetActiveSessions(handler.ActiveDialogs)
+
+	// фоновые задачи: напоминания о брошенных корзинах, уведомления по подпискам на товары,
+	// промокоды за приглашенных друзей и сгорание бонусов
+	jobs := scheduler.New(jobRepo)
+	if cfg.Features.CartReminders && cfg.CartReminderAfter > 0 {
+		reminderService := service.NewCartReminderService(cartRepo, cfg.CartReminderAfter, cfg.CartReminderInterval)
+		reminderService.SetSender(bot)
+		if err := jobs.Add("cart_reminders", cfg.CartReminderSchedule, 0, reminderService.Run); err != nil {
+			return nil, fmt.Errorf("ошибка расписания напоминаний: %w", err)
+		}
+	}
+	subscriptionService := service.NewSubscriptionService(subscriptionRepo, prodRepo)
+	subscriptionService.SetSender(bot)
+	if err := jobs.Add("product_subscriptions", cfg.SubscriptionSchedule, 0, subscriptionService.Run); err != nil {
+		return nil, fmt.Errorf("ошибка расписания уведомлений по подпискам: %w", err)
+	}
+	referralService.SetSender(bot)
+	if cfg.Features.Referrals {
+		if err := jobs.Add("referral_rewards", cfg.ReferralSchedule, 0, referralService.Run); err != nil {
+			return nil, fmt.Errorf("ошибка расписания наград за друзей: %w", err)
+		}
+	}
+	if cfg.Features.Loyalty {
+		if err := jobs.Add("loyalty_expire", cfg.LoyaltySchedule, 0, loyaltyService.Run); err != nil {
+			return nil, fmt.Errorf("ошибка расписания сгорания бонусов: %w", err)
+		}
+	}
+	if cfg.Backup.Keep > 0 {
+		if err := jobs.Add("db_backup", cfg.Backup.Schedule, 0, backups.Run); err != nil {
+			return nil, fmt.Errorf("ошибка расписания копий базы: %w", err)
+		}
+	}
+
+	// HTTP API для сайта с той же базой, включается адресом HTTP_ADDR
+	var api *httpDelivery.Server
+	if cfg.HTTPAddr != "" {
+		apiHandler := httpDelivery.NewHandler(repo, orderService, botAPI, cfg.TelegramToken, cfg.APIAdminKeys, cfg.APIClientKeys, cfg.WebAppDir)
+		routes := http.NewServeMux()
+		routes.Handle("/", apiHandler.Routes())
+
+		// веб-админка на том же сервере (/admin), если заданы ADMIN_WEB_USERS
+		if len(cfg.AdminWebUsers) > 0 {
+			adminHandler, err := web.NewHandler(repo, telegram.NewPhotoUploader(botAPI, cfg.AdminID), cfg.AdminWebUsers)
+			if err != nil {
+				return nil, fmt.Errorf("ошибка шаблонов админки: %w", err)
+			}
+			routes.Handle("/admin/", adminHandler.Routes())
+		}
+
+		api = httpDelivery.NewServer(cfg.HTTPAddr, routes)
+	}
+
+	// метрики Prometheus и проверки для Docker на отдельном адресе METRICS_ADDR
+	var metricsServer *httpDelivery.Server
+	if cfg.MetricsAddr != "" {
+		checks := []metrics.Check{
+			{Name: "db", Check: db.PingContext},
+			{Name: "telegram", Check: func(context.Context) error {
+				_, err := botAPI.GetMe()
+				return err
+			}},
+		}
+		metricsServer = httpDelivery.NewServer(cfg.MetricsAddr, appMetrics.Routes(checks))
+	}
 
 	// возвращаем готового, сборанного приложения
 	return &App{
 		bot:       bot,
+		cfg:       cfg,
+		api:       api,
+		metrics:   metricsServer,
+		scheduler: jobs,
 		logWriter: logWriter,
 	}, nil
 }
@@ -86,6 +258,68 @@ func (a *App) Run() {
 	// закрываем файл логов
 	defer a.logWriter.Close()
 
-	// запускаем бота
+	// HTTP API работает параллельно с ботом
+	if a.api != nil {
+		go func() {
+			if err := a.api.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Printf("Error running HTTP API: %v", err)
+			}
+		}()
+	}
+
+	// метрики и проверки здоровья тоже
+	if a.metrics != nil {
+		go func() {
+			if err := a.metrics.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Printf("Error running metrics server: %v", err)
+			}
+		}()
+	}
+
+	// планировщик работает в фоне, пока работает бот
+	a.scheduler.Start()
+	defer a.scheduler.Stop()
+
+	// запускаем бота: сам спрашивает обновления или принимает их от Телеграма (BOT_MODE)
+	if a.cfg.BotMode == config.ModeWebhook {
+		if err := a.bot.StartWebhook(a.cfg.WebhookURL, a.cfg.WebhookSecret, a.cfg.WebhookAddr); err != nil {
+			log.Printf("Error starting webhook: %v", err)
+		}
+		return
+	}
 	a.bot.Start()
 }
+
+// Restore - команда restore <файл копии> [флаги]: проверяет копию и ставит ее на место базы (DB_DSN).
+// Бот в это время должен быть остановлен, иначе он продолжит писать в старую базу.
+// Токен бота здесь не нужен, поэтому настройки только читаются, без Validate.
+func Restore(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("использование: restore <файл копии> [флаги]")
+	}
+	cfg, err := config.Load(args[1:])
+	if err != nil {
+		return err
+	}
+	previous, err := backup.Restore(args[0], cfg.DBDSN, cfg.Backup.Password)
+	if err != nil {
+		return err
+	}
+	log.Printf("База восстановлена из %s", args[0])
+	if previous != "" {
+		log.Printf("Прежняя база сохранена как %s", previous)
+	}
+	return nil
+}
+
+// PrintConfig - команда config print [флаги]: итоговые настройки без секретов и найденные в них ошибки
+func PrintConfig(args []string) error {
+	if len(args) < 1 || args[0] != "print" {
+		return fmt.Errorf("использование: config print [флаги]")
+	}
+	cfg, err := config.LoadValid(args[1:])
+	if cfg != nil {
+		cfg.Print(os.Stdout)
+	}
+	return err
+}
@@ -0,0 +1,282 @@
+// backup.go - Пакет backup делает копии базы SQLite без остановки бота и восстанавливает из них.
+// Копия снимается через VACUUM INTO (согласованный снимок даже во время записи), затем
+// по желанию сжимается gzip и шифруется AES-GCM ключом из пароля. В каталоге остаются
+// последние Keep копий, старые удаляются.
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// filePrefix - начало имени файла копии: storage-20251201-030000.db[.gz][.enc]
+	filePrefix = "storage-"
+	// timeLayout - время снимка в имени файла
+	timeLayout = "20060102-150405"
+	// extGzip, extEncrypted - расширения сжатой и зашифрованной копии
+	extGzip      = ".gz"
+	extEncrypted = ".enc"
+)
+
+// Формат зашифрованной копии: magic, соль для ключа, nonce, шифротекст AES-256-GCM.
+const (
+	magic         = "SPBAK1"
+	saltSize      = 16
+	keyIterations = 600000
+)
+
+// ErrNoBackups - в каталоге еще нет ни одной копии
+var ErrNoBackups = errors.New("копий базы еще нет")
+
+// Config - настройки копий
+type Config struct {
+	Dir      string // Каталог для копий
+	Keep     int    // Сколько последних копий хранить
+	Gzip     bool   // Сжимать копии
+	Password string // Пароль для шифрования (пусто - без шифрования)
+}
+
+// Backuper - делает копии одной базы
+type Backuper struct {
+	db  *sql.DB
+	cfg Config
+	mu  sync.Mutex // Одна копия за раз: по расписанию и по команде админа
+}
+
+// New - создает Backuper для базы db
+func New(db *sql.DB, cfg Config) *Backuper {
+	return &Backuper{db: db, cfg: cfg}
+}
+
+// Run - задача планировщика: новая копия и удаление лишних старых
+func (b *Backuper) Run(ctx context.Context) (string, error) {
+	path, err := b.Create(ctx)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s, %d КБ", filepath.Base(path), info.Size()/1024), nil
+}
+
+// Create - снимает копию и возвращает путь к файлу
+func (b *Backuper) Create(ctx context.Context) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := os.MkdirAll(b.cfg.Dir, 0700); err != nil {
+		return "", fmt.Errorf("каталог копий: %w", err)
+	}
+	name := filePrefix + time.Now().Format(timeLayout) + ".db"
+	snapshot := filepath.Join(b.cfg.Dir, name+".tmp")
+	defer os.Remove(snapshot)
+
+	// VACUUM INTO не перезаписывает файлы, поэтому снимок всегда в новый временный
+	os.Remove(snapshot)
+	if _, err := b.db.ExecContext(ctx, `VACUUM INTO ?`, snapshot); err != nil {
+		return "", fmt.Errorf("снимок базы: %w", err)
+	}
+	data, err := os.ReadFile(snapshot)
+	if err != nil {
+		return "", err
+	}
+
+	if b.cfg.Gzip {
+		if data, err = compress(data); err != nil {
+			return "", fmt.Errorf("сжатие копии: %w", err)
+		}
+		name += extGzip
+	}
+	if b.cfg.Password != "" {
+		if data, err = encrypt(data, b.cfg.Password); err != nil {
+			return "", fmt.Errorf("шифрование копии: %w", err)
+		}
+		name += extEncrypted
+	}
+
+	path := filepath.Join(b.cfg.Dir, name)
+	if err := writeFileAtomic(path, data); err != nil {
+		return "", err
+	}
+	if err := b.rotate(); err != nil {
+		return path, fmt.Errorf("удаление старых копий: %w", err)
+	}
+	return path, nil
+}
+
+// Latest - путь к последней копии (ErrNoBackups, если их нет)
+func (b *Backuper) Latest() (string, error) {
+	files, err := list(b.cfg.Dir)
+	if err != nil {
+		return "", err
+	}
+	if len(files) == 0 {
+		return "", ErrNoBackups
+	}
+	return files[len(files)-1], nil
+}
+
+// rotate - оставляет последние Keep копий
+func (b *Backuper) rotate() error {
+	files, err := list(b.cfg.Dir)
+	if err != nil || b.cfg.Keep <= 0 || len(files) <= b.cfg.Keep {
+		return err
+	}
+	var errs []error
+	for _, path := range files[:len(files)-b.cfg.Keep] {
+		if err := os.Remove(path); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// list - копии в каталоге от старых к новым (время снимка в имени, поэтому хватает сортировки по имени)
+func list(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.Type().IsRegular() && strings.HasPrefix(name, filePrefix) && !strings.HasSuffix(name, ".tmp") {
+			files = append(files, filepath.Join(dir, name))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Decode - содержимое базы из файла копии: расшифровывает и распаковывает по расширениям
+func Decode(path, password string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	name := path
+	if strings.HasSuffix(name, extEncrypted) {
+		if password == "" {
+			return nil, fmt.Errorf("копия зашифрована, нужен пароль (BACKUP_PASSWORD)")
+		}
+		if data, err = decrypt(data, password); err != nil {
+			return nil, err
+		}
+		name = strings.TrimSuffix(name, extEncrypted)
+	}
+	if strings.HasSuffix(name, extGzip) {
+		if data, err = decompress(data); err != nil {
+			return nil, fmt.Errorf("распаковка копии: %w", err)
+		}
+	}
+	return data, nil
+}
+
+// compress - gzip
+func compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decompress - обратно из gzip
+func decompress(data []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
+
+// newGCM - AES-256-GCM с ключом из пароля и соли
+func newGCM(password string, salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, password, salt, keyIterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encrypt - шифрует копию: на каждую копию своя соль и nonce
+func encrypt(data []byte, password string) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(password, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := append([]byte(magic), salt...)
+	out = append(out, nonce...)
+	return gcm.Seal(out, nonce, data, []byte(magic)), nil
+}
+
+// decrypt - расшифровывает копию. Неверный пароль или испорченный файл - ошибка.
+func decrypt(data []byte, password string) ([]byte, error) {
+	if !bytes.HasPrefix(data, []byte(magic)) || len(data) < len(magic)+saltSize {
+		return nil, fmt.Errorf("файл не похож на зашифрованную копию")
+	}
+	data = data[len(magic):]
+	salt, data := data[:saltSize], data[saltSize:]
+	gcm, err := newGCM(password, salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("файл копии обрезан")
+	}
+	nonce, data := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, data, []byte(magic))
+	if err != nil {
+		return nil, fmt.Errorf("не удалось расшифровать копию: неверный пароль или файл испорчен")
+	}
+	return plain, nil
+}
+
+// writeFileAtomic - пишет во временный файл и переименовывает: недописанной копии не бывает
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,151 @@
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newShopDB - база с таблицами магазина и одним товаром
+func newShopDB(t *testing.T, path, product string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	_, err = db.Exec(`CREATE TABLE users (id INTEGER); CREATE TABLE orders (id INTEGER);
+		CREATE TABLE products (name TEXT); INSERT INTO products (name) VALUES (?)`, product)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// productName - товар в базе по пути path
+func productName(t *testing.T, path string) string {
+	t.Helper()
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var name string
+	if err := db.QueryRow(`SELECT name FROM products`).Scan(&name); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+// TestBackupAndRestore - копия со сжатием и шифрованием, удаление старых копий
+// и восстановление с откладыванием прежней базы
+func TestBackupAndRestore(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      Config
+		wantExt  string
+		password string // с каким паролем восстанавливаем
+	}{
+		{name: "без сжатия", cfg: Config{Keep: 2}, wantExt: ".db"},
+		{name: "gzip", cfg: Config{Keep: 2, Gzip: true}, wantExt: ".db.gz"},
+		{name: "gzip и шифрование", cfg: Config{Keep: 2, Gzip: true, Password: "secret"}, wantExt: ".db.gz.enc", password: "secret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			tt.cfg.Dir = filepath.Join(dir, "backups")
+			db := newShopDB(t, filepath.Join(dir, "storage.db"), "Aqua")
+
+			// Две старые копии: после новой должны остаться последние Keep
+			if err := os.MkdirAll(tt.cfg.Dir, 0700); err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range []string{"storage-20200101-000000.db", "storage-20200102-000000.db"} {
+				if err := os.WriteFile(filepath.Join(tt.cfg.Dir, name), nil, 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			b := New(db, tt.cfg)
+			path, err := b.Create(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasSuffix(path, tt.wantExt) {
+				t.Fatalf("копия %s, ожидалось расширение %s", path, tt.wantExt)
+			}
+			files, err := list(tt.cfg.Dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(files) != 2 || filepath.Base(files[0]) != "storage-20200102-000000.db" {
+				t.Fatalf("остались копии %v, ожидались вторая старая и новая", files)
+			}
+			if latest, err := b.Latest(); err != nil || latest != path {
+				t.Fatalf("последняя копия %s (%v), ожидалась %s", latest, err, path)
+			}
+
+			// Восстанавливаем поверх другой базы: она откладывается, на ее месте - копия
+			target := filepath.Join(dir, "restored.db")
+			newShopDB(t, target, "Bois")
+			previous, err := Restore(path, target, tt.password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := productName(t, target); got != "Aqua" {
+				t.Errorf("после восстановления товар %q, ожидался Aqua", got)
+			}
+			if got := productName(t, previous); got != "Bois" {
+				t.Errorf("в отложенной базе товар %q, ожидался Bois", got)
+			}
+		})
+	}
+}
+
+// TestRestoreRejects - неверный пароль и файл без таблиц магазина не трогают текущую базу
+func TestRestoreRejects(t *testing.T) {
+	dir := t.TempDir()
+	db := newShopDB(t, filepath.Join(dir, "storage.db"), "Aqua")
+	encrypted, err := New(db, Config{Dir: filepath.Join(dir, "enc"), Password: "secret"}).Create(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := sql.Open("sqlite3", filepath.Join(dir, "other.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+	if _, err := other.Exec(`CREATE TABLE notes (text TEXT)`); err != nil {
+		t.Fatal(err)
+	}
+	foreign, err := New(other, Config{Dir: filepath.Join(dir, "foreign")}).Create(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		password string
+	}{
+		{name: "без пароля", path: encrypted},
+		{name: "чужой пароль", path: encrypted, password: "guess"},
+		{name: "не база магазина", path: foreign},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := filepath.Join(t.TempDir(), "storage.db")
+			newShopDB(t, target, "Bois")
+			if _, err := Restore(tt.path, target, tt.password); err == nil {
+				t.Fatal("копия восстановилась")
+			}
+			if got := productName(t, target); got != "Bois" {
+				t.Errorf("текущая база изменилась: товар %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+// restore.go - восстановление базы из копии. Выполняется отдельной командой (restore),
+// когда бот остановлен: копия проверяется целиком и только потом встает на место базы.
+// Текущая база не удаляется, а переименовывается, чтобы можно было вернуться к ней.
+package backup
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3" // Драйвер для SQLite
+)
+
+// requiredTables - без этих таблиц файл точно не база магазина
+var requiredTables = []string{"users", "products", "orders"}
+
+// Restore - проверяет копию path и ставит ее на место базы dbPath.
+// Возвращает, куда переименована прежняя база (пусто, если ее не было).
+func Restore(path, dbPath, password string) (string, error) {
+	data, err := Decode(path, password)
+	if err != nil {
+		return "", err
+	}
+
+	candidate := dbPath + ".restore"
+	if err := os.WriteFile(candidate, data, 0600); err != nil {
+		return "", err
+	}
+	if err := Validate(candidate); err != nil {
+		os.Remove(candidate)
+		return "", fmt.Errorf("копия не прошла проверку: %w", err)
+	}
+
+	// Прежнюю базу вместе с ее журналами откладываем: незавершенный журнал
+	// SQLite иначе применил бы к восстановленной базе
+	var previous string
+	if _, err := os.Stat(dbPath); err == nil {
+		previous = dbPath + ".before-restore-" + time.Now().Format(timeLayout)
+		for _, suffix := range []string{"", "-journal", "-wal", "-shm"} {
+			err := os.Rename(dbPath+suffix, previous+suffix)
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				os.Remove(candidate)
+				return "", fmt.Errorf("не удалось отложить текущую базу: %w", err)
+			}
+		}
+	}
+	if err := os.Rename(candidate, dbPath); err != nil {
+		return previous, fmt.Errorf("не удалось поставить копию на место базы: %w", err)
+	}
+	return previous, nil
+}
+
+// Validate - файл открывается как SQLite, проходит integrity_check и в нем есть таблицы магазина
+func Validate(path string) error {
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var result string
+	if err := db.QueryRow(`PRAGMA integrity_check`).Scan(&result); err != nil {
+		return fmt.Errorf("integrity_check: %w", err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("integrity_check: %s", result)
+	}
+	for _, table := range requiredTables {
+		var n int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, table).Scan(&n); err != nil {
+			return err
+		}
+		if n == 0 {
+			return fmt.Errorf("нет таблицы %s", table)
+		}
+	}
+	return nil
+}
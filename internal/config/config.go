@@ -0,0 +1,268 @@
+// config.go - настройки приложения. Значения берутся по порядку (следующее перекрывает предыдущее):
+// значения по умолчанию, файл YAML (CONFIG_FILE или флаг -config), переменные окружения
+// (и .env, если он есть) и флаги командной строки. Имя переменной задано тегом env,
+// ключ в файле - то же имя в нижнем регистре (telegram_token), флаг - через дефис (-telegram-token).
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type Config struct {
+	TelegramToken string `env:"TELEGRAM_TOKEN" secret:"true"`         // для работы телеграм бота
+	AdminID       int64  `env:"TELEGRAM_ID"`                          // админу будет достопно добавление нового каталога
+	PaymentToken  string `env:"PAYMENT_PROVIDER_TOKEN" secret:"true"` // токен платежного провайдера, необязательный (без него оплата при получении)
+	OrdersChatID  int64  `env:"ORDERS_CHAT_ID"`                       // чат или группа, куда приходят новые заказы (по умолчанию личка админа)
+	Currency      string `env:"CURRENCY" default:"RUB"`               // валюта магазина (код ISO 4217)
+
+	BotMode       string `env:"BOT_MODE" default:"polling"`   // как получать обновления: polling или webhook
+	WebhookURL    string `env:"WEBHOOK_URL" secret:"true"`    // адрес (https), на который Телеграм присылает обновления в режиме webhook
+	WebhookSecret string `env:"WEBHOOK_SECRET" secret:"true"` // секрет, который Телеграм присылает в заголовке каждого обновления: запросы без него не принимаем
+	WebhookAddr   string `env:"WEBHOOK_ADDR" default:":8443"` // на каком адресе бот их принимает (обычно за прокси с TLS)
+
+	DBDriver string `env:"DB_DRIVER" default:"sqlite3"`          // драйвер базы (пока поддерживается только sqlite3)
+	DBDSN    string `env:"DB_DSN" default:"./assets/storage.db"` // файл базы
+
+	LogDir   string `env:"LOG_DIR" default:"logs"`   // каталог журналов действий пользователей
+	LogLevel string `env:"LOG_LEVEL" default:"info"` // что писать в журнал: debug, info, warn или error
+
+	HTTPAddr      string   `env:"HTTP_ADDR"`                     // адрес HTTP API, например ":8080" (пусто - API выключен)
+	APIAdminKeys  []string `env:"API_ADMIN_KEYS" secret:"true"`  // ключи API с правами админа
+	APIClientKeys []string `env:"API_CLIENT_KEYS" secret:"true"` // ключи API для сайта: каталог, корзина и заказы от имени покупателя
+
+	WebAppURL string `env:"WEBAPP_URL"` // адрес Mini App (https), по нему открывается кнопка "Открыть магазин"
+	WebAppDir string `env:"WEBAPP_DIR"` // папка со статикой Mini App, которую отдает HTTP сервер (необязательно)
+
+	AdminWebUsers map[string]string `env:"ADMIN_WEB_USERS" secret:"true"` // логины и пароли веб-админки (/admin), пусто - админка выключена
+
+	CartReminderAfter    time.Duration `env:"CART_REMINDER_AFTER" default:"24h"`             // через сколько без изменений напомнить о корзине (0 - не напоминать)
+	CartReminderInterval time.Duration `env:"CART_REMINDER_INTERVAL" default:"72h"`          // не чаще одного напоминания за это время одному покупателю
+	CartReminderSchedule string        `env:"CART_REMINDER_SCHEDULE" default:"*/15 * * * *"` // как часто искать брошенные корзины (cron или "@every 15m")
+
+	SubscriptionSchedule string `env:"SUBSCRIPTION_SCHEDULE" default:"*/5 * * * *"` // как часто рассылать уведомления о поступлении и снижении цены (cron или "@every 5m")
+
+	ReferralRewardPercent float64       `env:"REFERRAL_REWARD_PERCENT" default:"10"`     // скидка по промокоду, который получает пригласивший за друга
+	ReferralRewardTTL     time.Duration `env:"REFERRAL_REWARD_TTL" default:"2160h"`      // сколько действует этот промокод (0 - бессрочно)
+	ReferralSchedule      string        `env:"REFERRAL_SCHEDULE" default:"*/10 * * * *"` // как часто выдавать промокоды за оплаченные заказы друзей
+
+	LoyaltyEarnPercent     float64       `env:"LOYALTY_EARN_PERCENT" default:"5"`       // сколько процентов от оплаченных товаров возвращается бонусами (0 - не начислять)
+	LoyaltyMaxSpendPercent float64       `env:"LOYALTY_MAX_SPEND_PERCENT" default:"30"` // какую долю суммы товаров можно оплатить бонусами (0 - нельзя)
+	LoyaltyPointsTTL       time.Duration `env:"LOYALTY_POINTS_TTL" default:"8760h"`     // через сколько бонусы сгорают (0 - не сгорают)
+	LoyaltySchedule        string        `env:"LOYALTY_SCHEDULE" default:"0 3 * * *"`   // как часто сжигать просроченные бонусы
+
+	RateLimit        int           `env:"RATE_LIMIT" default:"30"`         // сколько обновлений в минуту принимаем от одного пользователя (0 - без ограничения)
+	FloodCooldown    time.Duration `env:"FLOOD_COOLDOWN" default:"1m"`     // пауза за первое превышение, каждое следующее подряд ее удваивает
+	FloodCooldownMax time.Duration `env:"FLOOD_COOLDOWN_MAX" default:"1h"` // больше этого пауза не растет
+
+	MetricsAddr string `env:"METRICS_ADDR"` // адрес сервера метрик и проверок (/metrics, /healthz, /readyz), пусто - выключен
+
+	AlertsChatID int64 `env:"ALERTS_CHAT_ID"` // чат для сообщений об ошибках и падениях бота (по умолчанию админ, 0 - не отправлять)
+
+	Backup   BackupConfig
+	Features Features
+
+	sources map[string]string // откуда взято каждое значение (для config print)
+}
+
+// BackupConfig - копии базы
+type BackupConfig struct {
+	Dir      string `env:"BACKUP_DIR" default:"./backups"`      // каталог для копий
+	Keep     int    `env:"BACKUP_KEEP" default:"7"`             // сколько последних копий хранить (0 - копии по расписанию не делаем)
+	Gzip     bool   `env:"BACKUP_GZIP" default:"true"`          // сжимать копии
+	Password string `env:"BACKUP_PASSWORD" secret:"true"`       // пароль для шифрования копий (пусто - без шифрования)
+	Schedule string `env:"BACKUP_SCHEDULE" default:"0 4 * * *"` // как часто делать копию (cron или "@every 6h")
+}
+
+// Features - части магазина, которые можно выключить целиком
+type Features struct {
+	Referrals     bool `env:"FEATURE_REFERRALS" default:"true"`      // приглашение друзей и промокоды за них
+	Loyalty       bool `env:"FEATURE_LOYALTY" default:"true"`        // бонусные баллы
+	CartReminders bool `env:"FEATURE_CART_REMINDERS" default:"true"` // напоминания о брошенной корзине
+}
+
+// Режимы получения обновлений (BOT_MODE)
+const (
+	ModePolling = "polling"
+	ModeWebhook = "webhook"
+)
+
+// logLevels - допустимые LOG_LEVEL
+var logLevels = []string{"debug", "info", "warn", "error"}
+
+// webhookSecretPattern - какой секрет webhook принимает Телеграм (secret_token в setWebhook)
+var webhookSecretPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
+// Load - собирает настройки из всех источников. args - флаги командной строки (без имени программы).
+// Ошибки разбора значений собираются все сразу и возвращаются вместе с тем, что удалось прочитать;
+// nil вместо настроек - только при неверных флагах. Проверка смысла значений - в Validate.
+func Load(args []string) (*Config, error) {
+	cfg := &Config{}
+	err := load(cfg, args)
+	if cfg.sources == nil {
+		return nil, err
+	}
+
+	// Заказы и ошибки по умолчанию приходят админу
+	if cfg.sources["ORDERS_CHAT_ID"] == sourceDefault {
+		cfg.OrdersChatID = cfg.AdminID
+	}
+	if cfg.sources["ALERTS_CHAT_ID"] == sourceDefault {
+		cfg.AlertsChatID = cfg.AdminID
+	}
+	return cfg, err
+}
+
+// LoadValid - Load и Validate вместе: ошибки разбора и проверки одним списком.
+// Если значение не разобралось, его проверку не повторяем.
+func LoadValid(args []string) (*Config, error) {
+	cfg, err := Load(args)
+	if cfg == nil {
+		return nil, err
+	}
+	var errs Errors
+	errors.As(err, &errs)
+	failed := make(map[string]bool, len(errs))
+	for _, e := range errs {
+		if fe, ok := e.(*FieldError); ok {
+			failed[fe.Name] = true
+		}
+	}
+	var checks Errors
+	errors.As(cfg.Validate(), &checks)
+	for _, e := range checks {
+		if fe, ok := e.(*FieldError); ok && failed[fe.Name] {
+			continue
+		}
+		errs = append(errs, e)
+	}
+	return cfg, errs.err()
+}
+
+// Validate - проверяет, что с такими настройками бот запустится. Возвращает все ошибки сразу.
+func (c *Config) Validate() error {
+	var errs Errors
+
+	// 1. Бот и админ
+	if c.TelegramToken == "" {
+		errs.add("TELEGRAM_TOKEN", "не задан")
+	}
+	if c.AdminID == 0 {
+		errs.add("TELEGRAM_ID", "не задан")
+	}
+	if len(c.Currency) != 3 || strings.ToUpper(c.Currency) != c.Currency {
+		errs.add("CURRENCY", "ожидается код валюты из трех заглавных букв, например RUB, получено %q", c.Currency)
+	}
+
+	// 2. Получение обновлений: в режиме webhook Телеграм шлет только на https
+	switch c.BotMode {
+	case ModePolling:
+	case ModeWebhook:
+		if !strings.HasPrefix(c.WebhookURL, "https://") {
+			errs.add("WEBHOOK_URL", "в режиме webhook нужен адрес, начинающийся с https://")
+		}
+		if !webhookSecretPattern.MatchString(c.WebhookSecret) {
+			errs.add("WEBHOOK_SECRET", "в режиме webhook нужен секрет до 256 символов из латинских букв, цифр, _ и -")
+		}
+		if c.WebhookAddr == "" {
+			errs.add("WEBHOOK_ADDR", "в режиме webhook нужен адрес для приема обновлений")
+		}
+	default:
+		errs.add("BOT_MODE", "ожидается %s или %s, получено %q", ModePolling, ModeWebhook, c.BotMode)
+	}
+
+	// 3. База и журнал
+	if c.DBDriver != "sqlite3" {
+		errs.add("DB_DRIVER", "поддерживается только sqlite3, получено %q", c.DBDriver)
+	}
+	if c.DBDSN == "" {
+		errs.add("DB_DSN", "не задан файл базы")
+	}
+	if c.LogDir == "" {
+		errs.add("LOG_DIR", "не задан каталог журналов")
+	}
+	if !contains(logLevels, c.LogLevel) {
+		errs.add("LOG_LEVEL", "ожидается одно из %s, получено %q", strings.Join(logLevels, ", "), c.LogLevel)
+	}
+
+	// 4. Mini App: Телеграм открывает только https-адреса
+	if c.WebAppURL != "" && !strings.HasPrefix(c.WebAppURL, "https://") {
+		errs.add("WEBAPP_URL", "должен начинаться с https://")
+	}
+
+	// 5. Проценты программ лояльности
+	// Промокод за друга выдается только при включенных приглашениях, скидка 0% бессмысленна
+	if c.Features.Referrals && (c.ReferralRewardPercent <= 0 || c.ReferralRewardPercent > 100) {
+		errs.add("REFERRAL_REWARD_PERCENT", "должен быть числом больше 0 и не больше 100, получено %v", c.ReferralRewardPercent)
+	}
+	for name, p := range map[string]float64{"LOYALTY_EARN_PERCENT": c.LoyaltyEarnPercent, "LOYALTY_MAX_SPEND_PERCENT": c.LoyaltyMaxSpendPercent} {
+		if p < 0 || p > 100 {
+			errs.add(name, "должен быть числом от 0 до 100, получено %v", p)
+		}
+	}
+
+	// 6. Флуд и копии
+	if c.RateLimit < 0 {
+		errs.add("RATE_LIMIT", "должен быть целым числом от 0, получено %d", c.RateLimit)
+	}
+	if c.FloodCooldownMax < c.FloodCooldown {
+		errs.add("FLOOD_COOLDOWN_MAX", "должен быть не меньше FLOOD_COOLDOWN")
+	}
+	if c.Backup.Keep < 0 {
+		errs.add("BACKUP_KEEP", "должен быть целым числом от 0, получено %d", c.Backup.Keep)
+	}
+
+	return errs.err()
+}
+
+// FieldError - ошибка в одной настройке
+type FieldError struct {
+	Name    string // имя переменной окружения
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return e.Name + ": " + e.Message
+}
+
+// Errors - все ошибки настроек сразу, чтобы не исправлять их по одной за запуск
+type Errors []error
+
+func (e Errors) Error() string {
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = "  - " + err.Error()
+	}
+	return fmt.Sprintf("ошибок в настройках: %d\n%s", len(e), strings.Join(lines, "\n"))
+}
+
+// Unwrap - для errors.Is и errors.As
+func (e Errors) Unwrap() []error {
+	return e
+}
+
+// add - добавляет ошибку настройки name
+func (e *Errors) add(name, format string, args ...any) {
+	*e = append(*e, &FieldError{Name: name, Message: fmt.Sprintf(format, args...)})
+}
+
+// err - nil, если ошибок нет
+func (e Errors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// contains - есть ли s в списке
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
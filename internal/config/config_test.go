@@ -0,0 +1,162 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+// writeFile - файл настроек во временном каталоге теста
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// fieldNames - имена настроек с ошибками, по алфавиту
+func fieldNames(t *testing.T, err error) []string {
+	t.Helper()
+	var errs Errors
+	if err != nil && !errors.As(err, &errs) {
+		t.Fatalf("ожидались ошибки настроек, получено %v", err)
+	}
+	var names []string
+	for _, e := range errs {
+		var fe *FieldError
+		if !errors.As(e, &fe) {
+			t.Fatalf("ошибка без имени настройки: %v", e)
+		}
+		names = append(names, fe.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TestLoadPriority - значение берется из самого приоритетного источника:
+// умолчание < файл < окружение < флаг, и источник виден в config print
+func TestLoadPriority(t *testing.T) {
+	path := writeFile(t, "rate_limit: 10\nlog_level: warn\nflood_cooldown: 5m\napi_admin_keys: [a, b]\n")
+	t.Setenv("LOG_LEVEL", "error")
+	t.Setenv("FLOOD_COOLDOWN", "")
+
+	cfg, err := Load([]string{"-config", path, "-telegram-id", "42", "-log-level", "debug"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		env    string
+		got    any
+		want   any
+		source string
+	}{
+		{"CURRENCY", cfg.Currency, "RUB", sourceDefault},
+		{"RATE_LIMIT", cfg.RateLimit, 10, sourceFile},
+		{"FLOOD_COOLDOWN", cfg.FloodCooldown, 5 * time.Minute, sourceFile}, // пустая переменная не перекрывает файл
+		{"LOG_LEVEL", cfg.LogLevel, "debug", sourceFlag},
+		{"TELEGRAM_ID", cfg.AdminID, int64(42), sourceFlag},
+		{"ORDERS_CHAT_ID", cfg.OrdersChatID, int64(42), sourceDefault}, // по умолчанию заказы приходят админу
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, ожидалось %v", tt.env, tt.got, tt.want)
+		}
+		if source := cfg.sources[tt.env]; source != tt.source {
+			t.Errorf("%s взято из %s, ожидалось %s", tt.env, source, tt.source)
+		}
+	}
+	if len(cfg.APIAdminKeys) != 2 || cfg.APIAdminKeys[1] != "b" {
+		t.Errorf("API_ADMIN_KEYS = %v, ожидалось [a b]", cfg.APIAdminKeys)
+	}
+}
+
+// TestLoadErrors - ошибки разбора собираются все сразу, с именем настройки
+func TestLoadErrors(t *testing.T) {
+	path := writeFile(t, "rate_limit: many\nunknown_key: 1\n")
+	t.Setenv("FLOOD_COOLDOWN", "minute")
+
+	cfg, err := Load([]string{"-config", path, "-backup-gzip", "yes please"})
+	if cfg == nil {
+		t.Fatalf("настройки не вернулись вместе с ошибками: %v", err)
+	}
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 4 {
+		t.Fatalf("ожидалось 4 ошибки, получено %v", err)
+	}
+
+	if _, err := Load([]string{"-no-such-flag"}); err == nil {
+		t.Fatal("неизвестный флаг не дал ошибки")
+	}
+}
+
+// TestValidate - проверки смысла значений
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(c *Config)
+		want   []string // настройки с ошибками
+	}{
+		{name: "рабочие настройки", change: func(c *Config) {}},
+		{name: "без токена и админа", change: func(c *Config) { c.TelegramToken, c.AdminID = "", 0 }, want: []string{"TELEGRAM_ID", "TELEGRAM_TOKEN"}},
+		{name: "валюта строчными", change: func(c *Config) { c.Currency = "usd" }, want: []string{"CURRENCY"}},
+		{name: "валюта не RUB", change: func(c *Config) { c.Currency = "USD" }},
+		{name: "неизвестный режим", change: func(c *Config) { c.BotMode = "push" }, want: []string{"BOT_MODE"}},
+		{name: "webhook без https и секрета", change: func(c *Config) {
+			c.BotMode, c.WebhookURL = ModeWebhook, "http://example.com/hook"
+		}, want: []string{"WEBHOOK_SECRET", "WEBHOOK_URL"}},
+		{name: "webhook с неверным секретом", change: func(c *Config) {
+			c.BotMode, c.WebhookURL, c.WebhookSecret = ModeWebhook, "https://example.com/hook", "s e c r e t"
+		}, want: []string{"WEBHOOK_SECRET"}},
+		{name: "webhook", change: func(c *Config) {
+			c.BotMode, c.WebhookURL, c.WebhookSecret = ModeWebhook, "https://example.com/hook", "s3cret_token-1"
+		}},
+		{name: "скидка за друга 0%", change: func(c *Config) { c.ReferralRewardPercent = 0 }, want: []string{"REFERRAL_REWARD_PERCENT"}},
+		{name: "скидка за друга больше 100%", change: func(c *Config) { c.ReferralRewardPercent = 101 }, want: []string{"REFERRAL_REWARD_PERCENT"}},
+		{name: "скидка за друга 100%", change: func(c *Config) { c.ReferralRewardPercent = 100 }},
+		{name: "скидка за друга 0% без приглашений", change: func(c *Config) {
+			c.ReferralRewardPercent, c.Features.Referrals = 0, false
+		}},
+		{name: "кешбэк вне 0..100", change: func(c *Config) {
+			c.LoyaltyEarnPercent, c.LoyaltyMaxSpendPercent = -1, 150
+		}, want: []string{"LOYALTY_EARN_PERCENT", "LOYALTY_MAX_SPEND_PERCENT"}},
+		{name: "пауза за флуд", change: func(c *Config) {
+			c.FloodCooldown, c.FloodCooldownMax = time.Hour, time.Minute
+		}, want: []string{"FLOOD_COOLDOWN_MAX"}},
+		{name: "неизвестный уровень журнала", change: func(c *Config) { c.LogLevel = "trace" }, want: []string{"LOG_LEVEL"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Load([]string{"-telegram-token", "token", "-telegram-id", "1"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.change(cfg)
+			got := fieldNames(t, cfg.Validate())
+			if len(got) != len(tt.want) {
+				t.Fatalf("ошибки в %v, ожидались в %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("ошибки в %v, ожидались в %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// TestLoadValidSkipsUnparsed - значение, которое не разобралось, второй раз в проверке не ругается
+func TestLoadValidSkipsUnparsed(t *testing.T) {
+	t.Setenv("TELEGRAM_TOKEN", "token")
+	t.Setenv("TELEGRAM_ID", "not a number")
+
+	_, err := LoadValid(nil)
+	if got := fieldNames(t, err); len(got) != 1 || got[0] != "TELEGRAM_ID" {
+		t.Fatalf("ошибки в %v, ожидалась одна в TELEGRAM_ID", got)
+	}
+}
@@ -0,0 +1,277 @@
+// loader.go - чтение настроек из всех источников по тегам полей Config.
+// Поля перебираются через reflect, поэтому новая настройка - это одно поле с тегом env
+// (и default, если нужно): она сразу читается из файла, окружения и флагов и видна в config print.
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Откуда взято значение
+const (
+	sourceDefault = "default"
+	sourceFile    = "file"
+	sourceEnv     = "env"
+	sourceFlag    = "flag"
+)
+
+// field - одна настройка: поле Config и его теги
+type field struct {
+	env    string // имя переменной окружения
+	def    string // значение по умолчанию
+	secret bool   // при печати скрывается
+	value  reflect.Value
+}
+
+// fileKey - ключ в файле настроек
+func (f field) fileKey() string {
+	return strings.ToLower(f.env)
+}
+
+// flagName - имя флага
+func (f field) flagName() string {
+	return strings.ReplaceAll(f.fileKey(), "_", "-")
+}
+
+// fields - все настройки cfg по порядку объявления, включая вложенные структуры (Backup, Features)
+func fields(cfg *Config) []field {
+	var list []field
+	var walk func(v reflect.Value)
+	walk = func(v reflect.Value) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			env, ok := sf.Tag.Lookup("env")
+			if !ok {
+				if sf.Type.Kind() == reflect.Struct {
+					walk(v.Field(i))
+				}
+				continue
+			}
+			list = append(list, field{
+				env:    env,
+				def:    sf.Tag.Get("default"),
+				secret: sf.Tag.Get("secret") == "true",
+				value:  v.Field(i),
+			})
+		}
+	}
+	walk(reflect.ValueOf(cfg).Elem())
+	return list
+}
+
+// load - заполняет cfg: умолчания, файл, окружение, флаги
+func load(cfg *Config, args []string) error {
+	// .env необязателен: в Docker переменные приходят через --env-file или environment
+	godotenv.Load()
+
+	list := fields(cfg)
+
+	// 1. Флаги: -config и по флагу на каждую настройку
+	fs := flag.NewFlagSet("salle_parfume", flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "файл настроек YAML (CONFIG_FILE)")
+	flagValues := make(map[string]*string, len(list))
+	for _, f := range list {
+		flagValues[f.env] = fs.String(f.flagName(), "", f.env)
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("лишние аргументы: %s", strings.Join(fs.Args(), " "))
+	}
+	setFlags := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+
+	var errs Errors
+
+	// 2. Файл настроек
+	fileValues := make(map[string]string)
+	if *configFile != "" {
+		values, err := readFile(*configFile)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("файл настроек %s: %w", *configFile, err))
+		}
+		fileValues = values
+		known := make(map[string]bool, len(list))
+		for _, f := range list {
+			known[f.fileKey()] = true
+		}
+		for _, key := range sortedKeys(fileValues) {
+			if !known[key] {
+				errs = append(errs, fmt.Errorf("файл настроек %s: неизвестный ключ %q", *configFile, key))
+			}
+		}
+	}
+
+	// 3. Каждое значение берем из самого приоритетного источника
+	cfg.sources = make(map[string]string, len(list))
+	for _, f := range list {
+		raw, source := f.def, sourceDefault
+		if v, ok := fileValues[f.fileKey()]; ok {
+			raw, source = v, sourceFile
+		}
+		if v := os.Getenv(f.env); v != "" {
+			raw, source = v, sourceEnv
+		}
+		if setFlags[f.flagName()] {
+			raw, source = *flagValues[f.env], sourceFlag
+		}
+		cfg.sources[f.env] = source
+		if err := setValue(f.value, raw); err != nil {
+			errs.add(f.env, "%v", err)
+		}
+	}
+	return errs.err()
+}
+
+// readFile - файл YAML с плоскими ключами (telegram_token: ..., backup_keep: 7).
+// Списки можно писать списками YAML, логины админки - словарем логин: пароль.
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(raw))
+	for key, v := range raw {
+		values[strings.ToLower(key)] = fileValue(v)
+	}
+	return values, nil
+}
+
+// fileValue - значение из YAML в том же виде, что и в переменной окружения
+func fileValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = fileValue(item)
+		}
+		return strings.Join(items, ",")
+	case map[string]any:
+		items := make([]string, 0, len(v))
+		for _, key := range sortedKeys(v) {
+			items = append(items, key+":"+fileValue(v[key]))
+		}
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// durationType - time.Duration отличаем от int64
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setValue - разбирает строку в поле по его типу. Пустая строка - нулевое значение.
+func setValue(v reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+	switch {
+	case v.Type() == durationType:
+		if raw == "" {
+			v.SetInt(0)
+			return nil
+		}
+		d, err := time.ParseDuration(raw)
+		if err != nil || d < 0 {
+			return fmt.Errorf("ожидается длительность, например 24h или 90m, получено %q", raw)
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(raw)
+	case v.Kind() == reflect.Int || v.Kind() == reflect.Int64:
+		if raw == "" {
+			v.SetInt(0)
+			return nil
+		}
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("ожидается целое число, получено %q", raw)
+		}
+		v.SetInt(n)
+	case v.Kind() == reflect.Float64:
+		if raw == "" {
+			v.SetFloat(0)
+			return nil
+		}
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("ожидается число, получено %q", raw)
+		}
+		v.SetFloat(n)
+	case v.Kind() == reflect.Bool:
+		if raw == "" {
+			v.SetBool(false)
+			return nil
+		}
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("ожидается true или false, получено %q", raw)
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Slice:
+		v.Set(reflect.ValueOf(splitList(raw)))
+	case v.Kind() == reflect.Map:
+		users, err := parseUsers(raw)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(users))
+	default:
+		return fmt.Errorf("неподдерживаемый тип %s", v.Type())
+	}
+	return nil
+}
+
+// splitList - список значений через запятую, пустые элементы пропускаются
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// parseUsers - пары логин:пароль через запятую
+func parseUsers(s string) (map[string]string, error) {
+	users := make(map[string]string)
+	for _, item := range splitList(s) {
+		login, password, ok := strings.Cut(item, ":")
+		login = strings.TrimSpace(login)
+		if !ok || login == "" || password == "" {
+			return nil, fmt.Errorf("ожидается логин:пароль, получено %q", login)
+		}
+		users[login] = password
+	}
+	return users, nil
+}
+
+// sortedKeys - ключи словаря по алфавиту
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,52 @@
+// print.go - команда config print: итоговые настройки в формате .env с источником каждого значения.
+// Токены, ключи и пароли не печатаются, видно только, заданы ли они.
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// redacted - вместо заданного секрета
+const redacted = "***"
+
+// Print - печатает настройки в w
+func (c *Config) Print(w io.Writer) {
+	for _, f := range fields(c) {
+		value := formatValue(f.value)
+		if f.secret && value != "" {
+			value = redacted
+		}
+		if strings.ContainsAny(value, " #\"'") {
+			value = strconv.Quote(value)
+		}
+		fmt.Fprintf(w, "%s=%s # %s\n", f.env, value, c.sources[f.env])
+	}
+}
+
+// formatValue - значение поля в том виде, в каком его можно записать в переменную окружения
+func formatValue(v reflect.Value) string {
+	switch {
+	case v.Type() == durationType:
+		d := time.Duration(v.Int())
+		if d == 0 {
+			return "0"
+		}
+		return d.String()
+	case v.Kind() == reflect.Slice:
+		return strings.Join(v.Interface().([]string), ",")
+	case v.Kind() == reflect.Map:
+		users := v.Interface().(map[string]string)
+		items := make([]string, 0, len(users))
+		for _, login := range sortedKeys(users) {
+			items = append(items, login+":"+users[login])
+		}
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
@@ -0,0 +1,246 @@
+// admin.go — методы админа: товары и статусы заказов
+package http
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"salle_parfume/internal/domain"
+)
+
+// auditActorAPI - кто в журнале действий, если изменение пришло с админским ключом API
+const auditActorAPI = "api"
+
+// productRequest - товар от админа. Фото - это file_id уже загруженных в Телеграм картинок.
+type productRequest struct {
+	SKU         string       `json:"sku"`
+	Type        string       `json:"type"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Price       domain.Money `json:"price"`
+	ImageIDs    []string     `json:"image_ids"`
+	Weight      int          `json:"weight"` // Граммы, 0 - не указан
+	Stock       *int         `json:"stock"`  // Штуки, null - остаток не ведется
+}
+
+// orderStatusRequest - новый статус заказа
+type orderStatusRequest struct {
+	Status domain.OrderStatus `json:"status"`
+}
+
+// checkCurrency - цена только в валюте магазина (пусто - она и есть): товар в другой валюте
+// нельзя сложить с остальными в корзине
+func (req *productRequest) checkCurrency() error {
+	if req.Price.Currency != "" && req.Price.Currency != domain.DefaultCurrency {
+		return fmt.Errorf("цена должна быть в валюте магазина %s, а не %q", domain.DefaultCurrency, req.Price.Currency)
+	}
+	return nil
+}
+
+// apply - проверяет запрос и переносит поля в товар
+func (req *productRequest) apply(p *domain.Product) error {
+	t, err := domain.ParseProductType(req.Type)
+	if err != nil {
+		return err
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return fmt.Errorf("не указано название")
+	}
+	price := req.Price
+	if price.Currency == "" {
+		price.Currency = domain.DefaultCurrency
+	}
+	if err := domain.ValidatePrice(price); err != nil {
+		return err
+	}
+	if err := domain.ValidateWeight(req.Weight); err != nil {
+		return err
+	}
+	if err := domain.ValidateStock(req.Stock); err != nil {
+		return err
+	}
+	if len(req.ImageIDs) > domain.MaxProductImages {
+		return fmt.Errorf("больше %d фото добавить нельзя", domain.MaxProductImages)
+	}
+
+	p.SKU = strings.TrimSpace(req.SKU)
+	p.Type = t
+	p.Name = name
+	p.Description = req.Description
+	p.Price = price
+	p.Weight = req.Weight
+	p.Stock = req.Stock
+	if req.ImageIDs != nil {
+		p.Images = req.ImageIDs
+	}
+	return nil
+}
+
+// handleCreateProduct - POST /api/v1/admin/products
+func (h *Handler) handleCreateProduct(w http.ResponseWriter, r *http.Request) {
+	var req productRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if err := req.checkCurrency(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	product := &domain.Product{}
+	if err := req.apply(product); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	// Без фото карточку товара в боте не показать
+	if len(product.Images) == 0 {
+		writeError(w, http.StatusUnprocessableEntity, "нужно хотя бы одно фото")
+		return
+	}
+	if product.SKU != "" {
+		existing, err := h.repo.GetProductBySKU(product.SKU)
+		if err != nil {
+			writeInternalError(w, "get product by sku", err)
+			return
+		}
+		if existing != nil {
+			writeError(w, http.StatusConflict, "товар с таким артикулом уже есть")
+			return
+		}
+	}
+
+	if err := h.repo.CreateProduct(product); err != nil {
+		writeInternalError(w, "create product", err)
+		return
+	}
+	h.audit(domain.AuditProductCreate, domain.AuditObjectProduct, product.ID, fmt.Sprintf("%s (%s), %s", product.Name, product.SKU, product.Price))
+	h.writeProduct(w, http.StatusCreated, product)
+}
+
+// handleUpdateProduct - PUT /api/v1/admin/products/{id}.
+// Если image_ids не передан, фото товара не меняются.
+func (h *Handler) handleUpdateProduct(w http.ResponseWriter, r *http.Request) {
+	product, ok := h.getProduct(w, r)
+	if !ok {
+		return
+	}
+	var req productRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if err := req.checkCurrency(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.ImageIDs != nil && len(req.ImageIDs) == 0 {
+		writeError(w, http.StatusUnprocessableEntity, "нужно хотя бы одно фото")
+		return
+	}
+	// Пустой артикул в запросе - оставляем старый
+	if strings.TrimSpace(req.SKU) == "" {
+		req.SKU = product.SKU
+	}
+	old := *product
+	oldSKU := product.SKU
+	product.Images = nil
+	if err := req.apply(product); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	if product.SKU != oldSKU {
+		existing, err := h.repo.GetProductBySKU(product.SKU)
+		if err != nil {
+			writeInternalError(w, "get product by sku", err)
+			return
+		}
+		if existing != nil && existing.ID != product.ID {
+			writeError(w, http.StatusConflict, "товар с таким артикулом уже есть")
+			return
+		}
+	}
+
+	if err := h.repo.UpdateProduct(product); err != nil {
+		writeInternalError(w, "update product", err)
+		return
+	}
+
+	updated, err := h.repo.GetProductByID(product.ID)
+	if err != nil || updated == nil {
+		writeInternalError(w, "get product", fmt.Errorf("reload product %d: %v", product.ID, err))
+		return
+	}
+	if changes := domain.ProductChanges(&old, updated); len(changes) > 0 {
+		h.audit(domain.AuditProductUpdate, domain.AuditObjectProduct, product.ID, strings.Join(changes, "; "))
+	}
+	h.writeProduct(w, http.StatusOK, updated)
+}
+
+// writeProduct - отвечает товаром в том же виде, что и публичный каталог
+func (h *Handler) writeProduct(w http.ResponseWriter, status int, product *domain.Product) {
+	resp, err := h.newProductResponse(product)
+	if err != nil {
+		writeInternalError(w, "get product rating", err)
+		return
+	}
+	writeJSON(w, status, resp)
+}
+
+// handleAdminGetOrder - GET /api/v1/admin/orders/{id}: любой заказ
+func (h *Handler) handleAdminGetOrder(w http.ResponseWriter, r *http.Request) {
+	order, ok := h.getOrder(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, order)
+}
+
+// handleSetOrderStatus - PUT /api/v1/admin/orders/{id}/status
+func (h *Handler) handleSetOrderStatus(w http.ResponseWriter, r *http.Request) {
+	order, ok := h.getOrder(w, r)
+	if !ok {
+		return
+	}
+	var req orderStatusRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if !req.Status.Valid() {
+		writeError(w, http.StatusUnprocessableEntity, "неизвестный статус заказа")
+		return
+	}
+
+	// Тот же статус еще раз - ничего не меняем (PUT можно повторять)
+	if req.Status == order.Status {
+		writeJSON(w, http.StatusOK, order)
+		return
+	}
+	// Те же переходы, что в боте и админке: иначе повторная отмена вернула бы товары и бонусы еще раз
+	if !order.Status.CanBecome(req.Status) {
+		writeError(w, http.StatusConflict, fmt.Sprintf("заказ в статусе %s нельзя перевести в %s", order.Status, req.Status))
+		return
+	}
+
+	err := h.repo.UpdateOrderStatus(order.ID, req.Status)
+	if errors.Is(err, domain.ErrStatusChange) {
+		writeError(w, http.StatusConflict, "статус заказа только что изменился, запросите заказ заново")
+		return
+	}
+	if err != nil {
+		writeInternalError(w, "update order status", err)
+		return
+	}
+	h.audit(domain.AuditOrderStatus, domain.AuditObjectOrder, order.ID, fmt.Sprintf("%s → %s", order.Status, req.Status))
+	order.Status = req.Status
+	writeJSON(w, http.StatusOK, order)
+}
+
+// audit - записывает изменение через API в журнал действий админов (тот же, что у веб-админки)
+func (h *Handler) audit(action, object string, objectID int64, details string) {
+	entry := &domain.AuditEntry{Actor: auditActorAPI, Action: action, Object: object, ObjectID: objectID, Details: details}
+	if err := h.repo.AddAuditEntry(entry); err != nil {
+		log.Printf("Error adding audit entry %s %s %d from HTTP API: %v", action, object, objectID, err)
+	}
+}
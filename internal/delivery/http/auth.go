@@ -0,0 +1,126 @@
+// auth.go — доступ к закрытым методам API: ключи сайта и админа, initData Mini App
+package http
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"salle_parfume/internal/domain"
+)
+
+// customerHeader - заголовок с Телеграм ID покупателя, от имени которого сайт делает запрос
+const customerHeader = "X-Customer-ID"
+
+// apiKeys - ключи доступа: админские и ключи сайта
+type apiKeys struct {
+	admin  []string
+	client []string
+}
+
+func newAPIKeys(admin, client []string) *apiKeys {
+	return &apiKeys{admin: admin, client: client}
+}
+
+// requestKey - ключ из заголовка X-API-Key или Authorization: Bearer <ключ>
+func requestKey(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+// contains - есть ли ключ в списке. Сравнение за постоянное время, чтобы ключ нельзя было подобрать по времени ответа.
+func contains(keys []string, key string) bool {
+	found := false
+	for _, k := range keys {
+		if subtle.ConstantTimeCompare([]byte(k), []byte(key)) == 1 {
+			found = true
+		}
+	}
+	return found
+}
+
+func (k *apiKeys) isAdmin(key string) bool {
+	return key != "" && contains(k.admin, key)
+}
+
+// isClient - ключ сайта. Админский ключ тоже подходит.
+func (k *apiKeys) isClient(key string) bool {
+	return key != "" && (contains(k.client, key) || contains(k.admin, key))
+}
+
+// requireAdmin - пропускает только запросы с ключом админа
+func (h *Handler) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !h.keys.isAdmin(requestKey(r)) {
+			writeError(w, http.StatusUnauthorized, "нужен ключ API администратора")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// requestInitData - initData Mini App из заголовка Authorization: tma <initData> или X-Telegram-Init-Data
+func requestInitData(r *http.Request) string {
+	if data, ok := strings.CutPrefix(r.Header.Get("Authorization"), "tma "); ok {
+		return strings.TrimSpace(data)
+	}
+	return r.Header.Get("X-Telegram-Init-Data")
+}
+
+// requireCustomer - запросы от имени покупателя. Покупатель определяется одним из способов:
+//   - Mini App: initData с подписью Телеграма;
+//   - сайт: ключ сайта и Телеграм ID покупателя в X-Customer-ID.
+func (h *Handler) requireCustomer(next func(w http.ResponseWriter, r *http.Request, chatID int64)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if initData := requestInitData(r); initData != "" {
+			user, err := parseInitData(initData, h.botToken, h.now())
+			if err != nil {
+				writeError(w, http.StatusUnauthorized, err.Error())
+				return
+			}
+			if err := h.ensureUser(user); err != nil {
+				writeInternalError(w, "save webapp user", err)
+				return
+			}
+			// Mini App открывается из лички с ботом, там ID чата совпадает с ID пользователя
+			next(w, r, user.ID)
+			return
+		}
+
+		if !h.keys.isClient(requestKey(r)) {
+			writeError(w, http.StatusUnauthorized, "нужен ключ API")
+			return
+		}
+		chatID, err := strconv.ParseInt(r.Header.Get(customerHeader), 10, 64)
+		if err != nil || chatID == 0 {
+			writeError(w, http.StatusBadRequest, "укажите Телеграм ID покупателя в заголовке "+customerHeader)
+			return
+		}
+		next(w, r, chatID)
+	}
+}
+
+// ensureUser - заводит покупателя из Mini App в таблице users, если он еще не писал боту
+func (h *Handler) ensureUser(u *webAppUser) error {
+	existing, err := h.repo.GetUserByChatID(u.ID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return nil
+	}
+	if err := h.repo.CreateUser(&domain.User{ChatID: u.ID, Username: u.Username, FirstName: u.FirstName}); err != nil {
+		// Два запроса Mini App могли прийти одновременно, и пользователя уже создал соседний
+		if again, getErr := h.repo.GetUserByChatID(u.ID); getErr == nil && again != nil {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
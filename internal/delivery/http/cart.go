@@ -0,0 +1,145 @@
+// cart.go — корзина покупателя: та же корзина, что и в боте
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"salle_parfume/internal/domain"
+	"salle_parfume/internal/service"
+)
+
+// maxCartQuantity - больше этого количества одного товара в корзину не кладем
+const maxCartQuantity = 99
+
+// cartItemRequest - тело запроса на добавление или изменение строки корзины
+type cartItemRequest struct {
+	ProductID int64 `json:"product_id"`
+	Quantity  int   `json:"quantity"`
+}
+
+// clientErrors - ошибки, которые вызваны запросом покупателя, а не сбоем сервера.
+// Их текст отдаем как есть с кодом 422.
+var clientErrors = []error{
+	service.ErrPromoNotFound,
+	service.ErrPromoInactive,
+	service.ErrPromoNotApplicable,
+	service.ErrPromoMinOrder,
+	service.ErrPromoUsageLimit,
+	service.ErrPromoUserLimit,
+	service.ErrCartEmpty,
+	service.ErrDeliveryRequired,
+	service.ErrDeliveryMethod,
+	service.ErrDeliveryAddress,
+	service.ErrDeliveryAddressTooLong,
+	service.ErrOutOfStock,
+	domain.ErrNotEnoughPoints,
+}
+
+// writeServiceError - ответ на ошибку сервиса: понятная покупателю ошибка или 500
+func writeServiceError(w http.ResponseWriter, context string, err error) {
+	for _, e := range clientErrors {
+		if errors.Is(err, e) {
+			writeError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+	}
+	writeInternalError(w, context, err)
+}
+
+// writeQuote - отвечает расчетом корзины покупателя (промокод - из параметра promo_code)
+func (h *Handler) writeQuote(w http.ResponseWriter, r *http.Request, chatID int64) {
+	quote, err := h.orders.Quote(chatID, r.URL.Query().Get("promo_code"))
+	if err != nil {
+		writeServiceError(w, "calculate cart", err)
+		return
+	}
+	if quote.Lines == nil {
+		quote.Lines = []domain.QuoteLine{}
+	}
+	writeJSON(w, http.StatusOK, quote)
+}
+
+// handleGetCart - GET /api/v1/cart[?promo_code=SALE]: корзина с расчетом скидок
+func (h *Handler) handleGetCart(w http.ResponseWriter, r *http.Request, chatID int64) {
+	h.writeQuote(w, r, chatID)
+}
+
+// handleAddCartItem - POST /api/v1/cart/items: добавить товар (или увеличить количество)
+func (h *Handler) handleAddCartItem(w http.ResponseWriter, r *http.Request, chatID int64) {
+	var req cartItemRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Quantity == 0 {
+		req.Quantity = 1
+	}
+	if req.Quantity < 0 || req.Quantity > maxCartQuantity {
+		writeError(w, http.StatusBadRequest, "некорректное количество")
+		return
+	}
+
+	product, err := h.repo.GetProductByID(req.ProductID)
+	if err != nil {
+		writeInternalError(w, "get product", err)
+		return
+	}
+	if product == nil {
+		writeError(w, http.StatusNotFound, "товар не найден")
+		return
+	}
+	if !product.InStock() {
+		writeError(w, http.StatusUnprocessableEntity, "товара нет в наличии")
+		return
+	}
+
+	if err := h.repo.AddToCart(chatID, product.ID, req.Quantity); err != nil {
+		writeInternalError(w, "add to cart", err)
+		return
+	}
+	h.writeQuote(w, r, chatID)
+}
+
+// handleSetCartItem - PUT /api/v1/cart/items/{productID}: задать количество (0 - убрать)
+func (h *Handler) handleSetCartItem(w http.ResponseWriter, r *http.Request, chatID int64) {
+	productID, ok := pathID(w, r, "productID")
+	if !ok {
+		return
+	}
+	var req cartItemRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Quantity < 0 || req.Quantity > maxCartQuantity {
+		writeError(w, http.StatusBadRequest, "некорректное количество")
+		return
+	}
+
+	if err := h.repo.SetCartQuantity(chatID, productID, req.Quantity); err != nil {
+		writeInternalError(w, "update cart", err)
+		return
+	}
+	h.writeQuote(w, r, chatID)
+}
+
+// handleDeleteCartItem - DELETE /api/v1/cart/items/{productID}
+func (h *Handler) handleDeleteCartItem(w http.ResponseWriter, r *http.Request, chatID int64) {
+	productID, ok := pathID(w, r, "productID")
+	if !ok {
+		return
+	}
+	if err := h.repo.SetCartQuantity(chatID, productID, 0); err != nil {
+		writeInternalError(w, "remove from cart", err)
+		return
+	}
+	h.writeQuote(w, r, chatID)
+}
+
+// handleClearCart - DELETE /api/v1/cart
+func (h *Handler) handleClearCart(w http.ResponseWriter, r *http.Request, chatID int64) {
+	if err := h.repo.ClearCart(chatID); err != nil {
+		writeInternalError(w, "clear cart", err)
+		return
+	}
+	h.writeQuote(w, r, chatID)
+}
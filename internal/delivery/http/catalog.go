@@ -0,0 +1,345 @@
+// catalog.go — каталог: список товаров с фильтрами, карточка, фото и отзывы
+package http
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"salle_parfume/internal/domain"
+)
+
+// httpClientTimeout - сколько ждем Телеграм при скачивании фото
+const httpClientTimeout = 30 * time.Second
+
+// productResponse - товар для сайта. Вместо file_id Телеграма - ссылки на фото в этом API.
+type productResponse struct {
+	ID          int64                `json:"id"`
+	SKU         string               `json:"sku"`
+	Type        domain.ProductType   `json:"type"`
+	Name        string               `json:"name"`
+	Description string               `json:"description"`
+	Price       domain.Money         `json:"price"`
+	Images      []string             `json:"images"`
+	Rating      domain.ProductRating `json:"rating"`
+	InStock     bool                 `json:"in_stock"` // Точный остаток покупателям не показываем
+}
+
+// reviewResponse - опубликованный отзыв без служебных полей
+type reviewResponse struct {
+	ID         int64     `json:"id"`
+	AuthorName string    `json:"author_name"`
+	Rating     int       `json:"rating"`
+	Text       string    `json:"text"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// newProductResponse - собирает товар для ответа вместе с рейтингом
+func (h *Handler) newProductResponse(p *domain.Product) (productResponse, error) {
+	rating, err := h.repo.GetProductRating(p.ID)
+	if err != nil {
+		return productResponse{}, err
+	}
+
+	images := make([]string, len(p.Gallery()))
+	for i := range images {
+		images[i] = fmt.Sprintf("/api/v1/products/%d/images/%d", p.ID, i)
+	}
+
+	return productResponse{
+		ID:          p.ID,
+		SKU:         p.SKU,
+		Type:        p.Type,
+		Name:        p.Name,
+		Description: p.Description,
+		Price:       p.Price,
+		Images:      images,
+		Rating:      rating,
+		InStock:     p.InStock(),
+	}, nil
+}
+
+// productFilter - фильтры и сортировка каталога из параметров запроса
+type productFilter struct {
+	Type     domain.ProductType // Категория (пусто - все)
+	Query    string             // Поиск по названию и описанию без учета регистра
+	MinPrice int64              // Цена от, в копейках (0 - без ограничения)
+	MaxPrice int64              // Цена до, в копейках (0 - без ограничения)
+	Sort     string             // Сортировка: new, price, -price, name, rating
+	Limit    int
+	Offset   int
+}
+
+// productSorts - допустимые значения sort
+var productSorts = map[string]bool{"new": true, "price": true, "-price": true, "name": true, "rating": true}
+
+// parseProductFilter - читает фильтры из параметров запроса
+func parseProductFilter(r *http.Request) (productFilter, error) {
+	q := r.URL.Query()
+	f := productFilter{Query: strings.ToLower(strings.TrimSpace(q.Get("q"))), Sort: q.Get("sort")}
+
+	if s := q.Get("type"); s != "" {
+		t, err := domain.ParseProductType(s)
+		if err != nil {
+			return f, err
+		}
+		f.Type = t
+	}
+	for name, dst := range map[string]*int64{"min_price": &f.MinPrice, "max_price": &f.MaxPrice} {
+		if s := q.Get(name); s != "" {
+			v, err := strconv.ParseInt(s, 10, 64)
+			if err != nil || v < 0 {
+				return f, fmt.Errorf("некорректный параметр %s", name)
+			}
+			*dst = v
+		}
+	}
+	if f.Sort == "" {
+		f.Sort = "new"
+	}
+	if !productSorts[f.Sort] {
+		return f, fmt.Errorf("некорректный параметр sort")
+	}
+
+	var err error
+	if f.Limit, err = queryInt(r, "limit", 100, 500); err != nil {
+		return f, err
+	}
+	if f.Offset, err = queryInt(r, "offset", 0, 1<<30); err != nil {
+		return f, err
+	}
+	return f, nil
+}
+
+// match - подходит ли товар под фильтр
+func (f *productFilter) match(p *domain.Product) bool {
+	if f.Type != "" && p.Type != f.Type {
+		return false
+	}
+	if f.MinPrice > 0 && p.Price.Amount < f.MinPrice {
+		return false
+	}
+	if f.MaxPrice > 0 && p.Price.Amount > f.MaxPrice {
+		return false
+	}
+	if f.Query != "" && !strings.Contains(strings.ToLower(p.Name+" "+p.Description), f.Query) {
+		return false
+	}
+	return true
+}
+
+// sort - сортирует товары. При равенстве - новые первыми, чтобы порядок страниц не прыгал.
+func (f *productFilter) sort(products []productResponse) {
+	sort.SliceStable(products, func(i, j int) bool {
+		a, b := products[i], products[j]
+		switch f.Sort {
+		case "price":
+			if a.Price.Amount != b.Price.Amount {
+				return a.Price.Amount < b.Price.Amount
+			}
+		case "-price":
+			if a.Price.Amount != b.Price.Amount {
+				return a.Price.Amount > b.Price.Amount
+			}
+		case "name":
+			if an, bn := strings.ToLower(a.Name), strings.ToLower(b.Name); an != bn {
+				return an < bn
+			}
+		case "rating":
+			if a.Rating.Average != b.Rating.Average {
+				return a.Rating.Average > b.Rating.Average
+			}
+		}
+		return a.ID > b.ID
+	})
+}
+
+// handleListProducts - GET /api/v1/products: каталог с фильтрами, сортировкой и страницами.
+// Общее число найденных товаров - в заголовке X-Total-Count.
+func (h *Handler) handleListProducts(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseProductFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	products, err := h.repo.GetAllProducts()
+	if err != nil {
+		writeInternalError(w, "get products", err)
+		return
+	}
+
+	result := []productResponse{}
+	for i := range products {
+		if !filter.match(&products[i]) {
+			continue
+		}
+		resp, err := h.newProductResponse(&products[i])
+		if err != nil {
+			writeInternalError(w, "get product rating", err)
+			return
+		}
+		result = append(result, resp)
+	}
+	filter.sort(result)
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(len(result)))
+	start := min(filter.Offset, len(result))
+	end := min(start+filter.Limit, len(result))
+	writeJSON(w, http.StatusOK, result[start:end])
+}
+
+// typeCount - сколько товаров в категории
+type typeCount struct {
+	Type  domain.ProductType `json:"type"`
+	Count int                `json:"count"`
+}
+
+// filtersResponse - что можно выбрать в фильтрах Mini App
+type filtersResponse struct {
+	Types    []typeCount  `json:"types"`
+	MinPrice domain.Money `json:"min_price"`
+	MaxPrice domain.Money `json:"max_price"`
+}
+
+// handleProductFilters - GET /api/v1/products/filters: категории с количеством товаров и диапазон цен
+func (h *Handler) handleProductFilters(w http.ResponseWriter, r *http.Request) {
+	products, err := h.repo.GetAllProducts()
+	if err != nil {
+		writeInternalError(w, "get products", err)
+		return
+	}
+
+	resp := filtersResponse{Types: []typeCount{}}
+	counts := make(map[domain.ProductType]int)
+	for i, p := range products {
+		counts[p.Type]++
+		if i == 0 || p.Price.Less(resp.MinPrice) {
+			resp.MinPrice = p.Price
+		}
+		if i == 0 || resp.MaxPrice.Less(p.Price) {
+			resp.MaxPrice = p.Price
+		}
+	}
+	for _, t := range []domain.ProductType{domain.TypeFemale, domain.TypeMale, domain.TypeUnisex} {
+		if counts[t] > 0 {
+			resp.Types = append(resp.Types, typeCount{Type: t, Count: counts[t]})
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// getProduct - товар из пути запроса. Если товара нет, сам отвечает 404.
+func (h *Handler) getProduct(w http.ResponseWriter, r *http.Request) (*domain.Product, bool) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return nil, false
+	}
+	product, err := h.repo.GetProductByID(id)
+	if err != nil {
+		writeInternalError(w, "get product", err)
+		return nil, false
+	}
+	if product == nil {
+		writeError(w, http.StatusNotFound, "товар не найден")
+		return nil, false
+	}
+	return product, true
+}
+
+// handleGetProduct - GET /api/v1/products/{id}
+func (h *Handler) handleGetProduct(w http.ResponseWriter, r *http.Request) {
+	product, ok := h.getProduct(w, r)
+	if !ok {
+		return
+	}
+	resp, err := h.newProductResponse(product)
+	if err != nil {
+		writeInternalError(w, "get product rating", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleProductImage - GET /api/v1/products/{id}/images/{n}: отдает фото товара.
+// Фото хранятся в Телеграме, поэтому скачиваем его оттуда и передаем клиенту как есть.
+func (h *Handler) handleProductImage(w http.ResponseWriter, r *http.Request) {
+	product, ok := h.getProduct(w, r)
+	if !ok {
+		return
+	}
+	gallery := product.Gallery()
+	n, err := strconv.Atoi(r.PathValue("n"))
+	if err != nil || n < 0 || n >= len(gallery) {
+		writeError(w, http.StatusNotFound, "фото не найдено")
+		return
+	}
+
+	url, err := h.files.GetFileDirectURL(gallery[n])
+	if err != nil {
+		writeInternalError(w, "get file url", err)
+		return
+	}
+	resp, err := h.client.Get(url)
+	if err != nil {
+		// В тексте ошибки есть ссылка с токеном бота - в лог ее не пишем
+		writeInternalError(w, "download image", fmt.Errorf("product %d photo %d: request failed", product.ID, n))
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		writeInternalError(w, "download image", fmt.Errorf("product %d photo %d: status %d", product.ID, n, resp.StatusCode))
+		return
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" || contentType == "application/octet-stream" {
+		contentType = "image/jpeg" // Телеграм хранит фото в JPEG
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		log.Printf("Error sending image from HTTP API: %v", err)
+	}
+}
+
+// handleProductReviews - GET /api/v1/products/{id}/reviews?limit=20&offset=0
+func (h *Handler) handleProductReviews(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	limit, err := queryInt(r, "limit", 20, 100)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	offset, err := queryInt(r, "offset", 0, 1<<30)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	reviews, err := h.repo.GetApprovedReviews(id, limit, offset)
+	if err != nil {
+		writeInternalError(w, "get reviews", err)
+		return
+	}
+
+	result := []reviewResponse{}
+	for _, rv := range reviews {
+		result = append(result, reviewResponse{
+			ID:         rv.ID,
+			AuthorName: rv.AuthorName,
+			Rating:     rv.Rating,
+			Text:       rv.Text,
+			CreatedAt:  rv.CreatedAt,
+		})
+	}
+	writeJSON(w, http.StatusOK, result)
+}
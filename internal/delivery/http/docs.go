@@ -0,0 +1,16 @@
+// docs.go — описание API в формате OpenAPI
+package http
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+// handleOpenAPI - GET /api/v1/openapi.yaml
+func (h *Handler) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml; charset=utf-8")
+	w.Write(openAPISpec)
+}
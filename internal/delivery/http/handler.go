@@ -0,0 +1,162 @@
+// handler.go — HTTP API магазина: каталог, корзина и заказы в JSON.
+// Работает с теми же репозиториями и сервисами, что и телеграм бот,
+// поэтому сайт и бот видят одну и ту же базу. Описание API - openapi.yaml.
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"salle_parfume/internal/domain"
+	"salle_parfume/internal/repository"
+)
+
+// OrderService - интерфейс расчета корзины, доставки и оформления заказа
+type OrderService interface {
+	Quote(chatID int64, code string) (*domain.Quote, error)
+	DeliveryOptions(quote *domain.Quote) ([]domain.DeliveryOption, error)
+	Checkout(chatID int64, code string, delivery domain.DeliveryChoice, usePoints bool) (*domain.Order, error)
+}
+
+// FileURLResolver - ссылка на скачивание файла Телеграма по file_id.
+// Реализует *tgbotapi.BotAPI. В ссылке есть токен бота, поэтому наружу она не отдается.
+type FileURLResolver interface {
+	GetFileDirectURL(fileID string) (string, error)
+}
+
+// maxBodySize - ограничение на размер тела запроса
+const maxBodySize = 1 << 20
+
+// Handler - обработчики HTTP API
+type Handler struct {
+	repo   *repository.Repository // Контейнер интерфейсов репозиториев
+	orders OrderService
+	files  FileURLResolver
+	keys   *apiKeys     // Ключи доступа к закрытым методам
+	client *http.Client // Для скачивания фото товаров из Телеграма
+
+	botToken  string           // Токен бота - им подписаны initData Mini App
+	webAppDir string           // Статика Mini App (пусто - не отдаем)
+	now       func() time.Time // Текущее время (для проверки срока initData)
+}
+
+// NewHandler - создает обработчики API.
+// adminKeys - ключи с полным доступом, clientKeys - ключи сайта (корзина и заказы от имени покупателя).
+// webAppDir - папка с собранным Mini App, ее файлы отдаются с корня сайта.
+func NewHandler(repo *repository.Repository, orders OrderService, files FileURLResolver, botToken string, adminKeys, clientKeys []string, webAppDir string) *Handler {
+	return &Handler{
+		repo:      repo,
+		orders:    orders,
+		files:     files,
+		keys:      newAPIKeys(adminKeys, clientKeys),
+		client:    &http.Client{Timeout: httpClientTimeout},
+		botToken:  botToken,
+		webAppDir: webAppDir,
+		now:       time.Now,
+	}
+}
+
+// Routes - таблица маршрутов API
+func (h *Handler) Routes() http.Handler {
+	mux := http.NewServeMux()
+
+	// Документация
+	mux.HandleFunc("GET /api/v1/openapi.yaml", h.handleOpenAPI)
+
+	// Каталог - открыт для всех
+	mux.HandleFunc("GET /api/v1/products", h.handleListProducts)
+	mux.HandleFunc("GET /api/v1/products/filters", h.handleProductFilters)
+	mux.HandleFunc("GET /api/v1/products/{id}", h.handleGetProduct)
+	mux.HandleFunc("GET /api/v1/products/{id}/images/{n}", h.handleProductImage)
+	mux.HandleFunc("GET /api/v1/products/{id}/reviews", h.handleProductReviews)
+
+	// Корзина и заказы покупателя - initData Mini App или ключ сайта и ID покупателя
+	mux.HandleFunc("GET /api/v1/me", h.requireCustomer(h.handleMe))
+	mux.HandleFunc("GET /api/v1/cart", h.requireCustomer(h.handleGetCart))
+	mux.HandleFunc("POST /api/v1/cart/items", h.requireCustomer(h.handleAddCartItem))
+	mux.HandleFunc("PUT /api/v1/cart/items/{productID}", h.requireCustomer(h.handleSetCartItem))
+	mux.HandleFunc("DELETE /api/v1/cart/items/{productID}", h.requireCustomer(h.handleDeleteCartItem))
+	mux.HandleFunc("DELETE /api/v1/cart", h.requireCustomer(h.handleClearCart))
+	mux.HandleFunc("GET /api/v1/delivery", h.requireCustomer(h.handleDeliveryOptions))
+	mux.HandleFunc("POST /api/v1/orders", h.requireCustomer(h.handleCheckout))
+	mux.HandleFunc("GET /api/v1/orders/{id}", h.requireCustomer(h.handleGetOrder))
+
+	// Управление магазином - только ключ админа
+	mux.HandleFunc("POST /api/v1/admin/products", h.requireAdmin(h.handleCreateProduct))
+	mux.HandleFunc("PUT /api/v1/admin/products/{id}", h.requireAdmin(h.handleUpdateProduct))
+	mux.HandleFunc("GET /api/v1/admin/orders/{id}", h.requireAdmin(h.handleAdminGetOrder))
+	mux.HandleFunc("PUT /api/v1/admin/orders/{id}/status", h.requireAdmin(h.handleSetOrderStatus))
+
+	// Сам Mini App (HTML, JS, CSS)
+	if h.webAppDir != "" {
+		mux.Handle("GET /", http.FileServer(http.Dir(h.webAppDir)))
+	}
+
+	return mux
+}
+
+// errorResponse - тело ответа с ошибкой
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// writeJSON - отправляет ответ в JSON
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Error writing response: %v", err)
+	}
+}
+
+// writeError - ответ с ошибкой. Текст можно показывать пользователю сайта.
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message})
+}
+
+// writeInternalError - пишет ошибку в лог, а клиенту отдает общий текст без подробностей
+func writeInternalError(w http.ResponseWriter, context string, err error) {
+	log.Printf("Error in HTTP API, %s: %v", context, err)
+	writeError(w, http.StatusInternalServerError, "внутренняя ошибка, попробуйте позже")
+}
+
+// decodeJSON - читает тело запроса. Неизвестные поля - ошибка, чтобы опечатки в запросе не терялись молча.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "некорректный JSON: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// pathID - числовой параметр из пути (/products/{id})
+func pathID(w http.ResponseWriter, r *http.Request, name string) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue(name), 10, 64)
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, "некорректный параметр "+name)
+		return 0, false
+	}
+	return id, true
+}
+
+// queryInt - числовой параметр запроса со значением по умолчанию и верхней границей
+func queryInt(r *http.Request, name string, def, max int) (int, error) {
+	s := r.URL.Query().Get(name)
+	if s == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, errors.New("некорректный параметр " + name)
+	}
+	if n > max {
+		n = max
+	}
+	return n, nil
+}
@@ -0,0 +1,82 @@
+// initdata.go — проверка initData, которые Телеграм передает в Mini App.
+// Подпись считается от токена бота, поэтому подделать пользователя без токена нельзя.
+// Алгоритм: https://core.telegram.org/bots/webapps#validating-data-received-via-the-mini-app
+package http
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// initDataMaxAge - сколько действуют initData. Mini App, открытый дольше, нужно перезапустить.
+const initDataMaxAge = 24 * time.Hour
+
+// Ошибки проверки initData
+var (
+	errInitDataInvalid = errors.New("некорректные данные Mini App")
+	errInitDataExpired = errors.New("данные Mini App устарели, откройте магазин заново")
+)
+
+// webAppUser - пользователь Телеграма из initData
+type webAppUser struct {
+	ID           int64  `json:"id"`
+	FirstName    string `json:"first_name"`
+	LastName     string `json:"last_name"`
+	Username     string `json:"username"`
+	LanguageCode string `json:"language_code"`
+}
+
+// parseInitData - проверяет подпись и срок initData и возвращает пользователя
+func parseInitData(raw, botToken string, now time.Time) (*webAppUser, error) {
+	values, err := url.ParseQuery(raw)
+	if err != nil {
+		return nil, errInitDataInvalid
+	}
+	hash := values.Get("hash")
+	if hash == "" {
+		return nil, errInitDataInvalid
+	}
+
+	// Строка для проверки: все поля, кроме hash, в виде key=value по алфавиту через \n
+	pairs := make([]string, 0, len(values))
+	for key := range values {
+		if key == "hash" {
+			continue
+		}
+		pairs = append(pairs, key+"="+values.Get(key))
+	}
+	sort.Strings(pairs)
+
+	// secret = HMAC-SHA256(ключ "WebAppData", токен бота)
+	secret := hmac.New(sha256.New, []byte("WebAppData"))
+	secret.Write([]byte(botToken))
+	mac := hmac.New(sha256.New, secret.Sum(nil))
+	mac.Write([]byte(strings.Join(pairs, "\n")))
+
+	expected, err := hex.DecodeString(hash)
+	if err != nil || !hmac.Equal(mac.Sum(nil), expected) {
+		return nil, errInitDataInvalid
+	}
+
+	authDate, err := strconv.ParseInt(values.Get("auth_date"), 10, 64)
+	if err != nil {
+		return nil, errInitDataInvalid
+	}
+	if now.Sub(time.Unix(authDate, 0)) > initDataMaxAge {
+		return nil, errInitDataExpired
+	}
+
+	var user webAppUser
+	if err := json.Unmarshal([]byte(values.Get("user")), &user); err != nil || user.ID == 0 {
+		return nil, errInitDataInvalid
+	}
+	return &user, nil
+}
@@ -0,0 +1,96 @@
+// orders.go — оформление заказа и просмотр своего заказа покупателем
+package http
+
+import (
+	"log"
+	"net/http"
+
+	"salle_parfume/internal/domain"
+)
+
+// checkoutRequest - тело запроса на оформление заказа
+type checkoutRequest struct {
+	PromoCode string                `json:"promo_code"`
+	Delivery  domain.DeliveryChoice `json:"delivery"`
+	UsePoints bool                  `json:"use_points"` // Оплатить часть заказа бонусами
+}
+
+// handleDeliveryOptions - GET /api/v1/delivery[?promo_code=SALE]: способы доставки с ценой для текущей корзины
+func (h *Handler) handleDeliveryOptions(w http.ResponseWriter, r *http.Request, chatID int64) {
+	quote, err := h.orders.Quote(chatID, r.URL.Query().Get("promo_code"))
+	if err != nil {
+		writeServiceError(w, "calculate cart", err)
+		return
+	}
+	options, err := h.orders.DeliveryOptions(quote)
+	if err != nil {
+		writeInternalError(w, "get delivery options", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, options)
+}
+
+// handleCheckout - POST /api/v1/orders: оформить заказ из корзины с выбранной доставкой.
+// Заказ считается так же, как в боте; оплата - при получении.
+func (h *Handler) handleCheckout(w http.ResponseWriter, r *http.Request, chatID int64) {
+	var req checkoutRequest
+	if r.ContentLength != 0 && !decodeJSON(w, r, &req) {
+		return
+	}
+
+	order, err := h.orders.Checkout(chatID, req.PromoCode, req.Delivery, req.UsePoints)
+	if order == nil {
+		writeServiceError(w, "checkout", err)
+		return
+	}
+	if err != nil {
+		// Заказ уже сохранен, не получилось только отметить промокод или очистить корзину
+		log.Printf("Error finishing order %d from HTTP API: %v", order.ID, err)
+	}
+	writeJSON(w, http.StatusCreated, order)
+}
+
+// getOrder - заказ из пути запроса. Если заказа нет, сам отвечает 404.
+func (h *Handler) getOrder(w http.ResponseWriter, r *http.Request) (*domain.Order, bool) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return nil, false
+	}
+	order, err := h.repo.GetOrderByID(id)
+	if err != nil {
+		writeInternalError(w, "get order", err)
+		return nil, false
+	}
+	if order == nil {
+		writeError(w, http.StatusNotFound, "заказ не найден")
+		return nil, false
+	}
+	return order, true
+}
+
+// handleGetOrder - GET /api/v1/orders/{id}: покупатель видит только свои заказы
+func (h *Handler) handleGetOrder(w http.ResponseWriter, r *http.Request, chatID int64) {
+	order, ok := h.getOrder(w, r)
+	if !ok {
+		return
+	}
+	if order.ChatID != chatID {
+		writeError(w, http.StatusNotFound, "заказ не найден")
+		return
+	}
+	writeJSON(w, http.StatusOK, order)
+}
+
+// handleMe - GET /api/v1/me: покупатель, от имени которого идет запрос (для Mini App)
+func (h *Handler) handleMe(w http.ResponseWriter, r *http.Request, chatID int64) {
+	user, err := h.repo.GetUserByChatID(chatID)
+	if err != nil {
+		writeInternalError(w, "get user", err)
+		return
+	}
+	if user == nil {
+		writeError(w, http.StatusNotFound, "покупатель не найден")
+		return
+	}
+	writeJSON(w, http.StatusOK, user)
+}
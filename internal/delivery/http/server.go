@@ -0,0 +1,41 @@
+// server.go — запуск HTTP сервера API
+package http
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Server - HTTP сервер API
+type Server struct {
+	srv *http.Server
+}
+
+// NewServer - создает сервер на адресе addr (например, ":8080").
+// handler - маршруты API, к ним можно добавить другие (например, веб-админку).
+func NewServer(addr string, handler http.Handler) *Server {
+	return &Server{
+		srv: &http.Server{
+			Addr:              addr,
+			Handler:           handler,
+			ReadHeaderTimeout: 10 * time.Second,
+			ReadTimeout:       30 * time.Second,
+			WriteTimeout:      60 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+		},
+	}
+}
+
+// Start - принимает запросы, пока сервер не остановят.
+// После Shutdown возвращает http.ErrServerClosed.
+func (s *Server) Start() error {
+	log.Printf("HTTP API run: %s", s.srv.Addr)
+	return s.srv.ListenAndServe()
+}
+
+// Shutdown - останавливает сервер, дожидаясь текущих запросов
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.srv.Shutdown(ctx)
+}
@@ -0,0 +1,64 @@
+// backup.go — команда админа /backup: последняя копия базы документом в чат.
+// Копии по расписанию делает планировщик, /backup new снимает свежую прямо сейчас.
+package telegram
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"salle_parfume/internal/backup"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxBackupDocument - больше Телеграм не примет от бота документом
+const maxBackupDocument = 50 << 20
+
+// backupTimeout - сколько ждем свежую копию по команде
+const backupTimeout = 2 * time.Minute
+
+// handleBackup - команда админа /backup [new]
+func (h *Handler) handleBackup(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+
+	arg := strings.ToLower(strings.TrimSpace(message.CommandArguments()))
+	if arg != "" && arg != "new" {
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "backup.usage")))
+		return
+	}
+
+	path, err := h.backups.Latest()
+	if arg == "new" || errors.Is(err, backup.ErrNoBackups) {
+		ctx, cancel := context.WithTimeout(context.Background(), backupTimeout)
+		path, err = h.backups.Create(ctx)
+		cancel()
+	}
+	if err != nil {
+		log.Printf("Error making backup: %v", err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "backup.error")))
+		return
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		log.Printf("Error reading backup %s: %v", path, err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "backup.error")))
+		return
+	}
+	if info.Size() > maxBackupDocument {
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "backup.too_large", filepath.Base(path), info.Size()>>20)))
+		return
+	}
+
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FilePath(path))
+	doc.Caption = h.t(chatID, "backup.caption", info.ModTime().Local().Format("02.01.2006 15:04"))
+	if _, err := h.bot.Send(doc); err != nil {
+		log.Printf("Error sending backup: %v", err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "backup.error")))
+	}
+}
@@ -0,0 +1,138 @@
+// bans.go — блокировки пользователей админом: /ban, /unban и /bans.
+// Бот не отвечает заблокированным (middleware BanCheck), каждое действие попадает в журнал.
+package telegram
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"salle_parfume/internal/domain"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// bansListLimit - сколько последних блокировок показывает /bans
+const bansListLimit = 50
+
+// handleBan - команда админа /ban <ID|@username> [причина]
+func (h *Handler) handleBan(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+
+	target, reason, _ := strings.Cut(strings.TrimSpace(message.CommandArguments()), " ")
+	if target == "" {
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "bans.usage")))
+		return
+	}
+	userID, ok := h.findBanTarget(chatID, target)
+	if !ok {
+		return
+	}
+	if userID == h.adminID {
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "bans.cannot_ban_admin")))
+		return
+	}
+
+	actor := telegramUserName(message.From)
+	ban := &domain.Ban{UserID: userID, Reason: strings.TrimSpace(reason), Actor: "telegram:" + actor}
+	if err := h.abuse.Ban(ban); err != nil {
+		log.Printf("Error banning user %d: %v", userID, err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "bans.save_error")))
+		return
+	}
+	h.auditBan(domain.AuditUserBan, userID, actor, ban.Reason)
+	h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "bans.banned", userID)))
+}
+
+// handleUnban - команда админа /unban <ID|@username>
+func (h *Handler) handleUnban(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+
+	target := strings.TrimSpace(message.CommandArguments())
+	if target == "" {
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "bans.usage")))
+		return
+	}
+	userID, ok := h.findBanTarget(chatID, target)
+	if !ok {
+		return
+	}
+
+	unbanned, err := h.abuse.Unban(userID)
+	if err != nil {
+		log.Printf("Error unbanning user %d: %v", userID, err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "bans.save_error")))
+		return
+	}
+	if !unbanned {
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "bans.not_banned", userID)))
+		return
+	}
+	h.auditBan(domain.AuditUserUnban, userID, telegramUserName(message.From), "")
+	h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "bans.unbanned", userID)))
+}
+
+// handleBans - команда админа /bans: последние блокировки
+func (h *Handler) handleBans(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+
+	bans, err := h.abuse.Bans(bansListLimit, 0)
+	if err != nil {
+		log.Printf("Error getting bans: %v", err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "bans.list_error")))
+		return
+	}
+	if len(bans) == 0 {
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "bans.list_empty")))
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(h.t(chatID, "bans.list_title") + "\n\n")
+	for _, ban := range bans {
+		sb.WriteString(fmt.Sprintf("%d — %s, %s", ban.UserID, ban.CreatedAt.Local().Format("02.01.2006 15:04"), ban.Actor))
+		if ban.Reason != "" {
+			sb.WriteString(": " + ban.Reason)
+		}
+		sb.WriteString("\n")
+	}
+	h.bot.Send(tgbotapi.NewMessage(chatID, sb.String()))
+}
+
+// findBanTarget - Телеграм ID по аргументу команды: число или @username того, кто уже писал боту.
+// Если не нашли - сообщаем админу и возвращаем false.
+func (h *Handler) findBanTarget(chatID int64, target string) (int64, bool) {
+	if id, err := strconv.ParseInt(target, 10, 64); err == nil {
+		return id, true
+	}
+
+	username := strings.TrimPrefix(target, "@")
+	users, err := h.repo.FindUsers(username, bansListLimit)
+	if err != nil {
+		log.Printf("Error finding user %s: %v", target, err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "bans.find_error")))
+		return 0, false
+	}
+	for _, user := range users {
+		if strings.EqualFold(user.Username, username) {
+			return user.ChatID, true
+		}
+	}
+	h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "bans.user_not_found", target)))
+	return 0, false
+}
+
+// auditBan - запись о блокировке или разблокировке в журнал
+func (h *Handler) auditBan(action string, userID int64, actor, details string) {
+	entry := &domain.AuditEntry{
+		Actor:    "telegram:" + actor,
+		Action:   action,
+		Object:   domain.AuditObjectUser,
+		ObjectID: userID,
+		Details:  details,
+	}
+	if err := h.repo.AddAuditEntry(entry); err != nil {
+		log.Printf("Error adding audit entry for user %d: %v", userID, err)
+	}
+}
@@ -3,7 +3,15 @@
 package telegram
 
 import (
+	"context"
+	"crypto/subtle"
+	"fmt"
 	"log"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"salle_parfume/internal/domain"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -12,6 +20,9 @@ import (
 type Bot struct {
 	api     *tgbotapi.BotAPI
 	handler *Handler
+	// События не из Телеграма (например, заказ с сайта). Handler не рассчитан
+	// на параллельную работу, поэтому события выполняются в том же цикле, что и обновления.
+	events chan func()
 }
 
 // NewBot создает новый экземпляр бота
@@ -19,12 +30,17 @@ func NewBot(api *tgbotapi.BotAPI, handler *Handler) *Bot {
 	return &Bot{
 		api:     api,
 		handler: handler,
+		events:  make(chan func(), 100),
 	}
 }
 
-// Start запускает бесконечный цикл
+// Start запускает бесконечный цикл (режим polling: бот сам спрашивает обновления у Телеграма)
 func (b *Bot) Start() {
 	log.Printf("Telegram bot run: %s", b.api.Self.UserName)
+	// 0. если раньше бот работал через webhook, Телеграм не отдаст обновления, пока его не снять
+	if _, err := b.api.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		log.Printf("Error deleting webhook: %v", err)
+	}
 	// 1. создаем конфигурацию для получения обновлений
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
@@ -32,12 +48,142 @@ func (b *Bot) Start() {
 	updates := b.api.GetUpdatesChan(u)
 
 	// 3. цикл получения обновлений
-	for update := range updates {
-		// Мы не проверяем update.Message == nil здесь,
-		// так как это может быть CallbackQuery (нажатие на кнопку),
-		// который обрабатывается внутри handler.Handle
+	b.run(updates)
+}
+
+// webhookSecretHeader - заголовок, в котором Телеграм присылает секрет webhook
+const webhookSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// StartWebhook - режим webhook: Телеграм сам присылает обновления POST-запросами на link,
+// бот принимает их на адресе addr (обычно за прокси с TLS). Каждый запрос Телеграма несет
+// secret в заголовке, запросы без него отклоняем: иначе любой, кто узнал адрес,
+// мог бы прислать боту поддельное обновление.
+func (b *Bot) StartWebhook(link, secret, addr string) error {
+	wh, err := tgbotapi.NewWebhook(link)
+	if err != nil {
+		return fmt.Errorf("некорректный адрес webhook: %w", err)
+	}
+	// В tgbotapi v5 у WebhookConfig нет secret_token, поэтому setWebhook вызываем напрямую
+	params := tgbotapi.Params{"url": wh.URL.String(), "secret_token": secret}
+	if _, err := b.api.MakeRequest("setWebhook", params); err != nil {
+		return fmt.Errorf("не удалось установить webhook: %w", err)
+	}
+
+	path := wh.URL.Path
+	if path == "" {
+		path = "/"
+	}
+	updates := make(chan tgbotapi.Update, 100)
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get(webhookSecretHeader)), []byte(secret)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		update, err := b.api.HandleUpdate(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		updates <- *update
+	})
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		log.Printf("Telegram bot webhook run: %s%s", addr, path)
+		if err := srv.ListenAndServe(); err != nil {
+			log.Printf("Error webhook server: %v", err)
+			close(updates)
+		}
+	}()
+
+	log.Printf("Telegram bot run: %s", b.api.Self.UserName)
+	b.run(updates)
+	return nil
+}
 
-		// 4. передаем сообщение в обработчик, он сам решит что с ним делать
-		b.handler.Handle(update)
+// run - цикл обработки обновлений и событий
+func (b *Bot) run(updates tgbotapi.UpdatesChannel) {
+	for {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				return
+			}
+			// Мы не проверяем update.Message == nil здесь,
+			// так как это может быть CallbackQuery (нажатие на кнопку),
+			// который обрабатывается внутри handler.Handle
+
+			// 4. передаем сообщение в обработчик, он сам решит что с ним делать
+			b.handler.Handle(update)
+
+		case event := <-b.events:
+			b.runEvent(event)
+		}
 	}
 }
+
+// runEvent - выполняет событие. Паника в нем, как и в обработке обновления, не роняет бота.
+func (b *Bot) runEvent(event func()) {
+	defer func() {
+		if err := recover(); err != nil {
+			log.Printf("Error: panic in bot event: %v\n%s", err, debug.Stack())
+		}
+	}()
+	event()
+}
+
+// enqueue - выполнить fn в цикле бота. Не ждет выполнения, поэтому
+// безопасно вызывать и из самого обработчика (заказ, оформленный в боте).
+func (b *Bot) enqueue(fn func()) {
+	go func() { b.events <- fn }()
+}
+
+// NotifyNewOrder - сообщает админам о новом заказе (реализует service.OrderNotifier)
+func (b *Bot) NotifyNewOrder(order *domain.Order) {
+	b.enqueue(func() { b.handler.notifyNewOrder(order) })
+}
+
+// SendCartReminder - напоминает покупателю о корзине (реализует service.CartReminder).
+// Вызывается из планировщика, поэтому ждет, пока цикл бота выполнит отправку.
+func (b *Bot) SendCartReminder(ctx context.Context, cart domain.AbandonedCart) error {
+	result := make(chan error, 1)
+	b.enqueue(func() { result <- b.handler.sendCartReminder(cart) })
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// NotifySubscription - сообщает подписчику, что товар появился или подешевел
+// (реализует service.SubscriptionNotifier). Как и напоминания, ждет отправки в цикле бота.
+func (b *Bot) NotifySubscription(ctx context.Context, sub domain.Subscription, product *domain.Product) error {
+	result := make(chan error, 1)
+	b.enqueue(func() { result <- b.handler.notifySubscription(sub, product) })
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// NotifyReferralReward - сообщает пригласившему промокод за друга (реализует service.ReferralNotifier)
+func (b *Bot) NotifyReferralReward(ctx context.Context, ref domain.Referral, promo *domain.Promotion) error {
+	result := make(chan error, 1)
+	b.enqueue(func() { result <- b.handler.notifyReferralReward(ref, promo) })
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SendAlert - сообщение об ошибке в чат админов (реализует service.AlertSender).
+// Отправляет сразу, не через цикл бота: ошибка могла случиться в нем самом.
+func (b *Bot) SendAlert(chatID int64, text string) error {
+	_, err := b.api.Send(tgbotapi.NewMessage(chatID, text))
+	return err
+}
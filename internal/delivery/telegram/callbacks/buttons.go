@@ -0,0 +1,94 @@
+// buttons.go - все кнопки бота. Коды действий короткие и не должны повторяться
+// (повтор заметит Router при регистрации обработчиков).
+package callbacks
+
+// Главное меню
+var (
+	Catalog  = Action{"cat"}
+	About    = Action{"about"}
+	Help     = Action{"help"}
+	Cart     = Action{"cart"}   // Показать корзину новым сообщением
+	MyOrders = Action{"orders"} // "Мои заказы" новым сообщением
+	Invite   = Action{"invite"}
+	Bonus    = Action{"bonus"}
+)
+
+// Noop - кнопка-надпись (номер страницы, товар в корзине), ничего не делает
+var Noop = Action{"noop"}
+
+// Пошаговые диалоги (пакет dialog). Номер шага в кнопке отсекает нажатия под старыми вопросами.
+var (
+	DialogChoice  = KeyID{"dch"}      // Вариант ответа: dch:<значение>:<шаг>
+	DialogDone    = ID{"ddone"}       // Закончить шаг с несколькими фото: ddone:<шаг>
+	DialogBack    = ID{"dback"}       // К предыдущему шагу
+	DialogSkip    = ID{"dskip"}       // Пропустить необязательный шаг
+	DialogConfirm = Action{"dok"}     // Подтвердить ответы
+	DialogCancel  = Action{"dcancel"} // Прервать диалог
+)
+
+// Карточка товара
+var (
+	Buy              = ID{"buy"}   // В корзину: buy:<productID>
+	Photo            = Pair{"ph"}  // Листать фото: ph:<productID>:<номер фото>
+	Album            = ID{"album"} // Все фото альбомом: album:<productID>
+	SubscribeRestock = ID{"subrs"} // Сообщить о поступлении: subrs:<productID>
+	SubscribePrice   = ID{"subpr"} // Сообщить о снижении цены: subpr:<productID>
+	Unsubscribe      = ID{"unsub"} // Отписаться: unsub:<subscriptionID>
+)
+
+// Отзывы и оценки
+var (
+	Rate          = ID{"rate"}       // Оценить товар: rate:<productID>
+	Stars         = Pair{"stars"}    // Выбор звезд: stars:<productID>:<оценка>
+	ReviewSkip    = Action{"rvskip"} // Оставить оценку без текста
+	Reviews       = Pair{"rv"}       // Страница отзывов: rv:<productID>:<страница>
+	ReviewPhoto   = ID{"rvph"}       // Фото к отзыву: rvph:<reviewID>
+	ReviewApprove = ID{"rvok"}       // Модерация: одобрить отзыв
+	ReviewReject  = ID{"rvno"}       // Модерация: отклонить отзыв
+)
+
+// Корзина и оформление заказа
+var (
+	CartInc     = ID{"cinc"} // +1 штука товара: cinc:<productID>
+	CartDec     = ID{"cdec"} // -1 штука товара
+	CartDel     = ID{"cdel"} // Убрать товар из корзины
+	CartClear   = Action{"cclear"}
+	CartPromo   = Action{"cpromo"}   // Ввести промокод
+	CartUnpromo = Action{"cunpromo"} // Убрать промокод
+	CartPoints  = Action{"cpoints"}  // Списать бонусы или отказаться от списания
+	Checkout    = Action{"checkout"}
+	Delivery    = ID{"dlv"}        // Выбор способа доставки: dlv:<methodID>
+	RemindOff   = Action{"remoff"} // Не напоминать о брошенной корзине
+)
+
+// Language - выбор языка: lang:<код>
+var Language = Key{"lang"}
+
+// Редактирование текстов магазина админом
+var (
+	Text         = Key{"txt"}        // Карточка текста: txt:<ключ>
+	TextEdit     = Key{"txted"}      // Ввести новый текст
+	TextHistory  = Key{"txthist"}    // История версий
+	TextRollback = KeyID{"txtrb"}    // Вернуть версию: txtrb:<ключ>:<версия>
+	TextSave     = Action{"txtsave"} // Сохранить показанный черновик
+	TextCancel   = Action{"txtcancel"}
+)
+
+// Управление заказом из чата заказов
+var (
+	OrderConfirm   = ID{"ordok"}     // Подтвердить заказ: ordok:<orderID>
+	OrderShip      = ID{"ordship"}   // Отправлен (спросит трек-номер)
+	OrderDelivered = ID{"orddone"}   // Получен покупателем
+	OrderCancel    = ID{"ordcancel"} // Отменить (спросит подтверждение)
+	OrderCancelYes = ID{"ordcyes"}   // Точно отменить
+	OrderBack      = ID{"ordback"}   // Передумали отменять - вернуть кнопки
+)
+
+// Заказы покупателя ("Мои заказы")
+var (
+	MyOrdersPage     = ID{"myords"}   // Страница списка: myords:<страница>
+	MyOrder          = ID{"myord"}    // Карточка заказа
+	MyOrderRepeat    = ID{"myrep"}    // Повторить заказ: положить те же товары в корзину
+	MyOrderCancel    = ID{"mycancel"} // Отменить (спросит подтверждение)
+	MyOrderCancelYes = ID{"mycyes"}   // Точно отменить
+)
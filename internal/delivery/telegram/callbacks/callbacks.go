@@ -0,0 +1,224 @@
+// callbacks.go - Пакет callbacks описывает данные (callback_data) inline-кнопок бота: клавиатуры
+// собирают кнопки по этим описаниям, а обработчики регистрируются на них в Router, поэтому
+// формат данных кнопки задается в одном месте.
+//
+// Формат: <версия>:<действие>[:<аргумент>...], числа - в base36, например "1:buy:2n" (товар 95).
+// Телеграм принимает не больше 64 байт, поэтому действия - короткие коды.
+// Если формат поменяется, повышаем Version: старые кнопки, оставшиеся в истории чата,
+// не разберутся как новые, и бот предложит открыть меню заново.
+package callbacks
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Version - текущая версия формата данных кнопок
+const Version = "1"
+
+// MaxDataLength - ограничение Телеграма на callback_data
+const MaxDataLength = 64
+
+// sep - разделитель версии, действия и аргументов
+const sep = ":"
+
+// encode - собирает данные кнопки. Длинные данные Телеграм не примет, а строковые аргументы
+// с разделителем не разберутся, поэтому это ошибка в описании кнопки, а не в данных пользователя.
+func encode(action string, args ...string) string {
+	data := Version + sep + action
+	for _, arg := range args {
+		if arg == "" || strings.Contains(arg, sep) {
+			panic(fmt.Sprintf("callbacks: недопустимый аргумент %q кнопки %s", arg, action))
+		}
+		data += sep + arg
+	}
+	if len(data) > MaxDataLength {
+		panic(fmt.Sprintf("callbacks: данные кнопки %s длиннее %d байт: %s", action, MaxDataLength, data))
+	}
+	return data
+}
+
+// formatID - число в base36: короче десятичного
+func formatID(id int64) string {
+	return strconv.FormatInt(id, 36)
+}
+
+// parseID - число из base36
+func parseID(s string) (int64, bool) {
+	id, err := strconv.ParseInt(s, 36, 64)
+	return id, err == nil
+}
+
+// Action - кнопка без аргументов ("Каталог", "Очистить корзину")
+type Action struct{ name string }
+
+// Name - код действия, по нему кнопке назначаются права
+func (b Action) Name() string { return b.name }
+
+// Data - данные кнопки
+func (b Action) Data() string { return encode(b.name) }
+
+// ID - кнопка с одним числом: ID товара, заказа, номер страницы
+type ID struct{ name string }
+
+// Name - код действия, по нему кнопке назначаются права
+func (b ID) Name() string { return b.name }
+
+// Data - данные кнопки для id
+func (b ID) Data(id int64) string { return encode(b.name, formatID(id)) }
+
+// Pair - кнопка с двумя числами: товар и номер фото, товар и оценка
+type Pair struct{ name string }
+
+// Name - код действия, по нему кнопке назначаются права
+func (b Pair) Name() string { return b.name }
+
+// Data - данные кнопки для пары чисел
+func (b Pair) Data(first, second int64) string {
+	return encode(b.name, formatID(first), formatID(second))
+}
+
+// Key - кнопка со строковым ключом: код языка, ключ текста магазина
+type Key struct{ name string }
+
+// Name - код действия, по нему кнопке назначаются права
+func (b Key) Name() string { return b.name }
+
+// Data - данные кнопки для ключа
+func (b Key) Data(key string) string { return encode(b.name, key) }
+
+// KeyID - кнопка с ключом и числом: ключ текста и номер версии
+type KeyID struct{ name string }
+
+// Name - код действия, по нему кнопке назначаются права
+func (b KeyID) Name() string { return b.name }
+
+// Data - данные кнопки для ключа и числа
+func (b KeyID) Data(key string, id int64) string { return encode(b.name, key, formatID(id)) }
+
+// ActionOf - код действия из данных кнопки. Для кнопок другой версии - пустая строка.
+func ActionOf(data string) string {
+	rest, ok := strings.CutPrefix(data, Version+sep)
+	if !ok {
+		return ""
+	}
+	action, _, _ := strings.Cut(rest, sep)
+	return action
+}
+
+// Result - чем закончилась обработка нажатия
+type Result int
+
+// Результаты Dispatch.
+const (
+	Handled  Result = iota // Нашелся обработчик, он и ответил на нажатие
+	Outdated               // Кнопка другой версии (или старого формата без версии)
+	Invalid                // Действие неизвестно или аргументы не разобрались
+)
+
+// route - обработчик действия. false - аргументы не подошли.
+type route func(q *tgbotapi.CallbackQuery, args []string) bool
+
+// Router - обработчики нажатий по действиям кнопок
+type Router struct {
+	routes map[string]route
+}
+
+// NewRouter - создает пустой роутер
+func NewRouter() *Router {
+	return &Router{routes: make(map[string]route)}
+}
+
+// add - регистрирует действие. Два обработчика на одно действие - ошибка в коде, падаем при старте.
+func (r *Router) add(name string, handle route) {
+	if _, ok := r.routes[name]; ok {
+		panic("callbacks: повторная регистрация действия " + name)
+	}
+	r.routes[name] = handle
+}
+
+// HandleAction - обработчик кнопки без аргументов
+func (r *Router) HandleAction(b Action, handle func(q *tgbotapi.CallbackQuery)) {
+	r.add(b.name, func(q *tgbotapi.CallbackQuery, args []string) bool {
+		if len(args) != 0 {
+			return false
+		}
+		handle(q)
+		return true
+	})
+}
+
+// HandleID - обработчик кнопки с числом
+func (r *Router) HandleID(b ID, handle func(q *tgbotapi.CallbackQuery, id int64)) {
+	r.add(b.name, func(q *tgbotapi.CallbackQuery, args []string) bool {
+		if len(args) != 1 {
+			return false
+		}
+		id, ok := parseID(args[0])
+		if !ok {
+			return false
+		}
+		handle(q, id)
+		return true
+	})
+}
+
+// HandlePair - обработчик кнопки с двумя числами
+func (r *Router) HandlePair(b Pair, handle func(q *tgbotapi.CallbackQuery, first, second int64)) {
+	r.add(b.name, func(q *tgbotapi.CallbackQuery, args []string) bool {
+		if len(args) != 2 {
+			return false
+		}
+		first, ok1 := parseID(args[0])
+		second, ok2 := parseID(args[1])
+		if !ok1 || !ok2 {
+			return false
+		}
+		handle(q, first, second)
+		return true
+	})
+}
+
+// HandleKey - обработчик кнопки с ключом
+func (r *Router) HandleKey(b Key, handle func(q *tgbotapi.CallbackQuery, key string)) {
+	r.add(b.name, func(q *tgbotapi.CallbackQuery, args []string) bool {
+		if len(args) != 1 {
+			return false
+		}
+		handle(q, args[0])
+		return true
+	})
+}
+
+// HandleKeyID - обработчик кнопки с ключом и числом
+func (r *Router) HandleKeyID(b KeyID, handle func(q *tgbotapi.CallbackQuery, key string, id int64)) {
+	r.add(b.name, func(q *tgbotapi.CallbackQuery, args []string) bool {
+		if len(args) != 2 {
+			return false
+		}
+		id, ok := parseID(args[1])
+		if !ok {
+			return false
+		}
+		handle(q, args[0], id)
+		return true
+	})
+}
+
+// Dispatch - находит обработчик по данным кнопки и вызывает его.
+// Если обработчика нет (Outdated, Invalid), на нажатие никто не ответил - это дело вызывающего.
+func (r *Router) Dispatch(q *tgbotapi.CallbackQuery) Result {
+	rest, ok := strings.CutPrefix(q.Data, Version+sep)
+	if !ok {
+		return Outdated
+	}
+	parts := strings.Split(rest, sep)
+	handle, ok := r.routes[parts[0]]
+	if !ok || !handle(q, parts[1:]) {
+		return Invalid
+	}
+	return Handled
+}
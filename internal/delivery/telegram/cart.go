@@ -0,0 +1,297 @@
+// cart.go — корзина покупателя: добавление товаров, количество, промокоды
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"strings"
+
+	"salle_parfume/internal/delivery/telegram/callbacks"
+	"salle_parfume/internal/domain"
+	"salle_parfume/internal/service"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// registerCartCallbacks - кнопки корзины и оформления заказа
+func (h *Handler) registerCartCallbacks(r *callbacks.Router) {
+	r.HandleID(callbacks.Buy, h.handleAddToCart)
+
+	r.HandleAction(callbacks.Cart, func(callback *tgbotapi.CallbackQuery) {
+		h.handleCart(callback.Message.Chat.ID, 0)
+		h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+	})
+
+	r.HandleID(callbacks.CartInc, func(callback *tgbotapi.CallbackQuery, productID int64) {
+		chatID := callback.Message.Chat.ID
+		if !h.changeCartQuantity(chatID, productID, 1) {
+			h.bot.Request(tgbotapi.NewCallback(callback.ID, h.t(chatID, "cart.no_more_stock")))
+			return
+		}
+		h.handleCart(chatID, callback.Message.MessageID)
+		h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+	})
+
+	r.HandleID(callbacks.CartDec, func(callback *tgbotapi.CallbackQuery, productID int64) {
+		chatID := callback.Message.Chat.ID
+		h.changeCartQuantity(chatID, productID, -1)
+		h.handleCart(chatID, callback.Message.MessageID)
+		h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+	})
+
+	r.HandleID(callbacks.CartDel, func(callback *tgbotapi.CallbackQuery, productID int64) {
+		chatID := callback.Message.Chat.ID
+		if err := h.repo.SetCartQuantity(chatID, productID, 0); err != nil {
+			log.Printf("Error removing from cart: %v", err)
+		}
+		h.handleCart(chatID, callback.Message.MessageID)
+		h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+	})
+
+	r.HandleAction(callbacks.CartClear, func(callback *tgbotapi.CallbackQuery) {
+		chatID := callback.Message.Chat.ID
+		if err := h.repo.ClearCart(chatID); err != nil {
+			log.Printf("Error clearing cart: %v", err)
+		}
+		delete(h.appliedPromos, chatID)
+		delete(h.usePoints, chatID)
+		h.handleCart(chatID, callback.Message.MessageID)
+		h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+	})
+
+	r.HandleAction(callbacks.CartPromo, func(callback *tgbotapi.CallbackQuery) {
+		chatID := callback.Message.Chat.ID
+		h.userStates[chatID] = StateWaitingForPromoCode
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "cart.enter_promo")))
+		h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+	})
+
+	r.HandleAction(callbacks.CartUnpromo, func(callback *tgbotapi.CallbackQuery) {
+		chatID := callback.Message.Chat.ID
+		delete(h.appliedPromos, chatID)
+		h.handleCart(chatID, callback.Message.MessageID)
+		h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+	})
+
+	r.HandleAction(callbacks.CartPoints, func(callback *tgbotapi.CallbackQuery) {
+		chatID := callback.Message.Chat.ID
+		h.usePoints[chatID] = !h.usePoints[chatID]
+		h.handleCart(chatID, callback.Message.MessageID)
+		h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+	})
+
+	r.HandleAction(callbacks.Checkout, func(callback *tgbotapi.CallbackQuery) {
+		h.handleCheckout(callback.Message.Chat.ID)
+		h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+	})
+
+	r.HandleID(callbacks.Delivery, func(callback *tgbotapi.CallbackQuery, methodID int64) {
+		h.handleDeliveryChoice(callback.Message.Chat.ID, methodID)
+		h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+	})
+
+	r.HandleAction(callbacks.RemindOff, h.disableCartReminders)
+}
+
+// handleCartCommand - команда /cart
+func (h *Handler) handleCartCommand(message *tgbotapi.Message) {
+	h.handleCart(message.Chat.ID, 0)
+}
+
+// handleAddToCart - кнопка "В корзину" в карточке товара
+func (h *Handler) handleAddToCart(callback *tgbotapi.CallbackQuery, productID int64) {
+	chatID := callback.Message.Chat.ID
+
+	product, err := h.repo.GetProductByID(productID)
+	if err != nil || product == nil {
+		if err != nil {
+			log.Printf("Error getting product %d: %v", productID, err)
+		}
+		h.bot.Request(tgbotapi.NewCallback(callback.ID, h.t(chatID, "catalog.product_not_found")))
+		return
+	}
+	// Карточка могла висеть в чате с тех пор, когда товар еще был в наличии
+	if !product.InStock() {
+		h.bot.Request(tgbotapi.NewCallbackWithAlert(callback.ID, h.t(chatID, "cart.out_of_stock")))
+		return
+	}
+
+	if err := h.repo.AddToCart(chatID, productID, 1); err != nil {
+		log.Printf("Error adding to cart: %v", err)
+		h.bot.Request(tgbotapi.NewCallbackWithAlert(callback.ID, h.t(chatID, "cart.add_error")))
+		return
+	}
+	h.bot.Request(tgbotapi.NewCallback(callback.ID, h.t(chatID, "cart.added")))
+}
+
+// changeCartQuantity - увеличивает или уменьшает количество товара в корзине.
+// Возвращает false, если больше, чем есть на складе, положить нельзя.
+func (h *Handler) changeCartQuantity(chatID, productID int64, delta int) bool {
+	lines, err := h.repo.GetCart(chatID)
+	if err != nil {
+		log.Printf("Error getting cart: %v", err)
+		return true
+	}
+	for _, line := range lines {
+		if line.Product.ID == productID {
+			if delta > 0 && !line.Product.HasStock(line.Quantity+delta) {
+				return false
+			}
+			if err := h.repo.SetCartQuantity(chatID, productID, line.Quantity+delta); err != nil {
+				log.Printf("Error updating cart: %v", err)
+			}
+			return true
+		}
+	}
+	return true
+}
+
+// handleCart - показывает корзину с расчетом скидок.
+// messageID == 0 - отправить новое сообщение, иначе отредактировать существующее.
+func (h *Handler) handleCart(chatID int64, messageID int) {
+	lines, err := h.repo.GetCart(chatID)
+	if err != nil {
+		log.Printf("Error getting cart: %v", err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "cart.error")))
+		return
+	}
+
+	if len(lines) == 0 {
+		delete(h.appliedPromos, chatID)
+		delete(h.usePoints, chatID)
+		if messageID != 0 {
+			h.bot.Send(tgbotapi.NewEditMessageText(chatID, messageID, h.t(chatID, "cart.empty")))
+		} else {
+			h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "cart.empty")))
+		}
+		return
+	}
+
+	quote, err := h.quoteCart(chatID, lines)
+	if err != nil {
+		log.Printf("Error calculating cart: %v", err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "cart.calc_error")))
+		return
+	}
+
+	points := h.spendablePoints(chatID, quote)
+	text := h.t(chatID, "cart.title") + "\n\n" + h.formatQuote(chatID, quote) + h.formatQuotePoints(chatID, quote, points)
+	keyboard := h.keyboards.GetCartKeyboard(h.lang(chatID), quote, quote.PromoCode != "", points, h.usePoints[chatID])
+
+	if messageID != 0 {
+		edit := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, text, keyboard)
+		edit.ParseMode = "HTML"
+		h.bot.Send(edit)
+		return
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = keyboard
+	h.bot.Send(msg)
+}
+
+// quoteCart - считает корзину с учетом примененного промокода.
+// Если промокод перестал подходить (например, корзина изменилась), он снимается.
+func (h *Handler) quoteCart(chatID int64, lines []domain.CartLine) (*domain.Quote, error) {
+	var promo *domain.Promotion
+	if code, ok := h.appliedPromos[chatID]; ok {
+		p, err := h.pricing.CheckPromoCode(chatID, code, lines)
+		switch reason := promoErrorKey(err); {
+		case err == nil:
+			promo = p
+		case reason != "":
+			delete(h.appliedPromos, chatID)
+			h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "cart.promo_removed", code, h.t(chatID, reason))))
+		default:
+			return nil, err
+		}
+	}
+	return h.pricing.Calculate(lines, promo)
+}
+
+// handlePromoInput - покупатель ввел промокод
+func (h *Handler) handlePromoInput(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	h.userStates[chatID] = StateNone
+
+	code := strings.TrimSpace(message.Text)
+	lines, err := h.repo.GetCart(chatID)
+	if err != nil {
+		log.Printf("Error getting cart: %v", err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "cart.error")))
+		return
+	}
+
+	promo, err := h.pricing.CheckPromoCode(chatID, code, lines)
+	if err != nil {
+		if reason := promoErrorKey(err); reason != "" {
+			h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "cart.promo_rejected", h.t(chatID, reason))))
+		} else {
+			log.Printf("Error checking promo code: %v", err)
+			h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "cart.promo_check_error")))
+		}
+		return
+	}
+
+	h.appliedPromos[chatID] = promo.Code
+	h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "cart.promo_applied", promo.Code)))
+	h.handleCart(chatID, 0)
+}
+
+// promoErrors - ошибки промокода, которые можно показать покупателю, и ключи их текстов
+var promoErrors = []struct {
+	err error
+	key string
+}{
+	{service.ErrPromoNotFound, "promo_error.not_found"},
+	{service.ErrPromoInactive, "promo_error.inactive"},
+	{service.ErrPromoNotApplicable, "promo_error.not_applicable"},
+	{service.ErrPromoMinOrder, "promo_error.min_order"},
+	{service.ErrPromoUsageLimit, "promo_error.usage_limit"},
+	{service.ErrPromoUserLimit, "promo_error.user_limit"},
+}
+
+// promoErrorKey - ключ текста ошибки промокода.
+// Пустая строка - ошибка не про промокод (например, база недоступна), показывать ее покупателю не нужно.
+func promoErrorKey(err error) string {
+	if err == nil {
+		return ""
+	}
+	for _, e := range promoErrors {
+		if errors.Is(err, e.err) {
+			return e.key
+		}
+	}
+	return ""
+}
+
+// formatQuote - текст расчета корзины: строки, скидки и итог
+func (h *Handler) formatQuote(chatID int64, quote *domain.Quote) string {
+	var sb strings.Builder
+	for _, line := range quote.Lines {
+		sb.WriteString(fmt.Sprintf("%s × %d — %s", html.EscapeString(line.Name), line.Quantity, h.money(chatID, line.Total)))
+		if line.Discount.IsPositive() {
+			sb.WriteString(fmt.Sprintf(" <s>%s</s>", line.Total.Add(line.Discount).Decimal()))
+		}
+		if line.OutOfStock() {
+			if *line.Available == 0 {
+				sb.WriteString(" — " + h.t(chatID, "cart.line_sold_out"))
+			} else {
+				sb.WriteString(" — " + h.t(chatID, "cart.line_available", *line.Available))
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n" + h.t(chatID, "cart.subtotal", h.money(chatID, quote.Subtotal)))
+	if quote.AutoDiscount.IsPositive() {
+		sb.WriteString("\n" + h.t(chatID, "cart.auto_discount", h.money(chatID, quote.AutoDiscount)))
+	}
+	if quote.CodeDiscount.IsPositive() {
+		sb.WriteString("\n" + h.t(chatID, "cart.code_discount", html.EscapeString(quote.PromoCode), h.money(chatID, quote.CodeDiscount)))
+	}
+	sb.WriteString("\n" + h.t(chatID, "cart.total", h.money(chatID, quote.Total)))
+	return sb.String()
+}
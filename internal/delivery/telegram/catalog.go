@@ -0,0 +1,239 @@
+// catalog.go — массовый импорт каталога из CSV/XLSX и выгрузка командой /export
+package telegram
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"salle_parfume/internal/domain"
+	"salle_parfume/internal/spreadsheet"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxReportErrors - сколько ошибок показываем прямо в сообщении, остальные уходят файлом
+const maxReportErrors = 20
+
+// PhotoUploader - загружает фото в Телеграм, чтобы получить file_id.
+// Фото отправляется в служебный чат (чат админа) и сразу удаляется.
+type PhotoUploader struct {
+	bot    *tgbotapi.BotAPI
+	chatID int64
+	client *http.Client
+}
+
+// NewPhotoUploader - создает загрузчик фото через указанный чат
+func NewPhotoUploader(bot *tgbotapi.BotAPI, chatID int64) *PhotoUploader {
+	return &PhotoUploader{
+		bot:    bot,
+		chatID: chatID,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// UploadPhotoURL - Телеграм сам скачивает картинку по ссылке
+func (u *PhotoUploader) UploadPhotoURL(url string) (string, error) {
+	return u.upload(tgbotapi.FileURL(url))
+}
+
+// UploadPhotoBytes - загружает картинку из памяти (например, из ZIP-архива)
+func (u *PhotoUploader) UploadPhotoBytes(name string, data []byte) (string, error) {
+	return u.upload(tgbotapi.FileBytes{Name: name, Bytes: data})
+}
+
+func (u *PhotoUploader) upload(file tgbotapi.RequestFileData) (string, error) {
+	msg, err := u.bot.Send(tgbotapi.NewPhoto(u.chatID, file))
+	if err != nil {
+		return "", fmt.Errorf("не удалось загрузить фото: %w", err)
+	}
+	if len(msg.Photo) == 0 {
+		return "", fmt.Errorf("телеграм не принял файл как фото")
+	}
+	// Служебное сообщение больше не нужно, file_id остается рабочим
+	u.bot.Request(tgbotapi.NewDeleteMessage(u.chatID, msg.MessageID))
+
+	return msg.Photo[len(msg.Photo)-1].FileID, nil
+}
+
+// handleDocument - админ прислал файл: ZIP с фото или таблицу каталога.
+// Возвращает false, если файл не относится к импорту.
+func (h *Handler) handleDocument(message *tgbotapi.Message) bool {
+	if message.From == nil || message.From.ID != h.adminID {
+		return false
+	}
+	chatID := message.Chat.ID
+	ext := strings.ToLower(path.Ext(message.Document.FileName))
+	if ext != ".zip" && ext != ".csv" && ext != ".xlsx" {
+		return false
+	}
+
+	data, err := h.downloadFile(message.Document.FileID)
+	if err != nil {
+		log.Printf("Error downloading document: %v", err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "import.download_error")))
+		return true
+	}
+
+	if ext == ".zip" {
+		images, err := readImagesZip(data)
+		if err != nil {
+			h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "import.zip_error", err)))
+			return true
+		}
+		h.importImages[chatID] = images
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.plural(chatID, "import.zip_loaded", len(images))))
+		return true
+	}
+
+	var table [][]string
+	if ext == ".csv" {
+		table, err = spreadsheet.ReadCSV(data)
+	} else {
+		table, err = spreadsheet.ReadXLSX(data)
+	}
+	if err != nil {
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "import.table_error", err)))
+		return true
+	}
+
+	h.bot.Send(tgbotapi.NewMessage(chatID, h.plural(chatID, "import.started", len(table)-1)))
+
+	result, err := h.catalog.Import(table, h.importImages[chatID])
+	delete(h.importImages, chatID) // архив нужен только для одного импорта
+	if err != nil && result == nil {
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "import.failed", err)))
+		return true
+	}
+	if err != nil {
+		log.Printf("Error importing catalog: %v", err)
+	}
+	h.sendImportReport(chatID, result, err)
+	return true
+}
+
+// sendImportReport - отчет об импорте. Если ошибок много, полный список прикладываем файлом.
+func (h *Handler) sendImportReport(chatID int64, result *domain.ImportResult, importErr error) {
+	var sb strings.Builder
+	sb.WriteString(h.t(chatID, "import.report", result.Created, result.Updated, len(result.Errors)) + "\n")
+	if importErr != nil {
+		sb.WriteString("\n" + h.t(chatID, "import.interrupted") + "\n")
+	}
+
+	for i, e := range result.Errors {
+		if i == maxReportErrors {
+			sb.WriteString(h.t(chatID, "import.more_errors", len(result.Errors)-maxReportErrors) + "\n")
+			break
+		}
+		sb.WriteString(h.t(chatID, "import.row_error", e.Row, e.Message) + "\n")
+	}
+	h.bot.Send(tgbotapi.NewMessage(chatID, sb.String()))
+
+	if len(result.Errors) > maxReportErrors {
+		var report strings.Builder
+		for _, e := range result.Errors {
+			report.WriteString(h.t(chatID, "import.row_error", e.Row, e.Message) + "\n")
+		}
+		doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{Name: "import_errors.txt", Bytes: []byte(report.String())})
+		h.bot.Send(doc)
+	}
+}
+
+// handleExport - /export [csv|xlsx] - выгрузка каталога в том же формате, что и импорт
+func (h *Handler) handleExport(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+
+	format := strings.ToLower(strings.TrimSpace(message.CommandArguments()))
+	if format == "" {
+		format = "xlsx"
+	}
+	if format != "csv" && format != "xlsx" {
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "export.usage")))
+		return
+	}
+
+	table, err := h.catalog.Export()
+	if err != nil {
+		log.Printf("Error exporting catalog: %v", err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "export.error")))
+		return
+	}
+
+	var data []byte
+	if format == "csv" {
+		data, err = spreadsheet.WriteCSV(table)
+	} else {
+		data, err = spreadsheet.WriteXLSX(h.t(chatID, "export.sheet_name"), table)
+	}
+	if err != nil {
+		log.Printf("Error writing catalog file: %v", err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "export.error")))
+		return
+	}
+
+	name := fmt.Sprintf("catalog_%s.%s", time.Now().Format("2006-01-02"), format)
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{Name: name, Bytes: data})
+	doc.Caption = h.plural(chatID, "export.caption", len(table)-1)
+	if _, err := h.bot.Send(doc); err != nil {
+		log.Printf("Error sending catalog file: %v", err)
+	}
+}
+
+// downloadFile - скачивает файл, присланный в чат
+func (h *Handler) downloadFile(fileID string) ([]byte, error) {
+	url, err := h.bot.GetFileDirectURL(fileID)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// readImagesZip - достает картинки из архива: имя файла -> содержимое
+func readImagesZip(data []byte) (map[string][]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	images := make(map[string][]byte)
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		switch strings.ToLower(path.Ext(f.Name)) {
+		case ".jpg", ".jpeg", ".png", ".webp":
+		default:
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		images[f.Name] = content
+	}
+	if len(images) == 0 {
+		return nil, fmt.Errorf("в архиве нет картинок (jpg, png, webp)")
+	}
+	return images, nil
+}
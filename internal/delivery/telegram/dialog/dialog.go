@@ -0,0 +1,153 @@
+// dialog.go - Пакет dialog ведет пошаговые диалоги бота по описанию: какие шаги, что ждем
+// на каждом (текст, число, фото, кнопки, контакт, геопозиция), как проверить ответ.
+// Кнопки "Назад", "Пропустить" и "Отмена", итоговое подтверждение и истечение брошенного
+// диалога движок делает сам, поэтому в описании диалога остаются только вопросы и проверки.
+package dialog
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"salle_parfume/internal/domain"
+)
+
+// DefaultTimeout - через сколько без ответов диалог истекает, если в описании не задано иначе
+const DefaultTimeout = time.Hour
+
+// Input - что ждем от пользователя на шаге
+type Input int
+
+const (
+	InputText     Input = iota // Текст
+	InputNumber                // Число (запятая как разделитель дробной части тоже подходит)
+	InputPhoto                 // Одно фото (file_id)
+	InputPhotos                // Несколько фото до кнопки "Готово" (можно альбомом)
+	InputChoice                // Выбор кнопкой из Choices
+	InputContact               // Контакт кнопкой "Отправить номер" (номер телефона)
+	InputLocation              // Геопозиция ("широта,долгота")
+)
+
+// Choice - вариант ответа на шаг InputChoice
+type Choice struct {
+	Value string // Что сохраняем (короткое, без ":" - оно попадает в данные кнопки)
+	Label string // Ключ перевода подписи кнопки
+}
+
+// Step - один вопрос диалога
+type Step struct {
+	Key        string             // Под каким ключом сохраняем ответ
+	Prompt     string             // Ключ перевода вопроса
+	PromptArgs []any              // Подстановки в вопрос
+	Input      Input              // Что ждем
+	Choices    []Choice           // Варианты для InputChoice
+	Max        int                // Сколько фото можно прислать на шаге InputPhotos (0 - без ограничения)
+	Optional   bool               // Шаг можно пропустить
+	Validate   func(string) error // Проверка ответа (для InputPhotos - каждого file_id). Ошибку показываем пользователю
+}
+
+// Dialog - описание диалога
+type Dialog struct {
+	Name    string
+	Steps   []Step
+	Timeout time.Duration // Через сколько без ответов диалог истекает (0 - DefaultTimeout)
+
+	// Summary - текст итога перед подтверждением (HTML). nil - подтверждение не спрашиваем.
+	Summary func(chatID int64, session *domain.DialogSession) string
+	// Finish - все ответы получены (и подтверждены). Сессия к этому моменту уже удалена.
+	Finish func(chatID int64, session *domain.DialogSession)
+}
+
+// timeout - сколько ждем ответа
+func (d *Dialog) timeout() time.Duration {
+	if d.Timeout > 0 {
+		return d.Timeout
+	}
+	return DefaultTimeout
+}
+
+// ValidationError - ответ не подошел. Текст берется из переводов по ключу.
+type ValidationError struct {
+	Key  string
+	Args []any
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("dialog: invalid answer (%s)", e.Key)
+}
+
+// Invalid - ошибка проверки с текстом по ключу перевода
+func Invalid(key string, args ...any) error {
+	return &ValidationError{Key: key, Args: args}
+}
+
+// Store - где хранятся сессии. Реализации: репозиторий в базе (переживает перезапуск) и MemoryStore.
+type Store interface {
+	GetDialogSession(chatID int64) (*domain.DialogSession, error)
+	SaveDialogSession(session *domain.DialogSession) error
+	DeleteDialogSession(chatID int64) error
+	CountDialogSessions(since time.Time) (int, error)
+}
+
+// MemoryStore - сессии в памяти процесса (теряются при перезапуске)
+type MemoryStore struct {
+	mu       sync.Mutex
+	sessions map[int64]domain.DialogSession
+}
+
+// NewMemoryStore - создает пустое хранилище в памяти
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{sessions: make(map[int64]domain.DialogSession)}
+}
+
+// GetDialogSession - копия сессии, чтобы изменения без Save не попадали в хранилище
+func (m *MemoryStore) GetDialogSession(chatID int64) (*domain.DialogSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session, ok := m.sessions[chatID]
+	if !ok {
+		return nil, nil
+	}
+	session.Values = copyValues(session.Values)
+	return &session, nil
+}
+
+// SaveDialogSession - сохраняет копию сессии
+func (m *MemoryStore) SaveDialogSession(session *domain.DialogSession) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	saved := *session
+	saved.Values = copyValues(session.Values)
+	m.sessions[session.ChatID] = saved
+	return nil
+}
+
+// DeleteDialogSession - удаляет сессию
+func (m *MemoryStore) DeleteDialogSession(chatID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, chatID)
+	return nil
+}
+
+// CountDialogSessions - сколько сессий с ответами не раньше since
+func (m *MemoryStore) CountDialogSessions(since time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for _, session := range m.sessions {
+		if !session.UpdatedAt.Before(since) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// copyValues - копия ответов
+func copyValues(values map[string][]string) map[string][]string {
+	copied := make(map[string][]string, len(values))
+	for key, v := range values {
+		copied[key] = append([]string(nil), v...)
+	}
+	return copied
+}
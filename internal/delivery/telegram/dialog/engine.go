@@ -0,0 +1,493 @@
+// engine.go - Движок диалогов: задает вопросы по шагам, принимает ответы сообщениями
+// и кнопками, хранит прогресс в Store.
+package dialog
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"salle_parfume/internal/delivery/telegram/callbacks"
+	"salle_parfume/internal/domain"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Sender - отправка сообщений и ответов на нажатия (реализует *tgbotapi.BotAPI)
+type Sender interface {
+	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+	Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
+}
+
+// Translate - текст по ключу на языке пользователя
+type Translate func(chatID int64, key string, args ...any) string
+
+// Engine - движок диалогов
+type Engine struct {
+	bot     Sender
+	t       Translate
+	store   Store
+	dialogs map[string]*Dialog
+}
+
+// NewEngine - создает движок без диалогов (их добавляет Register)
+func NewEngine(bot Sender, t Translate, store Store) *Engine {
+	return &Engine{
+		bot:     bot,
+		t:       t,
+		store:   store,
+		dialogs: make(map[string]*Dialog),
+	}
+}
+
+// Register - добавляет диалог. Повтор имени или диалог без шагов - ошибка в коде, падаем при старте.
+func (e *Engine) Register(d *Dialog) {
+	if _, ok := e.dialogs[d.Name]; ok {
+		panic("dialog: повторная регистрация диалога " + d.Name)
+	}
+	if len(d.Steps) == 0 {
+		panic("dialog: у диалога " + d.Name + " нет шагов")
+	}
+	e.dialogs[d.Name] = d
+}
+
+// Start - начинает диалог с первого шага. Незаконченный прошлый диалог пользователя пропадает.
+func (e *Engine) Start(chatID int64, name string) {
+	d, ok := e.dialogs[name]
+	if !ok {
+		panic("dialog: неизвестный диалог " + name)
+	}
+	session := &domain.DialogSession{ChatID: chatID, Dialog: name, Values: make(map[string][]string)}
+	if e.save(session) {
+		e.ask(session, d, "")
+	}
+}
+
+// Active - есть ли у пользователя незаконченный диалог
+func (e *Engine) Active(chatID int64) bool {
+	session, _, _ := e.load(chatID)
+	return session != nil
+}
+
+// CountActive - сколько незаконченных и еще не истекших диалогов (для метрик).
+// Таймауты у диалогов разные, поэтому считаем по самому долгому.
+func (e *Engine) CountActive(now time.Time) (int, error) {
+	var timeout time.Duration
+	for _, d := range e.dialogs {
+		timeout = max(timeout, d.timeout())
+	}
+	return e.store.CountDialogSessions(now.Add(-timeout))
+}
+
+// Cancel - прерывает диалог пользователя
+func (e *Engine) Cancel(chatID int64) {
+	if err := e.store.DeleteDialogSession(chatID); err != nil {
+		log.Printf("Error deleting dialog session of %d: %v", chatID, err)
+	}
+	msg := tgbotapi.NewMessage(chatID, e.t(chatID, "dialog.cancelled"))
+	// Убираем кнопку "Отправить номер" или "Отправить геопозицию", если она была
+	msg.ReplyMarkup = tgbotapi.NewRemoveKeyboard(true)
+	e.bot.Send(msg)
+}
+
+// HandleMessage - ответ на текущий шаг. false - у пользователя нет диалога, сообщение не наше.
+// Команды, кроме /cancel, диалог не перехватывает: они работают как обычно.
+func (e *Engine) HandleMessage(message *tgbotapi.Message) bool {
+	chatID := message.Chat.ID
+	session, d, expired := e.load(chatID)
+	if expired {
+		e.bot.Send(tgbotapi.NewMessage(chatID, e.t(chatID, "dialog.expired")))
+		return !message.IsCommand()
+	}
+	if session == nil {
+		return false
+	}
+	if message.IsCommand() {
+		if message.Command() != "cancel" {
+			return false
+		}
+		e.Cancel(chatID)
+		return true
+	}
+	if session.Confirming {
+		e.bot.Send(tgbotapi.NewMessage(chatID, e.t(chatID, "dialog.use_buttons")))
+		return true
+	}
+
+	step := d.Steps[session.Step]
+	if step.Input == InputPhotos {
+		e.handlePhotos(message, session, d)
+		return true
+	}
+
+	value, err := e.read(step, message)
+	if err == nil && step.Validate != nil {
+		err = step.Validate(value)
+	}
+	if err != nil {
+		e.invalid(chatID, err)
+		return true
+	}
+	session.Values[step.Key] = []string{value}
+	e.next(session, d, "")
+	return true
+}
+
+// RegisterCallbacks - кнопки диалогов: варианты ответа и управление
+func (e *Engine) RegisterCallbacks(r *callbacks.Router) {
+	r.HandleKeyID(callbacks.DialogChoice, func(callback *tgbotapi.CallbackQuery, value string, step int64) {
+		e.onButton(callback, int(step), func(session *domain.DialogSession, d *Dialog) string {
+			current := d.Steps[session.Step]
+			if current.Input != InputChoice || !hasChoice(current.Choices, value) {
+				return "dialog.button_outdated"
+			}
+			if current.Validate != nil {
+				if err := current.Validate(value); err != nil {
+					e.invalid(session.ChatID, err)
+					return ""
+				}
+			}
+			session.Values[current.Key] = []string{value}
+			e.next(session, d, "")
+			return ""
+		})
+	})
+
+	r.HandleID(callbacks.DialogDone, func(callback *tgbotapi.CallbackQuery, step int64) {
+		e.onButton(callback, int(step), func(session *domain.DialogSession, d *Dialog) string {
+			if d.Steps[session.Step].Input != InputPhotos {
+				return "dialog.button_outdated"
+			}
+			e.finishPhotos(session, d)
+			return ""
+		})
+	})
+
+	r.HandleID(callbacks.DialogBack, func(callback *tgbotapi.CallbackQuery, step int64) {
+		e.onButton(callback, int(step), func(session *domain.DialogSession, d *Dialog) string {
+			switch {
+			case session.Confirming:
+				session.Confirming = false
+			case session.Step > 0:
+				session.Step--
+			default:
+				return "dialog.button_outdated"
+			}
+			// Ответ на шаг, к которому вернулись, спрашиваем заново
+			delete(session.Values, d.Steps[session.Step].Key)
+			session.MediaGroupID = ""
+			if e.save(session) {
+				e.ask(session, d, "")
+			}
+			return ""
+		})
+	})
+
+	r.HandleID(callbacks.DialogSkip, func(callback *tgbotapi.CallbackQuery, step int64) {
+		e.onButton(callback, int(step), func(session *domain.DialogSession, d *Dialog) string {
+			current := d.Steps[session.Step]
+			if !current.Optional {
+				return "dialog.button_outdated"
+			}
+			delete(session.Values, current.Key)
+			e.next(session, d, "")
+			return ""
+		})
+	})
+
+	r.HandleAction(callbacks.DialogConfirm, func(callback *tgbotapi.CallbackQuery) {
+		e.onButton(callback, -1, func(session *domain.DialogSession, d *Dialog) string {
+			if !session.Confirming {
+				return "dialog.button_outdated"
+			}
+			e.finish(session, d)
+			return ""
+		})
+	})
+
+	r.HandleAction(callbacks.DialogCancel, func(callback *tgbotapi.CallbackQuery) {
+		e.onButton(callback, -1, func(session *domain.DialogSession, d *Dialog) string {
+			e.Cancel(session.ChatID)
+			return ""
+		})
+	})
+}
+
+// onButton - общая часть кнопок: находит диалог и проверяет, что кнопка под текущим вопросом
+// (step < 0 - кнопка не привязана к шагу). handle возвращает ключ всплывающего ответа или "".
+func (e *Engine) onButton(callback *tgbotapi.CallbackQuery, step int, handle func(*domain.DialogSession, *Dialog) string) {
+	chatID := callback.Message.Chat.ID
+	session, d, expired := e.load(chatID)
+	var answer string
+	switch {
+	case expired:
+		answer = "dialog.expired"
+	case session == nil || (step >= 0 && position(session, d) != step):
+		answer = "dialog.button_outdated"
+	default:
+		answer = handle(session, d)
+	}
+	if answer != "" {
+		answer = e.t(chatID, answer)
+	}
+	e.bot.Request(tgbotapi.NewCallback(callback.ID, answer))
+}
+
+// position - номер текущего вопроса; у подтверждения - номер после последнего шага
+func position(session *domain.DialogSession, d *Dialog) int {
+	if session.Confirming {
+		return len(d.Steps)
+	}
+	return session.Step
+}
+
+// load - незаконченный диалог пользователя. Истекший удаляет и возвращает expired = true.
+func (e *Engine) load(chatID int64) (*domain.DialogSession, *Dialog, bool) {
+	session, err := e.store.GetDialogSession(chatID)
+	if err != nil {
+		log.Printf("Error getting dialog session of %d: %v", chatID, err)
+		return nil, nil, false
+	}
+	if session == nil {
+		return nil, nil, false
+	}
+	d, ok := e.dialogs[session.Dialog]
+	// Диалог убрали из кода или шагов стало меньше - сессию не продолжить
+	expired := !ok || session.Step >= len(d.Steps) || session.Expired(d.timeout(), time.Now())
+	if expired {
+		if err := e.store.DeleteDialogSession(chatID); err != nil {
+			log.Printf("Error deleting dialog session of %d: %v", chatID, err)
+		}
+		return nil, nil, true
+	}
+	if session.Values == nil {
+		session.Values = make(map[string][]string)
+	}
+	return session, d, false
+}
+
+// save - сохраняет прогресс. При ошибке сообщает пользователю и возвращает false.
+func (e *Engine) save(session *domain.DialogSession) bool {
+	session.UpdatedAt = time.Now()
+	if err := e.store.SaveDialogSession(session); err != nil {
+		log.Printf("Error saving dialog session of %d: %v", session.ChatID, err)
+		e.bot.Send(tgbotapi.NewMessage(session.ChatID, e.t(session.ChatID, "dialog.error")))
+		return false
+	}
+	return true
+}
+
+// next - следующий шаг, подтверждение или конец диалога. prefix - строка перед вопросом.
+func (e *Engine) next(session *domain.DialogSession, d *Dialog, prefix string) {
+	session.MediaGroupID = ""
+	if session.Step+1 < len(d.Steps) {
+		session.Step++
+	} else {
+		if d.Summary == nil {
+			e.finish(session, d)
+			return
+		}
+		session.Confirming = true
+	}
+	if e.save(session) {
+		e.ask(session, d, prefix)
+	}
+}
+
+// finish - удаляет сессию и отдает ответы диалогу
+func (e *Engine) finish(session *domain.DialogSession, d *Dialog) {
+	if err := e.store.DeleteDialogSession(session.ChatID); err != nil {
+		log.Printf("Error deleting dialog session of %d: %v", session.ChatID, err)
+	}
+	d.Finish(session.ChatID, session)
+}
+
+// ask - задает текущий вопрос или показывает итог для подтверждения
+func (e *Engine) ask(session *domain.DialogSession, d *Dialog, prefix string) {
+	chatID := session.ChatID
+	if session.Confirming {
+		msg := tgbotapi.NewMessage(chatID, prefix+d.Summary(chatID, session)+"\n\n"+e.t(chatID, "dialog.confirm"))
+		msg.ParseMode = "HTML"
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(e.button(chatID, "buttons.dialog_confirm", callbacks.DialogConfirm.Data())),
+			tgbotapi.NewInlineKeyboardRow(
+				e.button(chatID, "buttons.dialog_back", callbacks.DialogBack.Data(int64(len(d.Steps)))),
+				e.button(chatID, "buttons.dialog_cancel", callbacks.DialogCancel.Data()),
+			),
+		)
+		e.bot.Send(msg)
+		return
+	}
+
+	step := d.Steps[session.Step]
+	text := prefix + e.t(chatID, step.Prompt, step.PromptArgs...)
+	switch step.Input {
+	case InputContact, InputLocation:
+		// Кнопка запроса контакта или геопозиции бывает только в обычной клавиатуре,
+		// inline-кнопки к такому сообщению не добавить - отменяют диалог командой
+		msg := tgbotapi.NewMessage(chatID, text+"\n"+e.t(chatID, "dialog.cancel_hint"))
+		request := tgbotapi.NewKeyboardButtonContact(e.t(chatID, "buttons.send_contact"))
+		if step.Input == InputLocation {
+			request = tgbotapi.NewKeyboardButtonLocation(e.t(chatID, "buttons.send_location"))
+		}
+		msg.ReplyMarkup = tgbotapi.NewOneTimeReplyKeyboard(tgbotapi.NewKeyboardButtonRow(request))
+		e.bot.Send(msg)
+	default:
+		msg := tgbotapi.NewMessage(chatID, text)
+		msg.ReplyMarkup = e.keyboard(session, d)
+		e.bot.Send(msg)
+	}
+}
+
+// keyboard - варианты ответа (или "Готово" для фото) и кнопки управления под вопросом
+func (e *Engine) keyboard(session *domain.DialogSession, d *Dialog) tgbotapi.InlineKeyboardMarkup {
+	chatID := session.ChatID
+	step := d.Steps[session.Step]
+	pos := int64(session.Step)
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	switch step.Input {
+	case InputChoice:
+		// По два варианта в ряд
+		var row []tgbotapi.InlineKeyboardButton
+		for _, choice := range step.Choices {
+			row = append(row, e.button(chatID, choice.Label, callbacks.DialogChoice.Data(choice.Value, pos)))
+			if len(row) == 2 {
+				rows = append(rows, row)
+				row = nil
+			}
+		}
+		if len(row) > 0 {
+			rows = append(rows, row)
+		}
+	case InputPhotos:
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(e.button(chatID, "buttons.done", callbacks.DialogDone.Data(pos))))
+	}
+
+	var controls []tgbotapi.InlineKeyboardButton
+	if session.Step > 0 {
+		controls = append(controls, e.button(chatID, "buttons.dialog_back", callbacks.DialogBack.Data(pos)))
+	}
+	if step.Optional {
+		controls = append(controls, e.button(chatID, "buttons.dialog_skip", callbacks.DialogSkip.Data(pos)))
+	}
+	controls = append(controls, e.button(chatID, "buttons.dialog_cancel", callbacks.DialogCancel.Data()))
+	rows = append(rows, controls)
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// button - inline-кнопка с переведенной подписью
+func (e *Engine) button(chatID int64, label, data string) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData(e.t(chatID, label), data)
+}
+
+// read - ответ из сообщения в том виде, в каком его ждет шаг
+func (e *Engine) read(step Step, message *tgbotapi.Message) (string, error) {
+	switch step.Input {
+	case InputText:
+		text := strings.TrimSpace(message.Text)
+		if text == "" {
+			return "", Invalid("dialog.send_text")
+		}
+		return text, nil
+	case InputNumber:
+		text := strings.TrimSpace(message.Text)
+		if _, err := strconv.ParseFloat(strings.ReplaceAll(text, ",", "."), 64); err != nil {
+			return "", Invalid("dialog.send_number")
+		}
+		return text, nil
+	case InputPhoto:
+		if len(message.Photo) == 0 {
+			return "", Invalid("dialog.send_photo")
+		}
+		// Берем самое качественное фото (последнее в массиве)
+		return message.Photo[len(message.Photo)-1].FileID, nil
+	case InputContact:
+		if message.Contact == nil {
+			return "", Invalid("dialog.send_contact")
+		}
+		return message.Contact.PhoneNumber, nil
+	case InputLocation:
+		if message.Location == nil {
+			return "", Invalid("dialog.send_location")
+		}
+		return strconv.FormatFloat(message.Location.Latitude, 'f', -1, 64) + "," +
+			strconv.FormatFloat(message.Location.Longitude, 'f', -1, 64), nil
+	}
+	// InputChoice: ответ только кнопкой
+	return "", Invalid("dialog.use_buttons")
+}
+
+// invalid - объясняет, почему ответ не подошел
+func (e *Engine) invalid(chatID int64, err error) {
+	text := e.t(chatID, "dialog.invalid", err)
+	if v, ok := err.(*ValidationError); ok {
+		text = e.t(chatID, v.Key, v.Args...)
+	}
+	e.bot.Send(tgbotapi.NewMessage(chatID, text))
+}
+
+// handlePhotos - шаг с несколькими фото: копим, пока не нажмут "Готово" (или не напишут это словом)
+func (e *Engine) handlePhotos(message *tgbotapi.Message, session *domain.DialogSession, d *Dialog) {
+	chatID := message.Chat.ID
+	step := d.Steps[session.Step]
+
+	if len(message.Photo) == 0 {
+		if strings.EqualFold(strings.TrimSpace(message.Text), e.t(chatID, "dialog.done_word")) {
+			e.finishPhotos(session, d)
+			return
+		}
+		e.bot.Send(tgbotapi.NewMessage(chatID, e.t(chatID, "dialog.send_photo_or_done")))
+		return
+	}
+
+	// Фото из одного альбома приходят отдельными сообщениями - отвечаем только на первое
+	sameAlbum := message.MediaGroupID != "" && message.MediaGroupID == session.MediaGroupID
+	session.MediaGroupID = message.MediaGroupID
+
+	if step.Max > 0 && len(session.Values[step.Key]) >= step.Max {
+		if !sameAlbum && e.save(session) {
+			msg := tgbotapi.NewMessage(chatID, e.t(chatID, "dialog.photo_limit", step.Max))
+			msg.ReplyMarkup = e.keyboard(session, d)
+			e.bot.Send(msg)
+		}
+		return
+	}
+
+	fileID := message.Photo[len(message.Photo)-1].FileID
+	if step.Validate != nil {
+		if err := step.Validate(fileID); err != nil {
+			e.invalid(chatID, err)
+			return
+		}
+	}
+	session.Values[step.Key] = append(session.Values[step.Key], fileID)
+	if !e.save(session) || sameAlbum {
+		return
+	}
+	msg := tgbotapi.NewMessage(chatID, e.t(chatID, "dialog.photo_added"))
+	msg.ReplyMarkup = e.keyboard(session, d)
+	e.bot.Send(msg)
+}
+
+// finishPhotos - фото закончились, переходим дальше (хотя бы одно фото нужно, если шаг обязательный)
+func (e *Engine) finishPhotos(session *domain.DialogSession, d *Dialog) {
+	chatID := session.ChatID
+	n := len(session.Values[d.Steps[session.Step].Key])
+	if n == 0 && !d.Steps[session.Step].Optional {
+		e.bot.Send(tgbotapi.NewMessage(chatID, e.t(chatID, "dialog.no_photos")))
+		return
+	}
+	e.next(session, d, e.t(chatID, "dialog.photos_saved", n)+"\n")
+}
+
+// hasChoice - есть ли такой вариант ответа
+func hasChoice(choices []Choice, value string) bool {
+	for _, choice := range choices {
+		if choice.Value == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+// gallery.go — несколько фото у товара: просмотр покупателем (загружает их админ в диалоге /new)
+package telegram
+
+import (
+	"log"
+
+	"salle_parfume/internal/delivery/telegram/callbacks"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// registerGalleryCallbacks - кнопки фото товара: листание и альбом
+func (h *Handler) registerGalleryCallbacks(r *callbacks.Router) {
+	r.HandlePair(callbacks.Photo, func(callback *tgbotapi.CallbackQuery, productID, photo int64) {
+		h.handleSwipePhoto(callback, productID, int(photo))
+	})
+	r.HandleID(callbacks.Album, h.handleAlbum)
+}
+
+// handleSwipePhoto - листание фото прямо в карточке товара (меняем картинку в том же сообщении)
+func (h *Handler) handleSwipePhoto(callback *tgbotapi.CallbackQuery, productID int64, photo int) {
+	product, err := h.repo.GetProductByID(productID)
+	if err != nil || product == nil {
+		if err != nil {
+			log.Printf("Error getting product %d: %v", productID, err)
+		}
+		h.bot.Request(tgbotapi.NewCallback(callback.ID, h.t(callback.Message.Chat.ID, "catalog.product_not_found")))
+		return
+	}
+
+	gallery := product.Gallery()
+	if len(gallery) == 0 {
+		h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+		return
+	}
+	// Фото могли удалить, пока карточка висела в чате
+	if photo < 0 || photo >= len(gallery) {
+		photo = 0
+	}
+
+	media := tgbotapi.NewInputMediaPhoto(tgbotapi.FileID(gallery[photo]))
+	media.Caption = h.productCaption(callback.Message.Chat.ID, product)
+	media.ParseMode = "HTML"
+
+	keyboard := h.keyboards.GetProductKeyboard(h.lang(callback.Message.Chat.ID), product.ID, product.InStock(), photo, len(gallery))
+	edit := tgbotapi.EditMessageMediaConfig{
+		BaseEdit: tgbotapi.BaseEdit{
+			ChatID:      callback.Message.Chat.ID,
+			MessageID:   callback.Message.MessageID,
+			ReplyMarkup: &keyboard,
+		},
+		Media: media,
+	}
+	if _, err := h.bot.Request(edit); err != nil {
+		log.Printf("Error switching product photo: %v", err)
+	}
+	h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+}
+
+// handleAlbum - все фото товара одним альбомом
+func (h *Handler) handleAlbum(callback *tgbotapi.CallbackQuery, productID int64) {
+	chatID := callback.Message.Chat.ID
+
+	product, err := h.repo.GetProductByID(productID)
+	if err != nil || product == nil {
+		if err != nil {
+			log.Printf("Error getting product %d: %v", productID, err)
+		}
+		h.bot.Request(tgbotapi.NewCallback(callback.ID, h.t(callback.Message.Chat.ID, "catalog.product_not_found")))
+		return
+	}
+	h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+
+	gallery := product.Gallery()
+	if len(gallery) < 2 {
+		// Альбом из одного фото Телеграм не отправит
+		h.bot.Send(tgbotapi.NewPhoto(chatID, tgbotapi.FileID(product.ImageID)))
+		return
+	}
+
+	var media []interface{}
+	for i, fileID := range gallery {
+		photo := tgbotapi.NewInputMediaPhoto(tgbotapi.FileID(fileID))
+		if i == 0 {
+			photo.Caption = product.Name // подпись альбома показывается под первым фото
+		}
+		media = append(media, photo)
+	}
+	if _, err := h.bot.SendMediaGroup(tgbotapi.NewMediaGroup(chatID, media)); err != nil {
+		log.Printf("Error sending product album: %v", err)
+	}
+}
@@ -2,22 +2,28 @@
 package telegram
 
 import (
+	"context"
 	"fmt"
 	"log"
-	"strconv"
-	"strings"
 	"time"
 
+	"salle_parfume/internal/delivery/telegram/callbacks"
+	"salle_parfume/internal/delivery/telegram/dialog"
+	"salle_parfume/internal/delivery/telegram/keyboards"
 	"salle_parfume/internal/domain"
 	"salle_parfume/internal/repository"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-// MessageService - интерфейс для сервиса отправки приветсвенного сообщение пользователю
+// MessageService - интерфейс для сервиса исходящих сообщений.
+// Все тексты бота берутся отсюда по ключу на языке пользователя.
 type MessageService interface {
-	GetWelcomeMessage() string
-	GetAboutMessage() string
+	Text(lang, key string, args ...any) string
+	Plural(lang, key string, n int, args ...any) string
+	FormatMoney(lang string, m domain.Money) string
+	Languages() []string
+	MatchLanguage(code string) string
 }
 
 // ActivityLogger - интерфейс то как мы хотим чтобы было логирование телеграмма
@@ -26,32 +32,118 @@ type ActivityLogger interface {
 }
 
 // KeyboardProvider - интерфейс для предоставления клавиатур
+// Все подписи кнопок переводятся, поэтому первым аргументом идет язык пользователя.
 type KeyboardProvider interface {
-	GetMainMenu() tgbotapi.InlineKeyboardMarkup
-	GetProductTypeKeyboard() tgbotapi.InlineKeyboardMarkup // Добавили новый метод
-	GetBuyKeyboard(productID int64) tgbotapi.InlineKeyboardMarkup
+	GetMainMenu(lang string) keyboards.InlineKeyboardMarkup
+	GetProductKeyboard(lang string, productID int64, inStock bool, photo, photoCount int) tgbotapi.InlineKeyboardMarkup
+	GetRatingKeyboard(lang string, productID int64) tgbotapi.InlineKeyboardMarkup
+	GetReviewSkipKeyboard(lang string) tgbotapi.InlineKeyboardMarkup
+	GetModerationKeyboard(lang string, reviewID int64) tgbotapi.InlineKeyboardMarkup
+	GetReviewsPageKeyboard(lang string, productID int64, page, totalPages int, photoReviewIDs []int64) tgbotapi.InlineKeyboardMarkup
+	GetCartKeyboard(lang string, quote *domain.Quote, promoApplied bool, points int64, usePoints bool) tgbotapi.InlineKeyboardMarkup
+	GetLanguageKeyboard(lang string, languages []string) tgbotapi.InlineKeyboardMarkup
+	GetShopTextsKeyboard(lang string, keys []string) tgbotapi.InlineKeyboardMarkup
+	GetShopTextKeyboard(lang, key string) tgbotapi.InlineKeyboardMarkup
+	GetTextPreviewKeyboard(lang string) tgbotapi.InlineKeyboardMarkup
+	GetTextHistoryKeyboard(lang, key string, versions []int) tgbotapi.InlineKeyboardMarkup
+	GetOrderAdminKeyboard(lang string, order *domain.Order) *tgbotapi.InlineKeyboardMarkup // nil - действий больше нет
+	GetOrderCancelKeyboard(lang string, orderID int64) tgbotapi.InlineKeyboardMarkup
+	GetMyOrdersKeyboard(lang string, orders []domain.Order, page int, hasNext bool) tgbotapi.InlineKeyboardMarkup
+	GetMyOrderKeyboard(lang string, order *domain.Order) tgbotapi.InlineKeyboardMarkup
+	GetMyOrderCancelKeyboard(lang string, orderID int64) tgbotapi.InlineKeyboardMarkup
+	GetDeliveryKeyboard(lang string, options []domain.DeliveryOption) tgbotapi.InlineKeyboardMarkup
+	GetLocationKeyboard(lang string) tgbotapi.ReplyKeyboardMarkup
+	GetCartReminderKeyboard(lang string) tgbotapi.InlineKeyboardMarkup
+	GetSubscriptionsKeyboard(lang string, subs []domain.Subscription) tgbotapi.InlineKeyboardMarkup
+	GetInviteKeyboard(lang, link, text string) tgbotapi.InlineKeyboardMarkup
+}
+
+// PricingService - интерфейс расчета стоимости корзины со скидками
+type PricingService interface {
+	CheckPromoCode(chatID int64, code string, lines []domain.CartLine) (*domain.Promotion, error)
+	Calculate(lines []domain.CartLine, promo *domain.Promotion) (*domain.Quote, error)
+}
+
+// OrderService - интерфейс оформления заказа по посчитанной корзине и выбранной доставке
+type OrderService interface {
+	DeliveryOptions(quote *domain.Quote) ([]domain.DeliveryOption, error)
+	Delivery(quote *domain.Quote, choice domain.DeliveryChoice) (domain.OrderDelivery, error)
+	PlaceOrder(chatID int64, quote *domain.Quote, delivery domain.OrderDelivery, usePoints bool) (*domain.Order, error)
+}
+
+// ReferralService - интерфейс реферальной программы: приглашения по ссылке и награды за друзей
+type ReferralService interface {
+	Invite(referrerID, inviteeID int64) (bool, error)
+	Stats(chatID int64) (domain.ReferralStats, error)
+	RewardPercent() float64
+}
+
+// LoyaltyService - интерфейс бонусной программы: баланс, история и сколько можно списать в заказе
+type LoyaltyService interface {
+	Balance(chatID int64) (domain.LoyaltyBalance, error)
+	History(chatID int64, limit, offset int) ([]domain.LoyaltyEntry, error)
+	Spendable(chatID int64, quote *domain.Quote) (int64, error)
+	EarnFor(paid domain.Money) int64
+	EarnPercent() float64
+	MaxSpendPercent() float64
+}
+
+// AbuseService - интерфейс блокировок пользователей
+type AbuseService interface {
+	Ban(ban *domain.Ban) error
+	Unban(userID int64) (bool, error)
+	Bans(limit, offset int) ([]domain.Ban, error)
+}
+
+// BackupService - интерфейс копий базы
+type BackupService interface {
+	Create(ctx context.Context) (string, error)
+	Latest() (string, error)
+}
+
+// CatalogService - интерфейс массового импорта и экспорта каталога
+type CatalogService interface {
+	Import(table [][]string, images map[string][]byte) (*domain.ImportResult, error)
+	Export() ([][]string, error)
+}
+
+// TextService - интерфейс редактируемых текстов магазина (приветствие, о нас, доставка...)
+type TextService interface {
+	Current(key, lang string) (*domain.ShopText, error)
+	Render(key, lang string, vars map[string]string) (string, error)
+	Save(key, lang, body string, authorID int64) (*domain.ShopText, error)
+	History(key, lang string, limit int) ([]domain.ShopText, error)
+	Rollback(key, lang string, version int, authorID int64) (*domain.ShopText, error)
 }
 
 // Состояния FSM (Finite State Machine)
-// Это этапы нашего диалога
+// Это этапы нашего диалога. Многошаговые диалоги (например, /new) ведет движок dialog.
 type State int
 
 const (
-	StateNone                  State = iota
-	StateWaitingForType              // Ждем выбор типа
-	StateWaitingForPhoto             // Ждем фото
-	StateWaitingForName              // Ждем название
-	StateWaitingForDescription       // Ждем описание
-	StateWaitingForPrice             // Ждем цену
+	StateNone                 State = iota
+	StateWaitingForReviewText       // Ждем текст или фото отзыва
+	StateWaitingForPromoCode        // Ждем промокод
+	StateWaitingForShopText         // Ждем новый текст магазина от админа
+	StateWaitingForTracking         // Ждем трек-номер отправленного заказа
+	StateWaitingForAddress          // Ждем адрес доставки или геопозицию для курьера
 )
 
-// DraftProduct - временная структура (черновик), пока мы собираем данные
-type DraftProduct struct {
-	Type        domain.ProductType
-	ImageID     string
-	Name        string
-	Description string
-	Price       float64
+// stateNames - состояния по именам для логов и сообщений об ошибках
+var stateNames = map[State]string{
+	StateNone:                 "none",
+	StateWaitingForReviewText: "review_text",
+	StateWaitingForPromoCode:  "promo_code",
+	StateWaitingForShopText:   "shop_text",
+	StateWaitingForTracking:   "tracking",
+	StateWaitingForAddress:    "address",
+}
+
+func (s State) String() string {
+	if name, ok := stateNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("State(%d)", int(s))
 }
 
 // Handler — это структура, которая знает, как отвечать на сообщения.
@@ -60,31 +152,93 @@ type Handler struct {
 	services  MessageService
 	logger    ActivityLogger
 	keyboards KeyboardProvider
-	repo      repository.ProductRepository // Используем интерфейс, а не конкретную структуру
-	adminID   int64                        // ID админа
-	commands  map[string]func(*tgbotapi.Message)
+	pricing   PricingService
+	catalog   CatalogService
+	texts     TextService
+	orders    OrderService
+	referrals ReferralService
+	loyalty   LoyaltyService
+	abuse     AbuseService
+	backups   BackupService
+	repo      *repository.Repository // Контейнер интерфейсов репозиториев
+	adminID   int64                  // ID админа
+	// Чат (или группа) для новых заказов, кнопки управления заказом работают только в нем
+	ordersChatID int64
+	// Токен платежного провайдера. Если пустой - счет не выставляем, оплата при получении
+	paymentToken string
+	// Включенные части магазина: команд и кнопок выключенных нет
+	features      domain.Features
+	commands      map[string]func(*tgbotapi.Message)
+	commandRoles  map[string]Role   // Какие права нужны для команды (по умолчанию - любой покупатель)
+	callbackRoles map[string]Role   // То же для кнопок, по коду действия
+	callbacks     *callbacks.Router // Обработчики нажатий на inline-кнопки
+	dialogs       *dialog.Engine    // Пошаговые диалоги (добавление товара)
+	middlewares   []Middleware      // Общая обработка всех обновлений (см. Use)
+	pipeline      UpdateHandler     // route, обернутый в middlewares
 
 	// Состояние пользователя (где он сейчас в диалоге)
 	userStates map[int64]State
-	// Черновики товаров для каждого пользователя
-	drafts map[int64]*DraftProduct
+	// Черновики отзывов (оценка уже выбрана, ждем текст или фото)
+	reviewDrafts map[int64]*domain.Review
+	// Примененные к корзине промокоды
+	appliedPromos map[int64]string
+	// Фото из ZIP-архива, присланного админом перед таблицей каталога
+	importImages map[int64]map[string][]byte
+	// Язык пользователя (выбранный через /language или из настроек Телеграма)
+	langs map[int64]string
+	// Черновики текстов магазина, которые админ сейчас редактирует
+	textDrafts map[int64]*domain.ShopText
+	// Заказы, для которых ждем трек-номер (ключ - чат заказов, где нажали "Отправлен")
+	trackingDrafts map[int64]*trackingDraft
+	// Выбранный курьерский способ доставки, пока ждем от покупателя адрес
+	deliveryDrafts map[int64]int64
+	// Покупатели, которые решили оплатить часть заказа бонусами
+	usePoints map[int64]bool
+	// Пользователи, уже сохраненные в базе после запуска (см. UserUpsert)
+	knownUsers map[int64]bool
 }
 
 // NewHandler создает новый обработчик
 // Теперь принимает репозиторий (интерфейс) и ID админа
-func NewHandler(bot *tgbotapi.BotAPI, services MessageService, logger ActivityLogger, keyboards KeyboardProvider, repo repository.ProductRepository, adminID int64) *Handler {
+func NewHandler(bot *tgbotapi.BotAPI, services MessageService, logger ActivityLogger, keyboards KeyboardProvider, pricing PricingService, catalog CatalogService, texts TextService, orders OrderService, referrals ReferralService, loyalty LoyaltyService, abuse AbuseService, backups BackupService, repo *repository.Repository, adminID, ordersChatID int64, paymentToken string, features domain.Features) *Handler {
 	h := &Handler{
-		bot:        bot,
-		services:   services,
-		logger:     logger,
-		keyboards:  keyboards,
-		repo:       repo,
-		adminID:    adminID,
-		commands:   make(map[string]func(*tgbotapi.Message)),
-		userStates: make(map[int64]State),
-		drafts:     make(map[int64]*DraftProduct),
+		bot:            bot,
+		services:       services,
+		logger:         logger,
+		keyboards:      keyboards,
+		pricing:        pricing,
+		catalog:        catalog,
+		texts:          texts,
+		orders:         orders,
+		referrals:      referrals,
+		loyalty:        loyalty,
+		abuse:          abuse,
+		backups:        backups,
+		repo:           repo,
+		adminID:        adminID,
+		ordersChatID:   ordersChatID,
+		paymentToken:   paymentToken,
+		features:       features,
+		commands:       make(map[string]func(*tgbotapi.Message)),
+		commandRoles:   make(map[string]Role),
+		callbackRoles:  make(map[string]Role),
+		callbacks:      callbacks.NewRouter(),
+		userStates:     make(map[int64]State),
+		reviewDrafts:   make(map[int64]*domain.Review),
+		appliedPromos:  make(map[int64]string),
+		importImages:   make(map[int64]map[string][]byte),
+		langs:          make(map[int64]string),
+		textDrafts:     make(map[int64]*domain.ShopText),
+		trackingDrafts: make(map[int64]*trackingDraft),
+		deliveryDrafts: make(map[int64]int64),
+		usePoints:      make(map[int64]bool),
+		knownUsers:     make(map[int64]bool),
 	}
+	h.pipeline = h.route
+	h.dialogs = dialog.NewEngine(bot, h.t, repo)
 	h.initCommands()
+	h.initDialogs()
+	h.initCallbacks()
 	return h
 }
 
@@ -92,22 +246,151 @@ func NewHandler(bot *tgbotapi.BotAPI, services MessageService, logger ActivityLo
 func (h *Handler) initCommands() {
 	h.commands["start"] = h.handleStart
 	h.commands["new"] = h.handleNewProduct
+	h.commands["delivered"] = h.handleDelivered
+	h.commands["reviews"] = h.handlePendingReviews
+	h.commands["cart"] = h.handleCartCommand
+	h.commands["orders"] = h.handleMyOrdersCommand
+	h.commands["reminders"] = h.handleRemindersCommand
+	h.commands["subscriptions"] = h.handleSubscriptionsCommand
+	h.commands["promo_add"] = h.handlePromoAdd
+	h.commands["promo_off"] = h.handlePromoOff
+	h.commands["promo_on"] = h.handlePromoOn
+	h.commands["promos"] = h.handlePromoList
+	h.commands["export"] = h.handleExport
+	h.commands["language"] = h.handleLanguage
+	h.commands["about"] = h.shopTextCommand(domain.TextAbout)
+	h.commands["delivery"] = h.shopTextCommand(domain.TextDelivery)
+	h.commands["contacts"] = h.shopTextCommand(domain.TextContacts)
+	h.commands["faq"] = h.shopTextCommand(domain.TextFAQ)
+	h.commands["texts"] = h.handleShopTexts
+	h.commands["ban"] = h.handleBan
+	h.commands["unban"] = h.handleUnban
+	h.commands["bans"] = h.handleBans
+	h.commands["backup"] = h.handleBackup
+	if h.features.Referrals {
+		h.commands["invite"] = h.handleInviteCommand
+	}
+	if h.features.Loyalty {
+		h.commands["bonus"] = h.handleBonusCommand
+	}
+
+	// Команды админа: права проверяет middleware Auth до вызова обработчика
+	for _, command := range []string{"new", "delivered", "reviews", "promo_add", "promo_off", "promo_on", "promos", "export", "texts", "ban", "unban", "bans", "backup"} {
+		h.commandRoles[command] = RoleAdmin
+	}
 }
 
-// Handle - единая точка входа для обработки обновлений
+// initDialogs регистрирует пошаговые диалоги
+func (h *Handler) initDialogs() {
+	h.dialogs.Register(h.newProductDialog())
+}
+
+// initCallbacks регистрирует обработчики inline-кнопок
+func (h *Handler) initCallbacks() {
+	r := h.callbacks
+	h.registerMenuCallbacks(r)
+	h.dialogs.RegisterCallbacks(r)
+	h.registerGalleryCallbacks(r)
+	h.registerSubscriptionCallbacks(r)
+	h.registerReviewCallbacks(r)
+	h.registerCartCallbacks(r)
+	h.registerMyOrdersCallbacks(r)
+	h.registerShopTextCallbacks(r)
+	h.registerLanguageCallbacks(r)
+	h.registerOrderAdminCallbacks(r)
+
+	// Кнопки админа: права проверяет middleware Auth, как и для команд
+	for _, action := range []string{
+		callbacks.Text.Name(), callbacks.TextEdit.Name(), callbacks.TextHistory.Name(),
+		callbacks.TextRollback.Name(), callbacks.TextSave.Name(), callbacks.TextCancel.Name(),
+		callbacks.ReviewApprove.Name(), callbacks.ReviewReject.Name(),
+	} {
+		h.callbackRoles[action] = RoleAdmin
+	}
+	// Кнопки заказа работают и у всех в чате заказов
+	for _, action := range []string{
+		callbacks.OrderConfirm.Name(), callbacks.OrderShip.Name(), callbacks.OrderDelivered.Name(),
+		callbacks.OrderCancel.Name(), callbacks.OrderCancelYes.Name(), callbacks.OrderBack.Name(),
+	} {
+		h.callbackRoles[action] = RoleOrdersChat
+	}
+}
+
+// registerMenuCallbacks - кнопки главного меню
+func (h *Handler) registerMenuCallbacks(r *callbacks.Router) {
+	// menu - кнопка меню: показывает раздел новым сообщением и убирает часики
+	menu := func(show func(callback *tgbotapi.CallbackQuery)) func(*tgbotapi.CallbackQuery) {
+		return func(callback *tgbotapi.CallbackQuery) {
+			show(callback)
+			h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+		}
+	}
+
+	r.HandleAction(callbacks.Catalog, menu(func(callback *tgbotapi.CallbackQuery) {
+		h.handleCatalog(callback.Message.Chat.ID)
+	}))
+	r.HandleAction(callbacks.About, menu(func(callback *tgbotapi.CallbackQuery) {
+		h.sendShopText(callback.Message.Chat.ID, callback.From, domain.TextAbout, nil)
+	}))
+	// "Помощь" - ответы на частые вопросы
+	r.HandleAction(callbacks.Help, menu(func(callback *tgbotapi.CallbackQuery) {
+		h.sendShopText(callback.Message.Chat.ID, callback.From, domain.TextFAQ, nil)
+	}))
+	// "Пригласить друга" - ссылка и полученные промокоды
+	if h.features.Referrals {
+		r.HandleAction(callbacks.Invite, menu(func(callback *tgbotapi.CallbackQuery) {
+			h.sendInvite(callback.Message.Chat.ID)
+		}))
+	}
+	// "Мои бонусы" - баланс, ближайшее сгорание и история
+	if h.features.Loyalty {
+		r.HandleAction(callbacks.Bonus, menu(func(callback *tgbotapi.CallbackQuery) {
+			h.sendBonus(callback.Message.Chat.ID)
+		}))
+	}
+	// Кнопка-надпись (например, номер страницы)
+	r.HandleAction(callbacks.Noop, menu(func(*tgbotapi.CallbackQuery) {}))
+}
+
+// Handle - единая точка входа для обработки обновлений: цепочка middleware, затем route
 func (h *Handler) Handle(update tgbotapi.Update) {
-	start := time.Now()
+	h.pipeline(update)
+}
 
+// route - передает обновление нужному обработчику
+func (h *Handler) route(update tgbotapi.Update) {
 	// является ли это кнопкой. Если нет, пропускаем
 	if update.CallbackQuery != nil {
 		h.handleCallback(update.CallbackQuery)
 		return
 	}
 
+	// Telegram спрашивает, можно ли принять оплату по счету
+	if update.PreCheckoutQuery != nil {
+		h.handlePreCheckout(update.PreCheckoutQuery)
+		return
+	}
+
 	if update.Message == nil {
 		return
 	}
 
+	// Оплата по счету прошла
+	if update.Message.SuccessfulPayment != nil {
+		h.handleSuccessfulPayment(update.Message)
+		return
+	}
+
+	// Файлы каталога от админа (таблица или архив с фото)
+	if update.Message.Document != nil && h.handleDocument(update.Message) {
+		return
+	}
+
+	// Ответ на шаг диалога (например, /new)
+	if h.dialogs.HandleMessage(update.Message) {
+		return
+	}
+
 	// Проверяем, находится ли пользователь в процессе диалога
 	if state, ok := h.userStates[update.Message.Chat.ID]; ok && state != StateNone {
 		h.handleState(update.Message, state)
@@ -125,153 +408,53 @@ func (h *Handler) Handle(update tgbotapi.Update) {
 	} else {
 		h.handleUnknown(update.Message)
 	}
-
-	h.logger.LogTelegramUsersUse(update.Message.Chat.ID, update.Message.Text, time.Since(start))
-}
-
-// handleNewProduct - начало процесса добавления товара
-func (h *Handler) handleNewProduct(message *tgbotapi.Message) {
-	// Проверка прав доступа (Security)
-	if message.From.ID != h.adminID {
-		h.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "У вас нет прав для этой команды."))
-		return
-	}
-
-	msg := tgbotapi.NewMessage(message.Chat.ID, "Выберите тип духов:")
-	msg.ReplyMarkup = h.keyboards.GetProductTypeKeyboard()
-	h.bot.Send(msg)
-
-	// Переводим пользователя в состояние "Ждем выбор типа"
-	h.userStates[message.Chat.ID] = StateWaitingForType
-	h.drafts[message.Chat.ID] = &DraftProduct{}
 }
 
 // handleCallback - обработка нажатий на кнопки
 func (h *Handler) handleCallback(callback *tgbotapi.CallbackQuery) {
-	// записываем телеграм id клиента
-	chatID := callback.Message.Chat.ID
-	// кнопка которая была нажата
-	data := callback.Data
-
-	log.Printf("Callback: chatID=%d, data=%s", chatID, data)
-
-	// кнопка "Каталог"
-	if data == "catalog" {
-		h.handleCatalog(chatID)
+	// Кнопки под сообщениями, отправленными через inline-режим, приходят без сообщения
+	if callback.Message == nil {
 		h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
 		return
 	}
+	chatID := callback.Message.Chat.ID
 
-	if data == "about" {
-		h.handleAbout(chatID)
-		h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
-		return
-	}
+	log.Printf("Callback: chatID=%d, data=%s", chatID, callback.Data)
 
-	// Обработка кнопки "Купить"
-	if strings.HasPrefix(data, "buy_") {
-		h.handleBuy(chatID, data)
-		h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+	switch h.callbacks.Dispatch(callback) {
+	case callbacks.Handled:
 		return
+	case callbacks.Outdated:
+		log.Printf("Outdated callback from %d: %q", chatID, callback.Data)
+	case callbacks.Invalid:
+		log.Printf("Invalid callback from %d: %q", chatID, callback.Data)
 	}
 
-	// Проверяем, если это выбор типа, но стейт потерян (например, после рестарта бота)
-	if strings.HasPrefix(data, "type_") {
-		state, ok := h.userStates[chatID]
-		if !ok || state != StateWaitingForType {
-			log.Printf("State mismatch or lost context for user %d. State: %v", chatID, state)
-			h.bot.Send(tgbotapi.NewMessage(chatID, "Произошла ошибка (возможно, бот был перезапущен). Пожалуйста, введите /new заново."))
-			h.bot.Request(tgbotapi.NewCallback(callback.ID, "")) // Убираем часики
-			return
-		}
-	}
-
-	// Если ждем тип духов
-	if state, ok := h.userStates[chatID]; ok && state == StateWaitingForType {
-		draft := h.drafts[chatID]
-		if draft == nil {
-			// Если вдруг драфта нет (хотя стейт есть - странно, но подстрахуемся)
-			h.bot.Send(tgbotapi.NewMessage(chatID, "Внутренняя ошибка. Пожалуйста, начните заново /new"))
-			h.userStates[chatID] = StateNone
-			return
-		}
-
-		switch data {
-		case "type_female":
-			draft.Type = domain.TypeFemale
-		case "type_male":
-			draft.Type = domain.TypeMale
-		case "type_unisex":
-			draft.Type = domain.TypeUnisex
-		default:
-			return
-		}
-
-		log.Printf("User %d selected type: %s", chatID, draft.Type)
-
-		// Переходим к следующему шагу
-		h.userStates[chatID] = StateWaitingForPhoto
-		msg := tgbotapi.NewMessage(chatID, "Отправьте фотографию:")
-		h.bot.Send(msg)
-		h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
-	}
+	// Кнопка из старого сообщения (бот обновился) или с испорченными данными:
+	// объясняем и показываем актуальное меню, чтобы часики не висели
+	h.bot.Request(tgbotapi.NewCallback(callback.ID, h.t(chatID, "common.button_outdated")))
+	msg := tgbotapi.NewMessage(chatID, h.t(chatID, "common.current_menu"))
+	msg.ReplyMarkup = h.keyboards.GetMainMenu(h.lang(chatID))
+	h.bot.Send(msg)
 }
 
 // handleState - пошаговая обработка ввода данных
 func (h *Handler) handleState(message *tgbotapi.Message, state State) {
-	chatID := message.Chat.ID
-	draft := h.drafts[chatID]
-
 	switch state {
-	case StateWaitingForPhoto:
-		if message.Photo == nil {
-			h.bot.Send(tgbotapi.NewMessage(chatID, "Пожалуйста, отправьте фото."))
-			return
-		}
-		// Берем самое качественное фото (последнее в массиве)
-		photo := message.Photo[len(message.Photo)-1]
-		draft.ImageID = photo.FileID
-
-		h.userStates[chatID] = StateWaitingForName
-		h.bot.Send(tgbotapi.NewMessage(chatID, "Введите название:"))
-
-	case StateWaitingForName:
-		draft.Name = message.Text
-		h.userStates[chatID] = StateWaitingForDescription
-		h.bot.Send(tgbotapi.NewMessage(chatID, "Введите описание:"))
-
-	case StateWaitingForDescription:
-		draft.Description = message.Text
-		h.userStates[chatID] = StateWaitingForPrice
-		h.bot.Send(tgbotapi.NewMessage(chatID, "Введите цену товара:"))
-
-	case StateWaitingForPrice:
-		price, err := strconv.ParseFloat(message.Text, 64)
-		if err != nil {
-			h.bot.Send(tgbotapi.NewMessage(chatID, "Пожалуйста, введите корректное число."))
-			return
-		}
-		draft.Price = price
-
-		// Сохраняем готовый товар в базу
-		product := &domain.Product{
-			Type:        draft.Type,
-			Name:        draft.Name,
-			Description: draft.Description,
-			Price:       draft.Price,
-			ImageID:     draft.ImageID,
-		}
+	case StateWaitingForReviewText:
+		h.handleReviewInput(message)
 
-		if err := h.repo.CreateProduct(product); err != nil {
-			log.Printf("Error creating product: %v", err)
-			h.bot.Send(tgbotapi.NewMessage(chatID, "Ошибка при сохранении товара."))
-		} else {
-			h.bot.Send(tgbotapi.NewMessage(chatID, "Готово, духи добавлены в каталог!"))
-		}
+	case StateWaitingForPromoCode:
+		h.handlePromoInput(message)
+
+	case StateWaitingForShopText:
+		h.handleShopTextInput(message)
+
+	case StateWaitingForTracking:
+		h.handleTrackingInput(message)
 
-		// Сбрасываем состояние
-		h.userStates[chatID] = StateNone
-		delete(h.drafts, chatID)
+	case StateWaitingForAddress:
+		h.handleAddressInput(message)
 	}
 }
 
@@ -279,56 +462,51 @@ func (h *Handler) handleCatalog(chatID int64) {
 	products, err := h.repo.GetAllProducts()
 	if err != nil {
 		log.Printf("Error getting products: %v", err)
-		h.bot.Send(tgbotapi.NewMessage(chatID, "Ошибка при получении каталога."))
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "catalog.error")))
 		return
 	}
 
 	if len(products) == 0 {
-		h.bot.Send(tgbotapi.NewMessage(chatID, "Каталог пока пуст."))
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "catalog.empty")))
 		return
 	}
 
 	for _, p := range products {
-		text := fmt.Sprintf("<b>%s</b>\n\n%s\n\nЦена: %.2f руб.", p.Name, p.Description, p.Price)
 		msg := tgbotapi.NewPhoto(chatID, tgbotapi.FileID(p.ImageID))
-		msg.Caption = text
+		msg.Caption = h.productCaption(chatID, &p)
 		msg.ParseMode = "HTML"
-		msg.ReplyMarkup = h.keyboards.GetBuyKeyboard(p.ID)
+		msg.ReplyMarkup = h.keyboards.GetProductKeyboard(h.lang(chatID), p.ID, p.InStock(), 0, len(p.Gallery()))
 		h.bot.Send(msg)
 	}
 }
 
-// handleBuy - обработка нажатия кнопки "Купить"
-func (h *Handler) handleBuy(chatID int64, data string) {
-	productID := strings.TrimPrefix(data, "buy_")
-	h.bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Вы выбрали товар с ID: %s. \nФункционал оформления заказа находится в разработке.", productID)))
-}
-
-func (h *Handler) handleAbout(chatID int64) {
-	text := h.services.GetAboutMessage()
-	msg := tgbotapi.NewMessage(chatID, text)
-	msg.ParseMode = "HTML"
+// productCaption - подпись к карточке товара: название, описание, цена, наличие и рейтинг
+func (h *Handler) productCaption(chatID int64, p *domain.Product) string {
+	text := h.t(chatID, "catalog.caption", p.Name, p.Description, h.money(chatID, p.Price))
+	if !p.InStock() {
+		text += "\n" + h.t(chatID, "catalog.out_of_stock")
+	}
 
+	// Добавляем рейтинг, если у товара уже есть опубликованные отзывы
+	rating, err := h.repo.GetProductRating(p.ID)
+	if err != nil {
+		log.Printf("Error getting rating for product %d: %v", p.ID, err)
+	} else if rating.Count > 0 {
+		text += "\n" + h.plural(chatID, "catalog.rating", rating.Count, rating.Average, rating.Count)
+	}
+	return text
 }
 
-// handleStart - обрабатывает команду /start
+// handleStart - обрабатывает команду /start.
+// Запоминаем пользователя и, если он новый и пришел по ссылке друга, записываем приглашение.
 func (h *Handler) handleStart(message *tgbotapi.Message) {
-	// 1. Формируем текст ответа
-	text := h.services.GetWelcomeMessage()
-	// 2. Создаем сообщение для конкретного юзера
-	msg := tgbotapi.NewMessage(message.Chat.ID, text)
-
-	// 3. добавляем клавитуру к /start
-	msg.ReplyMarkup = h.keyboards.GetMainMenu()
-
-	// 4. Отправляем сообщение
-	if _, err := h.bot.Send(msg); err != nil {
-		log.Printf("ошибка отправки сообщения: %v", err)
-	}
+	h.registerUser(message)
+	// Приветствие (его меняет админ через /texts) вместе с главным меню
+	h.sendShopText(message.Chat.ID, message.From, domain.TextWelcome, h.keyboards.GetMainMenu(h.lang(message.Chat.ID)))
 }
 
 // handleUnknown — реакция на неизвестную команду
 func (h *Handler) handleUnknown(message *tgbotapi.Message) {
-	msg := tgbotapi.NewMessage(message.Chat.ID, "Я не знаю такой команды, введите /start")
+	msg := tgbotapi.NewMessage(message.Chat.ID, h.t(message.Chat.ID, "common.unknown_command"))
 	h.bot.Send(msg)
 }
@@ -4,85 +4,434 @@ package keyboards
 
 import (
 	"fmt"
+	"net/url"
+
+	"salle_parfume/internal/delivery/telegram/callbacks"
+	"salle_parfume/internal/domain"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-// Константы для Callback Data кнопок.
-// Использование констант помогает избежать ошибок "magic strings" и упрощает поддержку кода.
-const (
-	ButtonCatalog = "catalog"
-	ButtonAbout   = "about"
-	ButtonHelp    = "help"
-
-	TypeFemale = "type_female"
-	TypeMale   = "type_male"
-	TypeUnisex = "type_unisex"
-
-	PrefixBuy = "buy_%d"
-)
+// Translator - источник переведенных подписей кнопок
+type Translator interface {
+	Text(lang, key string, args ...any) string
+	FormatMoney(lang string, m domain.Money) string
+}
 
 // Service реализует логику создания клавиатур.
 // Он является поставщиком (Provider) разметки для сообщений бота.
-type Service struct{}
+type Service struct {
+	messages  Translator      // подписи кнопок на разных языках
+	webAppURL string          // адрес Mini App магазина (пусто - кнопки "Открыть магазин" нет)
+	features  domain.Features // выключенных частей магазина нет в главном меню
+}
 
 // NewService создает новый экземпляр сервиса клавиатур.
 // Возвращает указатель на структуру Service.
-func NewService() *Service {
-	// В данном случае инициализация простая, но в будущем здесь может быть
-	// внедрение зависимостей (например, локализация).
-	return &Service{}
+func NewService(messages Translator, webAppURL string, features domain.Features) *Service {
+	return &Service{messages: messages, webAppURL: webAppURL, features: features}
 }
 
 // GetMainMenu формирует и возвращает главную Inline-клавиатуру.
 // Обычно отображается после команды /start.
-func (s *Service) GetMainMenu() tgbotapi.InlineKeyboardMarkup {
+// Если настроен Mini App, сверху добавляется кнопка "Открыть магазин".
+func (s *Service) GetMainMenu(lang string) InlineKeyboardMarkup {
 	// Создаем клавиатуру с помощью tgbotapi
 	keyboards := tgbotapi.NewInlineKeyboardMarkup(
 		// Первый ряд кнопок
 		tgbotapi.NewInlineKeyboardRow(
-			// Кнопка "Каталог" отправляет callback_data "catalog"
-			tgbotapi.NewInlineKeyboardButtonData("Каталог", ButtonCatalog),
-			// Кнопка "О нас" отправляет callback_data "about"
-			tgbotapi.NewInlineKeyboardButtonData("О нас", ButtonAbout),
+			// Кнопка "Каталог"
+			tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "buttons.catalog"), callbacks.Catalog.Data()),
+			// Кнопка "О нас"
+			tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "buttons.about"), callbacks.About.Data()),
 		),
 		// Второй ряд кнопок
 		tgbotapi.NewInlineKeyboardRow(
-			// Кнопка "Помощь" отправляет callback_data "help"
-			tgbotapi.NewInlineKeyboardButtonData("Помощь", ButtonHelp),
+			// Кнопка "Корзина"
+			tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "buttons.cart"), callbacks.Cart.Data()),
+			// Кнопка "Помощь"
+			tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "buttons.help"), callbacks.Help.Data()),
+		),
+		// Третий ряд - история заказов и бонусы покупателя
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "buttons.my_orders"), callbacks.MyOrders.Data()),
+		),
+	)
+	if s.features.Loyalty {
+		row := &keyboards.InlineKeyboard[len(keyboards.InlineKeyboard)-1]
+		*row = append(*row, tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "buttons.bonus"), callbacks.Bonus.Data()))
+	}
+	// Четвертый ряд - приглашение друзей
+	if s.features.Referrals {
+		keyboards.InlineKeyboard = append(keyboards.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "buttons.invite"), callbacks.Invite.Data()),
+		))
+	}
+
+	menu := withWebApp(keyboards)
+	if s.webAppURL != "" {
+		shop := []InlineKeyboardButton{NewWebAppButton(s.messages.Text(lang, "buttons.open_shop"), s.webAppURL)}
+		menu.InlineKeyboard = append([][]InlineKeyboardButton{shop}, menu.InlineKeyboard...)
+	}
+	return menu
+}
+
+// GetProductKeyboard генерирует клавиатуру действия для конкретного товара.
+// Принимает productID для формирования уникального callback_data.
+// Если товара нет в наличии, вместо "В корзину" предлагается подписаться на поступление.
+// photo и photoCount - какое фото сейчас показано в карточке и сколько их всего:
+// если фото несколько, добавляется ряд для перелистывания.
+func (s *Service) GetProductKeyboard(lang string, productID int64, inStock bool, photo, photoCount int) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+
+	// Ряд 0: листаем фото по кругу и кнопка, чтобы открыть все фото альбомом
+	if photoCount > 1 {
+		prev := (photo - 1 + photoCount) % photoCount
+		next := (photo + 1) % photoCount
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("◀️", callbacks.Photo.Data(productID, int64(prev))),
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("🖼 %d/%d", photo+1, photoCount), callbacks.Album.Data(productID)),
+			tgbotapi.NewInlineKeyboardButtonData("▶️", callbacks.Photo.Data(productID, int64(next))),
+		))
+	}
+
+	// Ряд 1: покупка и слежение за ценой, а если товар закончился - подписка на поступление
+	buy := tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "buttons.add_to_cart"), callbacks.Buy.Data(productID)),
+		tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "buttons.watch_price"), callbacks.SubscribePrice.Data(productID)),
+	)
+	if !inStock {
+		buy = tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "buttons.notify_restock"), callbacks.SubscribeRestock.Data(productID)),
+		)
+	}
+
+	rows = append(rows,
+		buy,
+		// Ряд 2: отзывы о товаре и возможность оценить его
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "buttons.reviews"), callbacks.Reviews.Data(productID, 0)),
+			tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "buttons.rate"), callbacks.Rate.Data(productID)),
 		),
 	)
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// GetRatingKeyboard создает ряд кнопок со звездами от 1 до 5.
+func (s *Service) GetRatingKeyboard(lang string, productID int64) tgbotapi.InlineKeyboardMarkup {
+	var row []tgbotapi.InlineKeyboardButton
+	for stars := 1; stars <= 5; stars++ {
+		label := fmt.Sprintf("%d ⭐", stars)
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(label, callbacks.Stars.Data(productID, int64(stars))))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(row)
+}
 
-	return keyboards
+// GetReviewSkipKeyboard - кнопка, чтобы оставить только оценку без текста.
+func (s *Service) GetReviewSkipKeyboard(lang string) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "buttons.review_skip"), callbacks.ReviewSkip.Data()),
+		),
+	)
 }
 
-// GetProductTypeKeyboard создает клавиатуру для выбора категории товара.
-// Используется администратором при добавлении новой позиции.
-func (s *Service) GetProductTypeKeyboard() tgbotapi.InlineKeyboardMarkup {
-	// Формируем разметку клавиатуры
+// GetModerationKeyboard - кнопки админа для модерации отзыва.
+func (s *Service) GetModerationKeyboard(lang string, reviewID int64) tgbotapi.InlineKeyboardMarkup {
 	return tgbotapi.NewInlineKeyboardMarkup(
-		// Ряд 1: Основные гендерные типы
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("Женские", TypeFemale),
-			tgbotapi.NewInlineKeyboardButtonData("Мужские", TypeMale),
+			tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "buttons.approve"), callbacks.ReviewApprove.Data(reviewID)),
+			tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "buttons.reject"), callbacks.ReviewReject.Data(reviewID)),
+		),
+	)
+}
+
+// GetReviewsPageKeyboard - навигация по страницам отзывов.
+// photoReviewIDs - отзывы на странице, у которых есть фото (для каждого своя кнопка).
+func (s *Service) GetReviewsPageKeyboard(lang string, productID int64, page, totalPages int, photoReviewIDs []int64) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+
+	// Кнопки с фото к отзывам
+	for i, reviewID := range photoReviewIDs {
+		label := s.messages.Text(lang, "buttons.review_photo", i+1)
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(label, callbacks.ReviewPhoto.Data(reviewID)),
+		))
+	}
+
+	// Навигация: назад, номер страницы, вперед
+	var nav []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("◀️", callbacks.Reviews.Data(productID, int64(page-1))))
+	}
+	nav = append(nav, tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%d/%d", page+1, totalPages), callbacks.Noop.Data()))
+	if page < totalPages-1 {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("▶️", callbacks.Reviews.Data(productID, int64(page+1))))
+	}
+	rows = append(rows, nav)
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// GetCartKeyboard - управление корзиной: количество товаров, промокод и оформление.
+// promoApplied - применен ли промокод (тогда вместо "Промокод" показываем "Убрать промокод").
+// points - сколько бонусов можно списать (0 - кнопки бонусов нет), usePoints - списание уже включено.
+func (s *Service) GetCartKeyboard(lang string, quote *domain.Quote, promoApplied bool, points int64, usePoints bool) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+
+	// По ряду на каждый товар: ➖ название ➕ 🗑
+	for _, line := range quote.Lines {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("➖", callbacks.CartDec.Data(line.ProductID)),
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%s × %d", line.Name, line.Quantity), callbacks.Noop.Data()),
+			tgbotapi.NewInlineKeyboardButtonData("➕", callbacks.CartInc.Data(line.ProductID)),
+			tgbotapi.NewInlineKeyboardButtonData("🗑", callbacks.CartDel.Data(line.ProductID)),
+		))
+	}
+
+	promoButton := tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "buttons.promo"), callbacks.CartPromo.Data())
+	if promoApplied {
+		promoButton = tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "buttons.unpromo"), callbacks.CartUnpromo.Data())
+	}
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			promoButton,
+			tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "buttons.cart_clear"), callbacks.CartClear.Data()),
 		),
-		// Ряд 2: Универсальный тип
+	)
+	if points > 0 {
+		label := s.messages.Text(lang, "buttons.use_points", points)
+		if usePoints {
+			label = s.messages.Text(lang, "buttons.keep_points")
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(label, callbacks.CartPoints.Data()),
+		))
+	}
+	rows = append(rows,
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("Унисекс", TypeUnisex),
+			tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "buttons.checkout"), callbacks.Checkout.Data()),
 		),
 	)
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
 }
 
-// GetBuyKeyboard генерирует клавиатуру действия для конкретного товара.
-// Принимает productID для формирования уникального callback_data.
-func (s *Service) GetBuyKeyboard(productID int64) tgbotapi.InlineKeyboardMarkup {
-	// Формируем строку callback_data с ID товара (например, "buy_123")
-	callbackData := fmt.Sprintf(PrefixBuy, productID)
+// GetDeliveryKeyboard - способы доставки с ценой для текущей корзины, по кнопке на способ.
+// Под ними - возврат в корзину, если покупатель передумал.
+func (s *Service) GetDeliveryKeyboard(lang string, options []domain.DeliveryOption) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, option := range options {
+		cost := s.messages.Text(lang, "checkout.delivery_free")
+		if option.Cost.IsPositive() {
+			cost = s.messages.FormatMoney(lang, option.Cost)
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%s — %s", option.Method.Name, cost), callbacks.Delivery.Data(option.Method.ID)),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "buttons.back_to_cart"), callbacks.Cart.Data()),
+	))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// GetLocationKeyboard - обычная клавиатура с кнопкой "Отправить геопозицию" вместо ввода адреса.
+// Исчезает после нажатия, адрес текстом можно написать как обычно.
+func (s *Service) GetLocationKeyboard(lang string) tgbotapi.ReplyKeyboardMarkup {
+	keyboard := tgbotapi.NewReplyKeyboard(tgbotapi.NewKeyboardButtonRow(
+		tgbotapi.NewKeyboardButtonLocation(s.messages.Text(lang, "buttons.send_location")),
+	))
+	keyboard.OneTimeKeyboard = true
+	return keyboard
+}
+
+// GetCartReminderKeyboard - под напоминанием о корзине: открыть корзину, оформить или отказаться от напоминаний
+func (s *Service) GetCartReminderKeyboard(lang string) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "buttons.cart"), callbacks.Cart.Data()),
+			tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "buttons.checkout"), callbacks.Checkout.Data()),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "buttons.remind_off"), callbacks.RemindOff.Data()),
+		),
+	)
+}
+
+// GetLanguageKeyboard - выбор языка. Каждый язык подписан на самом себе ("English", "Русский"),
+// текущий язык отмечен галочкой.
+func (s *Service) GetLanguageKeyboard(lang string, languages []string) tgbotapi.InlineKeyboardMarkup {
+	var row []tgbotapi.InlineKeyboardButton
+	for _, code := range languages {
+		label := s.messages.Text(code, "language.name")
+		if code == lang {
+			label = "✅ " + label
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(label, callbacks.Language.Data(code)))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(row)
+}
+
+// GetShopTextsKeyboard - список текстов магазина для админа, по кнопке на текст.
+func (s *Service) GetShopTextsKeyboard(lang string, keys []string) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, key := range keys {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "texts.names."+key), callbacks.Text.Data(key)),
+		))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// GetShopTextKeyboard - действия с текстом: изменить или посмотреть историю.
+func (s *Service) GetShopTextKeyboard(lang, key string) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "buttons.text_edit"), callbacks.TextEdit.Data(key)),
+			tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "buttons.text_history"), callbacks.TextHistory.Data(key)),
+		),
+	)
+}
+
+// GetTextPreviewKeyboard - под предпросмотром нового текста: сохранить или отменить.
+func (s *Service) GetTextPreviewKeyboard(lang string) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "buttons.text_save"), callbacks.TextSave.Data()),
+			tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "buttons.text_cancel"), callbacks.TextCancel.Data()),
+		),
+	)
+}
+
+// GetTextHistoryKeyboard - кнопки отката к прошлым версиям текста (по три в ряд).
+func (s *Service) GetTextHistoryKeyboard(lang, key string, versions []int) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+	for _, v := range versions {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "buttons.text_rollback", v), callbacks.TextRollback.Data(key, int64(v))))
+		if len(row) == 3 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// GetOrderAdminKeyboard - действия с заказом в чате заказов.
+// Набор кнопок зависит от статуса: у полученного и отмененного заказа кнопок нет.
+func (s *Service) GetOrderAdminKeyboard(lang string, order *domain.Order) *tgbotapi.InlineKeyboardMarkup {
+	button := func(key string, action callbacks.ID) tgbotapi.InlineKeyboardButton {
+		return tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, key), action.Data(order.ID))
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+	if order.Status.CanBecome(domain.OrderStatusConfirmed) {
+		row = append(row, button("buttons.order_confirm", callbacks.OrderConfirm))
+	}
+	if order.Status.CanBecome(domain.OrderStatusShipped) {
+		row = append(row, button("buttons.order_ship", callbacks.OrderShip))
+	}
+	if order.Status.CanBecome(domain.OrderStatusDelivered) {
+		row = append(row, button("buttons.order_delivered", callbacks.OrderDelivered))
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	if order.Status.CanBecome(domain.OrderStatusCancelled) {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(button("buttons.order_cancel", callbacks.OrderCancel)))
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+	markup := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return &markup
+}
+
+// GetOrderCancelKeyboard - подтверждение отмены заказа, чтобы не отменить случайным нажатием.
+func (s *Service) GetOrderCancelKeyboard(lang string, orderID int64) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "buttons.order_cancel_yes"), callbacks.OrderCancelYes.Data(orderID)),
+			tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "buttons.order_cancel_no"), callbacks.OrderBack.Data(orderID)),
+		),
+	)
+}
+
+// GetMyOrdersKeyboard - список заказов покупателя: по кнопке на заказ и листание страниц
+func (s *Service) GetMyOrdersKeyboard(lang string, orders []domain.Order, page int, hasNext bool) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, order := range orders {
+		label := s.messages.Text(lang, "my_orders.button", order.ID, s.messages.Text(lang, "orders.statuses."+string(order.Status)))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(label, callbacks.MyOrder.Data(order.ID)),
+		))
+	}
+
+	var nav []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("◀️", callbacks.MyOrdersPage.Data(int64(page-1))))
+	}
+	if hasNext {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("▶️", callbacks.MyOrdersPage.Data(int64(page+1))))
+	}
+	if len(nav) > 0 {
+		rows = append(rows, nav)
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// GetSubscriptionsKeyboard - подписки покупателя: кнопка на каждую, чтобы отписаться
+func (s *Service) GetSubscriptionsKeyboard(lang string, subs []domain.Subscription) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, sub := range subs {
+		label := s.messages.Text(lang, "subscriptions.button_"+string(sub.Kind), sub.ProductName)
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(label, callbacks.Unsubscribe.Data(sub.ID)),
+		))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// GetInviteKeyboard - кнопка "Поделиться": Телеграм предложит выбрать чат и отправит туда ссылку с текстом
+func (s *Service) GetInviteKeyboard(lang, link, text string) tgbotapi.InlineKeyboardMarkup {
+	share := "https://t.me/share/url?url=" + url.QueryEscape(link) + "&text=" + url.QueryEscape(text)
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonURL(s.messages.Text(lang, "buttons.share_invite"), share),
+		),
+	)
+}
+
+// GetMyOrderKeyboard - действия покупателя с заказом. Отменить можно только новый заказ.
+func (s *Service) GetMyOrderKeyboard(lang string, order *domain.Order) tgbotapi.InlineKeyboardMarkup {
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "buttons.order_repeat"), callbacks.MyOrderRepeat.Data(order.ID)),
+		),
+	}
+	if order.Status == domain.OrderStatusNew {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "buttons.order_cancel"), callbacks.MyOrderCancel.Data(order.ID)),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "buttons.back_to_orders"), callbacks.MyOrdersPage.Data(0)),
+	))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
 
-	// Создаем клавиатуру с одной кнопкой "Купить"
+// GetMyOrderCancelKeyboard - покупатель подтверждает отмену заказа
+func (s *Service) GetMyOrderCancelKeyboard(lang string, orderID int64) tgbotapi.InlineKeyboardMarkup {
 	return tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("Купить", callbackData),
+			tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "buttons.order_cancel_yes"), callbacks.MyOrderCancelYes.Data(orderID)),
+			tgbotapi.NewInlineKeyboardButtonData(s.messages.Text(lang, "buttons.order_cancel_no"), callbacks.MyOrder.Data(orderID)),
 		),
 	)
 }
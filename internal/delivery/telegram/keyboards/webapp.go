@@ -0,0 +1,43 @@
+// webapp.go - кнопки, открывающие Mini App (магазин внутри Телеграма).
+// В tgbotapi v5.5.1 у кнопок еще нет поля web_app, поэтому добавляем его сами.
+package keyboards
+
+import tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+// WebAppInfo - адрес Mini App (только https)
+type WebAppInfo struct {
+	URL string `json:"url"`
+}
+
+// InlineKeyboardButton - обычная кнопка tgbotapi плюс web_app
+type InlineKeyboardButton struct {
+	tgbotapi.InlineKeyboardButton
+	WebApp *WebAppInfo `json:"web_app,omitempty"`
+}
+
+// InlineKeyboardMarkup - клавиатура, в которой могут быть кнопки Mini App.
+// Ее можно передать в ReplyMarkup любого сообщения вместо tgbotapi.InlineKeyboardMarkup.
+type InlineKeyboardMarkup struct {
+	InlineKeyboard [][]InlineKeyboardButton `json:"inline_keyboard"`
+}
+
+// NewWebAppButton - кнопка, которая открывает Mini App по адресу url
+func NewWebAppButton(text, url string) InlineKeyboardButton {
+	return InlineKeyboardButton{
+		InlineKeyboardButton: tgbotapi.InlineKeyboardButton{Text: text},
+		WebApp:               &WebAppInfo{URL: url},
+	}
+}
+
+// withWebApp - переводит клавиатуру tgbotapi в клавиатуру с поддержкой Mini App
+func withWebApp(markup tgbotapi.InlineKeyboardMarkup) InlineKeyboardMarkup {
+	result := InlineKeyboardMarkup{InlineKeyboard: make([][]InlineKeyboardButton, 0, len(markup.InlineKeyboard))}
+	for _, row := range markup.InlineKeyboard {
+		buttons := make([]InlineKeyboardButton, 0, len(row))
+		for _, b := range row {
+			buttons = append(buttons, InlineKeyboardButton{InlineKeyboardButton: b})
+		}
+		result.InlineKeyboard = append(result.InlineKeyboard, buttons)
+	}
+	return result
+}
@@ -0,0 +1,124 @@
+// language.go — язык пользователя и выбор языка командой /language
+package telegram
+
+import (
+	"log"
+	"strings"
+
+	"salle_parfume/internal/delivery/telegram/callbacks"
+	"salle_parfume/internal/domain"
+	"salle_parfume/internal/i18n"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// detectLanguage - запоминает язык пользователя при первом обращении.
+// Выбранный через /language язык важнее языка из настроек Телеграма.
+func (h *Handler) detectLanguage(chatID int64, from *tgbotapi.User) {
+	if _, ok := h.langs[chatID]; ok {
+		return
+	}
+	if lang := h.storedLanguage(chatID); lang != "" {
+		h.langs[chatID] = lang
+		return
+	}
+	if from != nil {
+		if lang := h.services.MatchLanguage(from.LanguageCode); lang != "" {
+			h.langs[chatID] = lang
+		}
+	}
+}
+
+// lang - язык, на котором пишем пользователю.
+// Для тех, кто еще не писал боту после запуска (например, уведомления), берем сохраненный выбор.
+func (h *Handler) lang(chatID int64) string {
+	if lang, ok := h.langs[chatID]; ok {
+		return lang
+	}
+	lang := h.storedLanguage(chatID)
+	if lang == "" {
+		lang = i18n.DefaultLanguage
+	}
+	h.langs[chatID] = lang
+	return lang
+}
+
+// storedLanguage - язык, выбранный пользователем через /language (пустая строка, если не выбирал)
+func (h *Handler) storedLanguage(chatID int64) string {
+	user, err := h.repo.GetUserByChatID(chatID)
+	if err != nil {
+		log.Printf("Error getting user %d: %v", chatID, err)
+		return ""
+	}
+	if user == nil {
+		return ""
+	}
+	return h.services.MatchLanguage(user.Language)
+}
+
+// t - текст по ключу на языке пользователя
+func (h *Handler) t(chatID int64, key string, args ...any) string {
+	return h.services.Text(h.lang(chatID), key, args...)
+}
+
+// plural - текст с формой множественного числа для n на языке пользователя
+func (h *Handler) plural(chatID int64, key string, n int, args ...any) string {
+	return h.services.Plural(h.lang(chatID), key, n, args...)
+}
+
+// money - сумма в формате языка пользователя
+func (h *Handler) money(chatID int64, m domain.Money) string {
+	return h.services.FormatMoney(h.lang(chatID), m)
+}
+
+// handleLanguage - команда /language [код]: без аргумента показывает кнопки выбора языка
+func (h *Handler) handleLanguage(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+
+	if code := strings.TrimSpace(message.CommandArguments()); code != "" {
+		lang := h.services.MatchLanguage(code)
+		if lang == "" {
+			h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "language.unknown", strings.Join(h.services.Languages(), ", "))))
+			return
+		}
+		h.setLanguage(message.From, chatID, lang)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, h.t(chatID, "language.choose"))
+	msg.ReplyMarkup = h.keyboards.GetLanguageKeyboard(h.lang(chatID), h.services.Languages())
+	h.bot.Send(msg)
+}
+
+// registerLanguageCallbacks - кнопки выбора языка
+func (h *Handler) registerLanguageCallbacks(r *callbacks.Router) {
+	r.HandleKey(callbacks.Language, h.handleLanguageCallback)
+}
+
+// handleLanguageCallback - нажатие на кнопку с языком
+func (h *Handler) handleLanguageCallback(callback *tgbotapi.CallbackQuery, code string) {
+	lang := h.services.MatchLanguage(code)
+	if lang == "" {
+		h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+		return
+	}
+	h.setLanguage(callback.From, callback.Message.Chat.ID, lang)
+	h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+}
+
+// setLanguage - сохраняет выбор языка и заново показывает меню уже на новом языке
+func (h *Handler) setLanguage(from *tgbotapi.User, chatID int64, lang string) {
+	user := &domain.User{ChatID: chatID, Language: lang}
+	if from != nil {
+		user.Username = from.UserName
+		user.FirstName = from.FirstName
+	}
+	if err := h.repo.SetUserLanguage(user); err != nil {
+		log.Printf("Error saving language for %d: %v", chatID, err)
+	}
+	h.langs[chatID] = lang
+
+	msg := tgbotapi.NewMessage(chatID, h.t(chatID, "language.changed"))
+	msg.ReplyMarkup = h.keyboards.GetMainMenu(lang)
+	h.bot.Send(msg)
+}
@@ -0,0 +1,101 @@
+// loyalty.go — бонусы покупателя: экран "Мои бонусы" (команда /bonus) с балансом,
+// ближайшим сгоранием и историей, а также строки про бонусы в корзине
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strings"
+
+	"salle_parfume/internal/domain"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// bonusHistorySize - сколько последних операций показываем на экране бонусов
+const bonusHistorySize = 10
+
+// handleBonusCommand - /bonus: баланс и история бонусов
+func (h *Handler) handleBonusCommand(message *tgbotapi.Message) {
+	h.sendBonus(message.Chat.ID)
+}
+
+// sendBonus - экран "Мои бонусы": сколько бонусов есть, как они начисляются,
+// когда сгорят ближайшие и последние операции
+func (h *Handler) sendBonus(chatID int64) {
+	balance, err := h.loyalty.Balance(chatID)
+	if err != nil {
+		log.Printf("Error getting loyalty balance of %d: %v", chatID, err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "loyalty.error")))
+		return
+	}
+	history, err := h.loyalty.History(chatID, bonusHistorySize, 0)
+	if err != nil {
+		log.Printf("Error getting loyalty history of %d: %v", chatID, err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "loyalty.error")))
+		return
+	}
+
+	lines := []string{
+		h.t(chatID, "loyalty.title"),
+		h.plural(chatID, "loyalty.balance", int(balance.Points), balance.Points, h.money(chatID, domain.PointsValue(balance.Points))),
+	}
+	if balance.Expiring > 0 {
+		lines = append(lines, h.plural(chatID, "loyalty.expiring", int(balance.Expiring), balance.Expiring, balance.ExpiresAt.Format(orderDateLayout)))
+	}
+	lines = append(lines, "", h.t(chatID, "loyalty.how", h.loyalty.EarnPercent(), h.loyalty.MaxSpendPercent()))
+
+	if len(history) > 0 {
+		lines = append(lines, "", h.t(chatID, "loyalty.history_title"))
+		for _, entry := range history {
+			lines = append(lines, fmt.Sprintf("%s  <b>%+d</b>  %s", entry.CreatedAt.Format(orderDateLayout), entry.Points, h.loyaltyEntryText(chatID, entry)))
+		}
+	}
+
+	msg := tgbotapi.NewMessage(chatID, strings.Join(lines, "\n"))
+	msg.ParseMode = "HTML"
+	h.bot.Send(msg)
+}
+
+// loyaltyEntryText - за что начислены или списаны бонусы
+func (h *Handler) loyaltyEntryText(chatID int64, entry domain.LoyaltyEntry) string {
+	if entry.Kind == domain.LoyaltyAdjust {
+		return h.t(chatID, "loyalty.kind_adjust", html.EscapeString(entry.Reason))
+	}
+	if entry.Kind == domain.LoyaltyExpire {
+		return h.t(chatID, "loyalty.kind_expire")
+	}
+	return h.t(chatID, "loyalty.kind_"+string(entry.Kind), entry.OrderID)
+}
+
+// spendablePoints - сколько бонусов покупатель может списать в этой корзине.
+// Если баланс не получить, корзину показываем без бонусов.
+func (h *Handler) spendablePoints(chatID int64, quote *domain.Quote) int64 {
+	points, err := h.loyalty.Spendable(chatID, quote)
+	if err != nil {
+		log.Printf("Error getting spendable points of %d: %v", chatID, err)
+		return 0
+	}
+	return points
+}
+
+// formatQuotePoints - строки про бонусы под итогом корзины: сколько можно списать или уже списано,
+// сколько останется оплатить и сколько бонусов начислим за заказ
+func (h *Handler) formatQuotePoints(chatID int64, quote *domain.Quote, points int64) string {
+	var sb strings.Builder
+	paid := quote.Total
+	switch {
+	case points > 0 && h.usePoints[chatID]:
+		value := domain.PointsValue(points)
+		paid = paid.Sub(value)
+		sb.WriteString("\n" + h.t(chatID, "cart.points_spent", h.money(chatID, value)))
+		sb.WriteString("\n" + h.t(chatID, "cart.to_pay", h.money(chatID, paid)))
+	case points > 0:
+		sb.WriteString("\n" + h.plural(chatID, "cart.points_available", int(points), points))
+	}
+	if earn := h.loyalty.EarnFor(paid); earn > 0 {
+		sb.WriteString("\n" + h.plural(chatID, "cart.points_earn", int(earn), earn))
+	}
+	return sb.String()
+}
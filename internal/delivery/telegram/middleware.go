@@ -0,0 +1,341 @@
+// middleware.go — общая обработка всех обновлений до того, как их получит обработчик:
+// восстановление после паники, логирование, метрики, сохранение пользователя, баны,
+// защита от флуда, права на команды и кнопки и язык. Цепочку собирает app.New через Handler.Use.
+package telegram
+
+import (
+	"fmt"
+	"log"
+	"runtime/debug"
+	"time"
+
+	"salle_parfume/internal/delivery/telegram/callbacks"
+	"salle_parfume/internal/domain"
+	"salle_parfume/internal/service"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// UpdateHandler - обработка одного обновления
+type UpdateHandler func(update tgbotapi.Update)
+
+// Middleware - обертка над обработкой обновления. Не вызвала next - обновление дальше не пошло.
+type Middleware func(next UpdateHandler) UpdateHandler
+
+// UpdateMetrics - интерфейс для метрик обработки обновлений
+type UpdateMetrics interface {
+	ObserveUpdate(kind string, duration time.Duration)
+}
+
+// ErrorReporter - интерфейс сообщений об ошибках админам
+type ErrorReporter interface {
+	Report(alert service.Alert)
+}
+
+// BanList - интерфейс списка заблокированных пользователей
+type BanList interface {
+	IsBanned(userID int64) (bool, error)
+}
+
+// FloodGuard - интерфейс защиты от флуда: решает, обрабатывать ли обновление пользователя
+type FloodGuard interface {
+	Check(userID int64, callback string, now time.Time) (service.FloodVerdict, error)
+}
+
+// Role - права пользователя в боте
+type Role int
+
+const (
+	RoleCustomer   Role = iota // Покупатель
+	RoleOrdersChat             // Любой участник чата заказов (ORDERS_CHAT_ID) - только кнопки заказов в нем
+	RoleAdmin                  // Главный админ (TELEGRAM_ID)
+)
+
+// Виды обновлений для логов и метрик
+const (
+	UpdateMessage     = "message"
+	UpdateCommand     = "command"
+	UpdateCallback    = "callback"
+	UpdatePreCheckout = "pre_checkout"
+	UpdatePayment     = "payment"
+	UpdateOther       = "other"
+)
+
+// Use - добавляет middleware в конец цепочки. Первый добавленный выполняется первым.
+func (h *Handler) Use(middlewares ...Middleware) {
+	h.middlewares = append(h.middlewares, middlewares...)
+	next := UpdateHandler(h.route)
+	for i := len(h.middlewares) - 1; i >= 0; i-- {
+		next = h.middlewares[i](next)
+	}
+	h.pipeline = next
+}
+
+// updateChatID - чат, в котором пришло обновление (0 - не из чата).
+// Оплату Телеграм присылает без чата, но она всегда идет из лички покупателя.
+func updateChatID(update tgbotapi.Update) int64 {
+	switch {
+	case update.Message != nil:
+		return update.Message.Chat.ID
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil:
+		return update.CallbackQuery.Message.Chat.ID
+	case update.PreCheckoutQuery != nil && update.PreCheckoutQuery.From != nil:
+		return update.PreCheckoutQuery.From.ID
+	}
+	return 0
+}
+
+// updateKind - вид обновления для логов и метрик
+func updateKind(update tgbotapi.Update) string {
+	switch {
+	case update.CallbackQuery != nil:
+		return UpdateCallback
+	case update.PreCheckoutQuery != nil:
+		return UpdatePreCheckout
+	case update.Message != nil && update.Message.SuccessfulPayment != nil:
+		return UpdatePayment
+	case update.Message != nil && update.Message.IsCommand():
+		return UpdateCommand
+	case update.Message != nil:
+		return UpdateMessage
+	}
+	return UpdateOther
+}
+
+// updateText - что прислал пользователь: текст сообщения или данные кнопки
+func updateText(update tgbotapi.Update) string {
+	switch {
+	case update.Message != nil:
+		return update.Message.Text
+	case update.CallbackQuery != nil:
+		return update.CallbackQuery.Data
+	}
+	return ""
+}
+
+// role - права отправителя
+func (h *Handler) role(from *tgbotapi.User) Role {
+	if from != nil && from.ID == h.adminID {
+		return RoleAdmin
+	}
+	return RoleCustomer
+}
+
+// callbackRole - права на нажатие: в чате заказов любой его участник получает права чата заказов
+func (h *Handler) callbackRole(callback *tgbotapi.CallbackQuery) Role {
+	role := h.role(callback.From)
+	if role < RoleOrdersChat && callback.Message != nil && callback.Message.Chat.ID == h.ordersChatID {
+		role = RoleOrdersChat
+	}
+	return role
+}
+
+// answerCallback - убирает часики с нажатой кнопки, если обновление - нажатие
+func (h *Handler) answerCallback(update tgbotapi.Update, text string) {
+	if update.CallbackQuery != nil {
+		h.bot.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, text))
+	}
+}
+
+// Recover - паника в обработчике одного обновления не роняет бота. Стек пишем в журнал,
+// админам отправляем сообщение с тем, где это случилось, пользователю - что что-то пошло не так.
+// Состояние пользователя сбрасываем: иначе следующее сообщение упадет на том же месте.
+func (h *Handler) Recover(reporter ErrorReporter) Middleware {
+	return func(next UpdateHandler) UpdateHandler {
+		return func(update tgbotapi.Update) {
+			defer func() {
+				err := recover()
+				if err == nil {
+					return
+				}
+				stack := debug.Stack()
+				log.Printf("Panic while handling update %d: %v\n%s", update.UpdateID, err, stack)
+				reporter.Report(service.Alert{
+					Title:   fmt.Sprintf("Паника: %v", err),
+					Context: h.updateContext(update),
+					Details: string(stack),
+				})
+
+				h.answerCallback(update, "")
+				if chatID := updateChatID(update); chatID != 0 {
+					h.userStates[chatID] = StateNone
+					h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "common.unexpected_error")))
+				}
+			}()
+			next(update)
+		}
+	}
+}
+
+// updateContext - где случилась ошибка: обновление, чат, кто, что прислал, состояние и диалог
+func (h *Handler) updateContext(update tgbotapi.Update) []string {
+	lines := []string{fmt.Sprintf("Обновление: %d (%s)", update.UpdateID, updateKind(update))}
+	chatID := updateChatID(update)
+	if chatID != 0 {
+		lines = append(lines, fmt.Sprintf("Чат: %d", chatID))
+	}
+	if from := update.SentFrom(); from != nil {
+		lines = append(lines, fmt.Sprintf("Пользователь: %s (%d)", telegramUserName(from), from.ID))
+	}
+	switch {
+	case update.Message != nil && update.Message.IsCommand():
+		lines = append(lines, "Команда: /"+update.Message.Command())
+	case update.CallbackQuery != nil:
+		lines = append(lines, "Кнопка: "+update.CallbackQuery.Data)
+	}
+	if chatID == 0 {
+		return lines
+	}
+	if state, ok := h.userStates[chatID]; ok && state != StateNone {
+		lines = append(lines, "Состояние: "+state.String())
+	}
+	if session, err := h.repo.GetDialogSession(chatID); err == nil && session != nil {
+		lines = append(lines, fmt.Sprintf("Диалог: %s, шаг %d", session.Dialog, session.Step))
+	}
+	return lines
+}
+
+// Logging - журнал действий пользователей: что прислали и сколько заняла обработка
+func (h *Handler) Logging() Middleware {
+	return func(next UpdateHandler) UpdateHandler {
+		return func(update tgbotapi.Update) {
+			start := time.Now()
+			next(update)
+			if chatID := updateChatID(update); chatID != 0 {
+				h.logger.LogTelegramUsersUse(chatID, updateText(update), time.Since(start))
+			}
+		}
+	}
+}
+
+// Metrics - время обработки по видам обновлений
+func (h *Handler) Metrics(metrics UpdateMetrics) Middleware {
+	return func(next UpdateHandler) UpdateHandler {
+		return func(update tgbotapi.Update) {
+			start := time.Now()
+			next(update)
+			metrics.ObserveUpdate(updateKind(update), time.Since(start))
+		}
+	}
+}
+
+// ActiveDialogs - сколько пользователей сейчас в пошаговых диалогах (для метрик).
+// Считает по базе, поэтому можно вызывать из другой горутины.
+func (h *Handler) ActiveDialogs() (int, error) {
+	return h.dialogs.CountActive(time.Now())
+}
+
+// UserUpsert - сохраняет пользователя при первом обращении из лички.
+// /start пропускаем: там пользователя сохраняет registerUser, чтобы засчитать приглашение.
+func (h *Handler) UserUpsert() Middleware {
+	return func(next UpdateHandler) UpdateHandler {
+		return func(update tgbotapi.Update) {
+			from := update.SentFrom()
+			isStart := update.Message != nil && update.Message.Command() == "start"
+			if from != nil && !h.knownUsers[from.ID] && updateChatID(update) == from.ID && !isStart {
+				user := &domain.User{ChatID: from.ID, Username: from.UserName, FirstName: from.FirstName}
+				if _, err := h.repo.EnsureUser(user); err != nil {
+					log.Printf("Error saving user %d: %v", from.ID, err)
+				} else {
+					h.knownUsers[from.ID] = true
+				}
+			}
+			next(update)
+		}
+	}
+}
+
+// BanCheck - обновления заблокированных пользователей не обрабатываем.
+// Нажатие все равно отвечаем, чтобы кнопка не висела с часиками.
+func (h *Handler) BanCheck(bans BanList) Middleware {
+	return func(next UpdateHandler) UpdateHandler {
+		return func(update tgbotapi.Update) {
+			if from := update.SentFrom(); from != nil && h.role(from) != RoleAdmin {
+				banned, err := bans.IsBanned(from.ID)
+				if err != nil {
+					log.Printf("Error checking ban of %d: %v", from.ID, err)
+				}
+				if banned {
+					h.answerCallback(update, "")
+					return
+				}
+			}
+			next(update)
+		}
+	}
+}
+
+// AntiFlood - защита от флуда: лишние обновления не обрабатываем, о начавшейся паузе
+// предупреждаем один раз. Админа не ограничиваем.
+func (h *Handler) AntiFlood(guard FloodGuard) Middleware {
+	return func(next UpdateHandler) UpdateHandler {
+		return func(update tgbotapi.Update) {
+			from := update.SentFrom()
+			if from == nil || h.role(from) == RoleAdmin {
+				next(update)
+				return
+			}
+
+			var callback string
+			if update.CallbackQuery != nil {
+				callback = update.CallbackQuery.Data
+			}
+			verdict, err := guard.Check(from.ID, callback, time.Now())
+			if err != nil {
+				log.Printf("Error saving flood event of %d: %v", from.ID, err)
+			}
+			if verdict.Allow {
+				next(update)
+				return
+			}
+
+			chatID := updateChatID(update)
+			if verdict.Cooldown == 0 {
+				h.answerCallback(update, "")
+				return
+			}
+			log.Printf("Flood by %d, pause %s", from.ID, verdict.Cooldown)
+			minutes := int((verdict.Cooldown + time.Minute - 1) / time.Minute)
+			text := h.plural(chatID, "common.flood_cooldown", minutes)
+			if update.Message != nil {
+				h.bot.Send(tgbotapi.NewMessage(chatID, text))
+			}
+			h.answerCallback(update, text)
+		}
+	}
+}
+
+// Locale - определяет язык пользователя до обработчика, чтобы все ответы были на нем
+func (h *Handler) Locale() Middleware {
+	return func(next UpdateHandler) UpdateHandler {
+		return func(update tgbotapi.Update) {
+			if chatID := updateChatID(update); chatID != 0 {
+				h.detectLanguage(chatID, update.SentFrom())
+			}
+			next(update)
+		}
+	}
+}
+
+// Auth - права на команды и кнопки: команды (commandRoles) и кнопки (callbackRoles) админа
+// остальным отвечают отказом
+func (h *Handler) Auth() Middleware {
+	return func(next UpdateHandler) UpdateHandler {
+		return func(update tgbotapi.Update) {
+			if update.Message != nil && update.Message.IsCommand() {
+				if required := h.commandRoles[update.Message.Command()]; h.role(update.Message.From) < required {
+					chatID := update.Message.Chat.ID
+					h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "common.no_rights")))
+					return
+				}
+			}
+			if callback := update.CallbackQuery; callback != nil {
+				if required := h.callbackRoles[callbacks.ActionOf(callback.Data)]; h.callbackRole(callback) < required {
+					h.bot.Request(tgbotapi.NewCallbackWithAlert(callback.ID, h.t(updateChatID(update), "common.no_rights_action")))
+					return
+				}
+			}
+			next(update)
+		}
+	}
+}
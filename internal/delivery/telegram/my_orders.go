@@ -0,0 +1,229 @@
+// my_orders.go — "Мои заказы": история заказов покупателя, карточка заказа,
+// повтор заказа в корзину и отмена, пока заказ еще новый
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strings"
+
+	"salle_parfume/internal/delivery/telegram/callbacks"
+	"salle_parfume/internal/domain"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// myOrdersPageSize - сколько заказов показываем на одной странице списка
+const myOrdersPageSize = 5
+
+// orderDateLayout - дата заказа в списке и карточке
+const orderDateLayout = "02.01.2006"
+
+// registerMyOrdersCallbacks - кнопки раздела "Мои заказы"
+func (h *Handler) registerMyOrdersCallbacks(r *callbacks.Router) {
+	r.HandleAction(callbacks.MyOrders, func(callback *tgbotapi.CallbackQuery) {
+		h.showMyOrders(callback.Message.Chat.ID, 0, 0)
+		h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+	})
+
+	r.HandleID(callbacks.MyOrdersPage, func(callback *tgbotapi.CallbackQuery, page int64) {
+		h.showMyOrders(callback.Message.Chat.ID, callback.Message.MessageID, int(page))
+		h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+	})
+
+	r.HandleID(callbacks.MyOrder, func(callback *tgbotapi.CallbackQuery, orderID int64) {
+		chatID := callback.Message.Chat.ID
+		if order := h.getMyOrder(chatID, orderID); order != nil {
+			h.showMyOrder(chatID, callback.Message.MessageID, order)
+		}
+		h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+	})
+
+	r.HandleID(callbacks.MyOrderRepeat, h.repeatOrder)
+
+	r.HandleID(callbacks.MyOrderCancel, func(callback *tgbotapi.CallbackQuery, orderID int64) {
+		chatID := callback.Message.Chat.ID
+		// Сначала спрашиваем, чтобы не отменить заказ случайным нажатием
+		if order := h.getMyOrder(chatID, orderID); order != nil {
+			h.bot.Send(tgbotapi.NewEditMessageReplyMarkup(chatID, callback.Message.MessageID, h.keyboards.GetMyOrderCancelKeyboard(h.lang(chatID), order.ID)))
+		}
+		h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+	})
+
+	r.HandleID(callbacks.MyOrderCancelYes, h.cancelMyOrder)
+}
+
+// handleMyOrdersCommand - команда /orders
+func (h *Handler) handleMyOrdersCommand(message *tgbotapi.Message) {
+	h.showMyOrders(message.Chat.ID, 0, 0)
+}
+
+// showMyOrders - страница истории заказов: номер, дата, статус, товары и сумма.
+// messageID == 0 - отправить новое сообщение, иначе отредактировать существующее.
+func (h *Handler) showMyOrders(chatID int64, messageID int, page int) {
+	if page < 0 {
+		page = 0
+	}
+	// Берем на один заказ больше, чтобы знать, есть ли следующая страница
+	orders, err := h.repo.GetUserOrders(chatID, myOrdersPageSize+1, page*myOrdersPageSize)
+	if err != nil {
+		log.Printf("Error getting orders of %d: %v", chatID, err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "orders.get_error")))
+		return
+	}
+	if len(orders) == 0 && page == 0 {
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "my_orders.empty")))
+		return
+	}
+	hasNext := len(orders) > myOrdersPageSize
+	if hasNext {
+		orders = orders[:myOrdersPageSize]
+	}
+
+	var sb strings.Builder
+	sb.WriteString(h.t(chatID, "my_orders.title"))
+	for _, order := range orders {
+		var items []string
+		for _, item := range order.Items {
+			items = append(items, fmt.Sprintf("%s × %d", html.EscapeString(item.Name), item.Quantity))
+		}
+		sb.WriteString("\n\n" + h.t(chatID, "my_orders.line", order.ID, order.CreatedAt.Local().Format(orderDateLayout),
+			h.t(chatID, "orders.statuses."+string(order.Status))))
+		sb.WriteString("\n" + strings.Join(items, ", "))
+		sb.WriteString("\n" + h.t(chatID, "cart.total", h.money(chatID, order.Total)))
+	}
+
+	keyboard := h.keyboards.GetMyOrdersKeyboard(h.lang(chatID), orders, page, hasNext)
+	if messageID != 0 {
+		edit := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, sb.String(), keyboard)
+		edit.ParseMode = "HTML"
+		h.bot.Send(edit)
+		return
+	}
+	msg := tgbotapi.NewMessage(chatID, sb.String())
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = keyboard
+	h.bot.Send(msg)
+}
+
+// getMyOrder - заказ покупателя по номеру. Чужие заказы не показываем, как и несуществующие.
+func (h *Handler) getMyOrder(chatID, orderID int64) *domain.Order {
+	order, err := h.repo.GetOrderByID(orderID)
+	if err != nil {
+		log.Printf("Error getting order %d: %v", orderID, err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "orders.get_error")))
+		return nil
+	}
+	if order == nil || order.ChatID != chatID {
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "orders.not_found", orderID)))
+		return nil
+	}
+	return order
+}
+
+// showMyOrder - карточка заказа вместо списка: состав, суммы, статус и трек-номер
+func (h *Handler) showMyOrder(chatID int64, messageID int, order *domain.Order) {
+	text := h.t(chatID, "orders.title", order.ID) + " " + h.t(chatID, "my_orders.date", order.CreatedAt.Local().Format(orderDateLayout)) +
+		"\n\n" + h.formatOrder(chatID, order) +
+		"\n\n" + h.t(chatID, "orders.status", h.t(chatID, "orders.statuses."+string(order.Status)))
+	if order.TrackingNumber != "" {
+		text += "\n" + h.t(chatID, "orders.tracking", html.EscapeString(order.TrackingNumber))
+	}
+
+	edit := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, text, h.keyboards.GetMyOrderKeyboard(h.lang(chatID), order))
+	edit.ParseMode = "HTML"
+	h.bot.Send(edit)
+}
+
+// repeatOrder - кладет в корзину те же товары в том же количестве.
+// Цены будут текущие, а товары, которых больше нет в каталоге или нет в наличии, пропускаются.
+func (h *Handler) repeatOrder(callback *tgbotapi.CallbackQuery, orderID int64) {
+	chatID := callback.Message.Chat.ID
+	order := h.getMyOrder(chatID, orderID)
+	if order == nil {
+		h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+		return
+	}
+
+	var added int
+	var missing, soldOut []string
+	for _, item := range order.Items {
+		product, err := h.repo.GetProductByID(item.ProductID)
+		if err != nil {
+			log.Printf("Error getting product %d: %v", item.ProductID, err)
+		}
+		if product == nil {
+			missing = append(missing, item.Name)
+			continue
+		}
+		if !product.InStock() {
+			soldOut = append(soldOut, product.Name)
+			continue
+		}
+		if err := h.repo.AddToCart(chatID, item.ProductID, item.Quantity); err != nil {
+			log.Printf("Error adding product %d to cart: %v", item.ProductID, err)
+			missing = append(missing, item.Name)
+			continue
+		}
+		added++
+	}
+
+	if len(missing) > 0 {
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "my_orders.missing", strings.Join(missing, ", "))))
+	}
+	if len(soldOut) > 0 {
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "my_orders.sold_out", strings.Join(soldOut, ", "))))
+	}
+	if added == 0 {
+		h.bot.Request(tgbotapi.NewCallbackWithAlert(callback.ID, h.t(chatID, "my_orders.nothing_added")))
+		return
+	}
+	h.bot.Request(tgbotapi.NewCallback(callback.ID, h.t(chatID, "cart.added")))
+	h.handleCart(chatID, 0)
+}
+
+// cancelMyOrder - покупатель отменяет заказ. Можно только пока заказ новый:
+// оплаченный или подтвержденный заказ отменяет магазин.
+func (h *Handler) cancelMyOrder(callback *tgbotapi.CallbackQuery, orderID int64) {
+	chatID := callback.Message.Chat.ID
+	order := h.getMyOrder(chatID, orderID)
+	if order == nil {
+		h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+		return
+	}
+
+	cancelled, err := h.repo.CancelUserOrder(chatID, order.ID)
+	if err != nil {
+		log.Printf("Error cancelling order %d: %v", order.ID, err)
+		h.bot.Request(tgbotapi.NewCallbackWithAlert(callback.ID, h.t(chatID, "orders.update_error")))
+		return
+	}
+	if !cancelled {
+		// Пока покупатель думал, магазин уже взял заказ в работу
+		if fresh, err := h.repo.GetOrderByID(order.ID); err == nil && fresh != nil {
+			order = fresh
+		}
+		h.showMyOrder(chatID, callback.Message.MessageID, order)
+		h.bot.Request(tgbotapi.NewCallbackWithAlert(callback.ID, h.t(chatID, "my_orders.cannot_cancel", h.t(chatID, "orders.statuses."+string(order.Status)))))
+		return
+	}
+
+	entry := &domain.AuditEntry{
+		Actor:    "telegram:" + telegramUserName(callback.From),
+		Action:   domain.AuditOrderStatus,
+		Object:   domain.AuditObjectOrder,
+		ObjectID: order.ID,
+		Details:  fmt.Sprintf("%s → %s", order.Status, domain.OrderStatusCancelled),
+	}
+	if err := h.repo.AddAuditEntry(entry); err != nil {
+		log.Printf("Error adding audit entry for order %d: %v", order.ID, err)
+	}
+	order.Status = domain.OrderStatusCancelled
+
+	h.showMyOrder(chatID, callback.Message.MessageID, order)
+	h.bot.Request(tgbotapi.NewCallback(callback.ID, h.t(chatID, "my_orders.cancelled")))
+
+	// Админы видят отмену в чате заказов
+	h.sendOrderCard(order, "orders_admin.cancelled_by_customer")
+}
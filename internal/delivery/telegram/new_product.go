@@ -0,0 +1,104 @@
+// new_product.go — добавление товара админом командой /new: пошаговый диалог
+// (тип, фото, название, описание, цена) с подтверждением перед сохранением
+package telegram
+
+import (
+	"html"
+	"log"
+	"time"
+
+	"salle_parfume/internal/delivery/telegram/dialog"
+	"salle_parfume/internal/domain"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// dialogNewProduct - имя диалога добавления товара
+const dialogNewProduct = "new_product"
+
+// newProductTimeout - сколько ждем, пока админ закончит с товаром (фото может быть долго искать)
+const newProductTimeout = 24 * time.Hour
+
+// handleNewProduct - начало процесса добавления товара
+func (h *Handler) handleNewProduct(message *tgbotapi.Message) {
+	h.userStates[message.Chat.ID] = StateNone
+	h.dialogs.Start(message.Chat.ID, dialogNewProduct)
+}
+
+// newProductDialog - шаги добавления товара
+func (h *Handler) newProductDialog() *dialog.Dialog {
+	return &dialog.Dialog{
+		Name:    dialogNewProduct,
+		Timeout: newProductTimeout,
+		Steps: []dialog.Step{
+			{
+				Key:    "type",
+				Prompt: "new_product.choose_type",
+				Input:  dialog.InputChoice,
+				Choices: []dialog.Choice{
+					{Value: string(domain.TypeFemale), Label: "buttons.type_female"},
+					{Value: string(domain.TypeMale), Label: "buttons.type_male"},
+					{Value: string(domain.TypeUnisex), Label: "buttons.type_unisex"},
+				},
+			},
+			{
+				Key:        "photos",
+				Prompt:     "new_product.send_photos",
+				PromptArgs: []any{domain.MaxProductImages},
+				Input:      dialog.InputPhotos,
+				Max:        domain.MaxProductImages,
+			},
+			{Key: "name", Prompt: "new_product.enter_name", Input: dialog.InputText},
+			{Key: "description", Prompt: "new_product.enter_description", Input: dialog.InputText},
+			{Key: "price", Prompt: "new_product.enter_price", Input: dialog.InputText, Validate: validateProductPrice},
+		},
+		Summary: h.newProductSummary,
+		Finish:  h.saveNewProduct,
+	}
+}
+
+// validateProductPrice - цена числом, без отрицательных и явно ошибочных (лишние нули и т.п.)
+func validateProductPrice(value string) error {
+	price, err := domain.ParseMoney(value, domain.DefaultCurrency)
+	if err != nil {
+		return dialog.Invalid("new_product.price_not_number")
+	}
+	if err := domain.ValidatePrice(price); err != nil {
+		return dialog.Invalid("new_product.price_invalid", err)
+	}
+	return nil
+}
+
+// newProductFromDialog - товар из ответов диалога (цена уже проверена на шаге)
+func newProductFromDialog(session *domain.DialogSession) *domain.Product {
+	price, _ := domain.ParseMoney(session.Value("price"), domain.DefaultCurrency)
+	return &domain.Product{
+		Type:        domain.ProductType(session.Value("type")),
+		Name:        session.Value("name"),
+		Description: session.Value("description"),
+		Price:       price,
+		Images:      session.Values["photos"],
+	}
+}
+
+// newProductSummary - товар целиком перед сохранением
+func (h *Handler) newProductSummary(chatID int64, session *domain.DialogSession) string {
+	product := newProductFromDialog(session)
+	return h.t(chatID, "new_product.summary",
+		html.EscapeString(product.Name),
+		html.EscapeString(product.Description),
+		h.t(chatID, "buttons.type_"+string(product.Type)),
+		len(product.Images),
+		h.money(chatID, product.Price),
+	)
+}
+
+// saveNewProduct - сохраняем готовый товар в базу
+func (h *Handler) saveNewProduct(chatID int64, session *domain.DialogSession) {
+	if err := h.repo.CreateProduct(newProductFromDialog(session)); err != nil {
+		log.Printf("Error creating product: %v", err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "new_product.save_error")))
+		return
+	}
+	h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "new_product.saved")))
+}
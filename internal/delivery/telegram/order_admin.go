@@ -0,0 +1,289 @@
+// order_admin.go — новые заказы в чате заказов и работа с ними кнопками:
+// подтвердить, отправить с трек-номером, отменить. Покупатель узнает о каждом шаге сам.
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"salle_parfume/internal/delivery/telegram/callbacks"
+	"salle_parfume/internal/domain"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// noTrackingNumber - ответ админа, если заказ отправлен без трек-номера
+const noTrackingNumber = "-"
+
+// trackingDraft - заказ, для которого админ нажал "Отправлен" и теперь вводит трек-номер
+type trackingDraft struct {
+	OrderID   int64
+	MessageID int    // Сообщение заказа в чате заказов, его обновим после ввода
+	UserID    int64  // Кто нажал кнопку: в группе трек-номер принимаем только от него
+	HandledBy string // Подпись "кто обработал" для сообщения заказа
+}
+
+// notifyNewOrder - присылает новый заказ в чат заказов с кнопками управления
+func (h *Handler) notifyNewOrder(order *domain.Order) {
+	h.sendOrderCard(order, "orders_admin.new")
+}
+
+// sendOrderCard - карточка заказа для админов: состав, покупатель, статус и кнопки
+func (h *Handler) sendOrderCard(order *domain.Order, titleKey string) {
+	chatID := h.ordersChatID
+	msg := tgbotapi.NewMessage(chatID, h.t(chatID, titleKey, order.ID)+"\n\n"+h.adminOrderText(order, ""))
+	msg.ParseMode = "HTML"
+	if keyboard := h.keyboards.GetOrderAdminKeyboard(h.lang(chatID), order); keyboard != nil {
+		msg.ReplyMarkup = keyboard
+	}
+	if _, err := h.bot.Send(msg); err != nil {
+		log.Printf("Error sending order %d to orders chat: %v", order.ID, err)
+	}
+}
+
+// adminOrderText - текст карточки заказа без заголовка
+func (h *Handler) adminOrderText(order *domain.Order, handledBy string) string {
+	chatID := h.ordersChatID
+
+	// Имя и username берем из базы, если покупатель уже писал боту
+	name := strconv.FormatInt(order.ChatID, 10)
+	var username string
+	if user, err := h.repo.GetUserByChatID(order.ChatID); err != nil {
+		log.Printf("Error getting customer %d: %v", order.ChatID, err)
+	} else if user != nil {
+		if user.FirstName != "" {
+			name = user.FirstName
+		}
+		if user.Username != "" {
+			username = " @" + user.Username
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(h.formatOrder(chatID, order))
+	sb.WriteString("\n\n" + h.t(chatID, "orders_admin.customer", order.ChatID, html.EscapeString(name), html.EscapeString(username), order.ChatID))
+	sb.WriteString("\n" + h.t(chatID, "orders_admin.status", h.t(chatID, "orders.statuses."+string(order.Status))))
+	if order.TrackingNumber != "" {
+		sb.WriteString("\n" + h.t(chatID, "orders_admin.tracking", html.EscapeString(order.TrackingNumber)))
+	}
+	if handledBy != "" {
+		sb.WriteString("\n" + h.t(chatID, "orders_admin.handled_by", html.EscapeString(handledBy), time.Now().Format("02.01 15:04")))
+	}
+	return sb.String()
+}
+
+// telegramUserName - как подписать пользователя в карточке заказа и в журнале
+func telegramUserName(user *tgbotapi.User) string {
+	if user.UserName != "" {
+		return "@" + user.UserName
+	}
+	if name := strings.TrimSpace(user.FirstName + " " + user.LastName); name != "" {
+		return name
+	}
+	return strconv.FormatInt(user.ID, 10)
+}
+
+// registerOrderAdminCallbacks - кнопки под карточкой заказа.
+// Работают в чате заказов (там все участники - сотрудники магазина) и у главного админа.
+func (h *Handler) registerOrderAdminCallbacks(r *callbacks.Router) {
+	r.HandleID(callbacks.OrderConfirm, h.orderAdminAction(func(callback *tgbotapi.CallbackQuery, order *domain.Order) {
+		h.changeOrderStatus(callback, order, domain.OrderStatusConfirmed)
+	}))
+
+	r.HandleID(callbacks.OrderShip, h.orderAdminAction(func(callback *tgbotapi.CallbackQuery, order *domain.Order) {
+		if !order.Status.CanBecome(domain.OrderStatusShipped) {
+			h.orderStatusConflict(callback, order)
+			return
+		}
+		chatID := callback.Message.Chat.ID
+		messageID := callback.Message.MessageID
+		// Статус сменим, когда админ пришлет трек-номер (или "-", если его нет)
+		h.trackingDrafts[chatID] = &trackingDraft{OrderID: order.ID, MessageID: messageID, UserID: callback.From.ID, HandledBy: telegramUserName(callback.From)}
+		h.userStates[chatID] = StateWaitingForTracking
+		msg := tgbotapi.NewMessage(chatID, h.t(chatID, "orders_admin.ask_tracking", order.ID, noTrackingNumber))
+		// В группе бот без прав админа видит только ответы на свои сообщения
+		msg.ReplyMarkup = tgbotapi.ForceReply{ForceReply: true}
+		msg.ReplyToMessageID = messageID
+		h.bot.Send(msg)
+		h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+	}))
+
+	r.HandleID(callbacks.OrderDelivered, h.orderAdminAction(func(callback *tgbotapi.CallbackQuery, order *domain.Order) {
+		h.changeOrderStatus(callback, order, domain.OrderStatusDelivered)
+	}))
+
+	r.HandleID(callbacks.OrderCancel, h.orderAdminAction(func(callback *tgbotapi.CallbackQuery, order *domain.Order) {
+		// Сначала спрашиваем, чтобы не отменить заказ случайным нажатием
+		if !order.Status.CanBecome(domain.OrderStatusCancelled) {
+			h.orderStatusConflict(callback, order)
+			return
+		}
+		chatID := callback.Message.Chat.ID
+		h.bot.Send(tgbotapi.NewEditMessageReplyMarkup(chatID, callback.Message.MessageID, h.keyboards.GetOrderCancelKeyboard(h.lang(chatID), order.ID)))
+		h.bot.Request(tgbotapi.NewCallback(callback.ID, h.t(chatID, "orders_admin.cancel_confirm")))
+	}))
+
+	r.HandleID(callbacks.OrderCancelYes, h.orderAdminAction(func(callback *tgbotapi.CallbackQuery, order *domain.Order) {
+		h.changeOrderStatus(callback, order, domain.OrderStatusCancelled)
+	}))
+
+	r.HandleID(callbacks.OrderBack, h.orderAdminAction(func(callback *tgbotapi.CallbackQuery, order *domain.Order) {
+		h.updateOrderCard(callback.Message.Chat.ID, callback.Message.MessageID, order, "")
+		h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+	}))
+}
+
+// orderAdminAction - общая часть кнопок заказа: загрузка заказа. Права (админ или чат заказов)
+// проверяет middleware Auth.
+func (h *Handler) orderAdminAction(do func(callback *tgbotapi.CallbackQuery, order *domain.Order)) func(*tgbotapi.CallbackQuery, int64) {
+	return func(callback *tgbotapi.CallbackQuery, orderID int64) {
+		chatID := callback.Message.Chat.ID
+
+		order, err := h.repo.GetOrderByID(orderID)
+		if err != nil || order == nil {
+			log.Printf("Error getting order %d: %v", orderID, err)
+			h.bot.Request(tgbotapi.NewCallbackWithAlert(callback.ID, h.t(chatID, "orders.not_found", orderID)))
+			return
+		}
+		do(callback, order)
+	}
+}
+
+// changeOrderStatus - смена статуса кнопкой: сохраняем, обновляем карточку и сообщаем покупателю
+func (h *Handler) changeOrderStatus(callback *tgbotapi.CallbackQuery, order *domain.Order, status domain.OrderStatus) {
+	chatID := callback.Message.Chat.ID
+	if !order.Status.CanBecome(status) {
+		h.orderStatusConflict(callback, order)
+		return
+	}
+	err := h.applyOrderStatus(order, status, telegramUserName(callback.From))
+	if errors.Is(err, domain.ErrStatusChange) {
+		// Статус успел смениться в веб-админке или через API: показываем, какой он теперь
+		if fresh, getErr := h.repo.GetOrderByID(order.ID); getErr == nil && fresh != nil {
+			order = fresh
+		}
+		h.orderStatusConflict(callback, order)
+		return
+	}
+	if err != nil {
+		log.Printf("Error updating order %d: %v", order.ID, err)
+		h.bot.Request(tgbotapi.NewCallbackWithAlert(callback.ID, h.t(chatID, "orders.update_error")))
+		return
+	}
+	h.updateOrderCard(chatID, callback.Message.MessageID, order, telegramUserName(callback.From))
+	h.bot.Request(tgbotapi.NewCallback(callback.ID, h.t(chatID, "orders_admin.status_changed", h.t(chatID, "orders.statuses."+string(status)))))
+}
+
+// orderStatusConflict - заказ уже обработал кто-то другой (например, в веб-админке).
+// Показываем актуальное состояние, чтобы кнопки не врали.
+func (h *Handler) orderStatusConflict(callback *tgbotapi.CallbackQuery, order *domain.Order) {
+	chatID := callback.Message.Chat.ID
+	h.updateOrderCard(chatID, callback.Message.MessageID, order, "")
+	h.bot.Request(tgbotapi.NewCallbackWithAlert(callback.ID, h.t(chatID, "orders_admin.not_allowed", h.t(chatID, "orders.statuses."+string(order.Status)))))
+}
+
+// applyOrderStatus - сохраняет новый статус, пишет журнал и сообщает покупателю
+func (h *Handler) applyOrderStatus(order *domain.Order, status domain.OrderStatus, actor string) error {
+	if err := h.repo.UpdateOrderStatus(order.ID, status); err != nil {
+		return err
+	}
+	entry := &domain.AuditEntry{
+		Actor:    "telegram:" + actor,
+		Action:   domain.AuditOrderStatus,
+		Object:   domain.AuditObjectOrder,
+		ObjectID: order.ID,
+		Details:  fmt.Sprintf("%s → %s", order.Status, status),
+	}
+	if err := h.repo.AddAuditEntry(entry); err != nil {
+		log.Printf("Error adding audit entry for order %d: %v", order.ID, err)
+	}
+	order.Status = status
+
+	customer := order.ChatID
+	switch status {
+	case domain.OrderStatusConfirmed:
+		h.bot.Send(tgbotapi.NewMessage(customer, h.t(customer, "orders.confirmed", order.ID)))
+	case domain.OrderStatusShipped:
+		text := h.t(customer, "orders.shipped", order.ID)
+		if order.TrackingNumber != "" {
+			text += "\n" + h.t(customer, "orders.tracking", html.EscapeString(order.TrackingNumber))
+		}
+		msg := tgbotapi.NewMessage(customer, text)
+		msg.ParseMode = "HTML"
+		h.bot.Send(msg)
+	case domain.OrderStatusCancelled:
+		h.bot.Send(tgbotapi.NewMessage(customer, h.t(customer, "orders.cancelled", order.ID)))
+	case domain.OrderStatusDelivered:
+		h.askForReviews(order)
+	}
+	return nil
+}
+
+// updateOrderCard - перерисовывает карточку заказа по его текущему состоянию.
+// Пометка "новый" в заголовке после обработки уже не нужна, поэтому заголовок обычный.
+func (h *Handler) updateOrderCard(chatID int64, messageID int, order *domain.Order, handledBy string) {
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, h.t(chatID, "orders.title", order.ID)+"\n\n"+h.adminOrderText(order, handledBy))
+	edit.ParseMode = "HTML"
+	edit.ReplyMarkup = h.keyboards.GetOrderAdminKeyboard(h.lang(chatID), order)
+	if _, err := h.bot.Send(edit); err != nil {
+		log.Printf("Error updating order %d card: %v", order.ID, err)
+	}
+}
+
+// handleTrackingInput - админ прислал трек-номер отправленного заказа
+func (h *Handler) handleTrackingInput(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	draft := h.trackingDrafts[chatID]
+	if draft == nil {
+		h.userStates[chatID] = StateNone
+		return
+	}
+	// В группе пишут и другие сотрудники - ждем ответа именно от нажавшего кнопку
+	if message.From == nil || message.From.ID != draft.UserID {
+		return
+	}
+
+	tracking := strings.TrimSpace(message.Text)
+	if tracking == "" || len(tracking) > domain.MaxTrackingNumberLength {
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "orders_admin.tracking_invalid", domain.MaxTrackingNumberLength, noTrackingNumber)))
+		return
+	}
+	if tracking == noTrackingNumber {
+		tracking = ""
+	}
+	delete(h.trackingDrafts, chatID)
+	h.userStates[chatID] = StateNone
+
+	order, err := h.repo.GetOrderByID(draft.OrderID)
+	if err != nil || order == nil {
+		log.Printf("Error getting order %d: %v", draft.OrderID, err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "orders.get_error")))
+		return
+	}
+	if !order.Status.CanBecome(domain.OrderStatusShipped) {
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "orders_admin.not_allowed", h.t(chatID, "orders.statuses."+string(order.Status)))))
+		return
+	}
+
+	if tracking != "" {
+		if err := h.repo.SetTrackingNumber(order.ID, tracking); err != nil {
+			log.Printf("Error saving tracking number for order %d: %v", order.ID, err)
+			h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "orders.update_error")))
+			return
+		}
+		order.TrackingNumber = tracking
+	}
+	if err := h.applyOrderStatus(order, domain.OrderStatusShipped, draft.HandledBy); err != nil {
+		log.Printf("Error updating order %d: %v", order.ID, err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "orders.update_error")))
+		return
+	}
+
+	h.updateOrderCard(chatID, draft.MessageID, order, draft.HandledBy)
+	h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "orders_admin.status_changed", h.t(chatID, "orders.statuses."+string(order.Status)))))
+}
@@ -0,0 +1,382 @@
+// orders.go — оформление заказов, оплата и смена их статуса админом
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+
+	"salle_parfume/internal/domain"
+	"salle_parfume/internal/service"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleCheckout - оформление заказа из корзины: сначала выбор доставки.
+// Если магазин способы доставки не настроил, заказ оформляется сразу.
+func (h *Handler) handleCheckout(chatID int64) {
+	quote := h.checkoutQuote(chatID)
+	if quote == nil {
+		return
+	}
+
+	options, err := h.orders.DeliveryOptions(quote)
+	if err != nil {
+		log.Printf("Error getting delivery options: %v", err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "checkout.error")))
+		return
+	}
+	if len(options) == 0 {
+		h.placeOrder(chatID, domain.DeliveryChoice{})
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, h.t(chatID, "checkout.choose_delivery", h.money(chatID, quote.Total)))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = h.keyboards.GetDeliveryKeyboard(h.lang(chatID), options)
+	h.bot.Send(msg)
+}
+
+// handleDeliveryChoice - покупатель выбрал способ доставки.
+// Самовывоз оформляем сразу, для курьера сначала спрашиваем адрес.
+func (h *Handler) handleDeliveryChoice(chatID, methodID int64) {
+	method, err := h.repo.GetDeliveryMethodByID(methodID)
+	if err != nil {
+		log.Printf("Error getting delivery method %d: %v", methodID, err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "checkout.error")))
+		return
+	}
+	if method == nil || !method.Active {
+		// Админ выключил способ, пока покупатель выбирал - показываем актуальный список
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "checkout.delivery_unavailable")))
+		h.handleCheckout(chatID)
+		return
+	}
+
+	if !method.NeedsAddress() {
+		h.placeOrder(chatID, domain.DeliveryChoice{MethodID: method.ID})
+		return
+	}
+
+	h.deliveryDrafts[chatID] = method.ID
+	h.userStates[chatID] = StateWaitingForAddress
+	msg := tgbotapi.NewMessage(chatID, h.t(chatID, "checkout.enter_address", html.EscapeString(method.Name)))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = h.keyboards.GetLocationKeyboard(h.lang(chatID))
+	h.bot.Send(msg)
+}
+
+// handleAddressInput - адрес доставки текстом или геопозиция для курьера.
+// Команда вместо адреса отменяет оформление, корзина остается как была.
+func (h *Handler) handleAddressInput(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	if message.IsCommand() {
+		h.userStates[chatID] = StateNone
+		delete(h.deliveryDrafts, chatID)
+		msg := tgbotapi.NewMessage(chatID, h.t(chatID, "checkout.address_cancelled"))
+		msg.ReplyMarkup = tgbotapi.NewRemoveKeyboard(false)
+		h.bot.Send(msg)
+		return
+	}
+
+	choice := domain.DeliveryChoice{MethodID: h.deliveryDrafts[chatID], Address: strings.TrimSpace(message.Text)}
+	if message.Location != nil {
+		choice.Location = &domain.Location{Latitude: message.Location.Latitude, Longitude: message.Location.Longitude}
+	}
+	if choice.Address == "" && choice.Location == nil {
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "checkout.address_empty")))
+		return
+	}
+	if len([]rune(choice.Address)) > domain.MaxDeliveryAddressLength {
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "checkout.address_too_long", domain.MaxDeliveryAddressLength)))
+		return
+	}
+
+	h.userStates[chatID] = StateNone
+	delete(h.deliveryDrafts, chatID)
+	// Убираем кнопку геопозиции, дальше она не нужна
+	msg := tgbotapi.NewMessage(chatID, h.t(chatID, "checkout.address_accepted"))
+	msg.ReplyMarkup = tgbotapi.NewRemoveKeyboard(false)
+	h.bot.Send(msg)
+
+	h.placeOrder(chatID, choice)
+}
+
+// checkoutQuote - корзина для оформления, посчитанная заново, чтобы заказ и счет совпадали
+// с тем, что покупатель видел. nil - оформлять нечего, покупателю уже ответили.
+func (h *Handler) checkoutQuote(chatID int64) *domain.Quote {
+	lines, err := h.repo.GetCart(chatID)
+	if err != nil {
+		log.Printf("Error getting cart: %v", err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "checkout.error")))
+		return nil
+	}
+	if len(lines) == 0 {
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "cart.empty")))
+		return nil
+	}
+
+	// Промокод проверяем еще раз: лимиты могли закончиться, пока покупатель думал
+	_, hasCode := h.appliedPromos[chatID]
+	quote, err := h.quoteCart(chatID, lines)
+	if err != nil {
+		log.Printf("Error calculating cart: %v", err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "checkout.error")))
+		return nil
+	}
+	if hasCode && quote.PromoCode == "" {
+		// Промокод сняли - показываем корзину с новой суммой, пусть покупатель подтвердит еще раз
+		h.handleCart(chatID, 0)
+		return nil
+	}
+	if quote.OutOfStock() {
+		// Товар раскупили, пока он лежал в корзине - показываем, чего не хватает
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "checkout.out_of_stock")))
+		h.handleCart(chatID, 0)
+		return nil
+	}
+	return quote
+}
+
+// placeOrder - оформляет заказ с выбранной доставкой и выставляет счет.
+// Корзину считаем еще раз: пока покупатель выбирал доставку, она могла измениться.
+func (h *Handler) placeOrder(chatID int64, choice domain.DeliveryChoice) {
+	quote := h.checkoutQuote(chatID)
+	if quote == nil {
+		return
+	}
+
+	delivery, err := h.orders.Delivery(quote, choice)
+	switch {
+	case errors.Is(err, service.ErrDeliveryRequired), errors.Is(err, service.ErrDeliveryMethod):
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "checkout.delivery_unavailable")))
+		h.handleCheckout(chatID)
+		return
+	case err != nil:
+		log.Printf("Error calculating delivery: %v", err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "checkout.error")))
+		return
+	}
+
+	order, err := h.orders.PlaceOrder(chatID, quote, delivery, h.usePoints[chatID])
+	if errors.Is(err, domain.ErrNotEnoughPoints) {
+		// Бонусы сгорели или списались в другом заказе - показываем корзину с новым расчетом
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "checkout.points_changed")))
+		h.handleCart(chatID, 0)
+		return
+	}
+	if errors.Is(err, domain.ErrOutOfStock) {
+		// Последние штуки купили в другом заказе, пока оформлялся этот
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "checkout.out_of_stock")))
+		h.handleCart(chatID, 0)
+		return
+	}
+	if order == nil {
+		log.Printf("Error creating order: %v", err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "checkout.error")))
+		return
+	}
+	if err != nil {
+		// Заказ уже сохранен, не получилось только отметить промокод или очистить корзину
+		log.Printf("Error finishing order %d: %v", order.ID, err)
+	}
+	delete(h.appliedPromos, chatID)
+	delete(h.usePoints, chatID)
+
+	h.sendInvoice(order)
+}
+
+// sendInvoice - выставляет счет на оплату заказа.
+// Если платежный провайдер не настроен, отправляет итог заказа с оплатой при получении.
+func (h *Handler) sendInvoice(order *domain.Order) {
+	chatID := order.ChatID
+	summary := h.t(chatID, "orders.title", order.ID) + "\n\n" + h.formatOrder(chatID, order)
+
+	if h.paymentToken == "" {
+		msg := tgbotapi.NewMessage(chatID, summary+"\n\n"+h.t(chatID, "orders.pay_on_delivery"))
+		msg.ParseMode = "HTML"
+		h.bot.Send(msg)
+		return
+	}
+
+	// Позиции счета - это строки заказа уже со скидками, поэтому их сумма равна итогу заказа.
+	// Оплаченное бонусами вычитаем из строк пропорционально их сумме: отрицательных строк
+	// Телеграм не принимает, а бонусами оплачивается не больше части суммы товаров.
+	weights := make([]domain.Money, len(order.Items))
+	for i, item := range order.Items {
+		weights[i] = item.Total()
+	}
+	paidByPoints := domain.PointsValue(order.PointsSpent).Allocate(weights)
+	var prices []tgbotapi.LabeledPrice
+	var names []string
+	for i, item := range order.Items {
+		prices = append(prices, tgbotapi.LabeledPrice{
+			Label:  fmt.Sprintf("%s × %d", item.Name, item.Quantity),
+			Amount: int(item.Total().Sub(paidByPoints[i]).Amount),
+		})
+		names = append(names, item.Name)
+	}
+	// Доставка отдельной строкой, чтобы сумма строк по-прежнему совпадала с итогом
+	if order.Delivery.Cost.IsPositive() {
+		prices = append(prices, tgbotapi.LabeledPrice{
+			Label:  h.t(chatID, "orders.invoice_delivery", order.Delivery.Name),
+			Amount: int(order.Delivery.Cost.Amount),
+		})
+	}
+
+	description := strings.Join(names, ", ")
+	if len([]rune(description)) > 255 {
+		description = string([]rune(description)[:252]) + "..."
+	}
+
+	invoice := tgbotapi.NewInvoice(chatID, h.t(chatID, "orders.invoice_title", order.ID), description,
+		fmt.Sprintf("order_%d", order.ID), h.paymentToken, "", order.Total.Currency, prices)
+	if _, err := h.bot.Send(invoice); err != nil {
+		log.Printf("Error sending invoice for order %d: %v", order.ID, err)
+		msg := tgbotapi.NewMessage(chatID, summary+"\n\n"+h.t(chatID, "orders.invoice_error"))
+		msg.ParseMode = "HTML"
+		h.bot.Send(msg)
+	}
+}
+
+// handlePreCheckout - последняя проверка перед списанием денег
+func (h *Handler) handlePreCheckout(query *tgbotapi.PreCheckoutQuery) {
+	answer := tgbotapi.PreCheckoutConfig{PreCheckoutQueryID: query.ID}
+	chatID := query.From.ID // счета выставляются только в личке, там ID чата совпадает с ID пользователя
+
+	var orderID int64
+	_, err := fmt.Sscanf(query.InvoicePayload, "order_%d", &orderID)
+	order, getErr := h.repo.GetOrderByID(orderID)
+
+	switch {
+	case err != nil || getErr != nil || order == nil:
+		answer.ErrorMessage = h.t(chatID, "orders.not_found_short")
+	case order.Status != domain.OrderStatusNew:
+		answer.ErrorMessage = h.t(chatID, "orders.already_paid")
+	case int64(query.TotalAmount) != order.Total.Amount || query.Currency != order.Total.Currency:
+		answer.ErrorMessage = h.t(chatID, "orders.total_changed")
+	default:
+		answer.OK = true
+	}
+
+	if _, err := h.bot.Request(answer); err != nil {
+		log.Printf("Error answering pre-checkout query: %v", err)
+	}
+}
+
+// handleSuccessfulPayment - оплата прошла, отмечаем заказ оплаченным
+func (h *Handler) handleSuccessfulPayment(message *tgbotapi.Message) {
+	payment := message.SuccessfulPayment
+
+	var orderID int64
+	if _, err := fmt.Sscanf(payment.InvoicePayload, "order_%d", &orderID); err != nil {
+		log.Printf("Unknown invoice payload: %s", payment.InvoicePayload)
+		return
+	}
+
+	// Оплатить можно только новый заказ. Если его отменили между pre-checkout и оплатой,
+	// товары и бонусы уже вернулись: статус не трогаем, а деньги админ возвращает вручную
+	// (строка "Error" уходит в чат ошибок).
+	err := h.repo.UpdateOrderStatus(orderID, domain.OrderStatusPaid)
+	if errors.Is(err, domain.ErrStatusChange) {
+		log.Printf("Error order %d was paid but is no longer new, refund %s (charge %s) manually",
+			orderID, domain.NewMoney(int64(payment.TotalAmount), payment.Currency), payment.TelegramPaymentChargeID)
+		h.bot.Send(tgbotapi.NewMessage(message.Chat.ID, h.t(message.Chat.ID, "orders.paid_not_new", orderID)))
+		return
+	}
+	if err != nil {
+		log.Printf("Error marking order %d as paid: %v", orderID, err)
+	}
+	h.bot.Send(tgbotapi.NewMessage(message.Chat.ID, h.t(message.Chat.ID, "orders.paid", orderID)))
+
+	// Сообщаем в чат заказов: оплаченный заказ можно собирать
+	order, err := h.repo.GetOrderByID(orderID)
+	if err != nil || order == nil {
+		log.Printf("Error getting paid order %d: %v", orderID, err)
+		return
+	}
+	h.sendOrderCard(order, "orders_admin.paid")
+}
+
+// formatOrder - текст позиций и сумм заказа на языке получателя
+func (h *Handler) formatOrder(chatID int64, order *domain.Order) string {
+	var sb strings.Builder
+	for _, item := range order.Items {
+		sb.WriteString(fmt.Sprintf("%s × %d — %s\n", html.EscapeString(item.Name), item.Quantity, h.money(chatID, item.Total())))
+	}
+	if order.Discount.IsPositive() {
+		sb.WriteString("\n" + h.t(chatID, "cart.subtotal", h.money(chatID, order.Subtotal)))
+		sb.WriteString("\n" + h.t(chatID, "orders.discount", h.money(chatID, order.Discount)))
+		if order.PromoCode != "" {
+			sb.WriteString(" " + h.t(chatID, "orders.discount_code", html.EscapeString(order.PromoCode)))
+		}
+	}
+	if order.Delivery.Name != "" {
+		sb.WriteString("\n" + h.formatDelivery(chatID, order.Delivery))
+	}
+	if order.PointsSpent > 0 {
+		sb.WriteString("\n" + h.t(chatID, "orders.points_spent", h.money(chatID, domain.PointsValue(order.PointsSpent))))
+	}
+	sb.WriteString("\n" + h.t(chatID, "cart.total", h.money(chatID, order.Total)))
+	if order.PointsEarned > 0 && order.Status != domain.OrderStatusCancelled {
+		sb.WriteString("\n" + h.plural(chatID, "orders.points_earned", int(order.PointsEarned), order.PointsEarned))
+	}
+	return sb.String()
+}
+
+// formatDelivery - способ и цена доставки, под ними адрес и ссылка на точку на карте
+func (h *Handler) formatDelivery(chatID int64, delivery domain.OrderDelivery) string {
+	cost := h.t(chatID, "checkout.delivery_free")
+	if delivery.Cost.IsPositive() {
+		cost = h.money(chatID, delivery.Cost)
+	}
+	text := h.t(chatID, "orders.delivery", html.EscapeString(delivery.Name), cost)
+	if delivery.Address != "" {
+		text += "\n" + h.t(chatID, "orders.delivery_address", html.EscapeString(delivery.Address))
+	}
+	if delivery.Location != nil {
+		text += "\n" + h.t(chatID, "orders.delivery_location", delivery.Location.MapURL(), delivery.Location.String())
+	}
+	return text
+}
+
+// handleDelivered - команда админа /delivered <номер заказа>.
+// Отмечает заказ полученным и предлагает покупателю оценить товары.
+func (h *Handler) handleDelivered(message *tgbotapi.Message) {
+	orderID, err := strconv.ParseInt(strings.TrimSpace(message.CommandArguments()), 10, 64)
+	if err != nil {
+		h.bot.Send(tgbotapi.NewMessage(message.Chat.ID, h.t(message.Chat.ID, "orders.delivered_usage")))
+		return
+	}
+
+	order, err := h.repo.GetOrderByID(orderID)
+	if err != nil {
+		log.Printf("Error getting order %d: %v", orderID, err)
+		h.bot.Send(tgbotapi.NewMessage(message.Chat.ID, h.t(message.Chat.ID, "orders.get_error")))
+		return
+	}
+	if order == nil {
+		h.bot.Send(tgbotapi.NewMessage(message.Chat.ID, h.t(message.Chat.ID, "orders.not_found", orderID)))
+		return
+	}
+
+	if err := h.applyOrderStatus(order, domain.OrderStatusDelivered, telegramUserName(message.From)); err != nil {
+		log.Printf("Error updating order %d: %v", order.ID, err)
+		h.bot.Send(tgbotapi.NewMessage(message.Chat.ID, h.t(message.Chat.ID, "orders.update_error")))
+		return
+	}
+	h.bot.Send(tgbotapi.NewMessage(message.Chat.ID, h.t(message.Chat.ID, "orders.marked_delivered", order.ID)))
+}
+
+// askForReviews - заказ получен: просим покупателя оценить каждый товар из заказа
+func (h *Handler) askForReviews(order *domain.Order) {
+	h.bot.Send(tgbotapi.NewMessage(order.ChatID, h.t(order.ChatID, "orders.delivered", order.ID)))
+	for _, item := range order.Items {
+		msg := tgbotapi.NewMessage(order.ChatID, h.t(order.ChatID, "orders.rate_item", item.Name))
+		msg.ReplyMarkup = h.keyboards.GetRatingKeyboard(h.lang(order.ChatID), item.ProductID)
+		h.bot.Send(msg)
+	}
+}
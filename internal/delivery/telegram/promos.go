@@ -0,0 +1,237 @@
+// promos.go — команды админа для управления акциями и промокодами
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"salle_parfume/internal/domain"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// promoDateLayout - формат дат в командах
+const promoDateLayout = "2006-01-02"
+
+// handlePromoAdd - команда админа /promo_add: создать промокод или автоматическую акцию
+func (h *Handler) handlePromoAdd(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	promo, err := h.parsePromotion(chatID, strings.Fields(message.CommandArguments()))
+	if err != nil {
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "common.error", err)+"\n\n"+h.t(chatID, "promos.usage")))
+		return
+	}
+	if err := promo.Validate(); err != nil {
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "common.error", err)))
+		return
+	}
+
+	if err := h.repo.CreatePromotion(promo); err != nil {
+		log.Printf("Error creating promotion: %v", err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "promos.save_error")))
+		return
+	}
+
+	h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "promos.created")+"\n"+h.formatPromotion(chatID, promo)))
+}
+
+// parsePromotion - разбирает аргументы команды /promo_add.
+// Тексты ошибок - на языке админа (chatID).
+func (h *Handler) parsePromotion(chatID int64, args []string) (*domain.Promotion, error) {
+	if len(args) < 3 {
+		return nil, errors.New(h.t(chatID, "promos.not_enough_args"))
+	}
+
+	promo := &domain.Promotion{
+		Kind:   domain.PromoKind(strings.ToLower(args[1])),
+		Active: true,
+	}
+	if strings.EqualFold(args[0], "auto") {
+		promo.Name = h.t(chatID, "promos.default_name")
+	} else {
+		promo.Code = strings.ToUpper(args[0])
+		promo.Name = promo.Code
+	}
+
+	// Процент - дробное число, фиксированная скидка - точная сумма в копейках
+	var err error
+	if promo.Kind == domain.PromoFixed {
+		promo.Amount, err = domain.ParseMoney(args[2], domain.DefaultCurrency)
+	} else {
+		promo.Percent, err = strconv.ParseFloat(strings.ReplaceAll(args[2], ",", "."), 64)
+	}
+	if err != nil {
+		return nil, errors.New(h.t(chatID, "promos.value_not_number"))
+	}
+
+	for _, arg := range args[3:] {
+		key, val, ok := strings.Cut(arg, "=")
+		if !ok {
+			return nil, errors.New(h.t(chatID, "promos.param_format", arg))
+		}
+
+		switch key {
+		case "min":
+			promo.MinOrder, err = domain.ParseMoney(val, domain.DefaultCurrency)
+		case "limit":
+			promo.UsageLimit, err = strconv.Atoi(val)
+		case "per_user":
+			promo.PerUserLimit, err = strconv.Atoi(val)
+		case "from":
+			promo.StartsAt, err = time.ParseInLocation(promoDateLayout, val, time.Local)
+		case "to":
+			// Дата окончания включительно: акция действует до конца этого дня
+			var day time.Time
+			day, err = time.ParseInLocation(promoDateLayout, val, time.Local)
+			promo.EndsAt = day.AddDate(0, 0, 1)
+		case "products":
+			for _, s := range strings.Split(val, ",") {
+				var id int64
+				id, err = strconv.ParseInt(s, 10, 64)
+				if err != nil {
+					break
+				}
+				promo.ProductIDs = append(promo.ProductIDs, id)
+			}
+		case "types":
+			for _, s := range strings.Split(val, ",") {
+				t, typeErr := domain.ParseProductType(s)
+				if typeErr != nil {
+					return nil, errors.New(h.t(chatID, "promos.unknown_type", s))
+				}
+				promo.ProductTypes = append(promo.ProductTypes, t)
+			}
+		case "name":
+			promo.Name = strings.ReplaceAll(val, "_", " ")
+		default:
+			return nil, errors.New(h.t(chatID, "promos.unknown_param", key))
+		}
+
+		if err != nil {
+			return nil, errors.New(h.t(chatID, "promos.bad_param_value", key))
+		}
+	}
+
+	return promo, nil
+}
+
+// handlePromoOff - команда админа /promo_off <КОД|ID>: выключить акцию
+func (h *Handler) handlePromoOff(message *tgbotapi.Message) {
+	h.setPromotionActive(message, false)
+}
+
+// handlePromoOn - команда админа /promo_on <КОД|ID>: снова включить акцию
+func (h *Handler) handlePromoOn(message *tgbotapi.Message) {
+	h.setPromotionActive(message, true)
+}
+
+// setPromotionActive - общая часть /promo_off и /promo_on
+func (h *Handler) setPromotionActive(message *tgbotapi.Message, active bool) {
+	chatID := message.Chat.ID
+
+	arg := strings.TrimSpace(message.CommandArguments())
+	if arg == "" {
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "promos.specify")))
+		return
+	}
+
+	// Автоматические акции без кода ищем по ID
+	var promo *domain.Promotion
+	var err error
+	if id, parseErr := strconv.ParseInt(arg, 10, 64); parseErr == nil {
+		promo, err = h.repo.GetPromotionByID(id)
+	} else {
+		promo, err = h.repo.GetPromotionByCode(arg)
+	}
+	if err != nil {
+		log.Printf("Error getting promotion %s: %v", arg, err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "promos.get_error")))
+		return
+	}
+	if promo == nil {
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "promos.not_found")))
+		return
+	}
+
+	if err := h.repo.SetPromotionActive(promo.ID, active); err != nil {
+		log.Printf("Error updating promotion %d: %v", promo.ID, err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "common.save_error")))
+		return
+	}
+
+	key := "promos.disabled"
+	if active {
+		key = "promos.enabled"
+	}
+	h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, key, promo.Name)))
+}
+
+// handlePromoList - команда админа /promos: список всех акций
+func (h *Handler) handlePromoList(message *tgbotapi.Message) {
+	promos, err := h.repo.GetAllPromotions()
+	if err != nil {
+		log.Printf("Error getting promotions: %v", err)
+		h.bot.Send(tgbotapi.NewMessage(message.Chat.ID, h.t(message.Chat.ID, "promos.list_error")))
+		return
+	}
+	if len(promos) == 0 {
+		h.bot.Send(tgbotapi.NewMessage(message.Chat.ID, h.t(message.Chat.ID, "promos.list_empty")))
+		return
+	}
+
+	var sb strings.Builder
+	for i := range promos {
+		used, err := h.repo.CountPromotionUses(promos[i].ID)
+		if err != nil {
+			log.Printf("Error counting promotion uses: %v", err)
+		}
+		sb.WriteString(h.formatPromotion(message.Chat.ID, &promos[i]))
+		sb.WriteString(h.plural(message.Chat.ID, "promos.used", used) + "\n\n")
+	}
+	h.bot.Send(tgbotapi.NewMessage(message.Chat.ID, sb.String()))
+}
+
+// formatPromotion - описание акции для админа
+func (h *Handler) formatPromotion(chatID int64, p *domain.Promotion) string {
+	var sb strings.Builder
+
+	title := p.Code
+	if p.IsAutomatic() {
+		title = h.t(chatID, "promos.auto_title", p.Name)
+	}
+	status := "✅"
+	if !p.Active {
+		status = "⛔"
+	}
+	sb.WriteString(fmt.Sprintf("%s #%d %s — ", status, p.ID, title))
+
+	if p.Kind == domain.PromoPercent {
+		sb.WriteString(fmt.Sprintf("%g%%\n", p.Percent))
+	} else {
+		sb.WriteString(h.money(chatID, p.Amount) + "\n")
+	}
+
+	if p.MinOrder.IsPositive() {
+		sb.WriteString(h.t(chatID, "promos.min_order", h.money(chatID, p.MinOrder)) + "\n")
+	}
+	if p.UsageLimit > 0 || p.PerUserLimit > 0 {
+		sb.WriteString(h.t(chatID, "promos.limits", p.UsageLimit, p.PerUserLimit) + "\n")
+	}
+	if !p.StartsAt.IsZero() {
+		sb.WriteString(h.t(chatID, "promos.starts", p.StartsAt.Format(promoDateLayout)) + "\n")
+	}
+	if !p.EndsAt.IsZero() {
+		sb.WriteString(h.t(chatID, "promos.ends", p.EndsAt.AddDate(0, 0, -1).Format(promoDateLayout)) + "\n")
+	}
+	if len(p.ProductIDs) > 0 {
+		sb.WriteString(h.t(chatID, "promos.products", fmt.Sprint(p.ProductIDs)) + "\n")
+	}
+	if len(p.ProductTypes) > 0 {
+		sb.WriteString(h.t(chatID, "promos.types", fmt.Sprint(p.ProductTypes)) + "\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,112 @@
+// referrals.go — реферальная программа: запись приглашения при первом /start по ссылке друга,
+// экран "Пригласить друга" (команда /invite) и сообщение о промокоде за друга от задачи планировщика
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"time"
+
+	"salle_parfume/internal/domain"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// registerUser - сохраняет пользователя при /start. Приглашение засчитываем только новому
+// пользователю: повторный /start по чужой ссылке ничего не меняет.
+func (h *Handler) registerUser(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	user := &domain.User{ChatID: chatID}
+	if message.From != nil {
+		user.Username, user.FirstName = message.From.UserName, message.From.FirstName
+	}
+	created, err := h.repo.EnsureUser(user)
+	if err != nil {
+		log.Printf("Error saving user %d: %v", chatID, err)
+		return
+	}
+	if !created || !h.features.Referrals {
+		return
+	}
+
+	referrerID, ok := domain.ParseReferralPayload(message.CommandArguments())
+	if !ok {
+		return
+	}
+	invited, err := h.referrals.Invite(referrerID, chatID)
+	if err != nil {
+		log.Printf("Error saving referral %d -> %d: %v", referrerID, chatID, err)
+		return
+	}
+	if invited {
+		log.Printf("User %d invited by %d", chatID, referrerID)
+		h.bot.Send(tgbotapi.NewMessage(referrerID, h.t(referrerID, "referral.friend_joined")))
+	}
+}
+
+// handleInviteCommand - /invite: ссылка для друзей и полученные за них промокоды
+func (h *Handler) handleInviteCommand(message *tgbotapi.Message) {
+	h.sendInvite(message.Chat.ID)
+}
+
+// inviteLink - пригласительная ссылка покупателя
+func (h *Handler) inviteLink(chatID int64) string {
+	return fmt.Sprintf("https://t.me/%s?start=%s", h.bot.Self.UserName, domain.ReferralPayload(chatID))
+}
+
+// sendInvite - экран "Пригласить друга": как работает программа, ссылка, сколько друзей пришло
+// и какие промокоды получены
+func (h *Handler) sendInvite(chatID int64) {
+	stats, err := h.referrals.Stats(chatID)
+	if err != nil {
+		log.Printf("Error getting referral stats of %d: %v", chatID, err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "referral.error")))
+		return
+	}
+
+	link := h.inviteLink(chatID)
+	lines := []string{
+		h.t(chatID, "referral.title"),
+		h.t(chatID, "referral.how", h.referrals.RewardPercent()),
+		"",
+		h.t(chatID, "referral.link", html.EscapeString(link)),
+		"",
+		h.plural(chatID, "referral.invited", stats.Invited, stats.Invited),
+	}
+	if len(stats.Rewards) > 0 {
+		lines = append(lines, "", h.t(chatID, "referral.rewards_title"))
+		now := time.Now()
+		for _, r := range stats.Rewards {
+			line := h.t(chatID, "referral.reward", html.EscapeString(r.Code), r.Percent)
+			switch {
+			case r.Used:
+				line += " " + h.t(chatID, "referral.reward_used")
+			case !r.EndsAt.IsZero() && !now.Before(r.EndsAt):
+				line += " " + h.t(chatID, "referral.reward_expired")
+			case !r.EndsAt.IsZero():
+				line += " " + h.t(chatID, "referral.reward_until", r.EndsAt.Format("02.01.2006"))
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	msg := tgbotapi.NewMessage(chatID, strings.Join(lines, "\n"))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = h.keyboards.GetInviteKeyboard(h.lang(chatID), link, h.t(chatID, "referral.share_text"))
+	h.bot.Send(msg)
+}
+
+// notifyReferralReward - друг оплатил первый заказ: сообщаем пригласившему его промокод
+func (h *Handler) notifyReferralReward(ref domain.Referral, promo *domain.Promotion) error {
+	chatID := ref.ReferrerID
+	text := h.t(chatID, "referral.rewarded", html.EscapeString(promo.Code), promo.Percent)
+	if !promo.EndsAt.IsZero() {
+		text += "\n" + h.t(chatID, "referral.reward_until", promo.EndsAt.Format("02.01.2006"))
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	_, err := h.bot.Send(msg)
+	return err
+}
@@ -0,0 +1,101 @@
+// reminders.go — напоминания о брошенной корзине: отправка по задаче планировщика,
+// отказ от напоминаний кнопкой и команда /reminders
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strings"
+
+	"salle_parfume/internal/domain"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// sendCartReminder - текст напоминания (его меняет админ через /texts) и что лежит в корзине.
+// Пустую корзину пропускаем: покупатель мог все убрать, пока шла задача.
+func (h *Handler) sendCartReminder(cart domain.AbandonedCart) error {
+	chatID := cart.ChatID
+	lines, err := h.repo.GetCart(chatID)
+	if err != nil {
+		return err
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	user, err := h.repo.GetUserByChatID(chatID)
+	if err != nil {
+		log.Printf("Error getting user %d: %v", chatID, err)
+	}
+	text, err := h.texts.Render(domain.TextCartReminder, h.lang(chatID), userTextVars(user))
+	if err != nil {
+		log.Printf("Error getting shop text %s: %v", domain.TextCartReminder, err)
+	}
+
+	var items []string
+	for _, line := range lines {
+		items = append(items, fmt.Sprintf("%s × %d", html.EscapeString(line.Product.Name), line.Quantity))
+	}
+	msg := tgbotapi.NewMessage(chatID, text+"\n\n"+strings.Join(items, "\n"))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = h.keyboards.GetCartReminderKeyboard(h.lang(chatID))
+	_, err = h.bot.Send(msg)
+	return err
+}
+
+// disableCartReminders - кнопка "Не напоминать" под напоминанием
+func (h *Handler) disableCartReminders(callback *tgbotapi.CallbackQuery) {
+	chatID := callback.Message.Chat.ID
+	if err := h.repo.SetCartReminders(chatID, false); err != nil {
+		log.Printf("Error disabling cart reminders for %d: %v", chatID, err)
+		h.bot.Request(tgbotapi.NewCallbackWithAlert(callback.ID, h.t(chatID, "reminders.error")))
+		return
+	}
+	// Убираем кнопку отказа, корзину и оформление оставляем
+	markup := h.keyboards.GetCartReminderKeyboard(h.lang(chatID))
+	markup.InlineKeyboard = markup.InlineKeyboard[:1]
+	h.bot.Send(tgbotapi.NewEditMessageReplyMarkup(chatID, callback.Message.MessageID, markup))
+	h.bot.Request(tgbotapi.NewCallbackWithAlert(callback.ID, h.t(chatID, "reminders.disabled")))
+}
+
+// handleRemindersCommand - /reminders on|off: включить или выключить напоминания о корзине.
+// Без аргумента показывает, включены ли они сейчас.
+func (h *Handler) handleRemindersCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	var enabled bool
+	switch strings.ToLower(strings.TrimSpace(message.CommandArguments())) {
+	case "on":
+		enabled = true
+	case "off":
+		enabled = false
+	case "":
+		on, err := h.repo.CartRemindersEnabled(chatID)
+		if err != nil {
+			log.Printf("Error getting cart reminders setting for %d: %v", chatID, err)
+			h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "reminders.error")))
+			return
+		}
+		key := "reminders.status_off"
+		if on {
+			key = "reminders.status_on"
+		}
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, key)))
+		return
+	default:
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "reminders.usage")))
+		return
+	}
+
+	if err := h.repo.SetCartReminders(chatID, enabled); err != nil {
+		log.Printf("Error setting cart reminders for %d: %v", chatID, err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "reminders.error")))
+		return
+	}
+	key := "reminders.disabled"
+	if enabled {
+		key = "reminders.enabled"
+	}
+	h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, key)))
+}
@@ -0,0 +1,322 @@
+// reviews.go — отзывы и оценки товаров, модерация отзывов админом
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strings"
+
+	"salle_parfume/internal/delivery/telegram/callbacks"
+	"salle_parfume/internal/domain"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// reviewsPageSize - сколько отзывов показываем на одной странице
+const reviewsPageSize = 5
+
+// registerReviewCallbacks - кнопки оценок, отзывов и модерации
+func (h *Handler) registerReviewCallbacks(r *callbacks.Router) {
+	r.HandleID(callbacks.Rate, h.handleRate)
+	r.HandlePair(callbacks.Stars, func(callback *tgbotapi.CallbackQuery, productID, stars int64) {
+		h.handleStars(callback, productID, int(stars))
+	})
+	r.HandleAction(callbacks.ReviewSkip, h.handleReviewSkip)
+	r.HandlePair(callbacks.Reviews, func(callback *tgbotapi.CallbackQuery, productID, page int64) {
+		h.handleReviewsPage(callback, productID, int(page))
+	})
+	r.HandleID(callbacks.ReviewPhoto, h.handleReviewPhoto)
+	r.HandleID(callbacks.ReviewApprove, func(callback *tgbotapi.CallbackQuery, reviewID int64) {
+		h.handleModeration(callback, reviewID, domain.ReviewStatusApproved)
+	})
+	r.HandleID(callbacks.ReviewReject, func(callback *tgbotapi.CallbackQuery, reviewID int64) {
+		h.handleModeration(callback, reviewID, domain.ReviewStatusRejected)
+	})
+}
+
+// canReview - проверяет, может ли покупатель оставить отзыв о товаре.
+// Если нельзя, возвращает текст причины на языке пользователя.
+func (h *Handler) canReview(chatID, productID int64) (bool, string) {
+	delivered, err := h.repo.HasDeliveredProduct(chatID, productID)
+	if err != nil {
+		log.Printf("Error checking delivered product: %v", err)
+		return false, h.t(chatID, "common.try_later")
+	}
+	if !delivered {
+		return false, h.t(chatID, "reviews.not_delivered")
+	}
+
+	// Один покупатель - один отзыв о товаре (повторно можно только после отклонения)
+	existing, err := h.repo.GetUserReview(chatID, productID)
+	if err != nil {
+		log.Printf("Error getting user review: %v", err)
+		return false, h.t(chatID, "common.try_later")
+	}
+	if existing != nil && existing.Status != domain.ReviewStatusRejected {
+		return false, h.t(chatID, "reviews.already_reviewed")
+	}
+	return true, ""
+}
+
+// handleRate - кнопка "Оценить" в карточке товара
+func (h *Handler) handleRate(callback *tgbotapi.CallbackQuery, productID int64) {
+	chatID := callback.Message.Chat.ID
+
+	if ok, reason := h.canReview(chatID, productID); !ok {
+		h.bot.Request(tgbotapi.NewCallbackWithAlert(callback.ID, reason))
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, h.t(chatID, "reviews.rate"))
+	msg.ReplyMarkup = h.keyboards.GetRatingKeyboard(h.lang(chatID), productID)
+	h.bot.Send(msg)
+	h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+}
+
+// handleStars - покупатель выбрал количество звезд
+func (h *Handler) handleStars(callback *tgbotapi.CallbackQuery, productID int64, stars int) {
+	chatID := callback.Message.Chat.ID
+
+	if stars < domain.MinRating || stars > domain.MaxRating {
+		h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+		return
+	}
+	if ok, reason := h.canReview(chatID, productID); !ok {
+		h.bot.Request(tgbotapi.NewCallbackWithAlert(callback.ID, reason))
+		return
+	}
+
+	// Запоминаем оценку и ждем текст или фото
+	h.reviewDrafts[chatID] = &domain.Review{
+		ProductID:  productID,
+		ChatID:     chatID,
+		AuthorName: callback.From.FirstName,
+		Rating:     stars,
+	}
+	h.userStates[chatID] = StateWaitingForReviewText
+
+	// Убираем звезды из сообщения, чтобы не выбрать оценку дважды
+	edit := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID, h.t(chatID, "reviews.your_rating", strings.Repeat("⭐", stars)))
+	h.bot.Send(edit)
+
+	msg := tgbotapi.NewMessage(chatID, h.t(chatID, "reviews.write"))
+	msg.ReplyMarkup = h.keyboards.GetReviewSkipKeyboard(h.lang(chatID))
+	h.bot.Send(msg)
+	h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+}
+
+// handleReviewSkip - кнопка "Без текста": сохраняем только оценку
+func (h *Handler) handleReviewSkip(callback *tgbotapi.CallbackQuery) {
+	chatID := callback.Message.Chat.ID
+
+	if h.userStates[chatID] != StateWaitingForReviewText {
+		h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+		return
+	}
+	h.submitReview(chatID)
+	h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+}
+
+// handleReviewInput - покупатель прислал текст или фото отзыва
+func (h *Handler) handleReviewInput(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	draft := h.reviewDrafts[chatID]
+	if draft == nil {
+		h.userStates[chatID] = StateNone
+		return
+	}
+
+	if message.Photo != nil {
+		// Берем самое качественное фото (последнее в массиве), подпись - это текст отзыва
+		draft.PhotoID = message.Photo[len(message.Photo)-1].FileID
+		draft.Text = message.Caption
+	} else if message.Text != "" {
+		draft.Text = message.Text
+	} else {
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "reviews.send_text_or_photo")))
+		return
+	}
+
+	h.submitReview(chatID)
+}
+
+// submitReview - сохраняет черновик отзыва и отправляет его на модерацию
+func (h *Handler) submitReview(chatID int64) {
+	draft := h.reviewDrafts[chatID]
+
+	// Сбрасываем состояние в любом случае, чтобы пользователь не застрял
+	h.userStates[chatID] = StateNone
+	delete(h.reviewDrafts, chatID)
+
+	if draft == nil {
+		return
+	}
+
+	if err := h.repo.CreateReview(draft); err != nil {
+		log.Printf("Error creating review: %v", err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "reviews.save_error")))
+		return
+	}
+
+	h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "reviews.submitted")))
+	h.sendReviewForModeration(draft)
+}
+
+// sendReviewForModeration - отправляет отзыв админу с кнопками одобрить/отклонить
+func (h *Handler) sendReviewForModeration(review *domain.Review) {
+	productName := fmt.Sprintf("ID %d", review.ProductID)
+	if product, err := h.repo.GetProductByID(review.ProductID); err == nil && product != nil {
+		productName = product.Name
+	}
+
+	text := h.t(h.adminID, "reviews.moderation",
+		review.ID, productName, review.AuthorName, review.ChatID, strings.Repeat("⭐", review.Rating))
+	if review.Text != "" {
+		text += "\n\n" + review.Text
+	}
+
+	keyboard := h.keyboards.GetModerationKeyboard(h.lang(h.adminID), review.ID)
+	if review.PhotoID != "" {
+		photo := tgbotapi.NewPhoto(h.adminID, tgbotapi.FileID(review.PhotoID))
+		photo.Caption = text
+		photo.ReplyMarkup = keyboard
+		h.bot.Send(photo)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(h.adminID, text)
+	msg.ReplyMarkup = keyboard
+	h.bot.Send(msg)
+}
+
+// handlePendingReviews - команда админа /reviews: показать очередь модерации
+func (h *Handler) handlePendingReviews(message *tgbotapi.Message) {
+	reviews, err := h.repo.GetPendingReviews()
+	if err != nil {
+		log.Printf("Error getting pending reviews: %v", err)
+		h.bot.Send(tgbotapi.NewMessage(message.Chat.ID, h.t(message.Chat.ID, "reviews.get_error")))
+		return
+	}
+	if len(reviews) == 0 {
+		h.bot.Send(tgbotapi.NewMessage(message.Chat.ID, h.t(message.Chat.ID, "reviews.queue_empty")))
+		return
+	}
+
+	for i := range reviews {
+		h.sendReviewForModeration(&reviews[i])
+	}
+}
+
+// handleModeration - админ одобрил или отклонил отзыв (права проверяет middleware Auth)
+func (h *Handler) handleModeration(callback *tgbotapi.CallbackQuery, reviewID int64, status domain.ReviewStatus) {
+	chatID := callback.Message.Chat.ID
+
+	review, err := h.repo.GetReviewByID(reviewID)
+	if err != nil || review == nil {
+		log.Printf("Error getting review %d: %v", reviewID, err)
+		h.bot.Request(tgbotapi.NewCallbackWithAlert(callback.ID, h.t(chatID, "reviews.not_found")))
+		return
+	}
+	if review.Status != domain.ReviewStatusPending {
+		h.bot.Request(tgbotapi.NewCallbackWithAlert(callback.ID, h.t(chatID, "reviews.already_moderated")))
+		return
+	}
+
+	if err := h.repo.UpdateReviewStatus(review.ID, status); err != nil {
+		log.Printf("Error updating review %d: %v", review.ID, err)
+		h.bot.Request(tgbotapi.NewCallbackWithAlert(callback.ID, h.t(chatID, "common.save_error")))
+		return
+	}
+
+	// Дописываем итог модерации в сообщение админа и убираем кнопки
+	result := h.t(chatID, "reviews.approved")
+	authorText := h.t(review.ChatID, "reviews.published")
+	if status == domain.ReviewStatusRejected {
+		result = h.t(chatID, "reviews.rejected")
+		authorText = h.t(review.ChatID, "reviews.not_published")
+	}
+
+	messageID := callback.Message.MessageID
+	if callback.Message.Photo != nil {
+		h.bot.Send(tgbotapi.NewEditMessageCaption(chatID, messageID, callback.Message.Caption+"\n\n"+result))
+	} else {
+		h.bot.Send(tgbotapi.NewEditMessageText(chatID, messageID, callback.Message.Text+"\n\n"+result))
+	}
+
+	h.bot.Send(tgbotapi.NewMessage(review.ChatID, authorText))
+	h.bot.Request(tgbotapi.NewCallback(callback.ID, result))
+}
+
+// handleReviewsPage - показывает страницу опубликованных отзывов о товаре
+func (h *Handler) handleReviewsPage(callback *tgbotapi.CallbackQuery, productID int64, page int) {
+	chatID := callback.Message.Chat.ID
+
+	rating, err := h.repo.GetProductRating(productID)
+	if err != nil {
+		log.Printf("Error getting rating: %v", err)
+		h.bot.Request(tgbotapi.NewCallbackWithAlert(callback.ID, h.t(chatID, "reviews.get_error")))
+		return
+	}
+	if rating.Count == 0 {
+		h.bot.Request(tgbotapi.NewCallbackWithAlert(callback.ID, h.t(chatID, "reviews.none")))
+		return
+	}
+
+	totalPages := (rating.Count + reviewsPageSize - 1) / reviewsPageSize
+	if page < 0 || page >= totalPages {
+		page = 0
+	}
+
+	reviews, err := h.repo.GetApprovedReviews(productID, reviewsPageSize, page*reviewsPageSize)
+	if err != nil {
+		log.Printf("Error getting reviews: %v", err)
+		h.bot.Request(tgbotapi.NewCallbackWithAlert(callback.ID, h.t(chatID, "reviews.get_error")))
+		return
+	}
+
+	// Собираем текст страницы
+	var sb strings.Builder
+	sb.WriteString(h.t(chatID, "reviews.page_title", rating.Average, rating.Count) + "\n")
+	var photoReviewIDs []int64
+	for _, rv := range reviews {
+		sb.WriteString(fmt.Sprintf("\n%s <b>%s</b>", strings.Repeat("⭐", rv.Rating), html.EscapeString(rv.AuthorName)))
+		if rv.PhotoID != "" {
+			photoReviewIDs = append(photoReviewIDs, rv.ID)
+			sb.WriteString(fmt.Sprintf(" 📷%d", len(photoReviewIDs)))
+		}
+		if rv.Text != "" {
+			sb.WriteString("\n" + html.EscapeString(rv.Text))
+		}
+		sb.WriteString("\n")
+	}
+
+	keyboard := h.keyboards.GetReviewsPageKeyboard(h.lang(chatID), productID, page, totalPages, photoReviewIDs)
+
+	// Из карточки товара (это фото) отправляем новое сообщение, при листании - редактируем текущее
+	if callback.Message.Photo != nil {
+		msg := tgbotapi.NewMessage(chatID, sb.String())
+		msg.ParseMode = "HTML"
+		msg.ReplyMarkup = keyboard
+		h.bot.Send(msg)
+	} else {
+		edit := tgbotapi.NewEditMessageTextAndMarkup(chatID, callback.Message.MessageID, sb.String(), keyboard)
+		edit.ParseMode = "HTML"
+		h.bot.Send(edit)
+	}
+	h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+}
+
+// handleReviewPhoto - отправляет фото, приложенное к опубликованному отзыву
+func (h *Handler) handleReviewPhoto(callback *tgbotapi.CallbackQuery, reviewID int64) {
+	review, err := h.repo.GetReviewByID(reviewID)
+	if err != nil || review == nil || review.Status != domain.ReviewStatusApproved || review.PhotoID == "" {
+		h.bot.Request(tgbotapi.NewCallbackWithAlert(callback.ID, h.t(callback.Message.Chat.ID, "reviews.photo_unavailable")))
+		return
+	}
+
+	photo := tgbotapi.NewPhoto(callback.Message.Chat.ID, tgbotapi.FileID(review.PhotoID))
+	photo.Caption = fmt.Sprintf("%s %s", strings.Repeat("⭐", review.Rating), review.AuthorName)
+	h.bot.Send(photo)
+	h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+}
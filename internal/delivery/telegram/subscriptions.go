@@ -0,0 +1,142 @@
+// subscriptions.go — подписки покупателя на товар: "Сообщить о поступлении" и "Следить за ценой",
+// список подписок командой /subscriptions и уведомления, которые рассылает задача планировщика
+package telegram
+
+import (
+	"html"
+	"log"
+	"strings"
+
+	"salle_parfume/internal/delivery/telegram/callbacks"
+	"salle_parfume/internal/domain"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// registerSubscriptionCallbacks - кнопки подписки в карточке товара и отписки в списке
+func (h *Handler) registerSubscriptionCallbacks(r *callbacks.Router) {
+	r.HandleID(callbacks.SubscribeRestock, func(callback *tgbotapi.CallbackQuery, productID int64) {
+		h.subscribe(callback, productID, domain.SubscriptionRestock)
+	})
+	r.HandleID(callbacks.SubscribePrice, func(callback *tgbotapi.CallbackQuery, productID int64) {
+		h.subscribe(callback, productID, domain.SubscriptionPriceDrop)
+	})
+	r.HandleID(callbacks.Unsubscribe, h.unsubscribe)
+}
+
+// subscribe - подписывает покупателя на товар. Запоминаем текущую цену:
+// о снижении сообщим, когда товар станет дешевле нее.
+func (h *Handler) subscribe(callback *tgbotapi.CallbackQuery, productID int64, kind domain.SubscriptionKind) {
+	chatID := callback.Message.Chat.ID
+	product, err := h.repo.GetProductByID(productID)
+	if err != nil || product == nil {
+		if err != nil {
+			log.Printf("Error getting product %d: %v", productID, err)
+		}
+		h.bot.Request(tgbotapi.NewCallback(callback.ID, h.t(chatID, "catalog.product_not_found")))
+		return
+	}
+	// Товар уже вернулся, пока карточка висела в чате - подписка не нужна
+	if kind == domain.SubscriptionRestock && product.InStock() {
+		h.bot.Request(tgbotapi.NewCallbackWithAlert(callback.ID, h.t(chatID, "subscriptions.in_stock")))
+		return
+	}
+
+	created, err := h.repo.Subscribe(&domain.Subscription{ChatID: chatID, ProductID: product.ID, Kind: kind, Price: product.Price})
+	if err != nil {
+		log.Printf("Error subscribing %d to product %d: %v", chatID, product.ID, err)
+		h.bot.Request(tgbotapi.NewCallbackWithAlert(callback.ID, h.t(chatID, "subscriptions.error")))
+		return
+	}
+	key := "subscriptions.already"
+	if created {
+		key = "subscriptions.subscribed_" + string(kind)
+	}
+	h.bot.Request(tgbotapi.NewCallbackWithAlert(callback.ID, h.t(chatID, key, product.Name)))
+}
+
+// unsubscribe - кнопка в списке подписок: отписываемся и обновляем список в том же сообщении
+func (h *Handler) unsubscribe(callback *tgbotapi.CallbackQuery, id int64) {
+	chatID := callback.Message.Chat.ID
+	if _, err := h.repo.Unsubscribe(chatID, id); err != nil {
+		log.Printf("Error unsubscribing %d from %d: %v", chatID, id, err)
+		h.bot.Request(tgbotapi.NewCallbackWithAlert(callback.ID, h.t(chatID, "subscriptions.error")))
+		return
+	}
+	h.sendSubscriptions(chatID, callback.Message.MessageID)
+	h.bot.Request(tgbotapi.NewCallback(callback.ID, h.t(chatID, "subscriptions.unsubscribed")))
+}
+
+// handleSubscriptionsCommand - /subscriptions: на что подписан покупатель
+func (h *Handler) handleSubscriptionsCommand(message *tgbotapi.Message) {
+	h.sendSubscriptions(message.Chat.ID, 0)
+}
+
+// sendSubscriptions - список подписок с кнопками отписки.
+// messageID == 0 - отправить новое сообщение, иначе отредактировать существующее.
+func (h *Handler) sendSubscriptions(chatID int64, messageID int) {
+	subs, err := h.repo.GetUserSubscriptions(chatID)
+	if err != nil {
+		log.Printf("Error getting subscriptions of %d: %v", chatID, err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "subscriptions.error")))
+		return
+	}
+
+	text := h.t(chatID, "subscriptions.empty")
+	if len(subs) > 0 {
+		lines := []string{h.t(chatID, "subscriptions.title")}
+		for _, sub := range subs {
+			name := html.EscapeString(sub.ProductName)
+			if sub.Kind == domain.SubscriptionPriceDrop {
+				lines = append(lines, h.t(chatID, "subscriptions.line_price_drop", name, h.money(chatID, sub.Price)))
+			} else {
+				lines = append(lines, h.t(chatID, "subscriptions.line_restock", name))
+			}
+		}
+		text = strings.Join(lines, "\n")
+	}
+
+	if messageID != 0 {
+		// Без клавиатуры в правке Телеграм убирает старые кнопки - так и надо, когда подписок не осталось
+		edit := tgbotapi.NewEditMessageText(chatID, messageID, text)
+		if len(subs) > 0 {
+			keyboard := h.keyboards.GetSubscriptionsKeyboard(h.lang(chatID), subs)
+			edit.ReplyMarkup = &keyboard
+		}
+		edit.ParseMode = "HTML"
+		h.bot.Send(edit)
+		return
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	if len(subs) > 0 {
+		msg.ReplyMarkup = h.keyboards.GetSubscriptionsKeyboard(h.lang(chatID), subs)
+	}
+	h.bot.Send(msg)
+}
+
+// notifySubscription - карточка товара с пометкой, что он появился или подешевел.
+// Под карточкой обычные кнопки товара, чтобы сразу положить его в корзину.
+func (h *Handler) notifySubscription(sub domain.Subscription, product *domain.Product) error {
+	chatID := sub.ChatID
+	name := html.EscapeString(product.Name)
+	notice := h.t(chatID, "subscriptions.restocked", name)
+	if sub.Kind == domain.SubscriptionPriceDrop {
+		notice = h.t(chatID, "subscriptions.price_dropped", name, h.money(chatID, sub.Price), h.money(chatID, product.Price))
+	}
+	keyboard := h.keyboards.GetProductKeyboard(h.lang(chatID), product.ID, product.InStock(), 0, len(product.Gallery()))
+
+	if product.ImageID == "" {
+		msg := tgbotapi.NewMessage(chatID, notice)
+		msg.ParseMode = "HTML"
+		msg.ReplyMarkup = keyboard
+		_, err := h.bot.Send(msg)
+		return err
+	}
+	msg := tgbotapi.NewPhoto(chatID, tgbotapi.FileID(product.ImageID))
+	msg.Caption = notice + "\n\n" + h.productCaption(chatID, product)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = keyboard
+	_, err := h.bot.Send(msg)
+	return err
+}
@@ -0,0 +1,290 @@
+// texts.go — тексты магазина (приветствие, о нас, доставка, контакты, FAQ):
+// показ покупателю и редактирование админом с предпросмотром и историей версий
+package telegram
+
+import (
+	"errors"
+	"html"
+	"log"
+	"strings"
+
+	"salle_parfume/internal/delivery/telegram/callbacks"
+	"salle_parfume/internal/domain"
+	"salle_parfume/internal/service"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// textHistoryLimit - сколько последних версий показываем в истории
+const textHistoryLimit = 10
+
+// textDateLayout - формат даты версии текста
+const textDateLayout = "2006-01-02 15:04"
+
+// textVars - значения подстановок для шаблонов. Тексты отправляются в HTML,
+// поэтому имя экранируем, чтобы "<" в имени не сломал разметку.
+func textVars(from *tgbotapi.User) map[string]string {
+	if from == nil {
+		return nil
+	}
+	username := from.UserName
+	if username != "" {
+		username = "@" + username
+	}
+	return map[string]string{
+		domain.PlaceholderFirstName: html.EscapeString(from.FirstName),
+		domain.PlaceholderLastName:  html.EscapeString(from.LastName),
+		domain.PlaceholderUsername:  html.EscapeString(username),
+	}
+}
+
+// userTextVars - подстановки для текста, который бот отправляет сам, без сообщения от покупателя
+// (например, напоминание). Пользователя может не быть в базе - тогда подстановки пустые.
+func userTextVars(user *domain.User) map[string]string {
+	if user == nil {
+		return nil
+	}
+	username := user.Username
+	if username != "" {
+		username = "@" + username
+	}
+	return map[string]string{
+		domain.PlaceholderFirstName: html.EscapeString(user.FirstName),
+		domain.PlaceholderUsername:  html.EscapeString(username),
+	}
+}
+
+// sendShopText - отправляет покупателю текст магазина с подставленным именем.
+// markup - клавиатура под сообщением (nil - без нее).
+func (h *Handler) sendShopText(chatID int64, from *tgbotapi.User, key string, markup any) {
+	text, err := h.texts.Render(key, h.lang(chatID), textVars(from))
+	if err != nil {
+		log.Printf("Error getting shop text %s: %v", key, err)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = markup
+	if _, err := h.bot.Send(msg); err != nil {
+		log.Printf("Error sending message: %v", err)
+	}
+}
+
+// shopTextCommand - команда, которая просто показывает текст магазина (/about, /delivery, ...)
+func (h *Handler) shopTextCommand(key string) func(*tgbotapi.Message) {
+	return func(message *tgbotapi.Message) {
+		h.sendShopText(message.Chat.ID, message.From, key, nil)
+	}
+}
+
+// handleShopTexts - команда админа /texts: список текстов магазина на языке админа
+func (h *Handler) handleShopTexts(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+
+	lang := h.lang(chatID)
+	var sb strings.Builder
+	sb.WriteString(h.t(chatID, "text_admin.list_title", lang) + "\n")
+	for _, key := range domain.ShopTextKeys {
+		text, err := h.texts.Current(key, lang)
+		if err != nil {
+			log.Printf("Error getting shop text %s: %v", key, err)
+			h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "text_admin.get_error")))
+			return
+		}
+		sb.WriteString("\n• " + h.t(chatID, "texts.names."+key) + " — " + h.textVersionInfo(chatID, text))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, sb.String())
+	msg.ReplyMarkup = h.keyboards.GetShopTextsKeyboard(lang, domain.ShopTextKeys)
+	h.bot.Send(msg)
+}
+
+// textVersionInfo - "версия 3 от 2025-12-01 10:00" или "по умолчанию"
+func (h *Handler) textVersionInfo(chatID int64, text *domain.ShopText) string {
+	if text.Version == 0 {
+		return h.t(chatID, "text_admin.default")
+	}
+	return h.t(chatID, "text_admin.version", text.Version, text.CreatedAt.Local().Format(textDateLayout))
+}
+
+// registerShopTextCallbacks - кнопки редактирования текстов. Все они только для админа
+// (права проверяет middleware Auth по callbackRoles).
+func (h *Handler) registerShopTextCallbacks(r *callbacks.Router) {
+	// admin - выполняет действие и убирает часики
+	admin := func(do func(chatID int64, callback *tgbotapi.CallbackQuery)) func(*tgbotapi.CallbackQuery) {
+		return func(callback *tgbotapi.CallbackQuery) {
+			chatID := callback.Message.Chat.ID
+			do(chatID, callback)
+			h.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+		}
+	}
+	// byKey - то же для кнопки с ключом текста
+	byKey := func(do func(chatID int64, key string)) func(*tgbotapi.CallbackQuery, string) {
+		return func(callback *tgbotapi.CallbackQuery, key string) {
+			admin(func(chatID int64, _ *tgbotapi.CallbackQuery) { do(chatID, key) })(callback)
+		}
+	}
+
+	r.HandleKey(callbacks.Text, byKey(h.showShopText))
+	r.HandleKey(callbacks.TextEdit, byKey(h.startShopTextEdit))
+	r.HandleKey(callbacks.TextHistory, byKey(h.showShopTextHistory))
+	r.HandleKeyID(callbacks.TextRollback, func(callback *tgbotapi.CallbackQuery, key string, version int64) {
+		admin(func(chatID int64, _ *tgbotapi.CallbackQuery) { h.rollbackShopText(chatID, key, int(version)) })(callback)
+	})
+	r.HandleAction(callbacks.TextSave, admin(func(chatID int64, callback *tgbotapi.CallbackQuery) {
+		h.saveShopText(chatID, callback.Message.MessageID)
+	}))
+	r.HandleAction(callbacks.TextCancel, admin(func(chatID int64, callback *tgbotapi.CallbackQuery) {
+		h.cancelShopText(chatID, callback.Message.MessageID)
+	}))
+}
+
+// showShopText - карточка текста: текущая версия и шаблон как есть, с подстановками и тегами
+func (h *Handler) showShopText(chatID int64, key string) {
+	if !domain.IsShopTextKey(key) {
+		return
+	}
+	lang := h.lang(chatID)
+	text, err := h.texts.Current(key, lang)
+	if err != nil {
+		log.Printf("Error getting shop text %s: %v", key, err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "text_admin.get_error")))
+		return
+	}
+
+	card := h.t(chatID, "text_admin.card", h.t(chatID, "texts.names."+key), lang, h.textVersionInfo(chatID, text), text.Body)
+	msg := tgbotapi.NewMessage(chatID, card)
+	msg.ReplyMarkup = h.keyboards.GetShopTextKeyboard(lang, key)
+	h.bot.Send(msg)
+}
+
+// startShopTextEdit - ждем от админа новый текст
+func (h *Handler) startShopTextEdit(chatID int64, key string) {
+	if !domain.IsShopTextKey(key) {
+		return
+	}
+	h.textDrafts[chatID] = &domain.ShopText{Key: key, Lang: h.lang(chatID)}
+	h.userStates[chatID] = StateWaitingForShopText
+
+	placeholders := "{" + strings.Join(domain.TextPlaceholders, "}, {") + "}"
+	h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "text_admin.enter", h.t(chatID, "texts.names."+key), placeholders)))
+}
+
+// handleShopTextInput - админ прислал новый текст: проверяем и показываем, как его увидит покупатель.
+// Пока текст не сохранен, можно прислать исправленный вариант - предпросмотр покажется заново.
+func (h *Handler) handleShopTextInput(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	draft := h.textDrafts[chatID]
+	if draft == nil {
+		h.userStates[chatID] = StateNone
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "text_admin.expired")))
+		return
+	}
+
+	body := message.Text
+	if err := domain.ValidateTextTemplate(body); err != nil {
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "text_admin.invalid", err)))
+		return
+	}
+
+	// Предпросмотр отправляем так же, как его получит покупатель: если Телеграм
+	// не примет разметку, узнаем об этом сейчас, а не после сохранения
+	preview := tgbotapi.NewMessage(chatID, domain.RenderText(body, textVars(message.From)))
+	preview.ParseMode = "HTML"
+	if _, err := h.bot.Send(preview); err != nil {
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "text_admin.markup_error", err)))
+		return
+	}
+
+	draft.Body = body
+	msg := tgbotapi.NewMessage(chatID, h.t(chatID, "text_admin.preview"))
+	msg.ReplyMarkup = h.keyboards.GetTextPreviewKeyboard(h.lang(chatID))
+	h.bot.Send(msg)
+}
+
+// saveShopText - сохраняет показанный в предпросмотре текст новой версией
+func (h *Handler) saveShopText(chatID int64, messageID int) {
+	draft := h.textDrafts[chatID]
+	if draft == nil || draft.Body == "" {
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "text_admin.expired")))
+		return
+	}
+
+	text, err := h.texts.Save(draft.Key, draft.Lang, draft.Body, chatID)
+	if err != nil {
+		log.Printf("Error saving shop text %s: %v", draft.Key, err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "common.save_error")))
+		return
+	}
+
+	delete(h.textDrafts, chatID)
+	h.userStates[chatID] = StateNone
+	h.bot.Send(tgbotapi.NewEditMessageText(chatID, messageID, h.t(chatID, "text_admin.saved", text.Version)))
+}
+
+// cancelShopText - админ передумал менять текст
+func (h *Handler) cancelShopText(chatID int64, messageID int) {
+	delete(h.textDrafts, chatID)
+	h.userStates[chatID] = StateNone
+	h.bot.Send(tgbotapi.NewEditMessageText(chatID, messageID, h.t(chatID, "text_admin.cancelled")))
+}
+
+// showShopTextHistory - последние версии текста с кнопками отката
+func (h *Handler) showShopTextHistory(chatID int64, key string) {
+	if !domain.IsShopTextKey(key) {
+		return
+	}
+	lang := h.lang(chatID)
+	history, err := h.texts.History(key, lang, textHistoryLimit)
+	if err != nil {
+		log.Printf("Error getting shop text history %s: %v", key, err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "text_admin.get_error")))
+		return
+	}
+	if len(history) == 0 {
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "text_admin.history_empty")))
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(h.t(chatID, "text_admin.history_title", h.t(chatID, "texts.names."+key), lang) + "\n")
+	var versions []int
+	for i, text := range history {
+		sb.WriteString("\n" + h.t(chatID, "text_admin.history_line", text.Version, text.CreatedAt.Local().Format(textDateLayout), textExcerpt(text.Body)))
+		// Текущую версию возвращать незачем
+		if i > 0 {
+			versions = append(versions, text.Version)
+		}
+	}
+
+	msg := tgbotapi.NewMessage(chatID, sb.String())
+	if len(versions) > 0 {
+		msg.ReplyMarkup = h.keyboards.GetTextHistoryKeyboard(lang, key, versions)
+	}
+	h.bot.Send(msg)
+}
+
+// rollbackShopText - возвращает старую версию текста (сохраняется новой версией)
+func (h *Handler) rollbackShopText(chatID int64, key string, version int) {
+	text, err := h.texts.Rollback(key, h.lang(chatID), version, chatID)
+	if err != nil {
+		if errors.Is(err, service.ErrShopTextVersionNotFound) || errors.Is(err, service.ErrUnknownShopText) {
+			h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "text_admin.version_not_found")))
+			return
+		}
+		log.Printf("Error rolling back shop text %s to %d: %v", key, version, err)
+		h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "common.save_error")))
+		return
+	}
+	h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "text_admin.rolled_back", version, text.Version)))
+}
+
+// textExcerpt - начало текста в одну строку для списка версий
+func textExcerpt(body string) string {
+	const maxRunes = 50
+	line := strings.Join(strings.Fields(body), " ")
+	if r := []rune(line); len(r) > maxRunes {
+		return string(r[:maxRunes]) + "…"
+	}
+	return line
+}
@@ -0,0 +1,37 @@
+// audit.go — журнал действий админов в админке
+package web
+
+import (
+	"net/http"
+	"strconv"
+
+	"salle_parfume/internal/domain"
+)
+
+// auditPageSize - записей журнала на странице
+const auditPageSize = 100
+
+// auditPage - данные страницы журнала
+type auditPage struct {
+	Entries []domain.AuditEntry
+	Page    int // Номер страницы с нуля
+	HasNext bool
+}
+
+// handleAudit - GET /admin/audit?page=0: кто, когда и что менял, новые записи первыми
+func (h *Handler) handleAudit(w http.ResponseWriter, r *http.Request, s *session) {
+	n, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	n = max(n, 0)
+
+	// Берем на одну запись больше, чтобы понять, есть ли следующая страница
+	entries, err := h.repo.GetAuditLog(auditPageSize+1, n*auditPageSize)
+	if err != nil {
+		h.internalError(w, r, s, "get audit log", err)
+		return
+	}
+	data := auditPage{Entries: entries, Page: n}
+	if len(entries) > auditPageSize {
+		data.Entries, data.HasNext = entries[:auditPageSize], true
+	}
+	h.render(w, http.StatusOK, "audit", page(r, s, "Журнал действий", data))
+}
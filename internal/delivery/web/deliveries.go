@@ -0,0 +1,192 @@
+// deliveries.go — способы доставки в админке: пункты самовывоза и курьерские зоны с ценами
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"salle_parfume/internal/domain"
+)
+
+// deliveryPage - данные формы способа доставки
+type deliveryPage struct {
+	Method *domain.DeliveryMethod
+	New    bool
+	// Цены, как их ввел админ (чтобы не потерять ввод при ошибке)
+	FeeInput      string
+	PerKgInput    string
+	FreeFromInput string
+	Kinds         []domain.DeliveryKind
+}
+
+var deliveryKinds = []domain.DeliveryKind{domain.DeliveryPickup, domain.DeliveryCourier}
+
+// handleDeliveryMethods - GET /admin/delivery: все способы доставки, включенные и выключенные
+func (h *Handler) handleDeliveryMethods(w http.ResponseWriter, r *http.Request, s *session) {
+	methods, err := h.repo.GetDeliveryMethods(false)
+	if err != nil {
+		h.internalError(w, r, s, "get delivery methods", err)
+		return
+	}
+	h.render(w, http.StatusOK, "deliveries", page(r, s, "Доставка", methods))
+}
+
+// handleNewDeliveryMethod - GET /admin/delivery/new: пустая форма
+func (h *Handler) handleNewDeliveryMethod(w http.ResponseWriter, r *http.Request, s *session) {
+	data := deliveryPage{Method: &domain.DeliveryMethod{Kind: domain.DeliveryCourier, Active: true}, New: true, Kinds: deliveryKinds}
+	h.render(w, http.StatusOK, "delivery", page(r, s, "Новый способ доставки", data))
+}
+
+// handleCreateDeliveryMethod - POST /admin/delivery/new
+func (h *Handler) handleCreateDeliveryMethod(w http.ResponseWriter, r *http.Request, s *session) {
+	method := &domain.DeliveryMethod{}
+	form := deliveryFormInput(r)
+	form.New = true
+
+	if err := applyDeliveryForm(r, method); err != nil {
+		h.deliveryFormError(w, r, s, form, method, err)
+		return
+	}
+	if err := h.repo.CreateDeliveryMethod(method); err != nil {
+		h.internalError(w, r, s, "create delivery method", err)
+		return
+	}
+	h.audit(s, domain.AuditDeliveryCreate, domain.AuditObjectDelivery, method.ID, deliverySummary(method))
+	redirect(w, r, "/admin/delivery", "Способ доставки добавлен")
+}
+
+// handleEditDeliveryMethod - GET /admin/delivery/{id}
+func (h *Handler) handleEditDeliveryMethod(w http.ResponseWriter, r *http.Request, s *session) {
+	method, ok := h.getDeliveryMethod(w, r, s)
+	if !ok {
+		return
+	}
+	data := deliveryPage{
+		Method:        method,
+		FeeInput:      method.Fee.Decimal(),
+		PerKgInput:    method.PerKg.Decimal(),
+		FreeFromInput: method.FreeFrom.Decimal(),
+		Kinds:         deliveryKinds,
+	}
+	h.render(w, http.StatusOK, "delivery", page(r, s, method.Name, data))
+}
+
+// handleUpdateDeliveryMethod - POST /admin/delivery/{id}.
+// Способ не удаляется, а выключается: на него ссылаются старые заказы.
+func (h *Handler) handleUpdateDeliveryMethod(w http.ResponseWriter, r *http.Request, s *session) {
+	old, ok := h.getDeliveryMethod(w, r, s)
+	if !ok {
+		return
+	}
+	method := *old
+	form := deliveryFormInput(r)
+
+	if err := applyDeliveryForm(r, &method); err != nil {
+		h.deliveryFormError(w, r, s, form, &method, err)
+		return
+	}
+	before, after := deliverySummary(old), deliverySummary(&method)
+	if before == after {
+		redirect(w, r, fmt.Sprintf("/admin/delivery/%d", method.ID), "Изменений нет")
+		return
+	}
+	if err := h.repo.UpdateDeliveryMethod(&method); err != nil {
+		h.internalError(w, r, s, "update delivery method", err)
+		return
+	}
+	h.audit(s, domain.AuditDeliveryUpdate, domain.AuditObjectDelivery, method.ID, before+" → "+after)
+	redirect(w, r, fmt.Sprintf("/admin/delivery/%d", method.ID), "Сохранено")
+}
+
+// getDeliveryMethod - способ доставки из пути запроса. Если его нет, сам показывает 404.
+func (h *Handler) getDeliveryMethod(w http.ResponseWriter, r *http.Request, s *session) (*domain.DeliveryMethod, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		h.notFound(w, r, s, "Способ доставки не найден")
+		return nil, false
+	}
+	method, err := h.repo.GetDeliveryMethodByID(id)
+	if err != nil {
+		h.internalError(w, r, s, "get delivery method", err)
+		return nil, false
+	}
+	if method == nil {
+		h.notFound(w, r, s, "Способ доставки не найден")
+		return nil, false
+	}
+	return method, true
+}
+
+// deliveryFormInput - цены из формы как есть, чтобы показать их снова при ошибке
+func deliveryFormInput(r *http.Request) deliveryPage {
+	return deliveryPage{
+		FeeInput:      r.FormValue("fee"),
+		PerKgInput:    r.FormValue("per_kg"),
+		FreeFromInput: r.FormValue("free_from"),
+		Kinds:         deliveryKinds,
+	}
+}
+
+// applyDeliveryForm - проверяет поля формы и переносит их в способ доставки.
+// Пустая цена - ноль: бесплатная доставка, без доплаты за вес или без порога бесплатной доставки.
+func applyDeliveryForm(r *http.Request, m *domain.DeliveryMethod) error {
+	prices := []struct {
+		field, name string
+		dst         *domain.Money
+	}{
+		{"fee", "цена доставки", &m.Fee},
+		{"per_kg", "доплата за кг", &m.PerKg},
+		{"free_from", "порог бесплатной доставки", &m.FreeFrom},
+	}
+	for _, p := range prices {
+		value := strings.TrimSpace(r.FormValue(p.field))
+		if value == "" {
+			*p.dst = domain.NewMoney(0, domain.DefaultCurrency)
+			continue
+		}
+		price, err := domain.ParseMoney(value, domain.DefaultCurrency)
+		if err != nil {
+			return fmt.Errorf("%s указана неверно, пример: 300 или 299.90", p.name)
+		}
+		*p.dst = price
+	}
+
+	m.Kind = domain.DeliveryKind(r.FormValue("kind"))
+	m.Name = strings.TrimSpace(r.FormValue("name"))
+	m.Address = strings.TrimSpace(r.FormValue("address"))
+	m.Active = r.FormValue("active") != ""
+	return m.Validate()
+}
+
+// deliverySummary - способ доставки одной строкой для журнала
+func deliverySummary(m *domain.DeliveryMethod) string {
+	parts := []string{fmt.Sprintf("%s «%s»", deliveryKindNames[m.Kind], m.Name)}
+	if m.Address != "" {
+		parts = append(parts, m.Address)
+	}
+	parts = append(parts, m.Fee.String())
+	if m.PerKg.IsPositive() {
+		parts = append(parts, fmt.Sprintf("+%s/кг", m.PerKg))
+	}
+	if m.FreeFrom.IsPositive() {
+		parts = append(parts, fmt.Sprintf("бесплатно от %s", m.FreeFrom))
+	}
+	if !m.Active {
+		parts = append(parts, "выключен")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// deliveryFormError - показывает форму снова с ошибкой и введенными данными
+func (h *Handler) deliveryFormError(w http.ResponseWriter, r *http.Request, s *session, form deliveryPage, m *domain.DeliveryMethod, err error) {
+	form.Method = m
+	title := m.Name
+	if form.New {
+		title = "Новый способ доставки"
+	}
+	data := page(r, s, title, form)
+	data.Error = err.Error()
+	h.render(w, http.StatusUnprocessableEntity, "delivery", data)
+}
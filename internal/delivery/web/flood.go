@@ -0,0 +1,47 @@
+// flood.go — флуд и блокировки в админке: кто получал паузы и кто заблокирован.
+// Блокирует и разблокирует админ командами бота /ban и /unban, здесь только просмотр.
+package web
+
+import (
+	"net/http"
+	"strconv"
+
+	"salle_parfume/internal/domain"
+)
+
+// floodPageSize - превышений на странице
+const floodPageSize = 100
+
+// floodBansLimit - сколько последних блокировок показываем над превышениями
+const floodBansLimit = 100
+
+// floodPage - данные страницы флуда
+type floodPage struct {
+	Bans    []domain.Ban
+	Events  []domain.FloodEvent
+	Page    int // Номер страницы превышений с нуля
+	HasNext bool
+}
+
+// handleFlood - GET /admin/flood?page=0: блокировки и превышения, новые первыми
+func (h *Handler) handleFlood(w http.ResponseWriter, r *http.Request, s *session) {
+	n, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	n = max(n, 0)
+
+	bans, err := h.repo.GetBans(floodBansLimit, 0)
+	if err != nil {
+		h.internalError(w, r, s, "get bans", err)
+		return
+	}
+	// Берем на одно превышение больше, чтобы понять, есть ли следующая страница
+	events, err := h.repo.GetFloodEvents(floodPageSize+1, n*floodPageSize)
+	if err != nil {
+		h.internalError(w, r, s, "get flood events", err)
+		return
+	}
+	data := floodPage{Bans: bans, Events: events, Page: n}
+	if len(events) > floodPageSize {
+		data.Events, data.HasNext = events[:floodPageSize], true
+	}
+	h.render(w, http.StatusOK, "flood", page(r, s, "Флуд и блокировки", data))
+}
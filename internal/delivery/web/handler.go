@@ -0,0 +1,241 @@
+// handler.go — веб-админка магазина: товары, заказы, покупатели и журнал действий.
+// Страницы рендерятся на сервере из встроенных шаблонов, внешние сервисы не нужны.
+// Админка работает с той же базой, что и бот, и открывается на том же сервере, что и HTTP API (/admin).
+package web
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"salle_parfume/internal/domain"
+	"salle_parfume/internal/repository"
+)
+
+//go:embed templates/*.html
+var templatesFS embed.FS
+
+// PhotoUploader - загружает фото в Телеграм и возвращает file_id.
+// Реализует *telegram.PhotoUploader: фото уходит в чат админа и сразу удаляется.
+type PhotoUploader interface {
+	UploadPhotoBytes(name string, data []byte) (string, error)
+}
+
+// Handler - обработчики веб-админки
+type Handler struct {
+	repo      *repository.Repository
+	uploader  PhotoUploader
+	users     map[string]string // логин -> пароль
+	sessions  *sessionStore
+	templates map[string]*template.Template
+}
+
+// NewHandler - создает админку. users - логины и пароли админов.
+func NewHandler(repo *repository.Repository, uploader PhotoUploader, users map[string]string) (*Handler, error) {
+	templates, err := parseTemplates()
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{
+		repo:      repo,
+		uploader:  uploader,
+		users:     users,
+		sessions:  newSessionStore(sessionTTL),
+		templates: templates,
+	}, nil
+}
+
+// Routes - таблица маршрутов админки. Все страницы, кроме входа, только после логина.
+func (h *Handler) Routes() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /admin/login", h.handleLoginPage)
+	mux.HandleFunc("POST /admin/login", h.handleLogin)
+	mux.HandleFunc("POST /admin/logout", h.requireLogin(h.handleLogout))
+
+	mux.HandleFunc("GET /admin/{$}", h.requireLogin(func(w http.ResponseWriter, r *http.Request, s *session) {
+		http.Redirect(w, r, "/admin/orders", http.StatusSeeOther)
+	}))
+
+	// Товары
+	mux.HandleFunc("GET /admin/products", h.requireLogin(h.handleProducts))
+	mux.HandleFunc("GET /admin/products/new", h.requireLogin(h.handleNewProduct))
+	mux.HandleFunc("POST /admin/products/new", h.requireLogin(h.handleCreateProduct))
+	mux.HandleFunc("GET /admin/products/{id}", h.requireLogin(h.handleEditProduct))
+	mux.HandleFunc("POST /admin/products/{id}", h.requireLogin(h.handleUpdateProduct))
+	mux.HandleFunc("POST /admin/products/{id}/delete", h.requireLogin(h.handleDeleteProduct))
+
+	// Заказы
+	mux.HandleFunc("GET /admin/orders", h.requireLogin(h.handleOrders))
+	mux.HandleFunc("GET /admin/orders/{id}", h.requireLogin(h.handleOrder))
+	mux.HandleFunc("POST /admin/orders/{id}/status", h.requireLogin(h.handleOrderStatus))
+
+	// Способы доставки
+	mux.HandleFunc("GET /admin/delivery", h.requireLogin(h.handleDeliveryMethods))
+	mux.HandleFunc("GET /admin/delivery/new", h.requireLogin(h.handleNewDeliveryMethod))
+	mux.HandleFunc("POST /admin/delivery/new", h.requireLogin(h.handleCreateDeliveryMethod))
+	mux.HandleFunc("GET /admin/delivery/{id}", h.requireLogin(h.handleEditDeliveryMethod))
+	mux.HandleFunc("POST /admin/delivery/{id}", h.requireLogin(h.handleUpdateDeliveryMethod))
+
+	// Покупатели
+	mux.HandleFunc("GET /admin/users", h.requireLogin(h.handleUsers))
+	mux.HandleFunc("GET /admin/users/{chatID}", h.requireLogin(h.handleUser))
+	mux.HandleFunc("POST /admin/users/{chatID}/points", h.requireLogin(h.handleUserPoints))
+
+	// Журнал действий
+	mux.HandleFunc("GET /admin/audit", h.requireLogin(h.handleAudit))
+
+	// Фоновые задачи
+	mux.HandleFunc("GET /admin/jobs", h.requireLogin(h.handleJobs))
+
+	// Флуд и блокировки
+	mux.HandleFunc("GET /admin/flood", h.requireLogin(h.handleFlood))
+
+	return mux
+}
+
+// statusNames - статусы заказов по-русски
+var statusNames = map[domain.OrderStatus]string{
+	domain.OrderStatusNew:       "Новый",
+	domain.OrderStatusPaid:      "Оплачен",
+	domain.OrderStatusConfirmed: "Подтвержден",
+	domain.OrderStatusShipped:   "Отправлен",
+	domain.OrderStatusDelivered: "Получен",
+	domain.OrderStatusCancelled: "Отменен",
+}
+
+// deliveryKindNames - виды доставки по-русски
+var deliveryKindNames = map[domain.DeliveryKind]string{
+	domain.DeliveryPickup:  "Самовывоз",
+	domain.DeliveryCourier: "Курьер",
+}
+
+// loyaltyKindNames - операции с бонусами по-русски
+var loyaltyKindNames = map[domain.LoyaltyKind]string{
+	domain.LoyaltyEarn:   "Кешбэк за заказ",
+	domain.LoyaltySpend:  "Оплата заказа",
+	domain.LoyaltyRefund: "Возврат за отмену",
+	domain.LoyaltyRevoke: "Отмена кешбэка",
+	domain.LoyaltyExpire: "Сгорание",
+	domain.LoyaltyAdjust: "Корректировка",
+}
+
+// orderStatuses - статусы в порядке колонок на доске заказов
+var orderStatuses = []domain.OrderStatus{
+	domain.OrderStatusNew,
+	domain.OrderStatusPaid,
+	domain.OrderStatusConfirmed,
+	domain.OrderStatusShipped,
+	domain.OrderStatusDelivered,
+	domain.OrderStatusCancelled,
+}
+
+// templateFuncs - функции, доступные в шаблонах
+var templateFuncs = template.FuncMap{
+	"status": func(s domain.OrderStatus) string {
+		if name, ok := statusNames[s]; ok {
+			return name
+		}
+		return string(s)
+	},
+	"date": func(t time.Time) string {
+		return t.Local().Format("02.01.2006 15:04")
+	},
+	"add": func(a, b int) int { return a + b },
+	"deliveryKind": func(k domain.DeliveryKind) string {
+		if name, ok := deliveryKindNames[k]; ok {
+			return name
+		}
+		return string(k)
+	},
+	"loyaltyKind": func(k domain.LoyaltyKind) string {
+		if name, ok := loyaltyKindNames[k]; ok {
+			return name
+		}
+		return string(k)
+	},
+	"imageURL": func(productID int64, n int) string {
+		return fmt.Sprintf("/api/v1/products/%d/images/%d", productID, n)
+	},
+}
+
+// pages - страницы админки. Каждая собирается вместе с общим layout.html.
+var pages = []string{"login", "error", "products", "product", "orders", "order", "deliveries", "delivery", "users", "user", "audit", "jobs", "flood"}
+
+// parseTemplates - разбирает шаблоны один раз при старте, чтобы ошибки в них были видны сразу
+func parseTemplates() (map[string]*template.Template, error) {
+	templates := make(map[string]*template.Template, len(pages))
+	for _, name := range pages {
+		t, err := template.New("layout.html").Funcs(templateFuncs).ParseFS(templatesFS, "templates/layout.html", "templates/"+name+".html")
+		if err != nil {
+			return nil, err
+		}
+		templates[name] = t
+	}
+	return templates, nil
+}
+
+// pageData - общие данные всех страниц
+type pageData struct {
+	Title  string
+	Login  string // Кто вошел (пусто на странице входа)
+	CSRF   string // Токен для форм
+	Notice string // Сообщение об успехе после редиректа
+	Error  string // Ошибка, которую надо показать
+	Data   any    // Данные конкретной страницы
+}
+
+// render - отдает страницу
+func (h *Handler) render(w http.ResponseWriter, status int, name string, data pageData) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := h.templates[name].Execute(w, data); err != nil {
+		log.Printf("Error rendering admin web page %s: %v", name, err)
+	}
+}
+
+// page - данные страницы для вошедшего админа
+func page(r *http.Request, s *session, title string, data any) pageData {
+	return pageData{
+		Title:  title,
+		Login:  s.login,
+		CSRF:   s.csrf,
+		Notice: r.URL.Query().Get("notice"),
+		Data:   data,
+	}
+}
+
+// redirect - переход после успешной формы с сообщением (Post/Redirect/Get, чтобы F5 не повторял действие)
+func redirect(w http.ResponseWriter, r *http.Request, path, notice string) {
+	if notice != "" {
+		path += "?notice=" + url.QueryEscape(notice)
+	}
+	http.Redirect(w, r, path, http.StatusSeeOther)
+}
+
+// internalError - пишет ошибку в лог, а админу показывает общий текст
+func (h *Handler) internalError(w http.ResponseWriter, r *http.Request, s *session, context string, err error) {
+	log.Printf("Error in admin web, %s: %v", context, err)
+	data := page(r, s, "Ошибка", nil)
+	data.Error = "Внутренняя ошибка, подробности в логе сервера"
+	h.render(w, http.StatusInternalServerError, "error", data)
+}
+
+// notFound - страница не найдена
+func (h *Handler) notFound(w http.ResponseWriter, r *http.Request, s *session, message string) {
+	data := page(r, s, "Не найдено", nil)
+	data.Error = message
+	h.render(w, http.StatusNotFound, "error", data)
+}
+
+// audit - записывает действие админа в журнал. Ошибка журнала не отменяет само действие.
+func (h *Handler) audit(s *session, action, object string, objectID int64, details string) {
+	entry := &domain.AuditEntry{Actor: s.login, Action: action, Object: object, ObjectID: objectID, Details: details}
+	if err := h.repo.AddAuditEntry(entry); err != nil {
+		log.Printf("Error adding audit entry %s %s %d from admin web: %v", action, object, objectID, err)
+	}
+}
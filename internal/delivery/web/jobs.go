@@ -0,0 +1,37 @@
+// jobs.go — история запусков фоновых задач в админке
+package web
+
+import (
+	"net/http"
+	"strconv"
+
+	"salle_parfume/internal/domain"
+)
+
+// jobsPageSize - запусков на странице
+const jobsPageSize = 100
+
+// jobsPage - данные страницы запусков
+type jobsPage struct {
+	Runs    []domain.JobRun
+	Page    int // Номер страницы с нуля
+	HasNext bool
+}
+
+// handleJobs - GET /admin/jobs?page=0: запуски задач планировщика, новые первыми
+func (h *Handler) handleJobs(w http.ResponseWriter, r *http.Request, s *session) {
+	n, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	n = max(n, 0)
+
+	// Берем на один запуск больше, чтобы понять, есть ли следующая страница
+	runs, err := h.repo.GetJobRuns(jobsPageSize+1, n*jobsPageSize)
+	if err != nil {
+		h.internalError(w, r, s, "get job runs", err)
+		return
+	}
+	data := jobsPage{Runs: runs, Page: n}
+	if len(runs) > jobsPageSize {
+		data.Runs, data.HasNext = runs[:jobsPageSize], true
+	}
+	h.render(w, http.StatusOK, "jobs", page(r, s, "Фоновые задачи", data))
+}
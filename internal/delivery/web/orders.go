@@ -0,0 +1,116 @@
+// orders.go — заказы в админке: доска по статусам, карточка заказа и смена статуса
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"salle_parfume/internal/domain"
+)
+
+// boardLimit - сколько последних заказов показывать в колонке доски
+const boardLimit = 50
+
+// boardColumn - колонка доски заказов
+type boardColumn struct {
+	Status domain.OrderStatus
+	Orders []domain.Order
+}
+
+// orderPage - данные карточки заказа
+type orderPage struct {
+	Order    *domain.Order
+	Customer *domain.User         // nil, если покупателя нет в users (например, заказ через API)
+	Statuses []domain.OrderStatus // Текущий статус и те, в которые заказ можно перевести
+}
+
+// handleOrders - GET /admin/orders: доска заказов по статусам
+func (h *Handler) handleOrders(w http.ResponseWriter, r *http.Request, s *session) {
+	var board []boardColumn
+	for _, status := range orderStatuses {
+		orders, err := h.repo.GetOrdersByStatus(status, boardLimit)
+		if err != nil {
+			h.internalError(w, r, s, "get orders", err)
+			return
+		}
+		board = append(board, boardColumn{Status: status, Orders: orders})
+	}
+	h.render(w, http.StatusOK, "orders", page(r, s, "Заказы", board))
+}
+
+// handleOrder - GET /admin/orders/{id}
+func (h *Handler) handleOrder(w http.ResponseWriter, r *http.Request, s *session) {
+	order, ok := h.getOrder(w, r, s)
+	if !ok {
+		return
+	}
+	customer, err := h.repo.GetUserByChatID(order.ChatID)
+	if err != nil {
+		h.internalError(w, r, s, "get user", err)
+		return
+	}
+	data := orderPage{Order: order, Customer: customer, Statuses: []domain.OrderStatus{order.Status}}
+	for _, status := range orderStatuses {
+		if order.Status.CanBecome(status) {
+			data.Statuses = append(data.Statuses, status)
+		}
+	}
+	h.render(w, http.StatusOK, "order", page(r, s, fmt.Sprintf("Заказ №%d", order.ID), data))
+}
+
+// handleOrderStatus - POST /admin/orders/{id}/status
+func (h *Handler) handleOrderStatus(w http.ResponseWriter, r *http.Request, s *session) {
+	order, ok := h.getOrder(w, r, s)
+	if !ok {
+		return
+	}
+	status := domain.OrderStatus(r.FormValue("status"))
+	if !status.Valid() {
+		http.Error(w, "Неизвестный статус заказа", http.StatusUnprocessableEntity)
+		return
+	}
+	path := fmt.Sprintf("/admin/orders/%d", order.ID)
+	if status == order.Status {
+		redirect(w, r, path, "Статус не изменился")
+		return
+	}
+	// Те же переходы, что и у кнопок в боте: иначе повторная отмена вернула бы товары и бонусы еще раз
+	if !order.Status.CanBecome(status) {
+		http.Error(w, fmt.Sprintf("Заказ в статусе «%s» нельзя перевести в «%s»", statusNames[order.Status], statusNames[status]), http.StatusConflict)
+		return
+	}
+
+	err := h.repo.UpdateOrderStatus(order.ID, status)
+	if errors.Is(err, domain.ErrStatusChange) {
+		// Статус успел смениться в боте или через API, пока админ смотрел на страницу
+		http.Error(w, "Статус заказа уже изменили, обновите страницу", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		h.internalError(w, r, s, "update order status", err)
+		return
+	}
+	h.audit(s, domain.AuditOrderStatus, domain.AuditObjectOrder, order.ID, fmt.Sprintf("%s → %s", order.Status, status))
+	redirect(w, r, path, "Статус изменен: "+statusNames[status])
+}
+
+// getOrder - заказ из пути запроса. Если заказа нет, сам показывает 404.
+func (h *Handler) getOrder(w http.ResponseWriter, r *http.Request, s *session) (*domain.Order, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		h.notFound(w, r, s, "Заказ не найден")
+		return nil, false
+	}
+	order, err := h.repo.GetOrderByID(id)
+	if err != nil {
+		h.internalError(w, r, s, "get order", err)
+		return nil, false
+	}
+	if order == nil {
+		h.notFound(w, r, s, "Заказ не найден")
+		return nil, false
+	}
+	return order, true
+}
@@ -0,0 +1,327 @@
+// products.go — товары в админке: список, добавление, редактирование с загрузкой фото, удаление
+package web
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"salle_parfume/internal/domain"
+)
+
+// maxPhotoSize - Телеграм не принимает фото больше 10 МБ
+const maxPhotoSize = 10 << 20
+
+// productsPage - данные страницы списка товаров
+type productsPage struct {
+	Query    string
+	Products []domain.Product
+}
+
+// productPage - данные формы товара
+type productPage struct {
+	Product    *domain.Product
+	New        bool
+	PriceInput string // Цена, как ее ввел админ (чтобы не потерять ввод при ошибке)
+	Types      []domain.ProductType
+}
+
+var productTypes = []domain.ProductType{domain.TypeFemale, domain.TypeMale, domain.TypeUnisex}
+
+// handleProducts - GET /admin/products?q=: все товары, новые первыми, с поиском по названию и артикулу
+func (h *Handler) handleProducts(w http.ResponseWriter, r *http.Request, s *session) {
+	products, err := h.repo.GetAllProducts()
+	if err != nil {
+		h.internalError(w, r, s, "get products", err)
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query != "" {
+		q := strings.ToLower(query)
+		products = slices.DeleteFunc(products, func(p domain.Product) bool {
+			return !strings.Contains(strings.ToLower(p.Name), q) && !strings.Contains(strings.ToLower(p.SKU), q)
+		})
+	}
+	slices.SortFunc(products, func(a, b domain.Product) int { return cmp.Compare(b.ID, a.ID) })
+
+	h.render(w, http.StatusOK, "products", page(r, s, "Товары", productsPage{Query: query, Products: products}))
+}
+
+// handleNewProduct - GET /admin/products/new: пустая форма
+func (h *Handler) handleNewProduct(w http.ResponseWriter, r *http.Request, s *session) {
+	data := productPage{Product: &domain.Product{Type: domain.TypeFemale}, New: true, Types: productTypes}
+	h.render(w, http.StatusOK, "product", page(r, s, "Новый товар", data))
+}
+
+// handleCreateProduct - POST /admin/products/new
+func (h *Handler) handleCreateProduct(w http.ResponseWriter, r *http.Request, s *session) {
+	product := &domain.Product{}
+	form := productPage{New: true, PriceInput: r.FormValue("price"), Types: productTypes}
+
+	if err := h.applyProductForm(r, product); err != nil {
+		h.productFormError(w, r, s, form, product, err)
+		return
+	}
+	if err := h.checkSKU(product); err != nil {
+		h.productFormError(w, r, s, form, product, err)
+		return
+	}
+	images, err := h.uploadPhotos(r)
+	if err != nil {
+		h.productFormError(w, r, s, form, product, err)
+		return
+	}
+	// Без фото карточку товара в боте не показать
+	if len(images) == 0 {
+		h.productFormError(w, r, s, form, product, errors.New("нужно хотя бы одно фото"))
+		return
+	}
+	product.Images = images
+
+	if err := h.repo.CreateProduct(product); err != nil {
+		h.internalError(w, r, s, "create product", err)
+		return
+	}
+	h.audit(s, domain.AuditProductCreate, domain.AuditObjectProduct, product.ID, fmt.Sprintf("%s (%s), %s", product.Name, product.SKU, product.Price))
+	redirect(w, r, fmt.Sprintf("/admin/products/%d", product.ID), "Товар добавлен")
+}
+
+// handleEditProduct - GET /admin/products/{id}
+func (h *Handler) handleEditProduct(w http.ResponseWriter, r *http.Request, s *session) {
+	product, ok := h.getProduct(w, r, s)
+	if !ok {
+		return
+	}
+	data := productPage{Product: product, PriceInput: product.Price.Decimal(), Types: productTypes}
+	h.render(w, http.StatusOK, "product", page(r, s, product.Name, data))
+}
+
+// handleUpdateProduct - POST /admin/products/{id}.
+// Отмеченные фото удаляются, новые добавляются в конец галереи.
+func (h *Handler) handleUpdateProduct(w http.ResponseWriter, r *http.Request, s *session) {
+	old, ok := h.getProduct(w, r, s)
+	if !ok {
+		return
+	}
+	product := *old
+	product.Images = slices.Clone(old.Gallery())
+	form := productPage{PriceInput: r.FormValue("price"), Types: productTypes}
+
+	if err := h.applyProductForm(r, &product); err != nil {
+		h.productFormError(w, r, s, form, &product, err)
+		return
+	}
+	if product.SKU == "" {
+		product.SKU = old.SKU
+	}
+	if product.SKU != old.SKU {
+		if err := h.checkSKU(&product); err != nil {
+			h.productFormError(w, r, s, form, &product, err)
+			return
+		}
+	}
+
+	// Сначала убираем отмеченные фото (номера считаем по старой галерее), потом добавляем новые
+	remove := make(map[int]bool)
+	for _, v := range r.Form["remove_image"] {
+		if n, err := strconv.Atoi(v); err == nil {
+			remove[n] = true
+		}
+	}
+	var images []string
+	for i, fileID := range product.Images {
+		if !remove[i] {
+			images = append(images, fileID)
+		}
+	}
+	if len(images)+len(formFiles(r)) > domain.MaxProductImages {
+		h.productFormError(w, r, s, form, &product, fmt.Errorf("больше %d фото добавить нельзя", domain.MaxProductImages))
+		return
+	}
+	uploaded, err := h.uploadPhotos(r)
+	if err != nil {
+		h.productFormError(w, r, s, form, &product, err)
+		return
+	}
+	images = append(images, uploaded...)
+	if len(images) == 0 {
+		h.productFormError(w, r, s, form, &product, errors.New("нужно хотя бы одно фото"))
+		return
+	}
+	product.Images = images
+
+	changes := domain.ProductChanges(old, &product)
+	if len(changes) == 0 {
+		redirect(w, r, fmt.Sprintf("/admin/products/%d", product.ID), "Изменений нет")
+		return
+	}
+	if err := h.repo.UpdateProduct(&product); err != nil {
+		h.internalError(w, r, s, "update product", err)
+		return
+	}
+	h.audit(s, domain.AuditProductUpdate, domain.AuditObjectProduct, product.ID, strings.Join(changes, "; "))
+	redirect(w, r, fmt.Sprintf("/admin/products/%d", product.ID), "Сохранено")
+}
+
+// handleDeleteProduct - POST /admin/products/{id}/delete
+func (h *Handler) handleDeleteProduct(w http.ResponseWriter, r *http.Request, s *session) {
+	product, ok := h.getProduct(w, r, s)
+	if !ok {
+		return
+	}
+	if err := h.repo.DeleteProduct(product.ID); err != nil {
+		h.internalError(w, r, s, "delete product", err)
+		return
+	}
+	h.audit(s, domain.AuditProductDelete, domain.AuditObjectProduct, product.ID, fmt.Sprintf("%s (%s)", product.Name, product.SKU))
+	redirect(w, r, "/admin/products", "Товар «"+product.Name+"» удален")
+}
+
+// getProduct - товар из пути запроса. Если товара нет, сам показывает 404.
+func (h *Handler) getProduct(w http.ResponseWriter, r *http.Request, s *session) (*domain.Product, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		h.notFound(w, r, s, "Товар не найден")
+		return nil, false
+	}
+	product, err := h.repo.GetProductByID(id)
+	if err != nil {
+		h.internalError(w, r, s, "get product", err)
+		return nil, false
+	}
+	if product == nil {
+		h.notFound(w, r, s, "Товар не найден")
+		return nil, false
+	}
+	return product, true
+}
+
+// applyProductForm - проверяет поля формы и переносит их в товар (фото не трогает)
+func (h *Handler) applyProductForm(r *http.Request, p *domain.Product) error {
+	t, err := domain.ParseProductType(r.FormValue("type"))
+	if err != nil {
+		return err
+	}
+	name := strings.TrimSpace(r.FormValue("name"))
+	if name == "" {
+		return errors.New("не указано название")
+	}
+	price, err := domain.ParseMoney(r.FormValue("price"), domain.DefaultCurrency)
+	if err != nil {
+		return errors.New("цена указана неверно, пример: 4990 или 4990.50")
+	}
+	if err := domain.ValidatePrice(price); err != nil {
+		return err
+	}
+	// Пустой вес - не указан, для доставки возьмется вес по умолчанию
+	var weight int
+	if v := strings.TrimSpace(r.FormValue("weight")); v != "" {
+		if weight, err = strconv.Atoi(v); err != nil {
+			return errors.New("вес указывается целым числом граммов, пример: 350")
+		}
+		if err := domain.ValidateWeight(weight); err != nil {
+			return err
+		}
+	}
+
+	// Пустой остаток - не ведется, товар всегда в наличии
+	var stock *int
+	if v := strings.TrimSpace(r.FormValue("stock")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return errors.New("остаток указывается целым числом штук, пример: 12")
+		}
+		stock = &n
+	}
+	if err := domain.ValidateStock(stock); err != nil {
+		return err
+	}
+
+	p.SKU = strings.TrimSpace(r.FormValue("sku"))
+	p.Type = t
+	p.Name = name
+	p.Description = strings.TrimSpace(r.FormValue("description"))
+	p.Price = price
+	p.Weight = weight
+	p.Stock = stock
+	return nil
+}
+
+// checkSKU - артикул не должен повторяться
+func (h *Handler) checkSKU(p *domain.Product) error {
+	if p.SKU == "" {
+		return nil
+	}
+	existing, err := h.repo.GetProductBySKU(p.SKU)
+	if err != nil {
+		return fmt.Errorf("не удалось проверить артикул: %w", err)
+	}
+	if existing != nil && existing.ID != p.ID {
+		return fmt.Errorf("артикул %s уже занят товаром «%s»", p.SKU, existing.Name)
+	}
+	return nil
+}
+
+// uploadPhotos - отправляет фото из формы в Телеграм и возвращает их file_id по порядку
+func (h *Handler) uploadPhotos(r *http.Request) ([]string, error) {
+	files := formFiles(r)
+	if len(files) > domain.MaxProductImages {
+		return nil, fmt.Errorf("больше %d фото добавить нельзя", domain.MaxProductImages)
+	}
+
+	var fileIDs []string
+	for _, fh := range files {
+		ext := strings.ToLower(path.Ext(fh.Filename))
+		if ext != ".jpg" && ext != ".jpeg" && ext != ".png" && ext != ".webp" {
+			return nil, fmt.Errorf("%s: нужна картинка JPG, PNG или WEBP", fh.Filename)
+		}
+		if fh.Size > maxPhotoSize {
+			return nil, fmt.Errorf("%s: фото больше 10 МБ", fh.Filename)
+		}
+
+		f, err := fh.Open()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fh.Filename, err)
+		}
+		data, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fh.Filename, err)
+		}
+
+		fileID, err := h.uploader.UploadPhotoBytes(fh.Filename, data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fh.Filename, err)
+		}
+		fileIDs = append(fileIDs, fileID)
+	}
+	return fileIDs, nil
+}
+
+// formFiles - фото, выбранные в форме (форма без файлов - пустой список)
+func formFiles(r *http.Request) []*multipart.FileHeader {
+	if r.MultipartForm == nil {
+		return nil
+	}
+	return r.MultipartForm.File["images"]
+}
+
+// productFormError - показывает форму снова с ошибкой и введенными данными
+func (h *Handler) productFormError(w http.ResponseWriter, r *http.Request, s *session, form productPage, p *domain.Product, err error) {
+	form.Product = p
+	title := p.Name
+	if form.New {
+		title = "Новый товар"
+	}
+	data := page(r, s, title, form)
+	data.Error = err.Error()
+	h.render(w, http.StatusUnprocessableEntity, "product", data)
+}
@@ -0,0 +1,156 @@
+// session.go — вход в админку: логин и пароль из настроек, сессия в cookie, защита форм от CSRF
+package web
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+
+	"salle_parfume/internal/domain"
+)
+
+const (
+	sessionCookie = "sp_admin"     // Имя cookie с токеном сессии
+	sessionTTL    = 12 * time.Hour // Сколько живет сессия без повторного входа
+	loginDelay    = time.Second    // Пауза после неверного пароля, чтобы пароль было долго подбирать
+	maxFormSize   = 50 << 20       // Ограничение на размер формы вместе с фото
+)
+
+// session - вошедший админ
+type session struct {
+	login   string
+	csrf    string // Токен, который должен прийти с каждой формой
+	expires time.Time
+}
+
+// sessionStore - сессии в памяти. HTTP обрабатывает запросы параллельно, поэтому под мьютексом.
+// После перезапуска сервера админам нужно войти заново.
+type sessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]*session
+	ttl      time.Duration
+}
+
+func newSessionStore(ttl time.Duration) *sessionStore {
+	return &sessionStore{sessions: make(map[string]*session), ttl: ttl}
+}
+
+// newToken - случайный токен для cookie и форм
+func newToken() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// create - новая сессия, возвращает ее токен
+func (st *sessionStore) create(login string) string {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	// Заодно чистим просроченные, чтобы карта не росла бесконечно
+	now := time.Now()
+	for token, s := range st.sessions {
+		if now.After(s.expires) {
+			delete(st.sessions, token)
+		}
+	}
+
+	token := newToken()
+	st.sessions[token] = &session{login: login, csrf: newToken(), expires: now.Add(st.ttl)}
+	return token
+}
+
+// get - сессия по токену (nil, если нет или истекла)
+func (st *sessionStore) get(token string) *session {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	s, ok := st.sessions[token]
+	if !ok {
+		return nil
+	}
+	if time.Now().After(s.expires) {
+		delete(st.sessions, token)
+		return nil
+	}
+	return s
+}
+
+func (st *sessionStore) delete(token string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	delete(st.sessions, token)
+}
+
+// checkPassword - есть ли такой админ. Сравнение за постоянное время, как и для ключей API.
+func (h *Handler) checkPassword(login, password string) bool {
+	expected, ok := h.users[login]
+	if !ok {
+		// Сравниваем с самим собой, чтобы по времени ответа нельзя было узнать, есть ли логин
+		expected = password + "x"
+	}
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(password)) == 1 && ok
+}
+
+// requireLogin - пускает только вошедших админов, остальных отправляет на страницу входа.
+// Для POST проверяет CSRF-токен формы.
+func (h *Handler) requireLogin(next func(w http.ResponseWriter, r *http.Request, s *session)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var s *session
+		if cookie, err := r.Cookie(sessionCookie); err == nil {
+			s = h.sessions.get(cookie.Value)
+		}
+		if s == nil {
+			http.Redirect(w, r, "/admin/login", http.StatusSeeOther)
+			return
+		}
+		if r.Method == http.MethodPost {
+			r.Body = http.MaxBytesReader(w, r.Body, maxFormSize)
+			if subtle.ConstantTimeCompare([]byte(r.FormValue("csrf")), []byte(s.csrf)) != 1 {
+				http.Error(w, "Форма устарела или слишком большая, обновите страницу", http.StatusForbidden)
+				return
+			}
+		}
+		next(w, r, s)
+	}
+}
+
+// handleLoginPage - GET /admin/login
+func (h *Handler) handleLoginPage(w http.ResponseWriter, r *http.Request) {
+	h.render(w, http.StatusOK, "login", pageData{Title: "Вход"})
+}
+
+// handleLogin - POST /admin/login
+func (h *Handler) handleLogin(w http.ResponseWriter, r *http.Request) {
+	login := r.FormValue("login")
+	if !h.checkPassword(login, r.FormValue("password")) {
+		time.Sleep(loginDelay)
+		h.render(w, http.StatusUnauthorized, "login", pageData{Title: "Вход", Error: "Неверный логин или пароль", Data: login})
+		return
+	}
+
+	token := h.sessions.create(login)
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    token,
+		Path:     "/admin",
+		HttpOnly: true,
+		Secure:   r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https",
+		SameSite: http.SameSiteStrictMode,
+		MaxAge:   int(sessionTTL.Seconds()),
+	})
+	h.audit(&session{login: login}, domain.AuditLogin, "", 0, r.RemoteAddr)
+	http.Redirect(w, r, "/admin/orders", http.StatusSeeOther)
+}
+
+// handleLogout - POST /admin/logout
+func (h *Handler) handleLogout(w http.ResponseWriter, r *http.Request, s *session) {
+	if cookie, err := r.Cookie(sessionCookie); err == nil {
+		h.sessions.delete(cookie.Value)
+	}
+	http.SetCookie(w, &http.Cookie{Name: sessionCookie, Path: "/admin", MaxAge: -1})
+	http.Redirect(w, r, "/admin/login", http.StatusSeeOther)
+}
@@ -0,0 +1,154 @@
+// users.go — покупатели в админке: поиск и карточка с заказами
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"salle_parfume/internal/domain"
+)
+
+const (
+	usersLimit       = 50 // Сколько покупателей показывать в результатах поиска
+	userOrdersLimit  = 50 // Сколько последних заказов показывать в карточке покупателя
+	userLoyaltyLimit = 50 // Сколько последних операций с бонусами показывать в карточке покупателя
+)
+
+// usersPage - данные страницы поиска
+type usersPage struct {
+	Query   string
+	Users   []domain.User
+	Loyalty loyaltyTotals
+}
+
+// loyaltyTotals - итоги бонусной программы по счетам магазина
+type loyaltyTotals struct {
+	Cashback    int64 // Выдано кешбэком за вычетом отмененного
+	Redeemed    int64 // Потрачено на заказы за вычетом возвращенного
+	Expired     int64 // Сгорело
+	Adjusted    int64 // Начислено вручную (минус - списано)
+	Outstanding int64 // Сейчас на счетах покупателей
+}
+
+// userPage - данные карточки покупателя
+type userPage struct {
+	User    *domain.User
+	ChatID  int64
+	Orders  []domain.Order
+	Points  int64
+	Loyalty []domain.LoyaltyEntry
+	// Введенная корректировка, если ее не удалось провести
+	AdjustPoints string
+	AdjustReason string
+}
+
+// handleUsers - GET /admin/users?q=: поиск по Телеграм ID, username или имени.
+// Без запроса показывает последних зарегистрированных.
+func (h *Handler) handleUsers(w http.ResponseWriter, r *http.Request, s *session) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	users, err := h.repo.FindUsers(query, usersLimit)
+	if err != nil {
+		h.internalError(w, r, s, "find users", err)
+		return
+	}
+	totals, err := h.repo.GetLoyaltyTotals()
+	if err != nil {
+		h.internalError(w, r, s, "get loyalty totals", err)
+		return
+	}
+	// Счета магазина отдают бонусы со знаком минус, поэтому выданное переворачиваем
+	loyalty := loyaltyTotals{
+		Cashback: -totals[domain.LoyaltyAccountCashback],
+		Redeemed: totals[domain.LoyaltyAccountRedeemed],
+		Expired:  totals[domain.LoyaltyAccountExpired],
+		Adjusted: -totals[domain.LoyaltyAccountAdjustments],
+	}
+	loyalty.Outstanding = loyalty.Cashback + loyalty.Adjusted - loyalty.Redeemed - loyalty.Expired
+	h.render(w, http.StatusOK, "users", page(r, s, "Покупатели", usersPage{Query: query, Users: users, Loyalty: loyalty}))
+}
+
+// handleUser - GET /admin/users/{chatID}: покупатель, его заказы и бонусы.
+// Заказы показываем, даже если покупателя нет в users (оформил через API).
+func (h *Handler) handleUser(w http.ResponseWriter, r *http.Request, s *session) {
+	data, ok := h.getUserPage(w, r, s)
+	if !ok {
+		return
+	}
+	h.render(w, http.StatusOK, "user", page(r, s, "Покупатель "+strconv.FormatInt(data.ChatID, 10), data))
+}
+
+// handleUserPoints - POST /admin/users/{chatID}/points: ручное начисление или списание бонусов с причиной
+func (h *Handler) handleUserPoints(w http.ResponseWriter, r *http.Request, s *session) {
+	data, ok := h.getUserPage(w, r, s)
+	if !ok {
+		return
+	}
+	data.AdjustPoints = strings.TrimSpace(r.FormValue("points"))
+	data.AdjustReason = strings.TrimSpace(r.FormValue("reason"))
+
+	points, err := strconv.ParseInt(data.AdjustPoints, 10, 64)
+	if err != nil {
+		h.userFormError(w, r, s, data, errors.New("количество бонусов указано неверно, пример: 500 или -200"))
+		return
+	}
+	if err := domain.ValidateLoyaltyAdjust(points, data.AdjustReason); err != nil {
+		h.userFormError(w, r, s, data, err)
+		return
+	}
+	entry := &domain.LoyaltyEntry{ChatID: data.ChatID, Points: points, Reason: data.AdjustReason, Actor: s.login}
+	if err := h.repo.AdjustPoints(entry); err != nil {
+		if errors.Is(err, domain.ErrNotEnoughPoints) {
+			h.userFormError(w, r, s, data, fmt.Errorf("на счете %d бонусов, списать %d нельзя", data.Points, -points))
+			return
+		}
+		h.internalError(w, r, s, "adjust points", err)
+		return
+	}
+
+	h.audit(s, domain.AuditLoyaltyAdjust, domain.AuditObjectUser, data.ChatID, fmt.Sprintf("%+d: %s", points, data.AdjustReason))
+	redirect(w, r, fmt.Sprintf("/admin/users/%d", data.ChatID), fmt.Sprintf("Бонусы: %+d", points))
+}
+
+// getUserPage - карточка покупателя из пути запроса. Если такого покупателя нет, сам показывает 404.
+func (h *Handler) getUserPage(w http.ResponseWriter, r *http.Request, s *session) (userPage, bool) {
+	chatID, err := strconv.ParseInt(r.PathValue("chatID"), 10, 64)
+	if err != nil {
+		h.notFound(w, r, s, "Покупатель не найден")
+		return userPage{}, false
+	}
+	user, err := h.repo.GetUserByChatID(chatID)
+	if err != nil {
+		h.internalError(w, r, s, "get user", err)
+		return userPage{}, false
+	}
+	orders, err := h.repo.GetUserOrders(chatID, userOrdersLimit, 0)
+	if err != nil {
+		h.internalError(w, r, s, "get user orders", err)
+		return userPage{}, false
+	}
+	if user == nil && len(orders) == 0 {
+		h.notFound(w, r, s, "Покупатель не найден")
+		return userPage{}, false
+	}
+	points, err := h.repo.GetLoyaltyBalance(chatID)
+	if err != nil {
+		h.internalError(w, r, s, "get loyalty balance", err)
+		return userPage{}, false
+	}
+	loyalty, err := h.repo.GetLoyaltyHistory(chatID, userLoyaltyLimit, 0)
+	if err != nil {
+		h.internalError(w, r, s, "get loyalty history", err)
+		return userPage{}, false
+	}
+	return userPage{User: user, ChatID: chatID, Orders: orders, Points: points, Loyalty: loyalty}, true
+}
+
+// userFormError - показывает карточку покупателя снова с ошибкой и введенной корректировкой
+func (h *Handler) userFormError(w http.ResponseWriter, r *http.Request, s *session, form userPage, err error) {
+	data := page(r, s, "Покупатель "+strconv.FormatInt(form.ChatID, 10), form)
+	data.Error = err.Error()
+	h.render(w, http.StatusUnprocessableEntity, "user", data)
+}
@@ -0,0 +1,33 @@
+// abuse.go - Защита от флуда: заблокированные админом пользователи и записи о превышениях.
+// Записи о флуде нужны админу, чтобы решить, кого блокировать насовсем.
+package domain
+
+import "time"
+
+// FloodKind - что именно сделал пользователь.
+type FloodKind string
+
+// Константы видов флуда.
+const (
+	FloodRateLimit      FloodKind = "rate_limit"      // Слишком много обновлений за минуту
+	FloodRepeatCallback FloodKind = "repeat_callback" // Одну и ту же кнопку жмут раз за разом
+)
+
+// Ban - пользователь, которому бот не отвечает.
+type Ban struct {
+	UserID    int64     `json:"user_id"`
+	Reason    string    `json:"reason"` // Причина со слов админа (может быть пустой)
+	Actor     string    `json:"actor"`  // Кто заблокировал
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FloodEvent - одно превышение: пользователь получил паузу, пока бот его не слушает.
+type FloodEvent struct {
+	ID        int64         `json:"id"`
+	UserID    int64         `json:"user_id"`
+	Kind      FloodKind     `json:"kind"`
+	Details   string        `json:"details"`  // Например, сколько обновлений пришло или какая кнопка
+	Strike    int           `json:"strike"`   // Какое по счету превышение подряд (от него растет пауза)
+	Cooldown  time.Duration `json:"cooldown"` // Сколько длится пауза
+	CreatedAt time.Time     `json:"created_at"`
+}
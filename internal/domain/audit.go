@@ -0,0 +1,38 @@
+// audit.go - Журнал действий админов: кто, когда и что поменял в магазине.
+// Записи только добавляются, поэтому по журналу всегда можно восстановить историю правок.
+package domain
+
+import "time"
+
+// Действия, которые попадают в журнал.
+const (
+	AuditProductCreate  = "product.create"  // Добавлен товар
+	AuditProductUpdate  = "product.update"  // Изменены поля или фото товара
+	AuditProductDelete  = "product.delete"  // Товар удален
+	AuditOrderStatus    = "order.status"    // Сменен статус заказа
+	AuditDeliveryCreate = "delivery.create" // Добавлен способ доставки
+	AuditDeliveryUpdate = "delivery.update" // Изменен способ доставки (в том числе включен или выключен)
+	AuditLoyaltyAdjust  = "loyalty.adjust"  // Ручное начисление или списание бонусов покупателю
+	AuditUserBan        = "user.ban"        // Пользователь заблокирован
+	AuditUserUnban      = "user.unban"      // Пользователь разблокирован
+	AuditLogin          = "login"           // Вход в админку
+)
+
+// Объекты, над которыми выполняются действия.
+const (
+	AuditObjectProduct  = "product"
+	AuditObjectOrder    = "order"
+	AuditObjectDelivery = "delivery"
+	AuditObjectUser     = "user"
+)
+
+// AuditEntry - одна запись журнала.
+type AuditEntry struct {
+	ID        int64     `json:"id"`
+	Actor     string    `json:"actor"`     // Кто: логин в веб-админке или "api"
+	Action    string    `json:"action"`    // Что сделал (AuditProductCreate...)
+	Object    string    `json:"object"`    // Над чем (AuditObjectProduct...), пусто для входа
+	ObjectID  int64     `json:"object_id"` // ID товара или номер заказа
+	Details   string    `json:"details"`   // Что именно поменялось, в свободной форме
+	CreatedAt time.Time `json:"created_at"`
+}
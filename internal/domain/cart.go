@@ -0,0 +1,61 @@
+// cart.go - Описание корзины покупателя и расчета ее стоимости.
+package domain
+
+import "time"
+
+// CartLine - одна строка корзины: товар и его количество.
+type CartLine struct {
+	Product  Product `json:"product"`  // Актуальные данные товара из каталога
+	Quantity int     `json:"quantity"` // Сколько штук
+}
+
+// Quote - расчет стоимости корзины со всеми скидками.
+// Один и тот же расчет используется в корзине, при оформлении заказа и в счете на оплату,
+// поэтому суммы везде совпадают.
+type Quote struct {
+	Lines        []QuoteLine `json:"lines"`
+	Subtotal     Money       `json:"subtotal"`      // Сумма без скидок
+	AutoDiscount Money       `json:"auto_discount"` // Скидка по автоматическим акциям
+	CodeDiscount Money       `json:"code_discount"` // Скидка по промокоду
+	Total        Money       `json:"total"`         // К оплате
+	PromoCode    string      `json:"promo_code"`    // Примененный промокод (пусто, если нет)
+	Weight       int         `json:"weight"`        // Вес посылки в граммах, от него зависит цена доставки
+}
+
+// QuoteLine - расчет одной строки корзины.
+type QuoteLine struct {
+	ProductID int64  `json:"product_id"`
+	Name      string `json:"name"`
+	Quantity  int    `json:"quantity"`
+	UnitPrice Money  `json:"unit_price"` // Цена за штуку без скидок
+	Discount  Money  `json:"discount"`   // Скидка на всю строку
+	Total     Money  `json:"total"`      // Итог строки со скидкой
+	Available *int   `json:"available"`  // Остаток товара, если его не хватает на Quantity (иначе nil)
+}
+
+// OutOfStock - не хватает ли остатка на эту строку
+func (l *QuoteLine) OutOfStock() bool {
+	return l.Available != nil
+}
+
+// OutOfStock - есть ли в корзине товары, которых не хватает на складе
+func (q *Quote) OutOfStock() bool {
+	for _, line := range q.Lines {
+		if line.OutOfStock() {
+			return true
+		}
+	}
+	return false
+}
+
+// Discount - общая сумма скидок.
+func (q *Quote) Discount() Money {
+	return q.AutoDiscount.Add(q.CodeDiscount)
+}
+
+// AbandonedCart - корзина, которую давно не трогали и по которой можно напомнить покупателю.
+type AbandonedCart struct {
+	ChatID    int64     `json:"chat_id"`
+	Items     int       `json:"items"`      // Сколько товаров (штук) лежит в корзине
+	UpdatedAt time.Time `json:"updated_at"` // Последнее изменение корзины
+}
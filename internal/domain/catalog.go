@@ -0,0 +1,15 @@
+// catalog.go - результат массового импорта каталога из таблицы.
+package domain
+
+// ImportError - ошибка в конкретной строке файла
+type ImportError struct {
+	Row     int    `json:"row"` // Номер строки как в Excel (заголовок - строка 1)
+	Message string `json:"message"`
+}
+
+// ImportResult - итог импорта: сколько товаров создано, обновлено и какие строки пропущены
+type ImportResult struct {
+	Created int           `json:"created"`
+	Updated int           `json:"updated"`
+	Errors  []ImportError `json:"errors"`
+}
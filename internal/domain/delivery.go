@@ -0,0 +1,105 @@
+// delivery.go - Описание способов доставки и расчета ее стоимости.
+// Способы настраивает админ: пункты самовывоза и курьерские зоны со своей ценой.
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DeliveryKind - вид доставки.
+type DeliveryKind string
+
+// Константы видов доставки.
+const (
+	DeliveryPickup  DeliveryKind = "pickup"  // Самовывоз из пункта выдачи (адрес покупателя не нужен)
+	DeliveryCourier DeliveryKind = "courier" // Курьер по адресу покупателя в пределах зоны
+)
+
+// DeliveryMethod - способ доставки: пункт самовывоза или курьерская зона.
+// Цена = Fee + PerKg за каждый начатый килограмм. PerKg == 0 - фиксированная цена.
+type DeliveryMethod struct {
+	ID       int64        `json:"id"`
+	Kind     DeliveryKind `json:"kind"`      // Самовывоз или курьер
+	Name     string       `json:"name"`      // Название для покупателя ("Курьер по Москве в пределах МКАД")
+	Address  string       `json:"address"`   // Адрес пункта самовывоза или описание зоны
+	Fee      Money        `json:"fee"`       // Базовая цена доставки
+	PerKg    Money        `json:"per_kg"`    // Доплата за каждый начатый килограмм (0 - цена не зависит от веса)
+	FreeFrom Money        `json:"free_from"` // Бесплатно от этой суммы товаров (0 - всегда платно)
+	Active   bool         `json:"active"`    // Выключенный способ покупателю не предлагается
+}
+
+// Cost - цена доставки заказа на сумму goods (товары со скидками) весом weight граммов.
+func (m *DeliveryMethod) Cost(goods Money, weight int) Money {
+	if m.FreeFrom.IsPositive() && !goods.Less(m.FreeFrom) {
+		return NewMoney(0, m.Fee.Currency)
+	}
+	kg := (weight + 999) / 1000
+	return m.Fee.Add(m.PerKg.Mul(kg))
+}
+
+// Validate - проверка способа доставки перед сохранением.
+func (m *DeliveryMethod) Validate() error {
+	if m.Kind != DeliveryPickup && m.Kind != DeliveryCourier {
+		return fmt.Errorf("неизвестный вид доставки %q (нужно pickup или courier)", m.Kind)
+	}
+	if strings.TrimSpace(m.Name) == "" {
+		return errors.New("не указано название")
+	}
+	if m.Kind == DeliveryPickup && strings.TrimSpace(m.Address) == "" {
+		return errors.New("для самовывоза нужен адрес пункта")
+	}
+	if m.Fee.IsNegative() || m.PerKg.IsNegative() || m.FreeFrom.IsNegative() {
+		return errors.New("цены не могут быть отрицательными")
+	}
+	return nil
+}
+
+// NeedsAddress - нужно ли спрашивать у покупателя адрес.
+func (m *DeliveryMethod) NeedsAddress() bool {
+	return m.Kind == DeliveryCourier
+}
+
+// DeliveryOption - способ доставки с ценой для конкретной корзины.
+type DeliveryOption struct {
+	Method DeliveryMethod `json:"method"`
+	Cost   Money          `json:"cost"`
+}
+
+// Location - точка на карте (геопозиция из Телеграма).
+type Location struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// MapURL - ссылка на точку в картах, чтобы курьер открыл ее одним нажатием.
+func (l Location) MapURL() string {
+	return fmt.Sprintf("https://maps.google.com/?q=%.6f,%.6f", l.Latitude, l.Longitude)
+}
+
+// String - координаты в привычном виде "55.755800, 37.617300".
+func (l Location) String() string {
+	return fmt.Sprintf("%.6f, %.6f", l.Latitude, l.Longitude)
+}
+
+// DeliveryChoice - что выбрал покупатель при оформлении: способ и, для курьера, адрес или точку на карте.
+type DeliveryChoice struct {
+	MethodID int64     `json:"method_id"`
+	Address  string    `json:"address"`
+	Location *Location `json:"location,omitempty"`
+}
+
+// MaxDeliveryAddressLength - длиннее адрес не бывает, скорее всего это не адрес.
+const MaxDeliveryAddressLength = 500
+
+// OrderDelivery - доставка заказа: снимок способа и цены на момент покупки.
+// Нулевое значение - доставка не выбиралась (магазин без настроенных способов).
+type OrderDelivery struct {
+	MethodID int64        `json:"method_id"`
+	Kind     DeliveryKind `json:"kind"`
+	Name     string       `json:"name"`
+	Cost     Money        `json:"cost"`
+	Address  string       `json:"address"`            // Адрес покупателя (для курьера) или пункта самовывоза
+	Location *Location    `json:"location,omitempty"` // Точка на карте, если покупатель прислал геопозицию
+}
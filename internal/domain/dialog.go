@@ -0,0 +1,29 @@
+// dialog.go - Пошаговые диалоги бота (добавление товара и т.п.): где пользователь сейчас
+// и что уже ответил. Сессия хранится в базе, поэтому диалог переживает перезапуск бота.
+package domain
+
+import "time"
+
+// DialogSession - незаконченный диалог пользователя.
+type DialogSession struct {
+	ChatID       int64               `json:"chat_id"`
+	Dialog       string              `json:"dialog"`         // Имя диалога, например "new_product"
+	Step         int                 `json:"step"`           // Номер текущего шага
+	Values       map[string][]string `json:"values"`         // Ответы по ключам шагов (у шага с фото - несколько file_id)
+	Confirming   bool                `json:"confirming"`     // Все шаги пройдены, ждем подтверждения
+	MediaGroupID string              `json:"media_group_id"` // Последний альбом, на который уже ответили
+	UpdatedAt    time.Time           `json:"updated_at"`     // Время последнего ответа (по нему диалог истекает)
+}
+
+// Value - ответ на шаг (пустая строка, если шаг пропущен)
+func (s *DialogSession) Value(key string) string {
+	if values := s.Values[key]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Expired - диалог брошен дольше, чем timeout
+func (s *DialogSession) Expired(timeout time.Duration, now time.Time) bool {
+	return timeout > 0 && now.Sub(s.UpdatedAt) > timeout
+}
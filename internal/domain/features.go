@@ -0,0 +1,9 @@
+// features.go - Части магазина, которые владелец может выключить настройками (FEATURE_*).
+// Выключенная часть пропадает из меню и команд бота, ее фоновые задачи не запускаются.
+package domain
+
+// Features - включенные части магазина
+type Features struct {
+	Referrals bool // приглашение друзей и промокоды за них
+	Loyalty   bool // бонусные баллы
+}
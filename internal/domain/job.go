@@ -0,0 +1,36 @@
+// job.go - Фоновые задачи по расписанию: записи о запусках.
+// Каждый запуск сохраняется в базе, поэтому после перезапуска бота планировщик знает,
+// когда задача выполнялась в последний раз, а админ видит историю и ошибки.
+package domain
+
+import "time"
+
+// JobStatus - чем закончился запуск задачи.
+type JobStatus string
+
+// Константы статусов запуска.
+const (
+	JobRunning JobStatus = "running" // Выполняется (или процесс упал, не дождавшись конца)
+	JobOK      JobStatus = "ok"      // Выполнена
+	JobFailed  JobStatus = "failed"  // Завершилась с ошибкой
+)
+
+// JobRun - один запуск фоновой задачи.
+type JobRun struct {
+	ID         int64     `json:"id"`
+	Job        string    `json:"job"`         // Имя задачи (cart_reminders, ...)
+	Owner      string    `json:"owner"`       // Какой экземпляр бота выполнял: хост и PID
+	Status     JobStatus `json:"status"`      // Результат
+	Details    string    `json:"details"`     // Итог одной строкой ("отправлено 3 из 4")
+	Error      string    `json:"error"`       // Текст ошибки, если задача упала
+	StartedAt  time.Time `json:"started_at"`  // Когда начался
+	FinishedAt time.Time `json:"finished_at"` // Когда закончился (нулевое время - еще идет)
+}
+
+// Duration - сколько длился запуск.
+func (r *JobRun) Duration() time.Duration {
+	if r.FinishedAt.IsZero() {
+		return 0
+	}
+	return r.FinishedAt.Sub(r.StartedAt)
+}
@@ -0,0 +1,112 @@
+// loyalty.go - Бонусная программа (кешбэк баллами).
+// Бонусы начисляются за оплаченные заказы, списываются при оформлении (не больше доли суммы)
+// и сгорают, если ими долго не пользоваться. Один бонус - один рубль скидки.
+//
+// Баллы ведутся как в бухгалтерии, по двойной записи: каждая операция - это проводка
+// между счетом покупателя и одним из счетов магазина, поэтому сумма всех проводок всегда ноль,
+// и по счетам магазина видно, сколько бонусов выдано, потрачено и сгорело.
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// LoyaltyKind - вид операции с бонусами.
+type LoyaltyKind string
+
+// Константы видов операций.
+const (
+	LoyaltyEarn   LoyaltyKind = "earn"   // Начислены за оплаченный заказ
+	LoyaltySpend  LoyaltyKind = "spend"  // Списаны в счет заказа
+	LoyaltyRefund LoyaltyKind = "refund" // Возвращены списанные: заказ отменен
+	LoyaltyRevoke LoyaltyKind = "revoke" // Отменены начисленные: заказ отменен
+	LoyaltyExpire LoyaltyKind = "expire" // Сгорели
+	LoyaltyAdjust LoyaltyKind = "adjust" // Ручная корректировка админом
+)
+
+// Счета магазина, с которых приходят и на которые уходят бонусы покупателей.
+const (
+	LoyaltyAccountCashback    = "shop:cashback"    // Кешбэк за заказы (начисления и их отмена)
+	LoyaltyAccountRedeemed    = "shop:redeemed"    // Оплачено бонусами (списания и возвраты)
+	LoyaltyAccountExpired     = "shop:expired"     // Сгоревшие бонусы
+	LoyaltyAccountAdjustments = "shop:adjustments" // Ручные корректировки
+)
+
+// LoyaltyCustomerAccount - счет бонусов покупателя.
+func LoyaltyCustomerAccount(chatID int64) string {
+	return fmt.Sprintf("customer:%d", chatID)
+}
+
+// ShopAccount - счет магазина, с которым проводится операция этого вида.
+func (k LoyaltyKind) ShopAccount() string {
+	switch k {
+	case LoyaltyEarn, LoyaltyRevoke:
+		return LoyaltyAccountCashback
+	case LoyaltySpend, LoyaltyRefund:
+		return LoyaltyAccountRedeemed
+	case LoyaltyExpire:
+		return LoyaltyAccountExpired
+	}
+	return LoyaltyAccountAdjustments
+}
+
+// LoyaltyEntry - одна операция с бонусами покупателя.
+type LoyaltyEntry struct {
+	ID        int64       `json:"id"`
+	ChatID    int64       `json:"chat_id"`
+	Kind      LoyaltyKind `json:"kind"`
+	Points    int64       `json:"points"`   // Изменение баланса покупателя: плюс - начисление, минус - списание
+	OrderID   int64       `json:"order_id"` // Заказ, к которому относится операция (0 - без заказа)
+	Reason    string      `json:"reason"`   // Причина ручной корректировки
+	Actor     string      `json:"actor"`    // Кто сделал корректировку (логин админа)
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// LoyaltyBalance - баланс покупателя и ближайшее сгорание.
+type LoyaltyBalance struct {
+	Points    int64     `json:"points"`     // Сколько бонусов можно потратить
+	Expiring  int64     `json:"expiring"`   // Сколько сгорит первым (0 - ничего)
+	ExpiresAt time.Time `json:"expires_at"` // Когда они сгорят
+}
+
+// PointsValue - сколько денег (в валюте магазина) стоят бонусы.
+func PointsValue(points int64) Money {
+	return NewMoney(points*100, DefaultCurrency)
+}
+
+// MoneyToPoints - сколько целых бонусов в сумме (копейки отбрасываются).
+// Бонусы бывают только в валюте магазина, для других валют - ноль.
+func MoneyToPoints(m Money) int64 {
+	if m.Currency != DefaultCurrency || m.Amount <= 0 {
+		return 0
+	}
+	return m.Amount / 100
+}
+
+// Ограничения ручной корректировки.
+const (
+	MaxLoyaltyAdjust       = 1_000_000 // Больше за раз не начисляем и не списываем - скорее всего ошибка ввода
+	MaxLoyaltyReasonLength = 200
+)
+
+// ErrNotEnoughPoints - бонусов на счете меньше, чем нужно списать.
+var ErrNotEnoughPoints = errors.New("недостаточно бонусов")
+
+// ValidateLoyaltyAdjust - проверяет ручную корректировку перед сохранением.
+func ValidateLoyaltyAdjust(points int64, reason string) error {
+	if points == 0 {
+		return errors.New("укажите, сколько бонусов начислить или списать")
+	}
+	if points > MaxLoyaltyAdjust || points < -MaxLoyaltyAdjust {
+		return fmt.Errorf("за раз можно начислить или списать не больше %d бонусов", MaxLoyaltyAdjust)
+	}
+	if reason == "" {
+		return errors.New("укажите причину корректировки")
+	}
+	if len([]rune(reason)) > MaxLoyaltyReasonLength {
+		return fmt.Errorf("причина длиннее %d символов", MaxLoyaltyReasonLength)
+	}
+	return nil
+}
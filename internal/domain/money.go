@@ -0,0 +1,196 @@
+// money.go - Денежная сумма в целых копейках.
+// Цены нельзя хранить во float64: 0.1 + 0.2 != 0.3, и при сложении корзины
+// и скидках набегают ошибки округления. Поэтому сумма - это целое число
+// минимальных единиц валюты (копеек) плюс код валюты.
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency - валюта магазина (код ISO 4217). Задается при запуске из настройки CURRENCY.
+var DefaultCurrency = "RUB"
+
+// Money - сумма денег в минимальных единицах валюты.
+// Нулевое значение Money{} - это ноль без валюты, его можно складывать с любой суммой.
+type Money struct {
+	Amount   int64  `json:"amount"`   // Сумма в копейках (центах и т.д.)
+	Currency string `json:"currency"` // Код валюты, например "RUB"
+}
+
+// currencySymbols - как показывать валюту покупателю
+var currencySymbols = map[string]string{
+	"RUB": "руб.",
+	"USD": "$",
+	"EUR": "€",
+}
+
+// NewMoney - сумма из копеек в указанной валюте.
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// ErrInvalidMoney - строку не удалось разобрать как сумму.
+var ErrInvalidMoney = errors.New("некорректная сумма")
+
+// ParseMoney - разбирает сумму из строки вида "1500", "1 499,90" или "1499.9".
+// Дробная часть - не больше двух знаков. Разбор идет без float, поэтому точный.
+func ParseMoney(s, currency string) (Money, error) {
+	s = strings.TrimSpace(s)
+	s = strings.ReplaceAll(s, " ", "")
+	s = strings.ReplaceAll(s, ",", ".")
+
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	whole, frac, hasFrac := strings.Cut(s, ".")
+	if whole == "" || len(frac) > 2 || (hasFrac && frac == "") {
+		return Money{}, ErrInvalidMoney
+	}
+	for len(frac) < 2 {
+		frac += "0"
+	}
+
+	rub, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || rub < 0 || rub > math.MaxInt64/100-1 {
+		return Money{}, ErrInvalidMoney
+	}
+	kop, err := strconv.ParseInt(frac, 10, 64)
+	if err != nil || kop < 0 {
+		return Money{}, ErrInvalidMoney
+	}
+
+	amount := rub*100 + kop
+	if negative {
+		amount = -amount
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// currencyOf - общая валюта двух сумм. Ноль без валюты подходит к любой.
+func (m Money) currencyOf(other Money) string {
+	switch {
+	case m.Currency == "":
+		return other.Currency
+	case other.Currency == "" || other.Currency == m.Currency:
+		return m.Currency
+	default:
+		// Смешивать валюты без конвертации - ошибка программы, а не пользователя
+		panic(fmt.Sprintf("money: разные валюты %s и %s", m.Currency, other.Currency))
+	}
+}
+
+// Add - сумма двух значений.
+func (m Money) Add(other Money) Money {
+	return Money{Amount: m.Amount + other.Amount, Currency: m.currencyOf(other)}
+}
+
+// Sub - разность двух значений.
+func (m Money) Sub(other Money) Money {
+	return Money{Amount: m.Amount - other.Amount, Currency: m.currencyOf(other)}
+}
+
+// Mul - умножение на количество.
+func (m Money) Mul(quantity int) Money {
+	return Money{Amount: m.Amount * int64(quantity), Currency: m.Currency}
+}
+
+// Percent - процент от суммы с округлением до копейки.
+func (m Money) Percent(percent float64) Money {
+	return Money{Amount: int64(math.Round(float64(m.Amount) * percent / 100)), Currency: m.Currency}
+}
+
+// Allocate - делит сумму на части пропорционально весам так, что части в сумме дают
+// ровно исходное значение (остаток от округления отдается последней части с ненулевым весом).
+func (m Money) Allocate(weights []Money) []Money {
+	parts := make([]Money, len(weights))
+	var totalWeight int64
+	last := -1
+	for i, w := range weights {
+		parts[i] = Money{Currency: m.Currency}
+		if w.Amount > 0 {
+			totalWeight += w.Amount
+			last = i
+		}
+	}
+	if totalWeight == 0 {
+		return parts
+	}
+
+	rest := m.Amount
+	for i, w := range weights {
+		if w.Amount <= 0 || i == last {
+			continue
+		}
+		share := int64(math.Round(float64(m.Amount) * float64(w.Amount) / float64(totalWeight)))
+		parts[i].Amount = share
+		rest -= share
+	}
+	parts[last].Amount = rest
+	return parts
+}
+
+// Min - меньшая из двух сумм.
+func (m Money) Min(other Money) Money {
+	if other.Amount < m.Amount {
+		return Money{Amount: other.Amount, Currency: m.currencyOf(other)}
+	}
+	return Money{Amount: m.Amount, Currency: m.currencyOf(other)}
+}
+
+// Max - большая из двух сумм.
+func (m Money) Max(other Money) Money {
+	if other.Amount > m.Amount {
+		return Money{Amount: other.Amount, Currency: m.currencyOf(other)}
+	}
+	return Money{Amount: m.Amount, Currency: m.currencyOf(other)}
+}
+
+// IsZero - сумма равна нулю.
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// IsPositive - сумма больше нуля.
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+// IsNegative - сумма меньше нуля.
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// Less - сумма меньше другой.
+func (m Money) Less(other Money) bool {
+	m.currencyOf(other)
+	return m.Amount < other.Amount
+}
+
+// Decimal - сумма без валюты с двумя знаками после точки, например "1499.90".
+func (m Money) Decimal() string {
+	amount := m.Amount
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, amount/100, amount%100)
+}
+
+// String - сумма для покупателя, например "1499.90 руб.".
+func (m Money) String() string {
+	currency := m.Currency
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	symbol, ok := currencySymbols[currency]
+	if !ok {
+		symbol = currency
+	}
+	return m.Decimal() + " " + symbol
+}
@@ -0,0 +1,92 @@
+// order.go - Описание сущности заказа.
+// Заказ хранит снимок товаров на момент покупки (название и цену),
+// чтобы изменение каталога не меняло уже оформленные заказы.
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// OrderStatus - статус заказа.
+type OrderStatus string
+
+// Константы статусов заказа.
+const (
+	OrderStatusNew       OrderStatus = "new"       // Новый, ждет оплаты или подтверждения
+	OrderStatusPaid      OrderStatus = "paid"      // Оплачен онлайн
+	OrderStatusConfirmed OrderStatus = "confirmed" // Подтвержден магазином
+	OrderStatusShipped   OrderStatus = "shipped"   // Отправлен
+	OrderStatusDelivered OrderStatus = "delivered" // Получен покупателем
+	OrderStatusCancelled OrderStatus = "cancelled" // Отменен
+)
+
+// Order - заказ покупателя.
+type Order struct {
+	ID        int64       `json:"id"`         // Номер заказа
+	ChatID    int64       `json:"chat_id"`    // Телеграм ID покупателя
+	Status    OrderStatus `json:"status"`     // Текущий статус
+	Subtotal  Money       `json:"subtotal"`   // Сумма без скидок
+	Discount  Money       `json:"discount"`   // Сумма всех скидок
+	Total     Money       `json:"total"`      // Итоговая сумма вместе с доставкой
+	PromoCode string      `json:"promo_code"` // Примененный промокод (пусто, если нет)
+	Items     []OrderItem `json:"items"`      // Позиции заказа
+	CreatedAt time.Time   `json:"created_at"` // Когда оформлен
+
+	TrackingNumber string        `json:"tracking_number"` // Трек-номер отправления (пусто, пока не отправлен или без номера)
+	Delivery       OrderDelivery `json:"delivery"`        // Способ и цена доставки
+
+	PointsSpent  int64 `json:"points_spent"`  // Списано бонусов (уже вычтены из Total)
+	PointsEarned int64 `json:"points_earned"` // Бонусов за заказ: начисляются, когда заказ оплачен или получен
+}
+
+// MaxTrackingNumberLength - длиннее трек-номеров у служб доставки не бывает, скорее всего это ошибка ввода.
+const MaxTrackingNumberLength = 64
+
+// OrderItem - одна позиция в заказе.
+type OrderItem struct {
+	ProductID int64  `json:"product_id"` // Какой товар
+	Name      string `json:"name"`       // Название на момент покупки
+	Price     Money  `json:"price"`      // Цена за штуку на момент покупки
+	Quantity  int    `json:"quantity"`   // Количество
+	Discount  Money  `json:"discount"`   // Скидка на всю позицию
+}
+
+// Total - итог позиции со скидкой.
+func (i OrderItem) Total() Money {
+	return i.Price.Mul(i.Quantity).Sub(i.Discount)
+}
+
+// Valid - известен ли такой статус.
+func (s OrderStatus) Valid() bool {
+	switch s {
+	case OrderStatusNew, OrderStatusPaid, OrderStatusConfirmed, OrderStatusShipped, OrderStatusDelivered, OrderStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// CanBecome - можно ли перевести заказ в статус next.
+// Отправленный заказ уже не отменить, а полученный и отмененный больше не меняются.
+func (s OrderStatus) CanBecome(next OrderStatus) bool {
+	switch next {
+	case OrderStatusConfirmed:
+		return s == OrderStatusNew || s == OrderStatusPaid
+	case OrderStatusShipped, OrderStatusCancelled:
+		return s == OrderStatusNew || s == OrderStatusPaid || s == OrderStatusConfirmed
+	case OrderStatusDelivered:
+		return s == OrderStatusShipped
+	case OrderStatusPaid:
+		return s == OrderStatusNew
+	}
+	return false
+}
+
+// ErrStatusChange - из текущего статуса заказ нельзя перевести в запрошенный (см. CanBecome)
+var ErrStatusChange = errors.New("заказ нельзя перевести в этот статус")
+
+// MarksPaid - считается ли заказ оплаченным после перехода в этот статус:
+// оплачен онлайн или получен покупателем (при оплате при получении).
+func (s OrderStatus) MarksPaid() bool {
+	return s == OrderStatusPaid || s == OrderStatusDelivered
+}
@@ -3,6 +3,13 @@
 // Он ничего не знает о базе данных или телеграме. Это просто структура данных.
 package domain
 
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
 // ProductType - специальный тип для категории духов.
 // Используем его вместо string, чтобы избежать опечаток (например, "femal" вместо "female").
 type ProductType string
@@ -18,9 +25,140 @@ const (
 // JSON теги нужны, если мы захотим превратить эту структуру в текст (например, для логов или API).
 type Product struct {
 	ID          int64       `json:"id"`          // Уникальный номер в базе данных
+	SKU         string      `json:"sku"`         // Артикул, по нему сверяется импорт каталога
 	Type        ProductType `json:"type"`        // Тип (муж/жен/уни)
 	Name        string      `json:"name"`        // Название (например, "Chanel No. 5")
 	Description string      `json:"description"` // Описание аромата
-	Price       float64     `json:"price"`       // Цена (дробное число)
+	Price       Money       `json:"price"`       // Цена в копейках с валютой
 	ImageID     string      `json:"image_id"`    // ID файла картинки в Телеграме (мы не храним само фото, только ссылку)
+	Images      []string    `json:"images"`      // Все фото товара по порядку (первое - обложка, совпадает с ImageID)
+	Weight      int         `json:"weight"`      // Вес с упаковкой в граммах для расчета доставки (0 - не указан)
+	Stock       *int        `json:"stock"`       // Остаток на складе в штуках (nil - не ведется, товар всегда в наличии)
+}
+
+// MaxProductImages - сколько фото можно прикрепить к товару.
+// Больше 10 Телеграм не покажет одним альбомом.
+const MaxProductImages = 10
+
+// Gallery - фото товара для показа. У старых товаров галереи нет, тогда это одна обложка.
+func (p *Product) Gallery() []string {
+	if len(p.Images) > 0 {
+		return p.Images
+	}
+	if p.ImageID != "" {
+		return []string{p.ImageID}
+	}
+	return nil
+}
+
+// DefaultProductWeight - вес флакона с коробкой, если у товара вес не указан (граммы).
+// Лучше немного переплатить за доставку, чем посчитать посылку невесомой.
+const DefaultProductWeight = 400
+
+// MaxProductWeight - тяжелее духи не бывают, скорее всего это опечатка (граммы).
+const MaxProductWeight = 20_000
+
+// ShippingWeight - вес товара для расчета доставки
+func (p *Product) ShippingWeight() int {
+	if p.Weight > 0 {
+		return p.Weight
+	}
+	return DefaultProductWeight
+}
+
+// ValidateWeight - вес в граммах: 0 (не указан) или разумное положительное число.
+func ValidateWeight(grams int) error {
+	if grams < 0 || grams > MaxProductWeight {
+		return fmt.Errorf("вес должен быть от 0 до %d г", MaxProductWeight)
+	}
+	return nil
+}
+
+// MaxProductStock - больше этого остатка не бывает, скорее всего это опечатка (штуки).
+const MaxProductStock = 100_000
+
+// InStock - можно ли купить товар сейчас
+func (p *Product) InStock() bool {
+	return p.Stock == nil || *p.Stock > 0
+}
+
+// HasStock - хватает ли остатка на quantity штук
+func (p *Product) HasStock(quantity int) bool {
+	return p.Stock == nil || *p.Stock >= quantity
+}
+
+// ErrOutOfStock - товара на складе меньше, чем заказано
+var ErrOutOfStock = errors.New("некоторых товаров из корзины нет в нужном количестве")
+
+// ValidateStock - остаток в штуках: nil (не ведется) или от 0 до MaxProductStock.
+func ValidateStock(stock *int) error {
+	if stock != nil && (*stock < 0 || *stock > MaxProductStock) {
+		return fmt.Errorf("остаток должен быть от 0 до %d шт", MaxProductStock)
+	}
+	return nil
+}
+
+// FormatStock - остаток для людей: "12 шт" или "не ведется"
+func FormatStock(stock *int) string {
+	if stock == nil {
+		return "не ведется"
+	}
+	return fmt.Sprintf("%d шт", *stock)
+}
+
+// MaxProductPrice - верхняя граница цены товара в минимальных единицах валюты (10 млн).
+// Все, что дороже, скорее всего опечатка (лишние нули при вводе цены админом).
+// Граница без валюты: валюта магазина задается при запуске (CURRENCY).
+const MaxProductPrice = 10_000_000 * 100
+
+// ValidatePrice - цена товара должна быть в валюте магазина, положительной и не абсурдно большой.
+// Товар в другой валюте нельзя сложить с остальными в корзине.
+func ValidatePrice(price Money) error {
+	if price.Currency != DefaultCurrency {
+		return fmt.Errorf("цена должна быть в валюте магазина %s, а не %q", DefaultCurrency, price.Currency)
+	}
+	if !price.IsPositive() {
+		return errors.New("цена должна быть больше нуля")
+	}
+	if price.Amount > MaxProductPrice {
+		return fmt.Errorf("цена не может быть больше %s", NewMoney(MaxProductPrice, DefaultCurrency))
+	}
+	return nil
+}
+
+// ParseProductType - разбирает категорию из текста: female/male/unisex или по-русски.
+func ParseProductType(s string) (ProductType, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "female", "женские", "женский":
+		return TypeFemale, nil
+	case "male", "мужские", "мужской":
+		return TypeMale, nil
+	case "unisex", "унисекс":
+		return TypeUnisex, nil
+	}
+	return "", fmt.Errorf("неизвестная категория %q (нужно female, male или unisex)", s)
+}
+
+// ProductChanges - какие поля товара поменялись, например "цена: 4 990,00 ₽ → 5 490,00 ₽".
+// Нужно для журнала действий админов. Пустой список - товар не менялся.
+func ProductChanges(old, updated *Product) []string {
+	var changes []string
+	field := func(name, before, after string) {
+		if before != after {
+			changes = append(changes, fmt.Sprintf("%s: %s → %s", name, before, after))
+		}
+	}
+	field("артикул", old.SKU, updated.SKU)
+	field("категория", string(old.Type), string(updated.Type))
+	field("название", old.Name, updated.Name)
+	if old.Description != updated.Description {
+		changes = append(changes, "описание")
+	}
+	field("цена", old.Price.String(), updated.Price.String())
+	field("вес", fmt.Sprintf("%d г", old.Weight), fmt.Sprintf("%d г", updated.Weight))
+	field("остаток", FormatStock(old.Stock), FormatStock(updated.Stock))
+	if !slices.Equal(old.Gallery(), updated.Gallery()) {
+		changes = append(changes, fmt.Sprintf("фото: %d → %d", len(old.Gallery()), len(updated.Gallery())))
+	}
+	return changes
 }
@@ -0,0 +1,90 @@
+// promo.go - Описание акций и промокодов.
+// Акция без кода применяется автоматически (например, -10% на все женские ароматы),
+// акция с кодом - только когда покупатель ввел этот код.
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// PromoKind - вид скидки.
+type PromoKind string
+
+// Константы видов скидки.
+const (
+	PromoPercent PromoKind = "percent" // Процент от суммы
+	PromoFixed   PromoKind = "fixed"   // Фиксированная сумма
+)
+
+// Promotion - акция или промокод.
+type Promotion struct {
+	ID           int64         `json:"id"`
+	Code         string        `json:"code"`           // Промокод (пусто - автоматическая акция)
+	Name         string        `json:"name"`           // Название для покупателя
+	Kind         PromoKind     `json:"kind"`           // Процент или фиксированная сумма
+	Percent      float64       `json:"percent"`        // Размер скидки в процентах (для PromoPercent)
+	Amount       Money         `json:"amount"`         // Размер скидки суммой (для PromoFixed)
+	MinOrder     Money         `json:"min_order"`      // Минимальная сумма заказа (0 - без ограничений)
+	UsageLimit   int           `json:"usage_limit"`    // Сколько раз можно использовать всего (0 - без ограничений)
+	PerUserLimit int           `json:"per_user_limit"` // Сколько раз может использовать один покупатель (0 - без ограничений)
+	StartsAt     time.Time     `json:"starts_at"`      // Начало действия (нулевое время - сразу)
+	EndsAt       time.Time     `json:"ends_at"`        // Окончание действия (нулевое время - бессрочно)
+	ProductIDs   []int64       `json:"product_ids"`    // Только для этих товаров
+	ProductTypes []ProductType `json:"product_types"`  // Только для этих категорий
+	Active       bool          `json:"active"`         // Выключенная акция не применяется
+}
+
+// IsAutomatic - акция без кода применяется ко всем подходящим товарам сама.
+func (p *Promotion) IsAutomatic() bool {
+	return p.Code == ""
+}
+
+// IsActiveAt - действует ли акция в указанный момент.
+func (p *Promotion) IsActiveAt(now time.Time) bool {
+	if !p.Active {
+		return false
+	}
+	if !p.StartsAt.IsZero() && now.Before(p.StartsAt) {
+		return false
+	}
+	if !p.EndsAt.IsZero() && !now.Before(p.EndsAt) {
+		return false
+	}
+	return true
+}
+
+// Matches - распространяется ли акция на товар.
+// Если ограничений по товарам и категориям нет, акция действует на весь каталог.
+func (p *Promotion) Matches(product Product) bool {
+	if len(p.ProductIDs) == 0 && len(p.ProductTypes) == 0 {
+		return true
+	}
+	return slices.Contains(p.ProductIDs, product.ID) || slices.Contains(p.ProductTypes, product.Type)
+}
+
+// Validate - проверяет, что акция заполнена корректно, перед сохранением.
+func (p *Promotion) Validate() error {
+	switch p.Kind {
+	case PromoPercent:
+		if p.Percent <= 0 || p.Percent > 100 {
+			return errors.New("процент скидки должен быть от 0 до 100")
+		}
+	case PromoFixed:
+		if !p.Amount.IsPositive() {
+			return errors.New("сумма скидки должна быть больше нуля")
+		}
+	default:
+		return fmt.Errorf("неизвестный вид скидки %q (нужен percent или fixed)", p.Kind)
+	}
+
+	if p.MinOrder.IsNegative() || p.UsageLimit < 0 || p.PerUserLimit < 0 {
+		return errors.New("ограничения не могут быть отрицательными")
+	}
+	if !p.StartsAt.IsZero() && !p.EndsAt.IsZero() && !p.EndsAt.After(p.StartsAt) {
+		return errors.New("дата окончания должна быть позже даты начала")
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+// referral.go - Реферальная программа: покупатель приглашает друзей своей ссылкой
+// t.me/<бот>?start=ref_<его Телеграм ID> и получает промокод, когда первый заказ друга оплачен.
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// referralPrefix - начало параметра /start в пригласительной ссылке
+const referralPrefix = "ref_"
+
+// ReferralPayload - параметр /start для ссылки покупателя.
+func ReferralPayload(chatID int64) string {
+	return fmt.Sprintf("%s%d", referralPrefix, chatID)
+}
+
+// ParseReferralPayload - Телеграм ID пригласившего из параметра /start.
+// false - это не пригласительная ссылка.
+func ParseReferralPayload(payload string) (int64, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(payload), referralPrefix)
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(rest, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// Referral - кто кого пригласил. Запись создается один раз, когда новый покупатель
+// впервые открывает бота по ссылке.
+type Referral struct {
+	ID         int64     `json:"id"`
+	ReferrerID int64     `json:"referrer_id"` // Телеграм ID пригласившего
+	InviteeID  int64     `json:"invitee_id"`  // Телеграм ID приглашенного
+	CreatedAt  time.Time `json:"created_at"`
+
+	OrderID    int64     `json:"order_id"`    // Первый оплаченный заказ друга (0 - еще не оплачивал)
+	RewardCode string    `json:"reward_code"` // Промокод, выданный пригласившему (пусто - еще не выдан)
+	RewardedAt time.Time `json:"rewarded_at"` // Когда выдан промокод
+}
+
+// Rewarded - получил ли пригласивший награду за этого друга.
+func (r *Referral) Rewarded() bool {
+	return r.RewardCode != ""
+}
+
+// ReferralReward - промокод, полученный за приглашенного друга.
+type ReferralReward struct {
+	Code    string    `json:"code"`
+	Percent float64   `json:"percent"` // Размер скидки
+	EndsAt  time.Time `json:"ends_at"` // До какого момента действует (нулевое время - бессрочно)
+	Used    bool      `json:"used"`    // Уже использован в заказе
+}
+
+// ReferralStats - итоги приглашений покупателя для экрана "Пригласить друга".
+type ReferralStats struct {
+	Invited int              `json:"invited"` // Сколько друзей пришло по ссылке
+	Rewards []ReferralReward `json:"rewards"` // Полученные промокоды, новые первыми
+}
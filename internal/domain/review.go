@@ -0,0 +1,41 @@
+// review.go - Описание сущности отзыва о товаре.
+// Отзыв может оставить только покупатель, который получил заказ с этим товаром.
+// Перед публикацией отзыв проходит модерацию админом.
+package domain
+
+import "time"
+
+// ReviewStatus - статус модерации отзыва.
+type ReviewStatus string
+
+// Константы статусов модерации.
+const (
+	ReviewStatusPending  ReviewStatus = "pending"  // Ждет модерации
+	ReviewStatusApproved ReviewStatus = "approved" // Опубликован
+	ReviewStatusRejected ReviewStatus = "rejected" // Отклонен
+)
+
+// Минимальная и максимальная оценка (звезды).
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+// Review - отзыв покупателя о товаре.
+type Review struct {
+	ID         int64        `json:"id"`
+	ProductID  int64        `json:"product_id"`  // О каком товаре отзыв
+	ChatID     int64        `json:"chat_id"`     // Кто оставил
+	AuthorName string       `json:"author_name"` // Имя автора для показа в карточке
+	Rating     int          `json:"rating"`      // Оценка от 1 до 5
+	Text       string       `json:"text"`        // Текст отзыва (может быть пустым)
+	PhotoID    string       `json:"photo_id"`    // ID фото в Телеграме (может быть пустым)
+	Status     ReviewStatus `json:"status"`      // Статус модерации
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+// ProductRating - средняя оценка товара по опубликованным отзывам.
+type ProductRating struct {
+	Average float64 `json:"average"` // Средняя оценка
+	Count   int     `json:"count"`   // Количество опубликованных отзывов
+}
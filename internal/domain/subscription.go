@@ -0,0 +1,43 @@
+// subscription.go - Подписки покупателей на товар: сообщить о поступлении или о снижении цены.
+// Уведомления отправляет фоновая задача: она сравнивает подписку с текущим остатком и ценой товара.
+package domain
+
+import "time"
+
+// SubscriptionKind - на что подписан покупатель.
+type SubscriptionKind string
+
+// Константы видов подписки.
+const (
+	SubscriptionRestock   SubscriptionKind = "restock"    // Товар снова в наличии (подписка одноразовая)
+	SubscriptionPriceDrop SubscriptionKind = "price_drop" // Цена снизилась (подписка остается до отписки)
+)
+
+// OneShot - удаляется ли подписка после первого уведомления.
+// О снижении цены сообщаем каждый раз, когда цена падает ниже прошлой.
+func (k SubscriptionKind) OneShot() bool {
+	return k == SubscriptionRestock
+}
+
+// Subscription - подписка покупателя на товар.
+type Subscription struct {
+	ID        int64            `json:"id"`
+	ChatID    int64            `json:"chat_id"`
+	ProductID int64            `json:"product_id"`
+	Kind      SubscriptionKind `json:"kind"`
+	Price     Money            `json:"price"` // Цена при подписке или при последнем уведомлении: сообщаем, когда станет ниже
+	CreatedAt time.Time        `json:"created_at"`
+
+	ProductName string `json:"product_name"` // Название товара из каталога (заполняется при чтении)
+}
+
+// Due - пора ли уведомить подписчика при таком состоянии товара.
+func (s *Subscription) Due(p *Product) bool {
+	switch s.Kind {
+	case SubscriptionRestock:
+		return p.InStock()
+	case SubscriptionPriceDrop:
+		return p.Price.Currency == s.Price.Currency && p.Price.Less(s.Price)
+	}
+	return false
+}
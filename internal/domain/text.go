@@ -0,0 +1,87 @@
+// text.go - Редактируемые тексты магазина: приветствие, "О нас", доставка, контакты, FAQ.
+// Админ меняет их прямо из бота, каждая правка сохраняется новой версией,
+// поэтому любую прошлую версию можно вернуть.
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Ключи текстов магазина.
+const (
+	TextWelcome  = "welcome"  // Приветствие на /start
+	TextAbout    = "about"    // О магазине
+	TextDelivery = "delivery" // Условия доставки
+	TextContacts = "contacts" // Контакты
+	TextFAQ      = "faq"      // Частые вопросы
+
+	TextCartReminder = "cart_reminder" // Напоминание о брошенной корзине
+)
+
+// ShopTextKeys - все тексты, которые можно редактировать, в порядке показа админу.
+var ShopTextKeys = []string{TextWelcome, TextAbout, TextDelivery, TextContacts, TextFAQ, TextCartReminder}
+
+// IsShopTextKey - есть ли такой редактируемый текст
+func IsShopTextKey(key string) bool {
+	return slices.Contains(ShopTextKeys, key)
+}
+
+// MaxShopTextLength - ограничение Телеграма на длину сообщения.
+const MaxShopTextLength = 4096
+
+// Подстановки в шаблонах текстов: {first_name} заменится на имя покупателя.
+const (
+	PlaceholderFirstName = "first_name"
+	PlaceholderLastName  = "last_name"
+	PlaceholderUsername  = "username"
+)
+
+// TextPlaceholders - все поддерживаемые подстановки.
+var TextPlaceholders = []string{PlaceholderFirstName, PlaceholderLastName, PlaceholderUsername}
+
+// ShopText - одна версия текста магазина на одном языке.
+type ShopText struct {
+	ID        int64     `json:"id"`
+	Key       string    `json:"key"`        // Какой это текст (welcome, about, ...)
+	Lang      string    `json:"lang"`       // Язык текста
+	Version   int       `json:"version"`    // Номер версии, с 1 для каждой пары ключ+язык
+	Body      string    `json:"body"`       // Шаблон с подстановками вида {first_name}
+	AuthorID  int64     `json:"author_id"`  // Кто сохранил версию
+	CreatedAt time.Time `json:"created_at"` // Когда сохранена
+}
+
+// placeholderPattern - {имя} из латинских букв, цифр и подчеркиваний
+var placeholderPattern = regexp.MustCompile(`\{([a-z0-9_]+)\}`)
+
+// ValidateTextTemplate - проверяет шаблон перед сохранением: не пустой,
+// влезает в одно сообщение и использует только известные подстановки.
+func ValidateTextTemplate(body string) error {
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("текст не может быть пустым")
+	}
+	if n := len([]rune(body)); n > MaxShopTextLength {
+		return fmt.Errorf("текст слишком длинный: %d символов, максимум %d", n, MaxShopTextLength)
+	}
+	for _, m := range placeholderPattern.FindAllStringSubmatch(body, -1) {
+		if !slices.Contains(TextPlaceholders, m[1]) {
+			return fmt.Errorf("неизвестная подстановка {%s}, доступны: {%s}", m[1], strings.Join(TextPlaceholders, "}, {"))
+		}
+	}
+	return nil
+}
+
+// RenderText - подставляет значения в шаблон. Подстановки без значения заменяются пустой строкой,
+// а фигурные скобки, которые не похожи на подстановку, остаются как есть.
+func RenderText(body string, vars map[string]string) string {
+	return placeholderPattern.ReplaceAllStringFunc(body, func(m string) string {
+		name := m[1 : len(m)-1]
+		if !slices.Contains(TextPlaceholders, name) {
+			return m
+		}
+		return vars[name]
+	})
+}
@@ -9,5 +9,6 @@ type User struct {
 	ChatID    int64     `json:"chat_id"`
 	Username  string    `json:"username"`
 	FirstName string    `json:"first_name"`
+	Language  string    `json:"language"` // Выбранный язык интерфейса (пусто - по настройкам Телеграма)
 	CreatedAt time.Time `json:"created_at"`
 }
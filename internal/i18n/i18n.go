@@ -0,0 +1,239 @@
+// i18n.go - каталог переводов всех текстов бота.
+// Тексты лежат в YAML-файлах locales/<язык>.yaml и вшиваются в бинарник при сборке.
+// Вложенные группы склеиваются в ключ через точку: cart.empty, orders.paid и т.д.
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultLanguage - язык по умолчанию и запасной, если перевода нет
+const DefaultLanguage = "ru"
+
+//go:embed locales/*.yaml
+var localesFS embed.FS
+
+// pluralForms - формы множественного числа (как в CLDR).
+// Если все ключи группы из этого списка, группа считается одним сообщением с формами.
+var pluralForms = map[string]bool{"zero": true, "one": true, "two": true, "few": true, "many": true, "other": true}
+
+// entry - одно сообщение: простой текст или формы множественного числа
+type entry struct {
+	text   string
+	plural map[string]string
+}
+
+// Catalog - тексты на всех языках
+type Catalog struct {
+	messages  map[string]map[string]entry // язык -> ключ -> сообщение
+	languages []string
+}
+
+// Load - загружает вшитые в бинарник переводы.
+// Возвращает ошибку, если в каком-то языке не хватает ключей языка по умолчанию:
+// так забытый перевод видно сразу при запуске, а не у пользователя.
+func Load() (*Catalog, error) {
+	return LoadFS(localesFS, "locales")
+}
+
+// LoadFS - загружает переводы из произвольной файловой системы (каждый файл - <язык>.yaml)
+func LoadFS(fsys fs.FS, dir string) (*Catalog, error) {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Catalog{messages: make(map[string]map[string]entry)}
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		var root map[string]any
+		if err := yaml.Unmarshal(data, &root); err != nil {
+			return nil, fmt.Errorf("ошибка разбора %s: %w", file, err)
+		}
+
+		lang := strings.TrimSuffix(path.Base(file), ".yaml")
+		messages := make(map[string]entry)
+		if err := flatten("", root, messages); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		c.messages[lang] = messages
+		c.languages = append(c.languages, lang)
+	}
+	sort.Strings(c.languages)
+
+	base, ok := c.messages[DefaultLanguage]
+	if !ok {
+		return nil, fmt.Errorf("нет переводов для языка по умолчанию %q", DefaultLanguage)
+	}
+	for _, lang := range c.languages {
+		var missing []string
+		for key := range base {
+			if _, ok := c.messages[lang][key]; !ok {
+				missing = append(missing, key)
+			}
+		}
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			return nil, fmt.Errorf("в языке %q нет ключей: %s", lang, strings.Join(missing, ", "))
+		}
+	}
+	return c, nil
+}
+
+// flatten - раскладывает вложенные группы YAML в плоский список ключей
+func flatten(prefix string, node map[string]any, out map[string]entry) error {
+	for name, value := range node {
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		switch v := value.(type) {
+		case string:
+			out[key] = entry{text: v}
+		case map[string]any:
+			if isPlural(v) {
+				forms := make(map[string]string, len(v))
+				for form, text := range v {
+					s, ok := text.(string)
+					if !ok {
+						return fmt.Errorf("форма %s.%s должна быть строкой", key, form)
+					}
+					forms[form] = s
+				}
+				if _, ok := forms["other"]; !ok {
+					return fmt.Errorf("у %s нет формы other", key)
+				}
+				out[key] = entry{plural: forms}
+				continue
+			}
+			if err := flatten(key, v, out); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("значение %s должно быть строкой или группой", key)
+		}
+	}
+	return nil
+}
+
+func isPlural(m map[string]any) bool {
+	for k := range m {
+		if !pluralForms[k] {
+			return false
+		}
+	}
+	return len(m) > 0
+}
+
+// Languages - список доступных языков
+func (c *Catalog) Languages() []string {
+	return c.languages
+}
+
+// Has - есть ли такой ключ в языке по умолчанию
+func (c *Catalog) Has(key string) bool {
+	_, ok := c.messages[DefaultLanguage][key]
+	return ok
+}
+
+// Match - подбирает язык каталога по коду из Телеграма (en, en-US, pt-br...).
+// Пустая строка - такого языка нет.
+func (c *Catalog) Match(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if code == "" {
+		return ""
+	}
+	base, _, _ := strings.Cut(strings.ReplaceAll(code, "_", "-"), "-")
+	for _, candidate := range []string{code, base} {
+		if _, ok := c.messages[candidate]; ok {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// Text - сообщение по ключу. Аргументы подставляются как в fmt.Sprintf.
+// Если ключа нет в языке, берется язык по умолчанию, а если нет и там - сам ключ.
+func (c *Catalog) Text(lang, key string, args ...any) string {
+	e, ok := c.lookup(lang, key)
+	if !ok {
+		return key
+	}
+	text := e.text
+	if e.plural != nil {
+		text = e.plural["other"]
+	}
+	return format(text, args)
+}
+
+// Plural - сообщение с формой множественного числа для n.
+// Если аргументы не переданы, в текст подставляется само n.
+func (c *Catalog) Plural(lang, key string, n int, args ...any) string {
+	e, ok := c.lookup(lang, key)
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		args = []any{n}
+	}
+	if e.plural == nil {
+		return format(e.text, args)
+	}
+
+	text, ok := e.plural[pluralCategory(lang, n)]
+	if !ok {
+		text = e.plural["other"]
+	}
+	return format(text, args)
+}
+
+func (c *Catalog) lookup(lang, key string) (entry, bool) {
+	if e, ok := c.messages[lang][key]; ok {
+		return e, true
+	}
+	e, ok := c.messages[DefaultLanguage][key]
+	return e, ok
+}
+
+func format(text string, args []any) string {
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// pluralCategory - правило выбора формы для языка.
+// Русский: 1 товар, 2 товара, 5 товаров (21 товар, 11 товаров). Остальные - как английский.
+func pluralCategory(lang string, n int) string {
+	if n < 0 {
+		n = -n
+	}
+	switch lang {
+	case "ru", "uk", "be":
+		mod10, mod100 := n%10, n%100
+		switch {
+		case mod10 == 1 && mod100 != 11:
+			return "one"
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return "few"
+		default:
+			return "many"
+		}
+	default:
+		if n == 1 {
+			return "one"
+		}
+		return "other"
+	}
+}
@@ -0,0 +1,119 @@
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// keyPattern - строка, похожая на ключ каталога: cart.empty, orders_admin.status.paid
+var keyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*(\.[a-z0-9_]+)+$`)
+
+// translateFuncs - функции, первым строковым аргументом которых идет ключ каталога
+var translateFuncs = map[string]bool{"t": true, "plural": true, "Text": true, "Plural": true}
+
+// TestCodeKeysResolve - каждый ключ каталога, написанный в коде строкой, есть в переводах.
+// Каталог на ненайденный ключ возвращает сам ключ, поэтому без проверки пользователь
+// увидит "common.no_rights" вместо текста. Строка с "_" в конце - начало ключа, который
+// дописывается в коде ("loyalty.kind_" + вид), для нее в каталоге должен быть хоть один такой ключ.
+// Пакет domain переводов не использует (там "loyalty.adjust" - действие журнала), его не смотрим.
+func TestCodeKeysResolve(t *testing.T) {
+	c, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sections := make(map[string]bool)
+	for key := range c.messages[DefaultLanguage] {
+		section, _, _ := strings.Cut(key, ".")
+		sections[section] = true
+	}
+
+	fset := token.NewFileSet()
+	checked := 0
+	err = filepath.WalkDir("..", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == "domain" {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		// Строки, переданные прямо в функции перевода, - ключи, даже если такого раздела в каталоге нет
+		callKeys := make(map[*ast.BasicLit]bool)
+		ast.Inspect(file, func(n ast.Node) bool {
+			if call, ok := n.(*ast.CallExpr); ok && translateFuncs[funcName(call.Fun)] {
+				for _, arg := range call.Args {
+					if lit, ok := arg.(*ast.BasicLit); ok {
+						callKeys[lit] = true
+					}
+				}
+			}
+			return true
+		})
+		ast.Inspect(file, func(n ast.Node) bool {
+			lit, ok := n.(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return true
+			}
+			key, err := strconv.Unquote(lit.Value)
+			if err != nil || !keyPattern.MatchString(key) {
+				return true
+			}
+			section, _, _ := strings.Cut(key, ".")
+			if !sections[section] && !callKeys[lit] {
+				return true
+			}
+			checked++
+			for _, lang := range c.Languages() {
+				if !hasKey(c.messages[lang], key) {
+					t.Errorf("%s: ключа %q нет в языке %q", fset.Position(lit.Pos()), key, lang)
+				}
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checked == 0 {
+		t.Fatal("в коде не найдено ни одного ключа каталога")
+	}
+}
+
+// hasKey - есть ли ключ, а для начала ключа ("loyalty.kind_") - хоть один ключ с таким началом
+func hasKey(messages map[string]entry, key string) bool {
+	if !strings.HasSuffix(key, "_") {
+		_, ok := messages[key]
+		return ok
+	}
+	for k := range messages {
+		if strings.HasPrefix(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// funcName - имя вызываемой функции или метода
+func funcName(fun ast.Expr) string {
+	switch f := fun.(type) {
+	case *ast.Ident:
+		return f.Name
+	case *ast.SelectorExpr:
+		return f.Sel.Name
+	}
+	return ""
+}
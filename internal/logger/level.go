@@ -0,0 +1,92 @@
+// level.go - уровень стандартного журнала (LOG_LEVEL). Уровень строки определяется
+// по ее началу, как в проекте принято писать сообщения: "Error ..." - ошибка,
+// "Flood ...", "Invalid callback ..." - предупреждение, "Callback: ..." - отладка, остальное - info.
+package logger
+
+import (
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Level - уровень журнала
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// levelNames - уровни по имени из настроек
+var levelNames = map[string]Level{
+	"debug": LevelDebug,
+	"info":  LevelInfo,
+	"warn":  LevelWarn,
+	"error": LevelError,
+}
+
+// ParseLevel - уровень по имени (debug, info, warn, error). Неизвестное имя - info.
+func ParseLevel(name string) Level {
+	if level, ok := levelNames[name]; ok {
+		return level
+	}
+	return LevelInfo
+}
+
+// linePrefixes - с чего начинаются строки предупреждений, ошибок и отладки
+var linePrefixes = []struct {
+	prefix string
+	level  Level
+}{
+	{"Error", LevelError},
+	{"Panic", LevelError},
+	{"Flood", LevelWarn},
+	{"Outdated", LevelWarn},
+	{"Invalid", LevelWarn},
+	{"Unknown", LevelWarn},
+	{"Alerts", LevelWarn},
+	{"Callback", LevelDebug},
+}
+
+// timePrefix - дата и время, которые log добавляет в начало строки
+var timePrefix = regexp.MustCompile(`^\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}(\.\d+)? `)
+
+// TrimTime - строка журнала без даты и времени в начале
+func TrimTime(line string) string {
+	return timePrefix.ReplaceAllString(line, "")
+}
+
+// lineLevel - уровень строки журнала
+func lineLevel(line string) Level {
+	line = TrimTime(line)
+	for _, p := range linePrefixes {
+		if strings.HasPrefix(line, p.prefix) {
+			return p.level
+		}
+	}
+	return LevelInfo
+}
+
+// LevelWriter - пропускает в next только строки не ниже заданного уровня
+type LevelWriter struct {
+	next  io.Writer
+	level Level
+}
+
+// NewLevelWriter - подключается через log.SetOutput
+func NewLevelWriter(next io.Writer, level Level) *LevelWriter {
+	return &LevelWriter{next: next, level: level}
+}
+
+// Write - log пишет по одной записи за вызов, поэтому уровень определяем по началу записи
+func (w *LevelWriter) Write(p []byte) (int, error) {
+	if lineLevel(string(p)) < w.level {
+		return len(p), nil
+	}
+	if _, err := w.next.Write(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
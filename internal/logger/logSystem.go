@@ -12,10 +12,10 @@ type LogWriter struct {
 	file *os.File // передаем файл логов
 }
 
-// NewLogWriter - нанимаем прораба
-func NewLogWriter(subDir, fileName string) (*LogWriter, error) {
+// NewLogWriter - нанимаем прораба. logDir - общий каталог журналов (LOG_DIR)
+func NewLogWriter(logDir, subDir, fileName string) (*LogWriter, error) {
 	// 1. определяем путь где хранятся логи
-	dir := filepath.Join("logs", subDir)
+	dir := filepath.Join(logDir, subDir)
 
 	// 2. то как будет в итоге называться файл
 	filePath := filepath.Join(dir, fileName)
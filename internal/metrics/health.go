@@ -0,0 +1,61 @@
+// health.go - проверки для Docker и оркестратора: /healthz - процесс жив,
+// /readyz - база и Телеграм доступны, можно принимать работу.
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// readyTimeout - сколько ждем каждую проверку готовности
+const readyTimeout = 5 * time.Second
+
+// Check - проверка готовности (например, ping базы)
+type Check struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// Routes - /metrics, /healthz и /readyz
+func (m *Metrics) Routes(checks []Check) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", m.Handler())
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeStatus(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		status, results := http.StatusOK, make(map[string]string, len(checks))
+		for _, c := range checks {
+			if err := runCheck(r.Context(), c); err != nil {
+				status, results[c.Name] = http.StatusServiceUnavailable, err.Error()
+				continue
+			}
+			results[c.Name] = "ok"
+		}
+		writeStatus(w, status, results)
+	})
+	return mux
+}
+
+// runCheck - проверка с таймаутом. Проверка, которая не слушает ctx, все равно не задержит ответ дольше таймаута.
+func runCheck(ctx context.Context, c Check) error {
+	ctx, cancel := context.WithTimeout(ctx, readyTimeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- c.Check(ctx) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// writeStatus - ответ проверки в JSON
+func writeStatus(w http.ResponseWriter, status int, body map[string]string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,89 @@
+// metrics.go - метрики магазина: обновления бота, ошибки Telegram API, запросы к базе,
+// незаконченные диалоги и новые заказы.
+package metrics
+
+import (
+	"net/http"
+	"path"
+	"time"
+)
+
+// Metrics - все метрики бота. Методы реализуют интерфейсы, через которые их пишут
+// middleware бота (ObserveUpdate), база (ObserveQuery) и сервис заказов (OrderCreated).
+type Metrics struct {
+	registry *Registry
+
+	updates        *Counter
+	updateDuration *Histogram
+	telegramErrors *Counter
+	queryDuration  *Histogram
+	ordersCreated  *Counter
+}
+
+// New - создает метрики
+func New() *Metrics {
+	r := NewRegistry()
+	return &Metrics{
+		registry:       r,
+		updates:        r.NewCounter("bot_updates_total", "Обновления от Телеграма по видам.", "type"),
+		updateDuration: r.NewHistogram("bot_update_duration_seconds", "Время обработки обновления по видам.", "type", DefaultBuckets),
+		telegramErrors: r.NewCounter("bot_telegram_api_errors_total", "Неудачные запросы к Telegram API по методам.", "method"),
+		queryDuration:  r.NewHistogram("bot_db_query_duration_seconds", "Время запросов к базе по видам (select, insert...).", "op", DefaultBuckets),
+		ordersCreated:  r.NewCounter("bot_orders_created_total", "Оформленные заказы (из бота, API и Mini App).", ""),
+	}
+}
+
+// Handler - ответ на GET /metrics
+func (m *Metrics) Handler() http.Handler {
+	return m.registry
+}
+
+// SetActiveSessions - как посчитать незаконченные диалоги пользователей (спрашиваем при каждом запросе метрик)
+func (m *Metrics) SetActiveSessions(count func() (int, error)) {
+	m.registry.NewGaugeFunc("bot_active_sessions", "Незаконченные пошаговые диалоги пользователей.", func() (float64, error) {
+		n, err := count()
+		return float64(n), err
+	})
+}
+
+// ObserveUpdate - обработано обновление вида kind
+func (m *Metrics) ObserveUpdate(kind string, duration time.Duration) {
+	m.updates.Inc(kind)
+	m.updateDuration.Observe(kind, duration)
+}
+
+// ObserveQuery - выполнен запрос к базе
+func (m *Metrics) ObserveQuery(op string, duration time.Duration) {
+	m.queryDuration.Observe(op, duration)
+}
+
+// OrderCreated - оформлен заказ
+func (m *Metrics) OrderCreated() {
+	m.ordersCreated.Inc("")
+}
+
+// HTTPClient - HTTP клиент бота (то же, что tgbotapi.HTTPClient)
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// TelegramClient - HTTP клиент бота, который считает неудачные запросы к Telegram API.
+// Неудачный - не дошел до Телеграма или вернулся не с кодом 200 (Телеграм так отвечает на ошибки).
+type TelegramClient struct {
+	next    HTTPClient
+	metrics *Metrics
+}
+
+// WrapTelegramClient - оборачивает клиент бота (BotAPI.Client)
+func (m *Metrics) WrapTelegramClient(next HTTPClient) *TelegramClient {
+	return &TelegramClient{next: next, metrics: m}
+}
+
+// Do - запрос к Telegram API. Метод берем из конца адреса: в адресе есть токен, его в метки не пишем.
+func (c *TelegramClient) Do(req *http.Request) (*http.Response, error) {
+	resp, err := c.next.Do(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		c.metrics.telegramErrors.Inc(path.Base(req.URL.Path))
+	}
+	return resp, err
+}
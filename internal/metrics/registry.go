@@ -0,0 +1,208 @@
+// registry.go - Пакет metrics собирает метрики бота и отдает их в текстовом формате Prometheus.
+// Метрик немного и у каждой не больше одной метки, поэтому обходимся без клиентской
+// библиотеки: счетчики, гистограммы и значения, которые считаются в момент запроса.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets - границы гистограмм длительностей в секундах: от миллисекунды до 10 секунд
+var DefaultBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// collector - метрика, которую можно записать в ответ
+type collector interface {
+	write(w io.Writer)
+}
+
+// Registry - набор метрик в порядке добавления
+type Registry struct {
+	mu         sync.Mutex
+	collectors []collector
+}
+
+// NewRegistry - создает пустой набор
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// add - добавляет метрику
+func (r *Registry) add(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.collectors = append(r.collectors, c)
+}
+
+// ServeHTTP - GET /metrics: все метрики в текстовом формате Prometheus
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	r.mu.Lock()
+	collectors := append([]collector(nil), r.collectors...)
+	r.mu.Unlock()
+	for _, c := range collectors {
+		c.write(w)
+	}
+}
+
+// Counter - счетчик, который только растет. label - имя метки (пусто - без меток).
+type Counter struct {
+	name, help, label string
+
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+// NewCounter - создает счетчик и добавляет его в набор
+func (r *Registry) NewCounter(name, help, label string) *Counter {
+	c := &Counter{name: name, help: help, label: label, values: make(map[string]float64)}
+	r.add(c)
+	return c
+}
+
+// Inc - плюс один для значения метки
+func (c *Counter) Inc(labelValue string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[labelValue]++
+}
+
+func (c *Counter) write(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	writeHeader(w, c.name, c.help, "counter")
+	if c.label == "" {
+		fmt.Fprintf(w, "%s %s\n", c.name, formatFloat(c.values[""]))
+		return
+	}
+	for _, value := range sortedKeys(c.values) {
+		fmt.Fprintf(w, "%s{%s} %s\n", c.name, labelPair(c.label, value), formatFloat(c.values[value]))
+	}
+}
+
+// histogramValue - наблюдения для одного значения метки
+type histogramValue struct {
+	counts []uint64 // По границам buckets, не накопительно
+	sum    float64
+	count  uint64
+}
+
+// Histogram - распределение длительностей в секундах
+type Histogram struct {
+	name, help, label string
+	buckets           []float64
+
+	mu     sync.Mutex
+	values map[string]*histogramValue
+}
+
+// NewHistogram - создает гистограмму с границами buckets (по возрастанию) и добавляет ее в набор
+func (r *Registry) NewHistogram(name, help, label string, buckets []float64) *Histogram {
+	h := &Histogram{name: name, help: help, label: label, buckets: buckets, values: make(map[string]*histogramValue)}
+	r.add(h)
+	return h
+}
+
+// Observe - одно наблюдение длительностью d
+func (h *Histogram) Observe(labelValue string, d time.Duration) {
+	seconds := d.Seconds()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	v, ok := h.values[labelValue]
+	if !ok {
+		v = &histogramValue{counts: make([]uint64, len(h.buckets))}
+		h.values[labelValue] = v
+	}
+	if i := sort.SearchFloat64s(h.buckets, seconds); i < len(h.buckets) {
+		v.counts[i]++
+	}
+	v.sum += seconds
+	v.count++
+}
+
+func (h *Histogram) write(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	writeHeader(w, h.name, h.help, "histogram")
+	for _, value := range sortedKeys(h.values) {
+		v := h.values[value]
+		prefix := ""
+		if h.label != "" {
+			prefix = labelPair(h.label, value) + ","
+		}
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += v.counts[i]
+			fmt.Fprintf(w, "%s_bucket{%sle=\"%s\"} %d\n", h.name, prefix, formatFloat(bound), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket{%sle=\"+Inf\"} %d\n", h.name, prefix, v.count)
+		labels := ""
+		if h.label != "" {
+			labels = "{" + labelPair(h.label, value) + "}"
+		}
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, labels, formatFloat(v.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, labels, v.count)
+	}
+}
+
+// GaugeFunc - значение, которое считается в момент запроса метрик (например, из базы)
+type GaugeFunc struct {
+	name, help string
+	fn         func() (float64, error)
+}
+
+// NewGaugeFunc - создает значение по функции и добавляет его в набор.
+// Если fn вернула ошибку, метрику в ответ не пишем: лучше пропуск, чем неверный ноль.
+func (r *Registry) NewGaugeFunc(name, help string, fn func() (float64, error)) *GaugeFunc {
+	g := &GaugeFunc{name: name, help: help, fn: fn}
+	r.add(g)
+	return g
+}
+
+func (g *GaugeFunc) write(w io.Writer) {
+	value, err := g.fn()
+	if err != nil {
+		fmt.Fprintf(w, "# %s: %v\n", g.name, strings.ReplaceAll(err.Error(), "\n", " "))
+		return
+	}
+	writeHeader(w, g.name, g.help, "gauge")
+	fmt.Fprintf(w, "%s %s\n", g.name, formatFloat(value))
+}
+
+// writeHeader - строки HELP и TYPE перед значениями метрики
+func writeHeader(w io.Writer, name, help, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, strings.ReplaceAll(help, "\n", " "), name, kind)
+}
+
+// labelEscaper - экранирование значения метки по формату Prometheus
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// labelPair - метка вида name="value"
+func labelPair(name, value string) string {
+	return name + `="` + labelEscaper.Replace(value) + `"`
+}
+
+// formatFloat - число в формате Prometheus
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// sortedKeys - значения меток по алфавиту, чтобы порядок в ответе не прыгал
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -3,19 +3,162 @@
 // Интерфейсы позволяют нам менять базу данных (например, с SQLite на Postgres) не меняя остальной код.
 package repository
 
-import "salle_parfume/internal/domain"
+import (
+	"time"
+
+	"salle_parfume/internal/domain"
+)
 
 // Authorization - Контракт для работы с пользователями.
 type Authorization interface {
-	CreateUser(user *domain.User) error                 // Сохранить нового пользователя
-	GetUserByChatID(chatID int64) (*domain.User, error) // Найти пользователя по ID чата
+	CreateUser(user *domain.User) error                       // Сохранить нового пользователя
+	EnsureUser(user *domain.User) (bool, error)               // Сохранить, если его еще нет (true - пользователь новый)
+	GetUserByChatID(chatID int64) (*domain.User, error)       // Найти пользователя по ID чата
+	SetUserLanguage(user *domain.User) error                  // Сохранить выбранный язык (создает пользователя, если его нет)
+	FindUsers(query string, limit int) ([]domain.User, error) // Поиск по Телеграм ID, username или имени
 }
 
 // ProductRepository - Контракт для работы с товарами (Духами).
 // Мы описываем ЧТО мы хотим делать, но не КАК.
 type ProductRepository interface {
-	CreateProduct(product *domain.Product) error // Сохранить товар
-	GetAllProducts() ([]domain.Product, error)   // Получить список всех товаров
+	CreateProduct(product *domain.Product) error              // Сохранить товар вместе с фото (заполняет product.ID и, если пусто, SKU)
+	GetAllProducts() ([]domain.Product, error)                // Получить список всех товаров
+	GetProductByID(id int64) (*domain.Product, error)         // Найти товар по ID (nil, если нет)
+	GetProductBySKU(sku string) (*domain.Product, error)      // Найти товар по артикулу (nil, если нет)
+	UpdateProduct(product *domain.Product) error              // Обновить товар по ID (если Images == nil, меняется только обложка)
+	SetProductImages(productID int64, fileIDs []string) error // Заменить все фото товара (первое станет обложкой)
+	DeleteProduct(id int64) error                             // Удалить товар с фото и из корзин (заказы хранят свой снимок)
+}
+
+// OrderRepository - Контракт для работы с заказами.
+type OrderRepository interface {
+	CreateOrder(order *domain.Order) error                                          // Сохранить заказ вместе с позициями (заполняет order.ID)
+	GetOrderByID(id int64) (*domain.Order, error)                                   // Найти заказ по номеру (nil, если нет)
+	UpdateOrderStatus(id int64, status domain.OrderStatus) error                    // Сменить статус заказа (domain.ErrStatusChange, если так нельзя)
+	SetTrackingNumber(id int64, tracking string) error                              // Сохранить трек-номер отправления
+	HasDeliveredProduct(chatID, productID int64) (bool, error)                      // Получал ли покупатель этот товар
+	GetOrdersByStatus(status domain.OrderStatus, limit int) ([]domain.Order, error) // Заказы в статусе, новые первыми
+	GetUserOrders(chatID int64, limit, offset int) ([]domain.Order, error)          // Заказы покупателя, новые первыми
+	CancelUserOrder(chatID, id int64) (bool, error)                                 // Отменить новый заказ покупателя (false, если заказ чужой или уже не новый)
+}
+
+// ReviewRepository - Контракт для работы с отзывами.
+type ReviewRepository interface {
+	CreateReview(review *domain.Review) error                                       // Сохранить отзыв (заполняет review.ID)
+	GetReviewByID(id int64) (*domain.Review, error)                                 // Найти отзыв по ID (nil, если нет)
+	GetUserReview(chatID, productID int64) (*domain.Review, error)                  // Последний отзыв покупателя о товаре (nil, если нет)
+	UpdateReviewStatus(id int64, status domain.ReviewStatus) error                  // Одобрить или отклонить
+	GetPendingReviews() ([]domain.Review, error)                                    // Очередь модерации
+	GetApprovedReviews(productID int64, limit, offset int) ([]domain.Review, error) // Опубликованные отзывы постранично
+	GetProductRating(productID int64) (domain.ProductRating, error)                 // Средняя оценка и число отзывов
+}
+
+// CartRepository - Контракт для работы с корзиной покупателя.
+type CartRepository interface {
+	AddToCart(chatID, productID int64, quantity int) error       // Добавить товар (или увеличить количество)
+	SetCartQuantity(chatID, productID int64, quantity int) error // Установить количество (0 - убрать из корзины)
+	GetCart(chatID int64) ([]domain.CartLine, error)             // Содержимое корзины с данными товаров
+	ClearCart(chatID int64) error                                // Очистить корзину
+	// Корзины без изменений с idleSince, по которым еще не напоминали после последнего изменения
+	// и не напоминали позже remindedSince. Покупатели, отказавшиеся от напоминаний, не попадают.
+	GetAbandonedCarts(idleSince, remindedSince time.Time, limit int) ([]domain.AbandonedCart, error)
+	MarkCartReminded(chatID int64) error               // Запомнить, что напоминание отправлено сейчас
+	SetCartReminders(chatID int64, enabled bool) error // Включить или выключить напоминания покупателю
+	CartRemindersEnabled(chatID int64) (bool, error)   // Включены ли напоминания (по умолчанию да)
+}
+
+// PromoRepository - Контракт для работы с акциями и промокодами.
+type PromoRepository interface {
+	CreatePromotion(promo *domain.Promotion) error             // Сохранить акцию (заполняет promo.ID)
+	GetPromotionByCode(code string) (*domain.Promotion, error) // Найти по коду без учета регистра (nil, если нет)
+	GetPromotionByID(id int64) (*domain.Promotion, error)      // Найти по ID (nil, если нет)
+	GetAllPromotions() ([]domain.Promotion, error)             // Все акции для админа
+	GetAutoPromotions() ([]domain.Promotion, error)            // Включенные акции без кода
+	SetPromotionActive(id int64, active bool) error            // Включить или выключить
+	CountPromotionUses(promoID int64) (int, error)             // Сколько раз использован всего
+	CountUserPromotionUses(promoID, chatID int64) (int, error) // Сколько раз использован покупателем
+	RecordPromotionUse(promoID, chatID, orderID int64) error   // Отметить использование в заказе
+}
+
+// TextRepository - Контракт для работы с редактируемыми текстами магазина.
+// Тексты не перезаписываются: каждое сохранение - новая версия.
+type TextRepository interface {
+	GetShopText(key, lang string) (*domain.ShopText, error)                     // Последняя версия (nil, если текст не меняли)
+	GetShopTextVersion(key, lang string, version int) (*domain.ShopText, error) // Конкретная версия (nil, если нет)
+	GetShopTextHistory(key, lang string, limit int) ([]domain.ShopText, error)  // Последние версии, новые первыми
+	SaveShopText(text *domain.ShopText) error                                   // Сохранить новой версией (заполняет ID, Version, CreatedAt)
+}
+
+// AuditRepository - Контракт для журнала действий админов.
+type AuditRepository interface {
+	AddAuditEntry(entry *domain.AuditEntry) error               // Записать действие (заполняет ID и CreatedAt)
+	GetAuditLog(limit, offset int) ([]domain.AuditEntry, error) // Записи журнала, новые первыми
+}
+
+// DeliveryRepository - Контракт для работы со способами доставки.
+type DeliveryRepository interface {
+	CreateDeliveryMethod(method *domain.DeliveryMethod) error            // Сохранить способ (заполняет method.ID)
+	UpdateDeliveryMethod(method *domain.DeliveryMethod) error            // Обновить способ по ID
+	GetDeliveryMethodByID(id int64) (*domain.DeliveryMethod, error)      // Найти по ID (nil, если нет)
+	GetDeliveryMethods(activeOnly bool) ([]domain.DeliveryMethod, error) // Все способы или только включенные, по порядку добавления
+}
+
+// JobRepository - Контракт для фоновых задач: история запусков и блокировки,
+// чтобы одну задачу не выполняли одновременно два экземпляра бота с общей базой.
+type JobRepository interface {
+	AcquireJobLock(job, owner string, until time.Time) (bool, error) // Взять блокировку до until (false - держит другой экземпляр)
+	ReleaseJobLock(job, owner string) error                          // Отпустить свою блокировку
+	CreateJobRun(run *domain.JobRun) error                           // Сохранить начало запуска (заполняет run.ID)
+	FinishJobRun(run *domain.JobRun) error                           // Сохранить итог запуска
+	GetLastJobRun(job string) (*domain.JobRun, error)                // Последний запуск задачи (nil, если не запускалась)
+	GetJobRuns(limit, offset int) ([]domain.JobRun, error)           // Запуски всех задач, новые первыми
+}
+
+// SubscriptionRepository - Контракт для подписок на поступление товара и снижение цены.
+type SubscriptionRepository interface {
+	Subscribe(sub *domain.Subscription) (bool, error)                 // Подписать (false - такая подписка уже есть; иначе заполняет ID)
+	Unsubscribe(chatID, id int64) (bool, error)                       // Отписать (false - подписка чужая или ее уже нет)
+	GetUserSubscriptions(chatID int64) ([]domain.Subscription, error) // Подписки покупателя, новые первыми
+	GetDueSubscriptions(limit int) ([]domain.Subscription, error)     // Подписки, по которым пора уведомить (товар появился или подешевел), старые первыми
+	DeleteSubscription(id int64) error                                // Удалить подписку после уведомления
+	SetSubscriptionPrice(id int64, price domain.Money) error          // Запомнить цену, о которой уже сообщили
+}
+
+// ReferralRepository - Контракт для реферальной программы: кто кого пригласил и какие награды выданы.
+type ReferralRepository interface {
+	CreateReferral(ref *domain.Referral) (bool, error)           // Запомнить приглашение (false - покупателя уже пригласили; иначе заполняет ID)
+	GetReferralStats(chatID int64) (domain.ReferralStats, error) // Сколько друзей пригласил покупатель и какие промокоды получил
+	GetDueReferrals(limit int) ([]domain.Referral, error)        // Приглашения без награды, где друг оплатил заказ (заполняет OrderID), старые первыми
+	SetReferralReward(id, orderID int64, code string) error      // Запомнить выданный промокод
+}
+
+// LoyaltyRepository - Контракт для бонусной программы. Списание и начисление по заказам
+// делает OrderRepository в транзакциях заказа, здесь - баланс, история и операции без заказа.
+type LoyaltyRepository interface {
+	GetLoyaltyBalance(chatID int64) (int64, error)                                    // Сколько бонусов у покупателя
+	GetOldestPoints(chatID int64) (int64, time.Time, error)                           // Остаток самого старого неистраченного начисления и его дата (0 - нет)
+	GetLoyaltyHistory(chatID int64, limit, offset int) ([]domain.LoyaltyEntry, error) // Операции покупателя, новые первыми
+	AdjustPoints(entry *domain.LoyaltyEntry) error                                    // Ручная корректировка (заполняет ID; списать больше баланса нельзя)
+	ExpirePoints(before time.Time, limit int) (int, error)                            // Сжечь остатки начислений старше before, вернуть их число
+	GetLoyaltyTotals() (map[string]int64, error)                                      // Обороты по счетам магазина
+}
+
+// DialogRepository - Контракт для сессий пошаговых диалогов бота.
+type DialogRepository interface {
+	GetDialogSession(chatID int64) (*domain.DialogSession, error) // Незаконченный диалог пользователя (nil, если нет)
+	SaveDialogSession(session *domain.DialogSession) error        // Сохранить шаг и ответы (создает сессию, если ее нет)
+	DeleteDialogSession(chatID int64) error                       // Закончить диалог
+	CountDialogSessions(since time.Time) (int, error)             // Сколько диалогов с ответами не раньше since
+}
+
+// AbuseRepository - Контракт для блокировок пользователей и записей о флуде.
+type AbuseRepository interface {
+	BanUser(ban *domain.Ban) error                                 // Заблокировать (повторная блокировка обновляет причину)
+	UnbanUser(userID int64) (bool, error)                          // Разблокировать (false - и не был заблокирован)
+	GetBan(userID int64) (*domain.Ban, error)                      // Блокировка пользователя (nil, если нет)
+	GetBans(limit, offset int) ([]domain.Ban, error)               // Заблокированные, новые первыми
+	CreateFloodEvent(event *domain.FloodEvent) error               // Записать превышение (заполняет ID)
+	GetFloodEvents(limit, offset int) ([]domain.FloodEvent, error) // Превышения, новые первыми
 }
 
 // Repository - Главная структура, которая объединяет все наши репозитории.
@@ -23,15 +166,54 @@ type ProductRepository interface {
 type Repository struct {
 	Authorization
 	ProductRepository
+	OrderRepository
+	ReviewRepository
+	CartRepository
+	PromoRepository
+	TextRepository
+	AuditRepository
+	DeliveryRepository
+	JobRepository
+	SubscriptionRepository
+	ReferralRepository
+	LoyaltyRepository
+	DialogRepository
+	AbuseRepository
 }
 
 // NewRepository - Конструктор. Собирает отдельные реализации в одну коробку.
 // Принимает:
 // auth - реализацию работы с юзерами
 // prod - реализацию работы с товарами
-func NewRepository(auth Authorization, prod ProductRepository) *Repository {
+// order - реализацию работы с заказами
+// review - реализацию работы с отзывами
+// cart - реализацию работы с корзиной
+// promo - реализацию работы с акциями и промокодами
+// text - реализацию работы с текстами магазина
+// audit - реализацию журнала действий админов
+// delivery - реализацию работы со способами доставки
+// job - реализацию истории и блокировок фоновых задач
+// sub - реализацию подписок на товары
+// ref - реализацию реферальной программы
+// loyalty - реализацию бонусной программы
+// dialog - реализацию сессий диалогов бота
+// abuse - реализацию блокировок и записей о флуде
+func NewRepository(auth Authorization, prod ProductRepository, order OrderRepository, review ReviewRepository, cart CartRepository, promo PromoRepository, text TextRepository, audit AuditRepository, delivery DeliveryRepository, job JobRepository, sub SubscriptionRepository, ref ReferralRepository, loyalty LoyaltyRepository, dialog DialogRepository, abuse AbuseRepository) *Repository {
 	return &Repository{
-		Authorization:     auth,
-		ProductRepository: prod,
+		Authorization:          auth,
+		ProductRepository:      prod,
+		OrderRepository:        order,
+		ReviewRepository:       review,
+		CartRepository:         cart,
+		PromoRepository:        promo,
+		TextRepository:         text,
+		AuditRepository:        audit,
+		DeliveryRepository:     delivery,
+		JobRepository:          job,
+		SubscriptionRepository: sub,
+		ReferralRepository:     ref,
+		LoyaltyRepository:      loyalty,
+		DialogRepository:       dialog,
+		AbuseRepository:        abuse,
 	}
 }
@@ -0,0 +1,143 @@
+// abuse.go - Реализация интерфейса AbuseRepository для SQLite.
+// Блокировки и записи о флуде в отдельных таблицах: пользователь мог ни разу не написать
+// в личку (нет в users), а блокировать его все равно нужно.
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"salle_parfume/internal/domain"
+	"salle_parfume/internal/repository"
+	"time"
+)
+
+// AbuseSqlite - блокировки и флуд.
+type AbuseSqlite struct {
+	db *sql.DB
+}
+
+// NewAbuseSqlite - создает репозиторий блокировок и таблицы для него.
+func NewAbuseSqlite(db *sql.DB) repository.AbuseRepository {
+	if err := createAbuseTables(db); err != nil {
+		fmt.Printf("Error creating abuse tables: %v\n", err)
+	}
+	return &AbuseSqlite{db: db}
+}
+
+// createAbuseTables - SQL запрос для создания таблиц блокировок и записей о флуде
+func createAbuseTables(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS bans (
+		user_id INTEGER PRIMARY KEY,
+		reason TEXT NOT NULL DEFAULT '',
+		actor TEXT NOT NULL DEFAULT '',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE TABLE IF NOT EXISTS flood_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		kind TEXT NOT NULL,                   -- rate_limit, repeat_callback
+		details TEXT NOT NULL DEFAULT '',
+		strike INTEGER NOT NULL DEFAULT 1,
+		cooldown_seconds INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_flood_events_user ON flood_events(user_id);
+	`
+	_, err := db.Exec(query)
+	return err
+}
+
+// BanUser - блокирует пользователя. Повторная блокировка меняет причину и автора, но не дату.
+func (r *AbuseSqlite) BanUser(ban *domain.Ban) error {
+	query := `
+	INSERT INTO bans (user_id, reason, actor) VALUES (?, ?, ?)
+	ON CONFLICT (user_id) DO UPDATE SET reason = excluded.reason, actor = excluded.actor`
+	if _, err := r.db.Exec(query, ban.UserID, ban.Reason, ban.Actor); err != nil {
+		return fmt.Errorf("failed to ban user: %w", err)
+	}
+	return nil
+}
+
+// UnbanUser - снимает блокировку
+func (r *AbuseSqlite) UnbanUser(userID int64) (bool, error) {
+	res, err := r.db.Exec(`DELETE FROM bans WHERE user_id = ?`, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to unban user: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to unban user: %w", err)
+	}
+	return n > 0, nil
+}
+
+// GetBan - блокировка пользователя
+func (r *AbuseSqlite) GetBan(userID int64) (*domain.Ban, error) {
+	ban := &domain.Ban{}
+	err := r.db.QueryRow(`SELECT user_id, reason, actor, created_at FROM bans WHERE user_id = ?`, userID).
+		Scan(&ban.UserID, &ban.Reason, &ban.Actor, &ban.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ban: %w", err)
+	}
+	return ban, nil
+}
+
+// GetBans - заблокированные, новые первыми
+func (r *AbuseSqlite) GetBans(limit, offset int) ([]domain.Ban, error) {
+	rows, err := r.db.Query(`SELECT user_id, reason, actor, created_at FROM bans ORDER BY created_at DESC, user_id DESC LIMIT ? OFFSET ?`, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bans: %w", err)
+	}
+	defer rows.Close()
+
+	var bans []domain.Ban
+	for rows.Next() {
+		var ban domain.Ban
+		if err := rows.Scan(&ban.UserID, &ban.Reason, &ban.Actor, &ban.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan ban: %w", err)
+		}
+		bans = append(bans, ban)
+	}
+	return bans, rows.Err()
+}
+
+// CreateFloodEvent - записывает превышение
+func (r *AbuseSqlite) CreateFloodEvent(event *domain.FloodEvent) error {
+	query := `INSERT INTO flood_events (user_id, kind, details, strike, cooldown_seconds) VALUES (?, ?, ?, ?, ?)`
+	res, err := r.db.Exec(query, event.UserID, event.Kind, event.Details, event.Strike, int64(event.Cooldown/time.Second))
+	if err != nil {
+		return fmt.Errorf("failed to create flood event: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get flood event id: %w", err)
+	}
+	event.ID = id
+	return nil
+}
+
+// GetFloodEvents - превышения, новые первыми
+func (r *AbuseSqlite) GetFloodEvents(limit, offset int) ([]domain.FloodEvent, error) {
+	rows, err := r.db.Query(`SELECT id, user_id, kind, details, strike, cooldown_seconds, created_at FROM flood_events ORDER BY id DESC LIMIT ? OFFSET ?`, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get flood events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []domain.FloodEvent
+	for rows.Next() {
+		var event domain.FloodEvent
+		var seconds int64
+		if err := rows.Scan(&event.ID, &event.UserID, &event.Kind, &event.Details, &event.Strike, &seconds, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan flood event: %w", err)
+		}
+		event.Cooldown = time.Duration(seconds) * time.Second
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,76 @@
+// audit.go - Реализация интерфейса AuditRepository для SQLite.
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"salle_parfume/internal/domain"
+	"salle_parfume/internal/repository"
+	"time"
+)
+
+// AuditSqlite - журнал действий админов.
+type AuditSqlite struct {
+	db *sql.DB
+}
+
+// NewAuditSqlite - создает репозиторий журнала и таблицу для него.
+func NewAuditSqlite(db *sql.DB) repository.AuditRepository {
+	if err := createAuditTable(db); err != nil {
+		fmt.Printf("Error creating audit table: %v\n", err)
+	}
+	return &AuditSqlite{db: db}
+}
+
+// createAuditTable - SQL запрос для создания таблицы журнала
+func createAuditTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS audit_log (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		actor TEXT NOT NULL,                -- Логин админа или "api"
+		action TEXT NOT NULL,               -- product.create, order.status...
+		object TEXT NOT NULL DEFAULT '',    -- product, order
+		object_id INTEGER NOT NULL DEFAULT 0,
+		details TEXT NOT NULL DEFAULT '',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	`
+	_, err := db.Exec(query)
+	return err
+}
+
+// AddAuditEntry - добавляет запись в журнал
+func (r *AuditSqlite) AddAuditEntry(entry *domain.AuditEntry) error {
+	entry.CreatedAt = time.Now().UTC().Truncate(time.Second)
+	res, err := r.db.Exec(`INSERT INTO audit_log (actor, action, object, object_id, details, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		entry.Actor, entry.Action, entry.Object, entry.ObjectID, entry.Details, entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to add audit entry: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get audit entry id: %w", err)
+	}
+	entry.ID = id
+	return nil
+}
+
+// GetAuditLog - записи журнала постранично, новые первыми
+func (r *AuditSqlite) GetAuditLog(limit, offset int) ([]domain.AuditEntry, error) {
+	rows, err := r.db.Query(`SELECT id, actor, action, object, object_id, details, created_at FROM audit_log
+		ORDER BY id DESC LIMIT ? OFFSET ?`, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit log: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []domain.AuditEntry
+	for rows.Next() {
+		var e domain.AuditEntry
+		if err := rows.Scan(&e.ID, &e.Actor, &e.Action, &e.Object, &e.ObjectID, &e.Details, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
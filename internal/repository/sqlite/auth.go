@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"salle_parfume/internal/domain"
 	"salle_parfume/internal/repository"
+	"strconv"
+	"strings"
 )
 
 type AuthSqlite struct {
@@ -29,11 +31,14 @@ func createUsersTable(db *sql.DB) error {
 		chat_id INTEGER NOT NULL UNIQUE,
 		username TEXT,
 		first_name TEXT,
+		language TEXT NOT NULL DEFAULT '', -- Язык, выбранный через /language (пусто - по настройкам Телеграма)
 		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
 	);
 	`
-	_, err := db.Exec(query)
-	return err
+	if _, err := db.Exec(query); err != nil {
+		return err
+	}
+	return addColumnIfMissing(db, "users", "language", "TEXT NOT NULL DEFAULT ''")
 }
 
 func (r *AuthSqlite) CreateUser(user *domain.User) error {
@@ -45,10 +50,25 @@ func (r *AuthSqlite) CreateUser(user *domain.User) error {
 	return nil
 }
 
+// EnsureUser - сохраняет пользователя при первом обращении к боту.
+// Возвращает true, если пользователя раньше не было.
+func (r *AuthSqlite) EnsureUser(user *domain.User) (bool, error) {
+	query := `INSERT INTO users (chat_id, username, first_name) VALUES (?, ?, ?) ON CONFLICT(chat_id) DO NOTHING`
+	res, err := r.db.Exec(query, user.ChatID, user.Username, user.FirstName)
+	if err != nil {
+		return false, fmt.Errorf("failed to create user: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to create user: %w", err)
+	}
+	return n > 0, nil
+}
+
 func (r *AuthSqlite) GetUserByChatID(chatID int64) (*domain.User, error) {
-	query := `SELECT id, chat_id, username, first_name, created_at FROM users WHERE chat_id = ?`
+	query := `SELECT id, chat_id, COALESCE(username, ''), COALESCE(first_name, ''), language, created_at FROM users WHERE chat_id = ?`
 	var user domain.User
-	err := r.db.QueryRow(query, chatID).Scan(&user.ID, &user.ChatID, &user.Username, &user.FirstName, &user.CreatedAt)
+	err := r.db.QueryRow(query, chatID).Scan(&user.ID, &user.ChatID, &user.Username, &user.FirstName, &user.Language, &user.CreatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -57,3 +77,39 @@ func (r *AuthSqlite) GetUserByChatID(chatID int64) (*domain.User, error) {
 	}
 	return &user, nil
 }
+
+// SetUserLanguage - сохраняет язык пользователя. Если пользователя еще нет в базе, создает его.
+func (r *AuthSqlite) SetUserLanguage(user *domain.User) error {
+	query := `INSERT INTO users (chat_id, username, first_name, language) VALUES (?, ?, ?, ?)
+		ON CONFLICT(chat_id) DO UPDATE SET language = excluded.language`
+	if _, err := r.db.Exec(query, user.ChatID, user.Username, user.FirstName, user.Language); err != nil {
+		return fmt.Errorf("failed to set user language: %w", err)
+	}
+	return nil
+}
+
+// FindUsers - ищет пользователей для админки: точное совпадение Телеграм ID
+// или часть username / имени без учета регистра. Новые пользователи первыми.
+func (r *AuthSqlite) FindUsers(query string, limit int) ([]domain.User, error) {
+	like := "%" + strings.ToLower(strings.TrimPrefix(query, "@")) + "%"
+	chatID, _ := strconv.ParseInt(query, 10, 64)
+
+	rows, err := r.db.Query(`
+		SELECT id, chat_id, COALESCE(username, ''), COALESCE(first_name, ''), language, created_at FROM users
+		WHERE chat_id = ? OR LOWER(COALESCE(username, '')) LIKE ? OR LOWER(COALESCE(first_name, '')) LIKE ?
+		ORDER BY id DESC LIMIT ?`, chatID, like, like, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []domain.User
+	for rows.Next() {
+		var user domain.User
+		if err := rows.Scan(&user.ID, &user.ChatID, &user.Username, &user.FirstName, &user.Language, &user.CreatedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
@@ -0,0 +1,180 @@
+// cart.go - Реализация интерфейса CartRepository для SQLite.
+// Корзина хранится в базе, чтобы не теряться при перезапуске бота.
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"salle_parfume/internal/domain"
+	"salle_parfume/internal/repository"
+	"time"
+)
+
+// CartSqlite - репозиторий корзин.
+type CartSqlite struct {
+	db *sql.DB
+}
+
+// NewCartSqlite - создает репозиторий корзин и таблицу для него.
+func NewCartSqlite(db *sql.DB) repository.CartRepository {
+	if err := createCartTable(db); err != nil {
+		fmt.Printf("Error creating cart table: %v\n", err)
+	}
+	return &CartSqlite{db: db}
+}
+
+// createCartTable - SQL запрос для создания таблицы корзин
+func createCartTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS cart_items (
+		chat_id INTEGER NOT NULL,
+		product_id INTEGER NOT NULL,
+		quantity INTEGER NOT NULL DEFAULT 1,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP, -- Последнее изменение корзины
+		PRIMARY KEY (chat_id, product_id)
+	);
+
+	-- Напоминания о брошенной корзине: когда напоминали и не отказался ли покупатель
+	CREATE TABLE IF NOT EXISTS cart_reminders (
+		chat_id INTEGER PRIMARY KEY,
+		sent_at DATETIME,                    -- Последнее напоминание (NULL - еще не было)
+		disabled INTEGER NOT NULL DEFAULT 0  -- 1 - покупатель нажал "Не напоминать"
+	);
+	`
+	_, err := db.Exec(query)
+	return err
+}
+
+// AddToCart - добавляет товар в корзину или увеличивает его количество
+func (r *CartSqlite) AddToCart(chatID, productID int64, quantity int) error {
+	query := `
+	INSERT INTO cart_items (chat_id, product_id, quantity) VALUES (?, ?, ?)
+	ON CONFLICT (chat_id, product_id) DO UPDATE SET
+		quantity = quantity + excluded.quantity,
+		updated_at = CURRENT_TIMESTAMP`
+	if _, err := r.db.Exec(query, chatID, productID, quantity); err != nil {
+		return fmt.Errorf("failed to add to cart: %w", err)
+	}
+	return nil
+}
+
+// SetCartQuantity - устанавливает количество товара. Ноль и меньше убирает товар из корзины.
+func (r *CartSqlite) SetCartQuantity(chatID, productID int64, quantity int) error {
+	var err error
+	if quantity <= 0 {
+		_, err = r.db.Exec(`DELETE FROM cart_items WHERE chat_id = ? AND product_id = ?`, chatID, productID)
+	} else {
+		_, err = r.db.Exec(`UPDATE cart_items SET quantity = ?, updated_at = CURRENT_TIMESTAMP WHERE chat_id = ? AND product_id = ?`,
+			quantity, chatID, productID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update cart: %w", err)
+	}
+	return nil
+}
+
+// GetCart - содержимое корзины вместе с актуальными данными товаров.
+// Товары, удаленные из каталога, в корзину не попадают.
+func (r *CartSqlite) GetCart(chatID int64) ([]domain.CartLine, error) {
+	query := `
+	SELECT p.id, p.sku, p.type, p.name, p.description, p.price_minor, p.currency, p.image_id, p.weight_grams, p.stock, c.quantity
+	FROM cart_items c
+	JOIN products p ON p.id = c.product_id
+	WHERE c.chat_id = ?
+	ORDER BY c.rowid`
+
+	rows, err := r.db.Query(query, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart: %w", err)
+	}
+	defer rows.Close()
+
+	var lines []domain.CartLine
+	for rows.Next() {
+		var line domain.CartLine
+		p := &line.Product
+		if err := rows.Scan(&p.ID, &p.SKU, &p.Type, &p.Name, &p.Description, &p.Price.Amount, &p.Price.Currency, &p.ImageID, &p.Weight, &p.Stock, &line.Quantity); err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
+
+// ClearCart - очищает корзину покупателя
+func (r *CartSqlite) ClearCart(chatID int64) error {
+	if _, err := r.db.Exec(`DELETE FROM cart_items WHERE chat_id = ?`, chatID); err != nil {
+		return fmt.Errorf("failed to clear cart: %w", err)
+	}
+	return nil
+}
+
+// GetAbandonedCarts - корзины для напоминания, давно брошенные первыми.
+// Время в cart_items пишется через CURRENT_TIMESTAMP (UTC без зоны), поэтому границы передаем в том же виде.
+func (r *CartSqlite) GetAbandonedCarts(idleSince, remindedSince time.Time, limit int) ([]domain.AbandonedCart, error) {
+	query := `
+	SELECT c.chat_id, SUM(c.quantity), MAX(c.updated_at)
+	FROM cart_items c
+	JOIN products p ON p.id = c.product_id
+	LEFT JOIN cart_reminders r ON r.chat_id = c.chat_id
+	WHERE COALESCE(r.disabled, 0) = 0
+	GROUP BY c.chat_id
+	HAVING MAX(c.updated_at) < ?
+		AND (MAX(r.sent_at) IS NULL OR (MAX(r.sent_at) < MAX(c.updated_at) AND MAX(r.sent_at) < ?))
+	ORDER BY MAX(c.updated_at)
+	LIMIT ?`
+
+	rows, err := r.db.Query(query, idleSince.UTC().Format(time.DateTime), remindedSince.UTC().Format(time.DateTime), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get abandoned carts: %w", err)
+	}
+	defer rows.Close()
+
+	var carts []domain.AbandonedCart
+	for rows.Next() {
+		var cart domain.AbandonedCart
+		var updatedAt string
+		if err := rows.Scan(&cart.ChatID, &cart.Items, &updatedAt); err != nil {
+			return nil, err
+		}
+		// MAX() теряет тип колонки, поэтому дату разбираем сами
+		if cart.UpdatedAt, err = time.Parse(time.DateTime, updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to parse cart time %q: %w", updatedAt, err)
+		}
+		carts = append(carts, cart)
+	}
+	return carts, rows.Err()
+}
+
+// MarkCartReminded - запоминает время напоминания
+func (r *CartSqlite) MarkCartReminded(chatID int64) error {
+	query := `INSERT INTO cart_reminders (chat_id, sent_at) VALUES (?, CURRENT_TIMESTAMP)
+		ON CONFLICT (chat_id) DO UPDATE SET sent_at = excluded.sent_at`
+	if _, err := r.db.Exec(query, chatID); err != nil {
+		return fmt.Errorf("failed to mark cart reminded: %w", err)
+	}
+	return nil
+}
+
+// SetCartReminders - включает или выключает напоминания о корзине
+func (r *CartSqlite) SetCartReminders(chatID int64, enabled bool) error {
+	query := `INSERT INTO cart_reminders (chat_id, disabled) VALUES (?, ?)
+		ON CONFLICT (chat_id) DO UPDATE SET disabled = excluded.disabled`
+	if _, err := r.db.Exec(query, chatID, !enabled); err != nil {
+		return fmt.Errorf("failed to set cart reminders: %w", err)
+	}
+	return nil
+}
+
+// CartRemindersEnabled - включены ли напоминания. Пока покупатель не отказался, включены.
+func (r *CartSqlite) CartRemindersEnabled(chatID int64) (bool, error) {
+	var disabled bool
+	err := r.db.QueryRow(`SELECT disabled FROM cart_reminders WHERE chat_id = ?`, chatID).Scan(&disabled)
+	if err == sql.ErrNoRows {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get cart reminders setting: %w", err)
+	}
+	return !disabled, nil
+}
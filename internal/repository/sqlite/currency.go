@@ -0,0 +1,62 @@
+// currency.go - валюта сумм в базе. Все суммы считаются в одной валюте магазина (CURRENCY):
+// сложить рубли с долларами нельзя, поэтому при запуске проверяем, что в базе нет сумм в другой валюте.
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// legacyCurrencyColumn - колонка валюты, добавляемая в старые таблицы. Старые строки получают
+// пустую валюту, ее заполняет CheckCurrency валютой магазина.
+const legacyCurrencyColumn = "TEXT NOT NULL DEFAULT ''"
+
+// currencyTables - таблицы, в которых у сумм хранится валюта
+var currencyTables = []string{"products", "orders", "delivery_methods", "product_subscriptions"}
+
+// CheckCurrency - строкам без валюты (из баз до появления колонки) проставляет currency,
+// а если в базе уже есть суммы в другой валюте, возвращает ошибку: смена CURRENCY на рабочей
+// базе требует пересчета цен, и без него расчет корзины сложил бы суммы в разных валютах.
+// Вызывается после создания таблиц репозиториями.
+func CheckCurrency(db *sql.DB, currency string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	found := make(map[string][]string)
+	for _, table := range currencyTables {
+		if _, err := tx.Exec(`UPDATE `+table+` SET currency = ? WHERE currency = ''`, currency); err != nil {
+			return fmt.Errorf("failed to fill currency of %s: %w", table, err)
+		}
+		rows, err := tx.Query(`SELECT DISTINCT currency FROM `+table+` WHERE currency != ?`, currency)
+		if err != nil {
+			return fmt.Errorf("failed to check currency of %s: %w", table, err)
+		}
+		for rows.Next() {
+			var other string
+			if err := rows.Scan(&other); err != nil {
+				rows.Close()
+				return err
+			}
+			found[other] = append(found[other], table)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+	if len(found) > 0 {
+		others := make([]string, 0, len(found))
+		for other, tables := range found {
+			others = append(others, fmt.Sprintf("%s (%s)", other, strings.Join(tables, ", ")))
+		}
+		sort.Strings(others)
+		return fmt.Errorf("в базе есть суммы в валюте %s, а валюта магазина (CURRENCY) - %s: верните прежнюю валюту или пересчитайте цены в базе",
+			strings.Join(others, ", "), currency)
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,54 @@
+package sqlite
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestCheckCurrency - строки старых баз без валюты получают валюту магазина,
+// а суммы в другой валюте не дают запуститься (иначе расчет корзины упал бы с паникой)
+func TestCheckCurrency(t *testing.T) {
+	tests := []struct {
+		name     string
+		rows     []string // валюты товаров в базе ("" - строка из старой базы)
+		currency string
+		wantErr  bool
+	}{
+		{name: "пустая база", currency: "USD"},
+		{name: "та же валюта", rows: []string{"RUB", "RUB"}, currency: "RUB"},
+		{name: "старые строки без валюты", rows: []string{"", "USD"}, currency: "USD"},
+		{name: "сменили валюту на рабочей базе", rows: []string{"RUB"}, currency: "USD", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			NewProductSqlite(db)
+			NewOrderSqlite(db)
+			NewDeliverySqlite(db)
+			NewSubscriptionSqlite(db)
+			for _, currency := range tt.rows {
+				if _, err := db.Exec(`INSERT INTO products (name, price_minor, currency) VALUES ('Aqua', 100, ?)`, currency); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := CheckCurrency(db, tt.currency)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "RUB (products)") {
+					t.Fatalf("ожидалась ошибка о товарах в RUB, получено %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var others int
+			if err := db.QueryRow(`SELECT COUNT(*) FROM products WHERE currency != ?`, tt.currency).Scan(&others); err != nil {
+				t.Fatal(err)
+			}
+			if others != 0 {
+				t.Fatalf("осталось %d товаров не в валюте %s", others, tt.currency)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+// delivery.go - Реализация интерфейса DeliveryRepository для SQLite.
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"salle_parfume/internal/domain"
+	"salle_parfume/internal/repository"
+)
+
+// DeliverySqlite - репозиторий способов доставки.
+type DeliverySqlite struct {
+	db *sql.DB
+}
+
+// NewDeliverySqlite - создает репозиторий способов доставки и таблицу для него.
+func NewDeliverySqlite(db *sql.DB) repository.DeliveryRepository {
+	if err := createDeliveryTable(db); err != nil {
+		fmt.Printf("Error creating delivery table: %v\n", err)
+	}
+	return &DeliverySqlite{db: db}
+}
+
+// createDeliveryTable - SQL запрос для создания таблицы способов доставки.
+// Цены в копейках, валюта одна на способ.
+func createDeliveryTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS delivery_methods (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		kind TEXT NOT NULL,                          -- pickup, courier
+		name TEXT NOT NULL,
+		address TEXT NOT NULL DEFAULT '',            -- Адрес пункта самовывоза или описание зоны
+		fee_minor INTEGER NOT NULL DEFAULT 0,        -- Базовая цена
+		per_kg_minor INTEGER NOT NULL DEFAULT 0,     -- Доплата за каждый начатый килограмм
+		free_from_minor INTEGER NOT NULL DEFAULT 0,  -- Бесплатно от этой суммы (0 - всегда платно)
+		currency TEXT NOT NULL,
+		active INTEGER NOT NULL DEFAULT 1
+	);
+	`
+	_, err := db.Exec(query)
+	return err
+}
+
+// deliveryColumns - общий список колонок способа доставки для SELECT
+const deliveryColumns = `id, kind, name, address, fee_minor, per_kg_minor, free_from_minor, currency, active`
+
+// scanDeliveryMethod - сканирует строку в структуру способа доставки
+func scanDeliveryMethod(row interface{ Scan(...any) error }) (domain.DeliveryMethod, error) {
+	var m domain.DeliveryMethod
+	var currency string
+	err := row.Scan(&m.ID, &m.Kind, &m.Name, &m.Address, &m.Fee.Amount, &m.PerKg.Amount, &m.FreeFrom.Amount, &currency, &m.Active)
+	m.Fee.Currency, m.PerKg.Currency, m.FreeFrom.Currency = currency, currency, currency
+	return m, err
+}
+
+// CreateDeliveryMethod - сохраняет новый способ доставки
+func (r *DeliverySqlite) CreateDeliveryMethod(method *domain.DeliveryMethod) error {
+	query := `INSERT INTO delivery_methods (kind, name, address, fee_minor, per_kg_minor, free_from_minor, currency, active) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	res, err := r.db.Exec(query, method.Kind, method.Name, method.Address, method.Fee.Amount, method.PerKg.Amount, method.FreeFrom.Amount,
+		method.Fee.Currency, method.Active)
+	if err != nil {
+		return fmt.Errorf("failed to create delivery method: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get delivery method id: %w", err)
+	}
+	method.ID = id
+	return nil
+}
+
+// UpdateDeliveryMethod - обновляет все поля способа доставки по ID
+func (r *DeliverySqlite) UpdateDeliveryMethod(method *domain.DeliveryMethod) error {
+	query := `UPDATE delivery_methods SET kind = ?, name = ?, address = ?, fee_minor = ?, per_kg_minor = ?, free_from_minor = ?, currency = ?, active = ? WHERE id = ?`
+	_, err := r.db.Exec(query, method.Kind, method.Name, method.Address, method.Fee.Amount, method.PerKg.Amount, method.FreeFrom.Amount,
+		method.Fee.Currency, method.Active, method.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update delivery method: %w", err)
+	}
+	return nil
+}
+
+// GetDeliveryMethodByID - способ доставки по ID. Если его нет, возвращает nil без ошибки.
+func (r *DeliverySqlite) GetDeliveryMethodByID(id int64) (*domain.DeliveryMethod, error) {
+	m, err := scanDeliveryMethod(r.db.QueryRow(`SELECT `+deliveryColumns+` FROM delivery_methods WHERE id = ?`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get delivery method: %w", err)
+	}
+	return &m, nil
+}
+
+// GetDeliveryMethods - способы доставки по порядку добавления
+func (r *DeliverySqlite) GetDeliveryMethods(activeOnly bool) ([]domain.DeliveryMethod, error) {
+	query := `SELECT ` + deliveryColumns + ` FROM delivery_methods`
+	if activeOnly {
+		query += ` WHERE active = 1`
+	}
+	rows, err := r.db.Query(query + ` ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get delivery methods: %w", err)
+	}
+	defer rows.Close()
+
+	var methods []domain.DeliveryMethod
+	for rows.Next() {
+		m, err := scanDeliveryMethod(rows)
+		if err != nil {
+			return nil, err
+		}
+		methods = append(methods, m)
+	}
+	return methods, rows.Err()
+}
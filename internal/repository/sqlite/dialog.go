@@ -0,0 +1,101 @@
+// dialog.go - Реализация интерфейса DialogRepository для SQLite.
+// Ответы хранятся одной JSON-строкой: у каждого диалога свой набор шагов.
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"salle_parfume/internal/domain"
+	"salle_parfume/internal/repository"
+	"time"
+)
+
+// DialogSqlite - сессии диалогов бота.
+type DialogSqlite struct {
+	db *sql.DB
+}
+
+// NewDialogSqlite - создает репозиторий сессий диалогов и таблицу для него.
+func NewDialogSqlite(db *sql.DB) repository.DialogRepository {
+	if err := createDialogSessionsTable(db); err != nil {
+		fmt.Printf("Error creating dialog sessions table: %v\n", err)
+	}
+	return &DialogSqlite{db: db}
+}
+
+// createDialogSessionsTable - SQL запрос для создания таблицы сессий.
+// У пользователя не больше одного незаконченного диалога.
+func createDialogSessionsTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS dialog_sessions (
+		chat_id INTEGER PRIMARY KEY,
+		dialog TEXT NOT NULL,
+		step INTEGER NOT NULL DEFAULT 0,
+		answers TEXT NOT NULL DEFAULT '{}',       -- JSON: ключ шага -> ответы
+		confirming INTEGER NOT NULL DEFAULT 0,    -- 1 - все шаги пройдены, ждем подтверждения
+		media_group_id TEXT NOT NULL DEFAULT '',
+		updated_at DATETIME NOT NULL
+	);
+	`
+	_, err := db.Exec(query)
+	return err
+}
+
+// GetDialogSession - незаконченный диалог пользователя
+func (r *DialogSqlite) GetDialogSession(chatID int64) (*domain.DialogSession, error) {
+	session := &domain.DialogSession{ChatID: chatID}
+	var answers string
+	err := r.db.QueryRow(`SELECT dialog, step, answers, confirming, media_group_id, updated_at FROM dialog_sessions WHERE chat_id = ?`, chatID).
+		Scan(&session.Dialog, &session.Step, &answers, &session.Confirming, &session.MediaGroupID, &session.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dialog session: %w", err)
+	}
+	if err := json.Unmarshal([]byte(answers), &session.Values); err != nil {
+		return nil, fmt.Errorf("failed to decode dialog answers: %w", err)
+	}
+	if session.Values == nil {
+		session.Values = make(map[string][]string)
+	}
+	return session, nil
+}
+
+// SaveDialogSession - сохраняет шаг и ответы
+func (r *DialogSqlite) SaveDialogSession(session *domain.DialogSession) error {
+	answers, err := json.Marshal(session.Values)
+	if err != nil {
+		return fmt.Errorf("failed to encode dialog answers: %w", err)
+	}
+	session.UpdatedAt = session.UpdatedAt.UTC().Truncate(time.Second)
+	query := `
+	INSERT INTO dialog_sessions (chat_id, dialog, step, answers, confirming, media_group_id, updated_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT (chat_id) DO UPDATE SET dialog = excluded.dialog, step = excluded.step, answers = excluded.answers,
+		confirming = excluded.confirming, media_group_id = excluded.media_group_id, updated_at = excluded.updated_at`
+	_, err = r.db.Exec(query, session.ChatID, session.Dialog, session.Step, string(answers), session.Confirming, session.MediaGroupID, session.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save dialog session: %w", err)
+	}
+	return nil
+}
+
+// DeleteDialogSession - удаляет сессию
+func (r *DialogSqlite) DeleteDialogSession(chatID int64) error {
+	if _, err := r.db.Exec(`DELETE FROM dialog_sessions WHERE chat_id = ?`, chatID); err != nil {
+		return fmt.Errorf("failed to delete dialog session: %w", err)
+	}
+	return nil
+}
+
+// CountDialogSessions - сколько диалогов с ответами не раньше since
+func (r *DialogSqlite) CountDialogSessions(since time.Time) (int, error) {
+	var n int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM dialog_sessions WHERE updated_at >= ?`, since.UTC()).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count dialog sessions: %w", err)
+	}
+	return n, nil
+}
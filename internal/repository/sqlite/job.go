@@ -0,0 +1,147 @@
+// job.go - Реализация интерфейса JobRepository для SQLite.
+// Блокировка - строка в job_locks со сроком действия: если экземпляр бота упал,
+// не отпустив ее, через срок задачу подхватит другой экземпляр.
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"salle_parfume/internal/domain"
+	"salle_parfume/internal/repository"
+	"time"
+)
+
+// JobSqlite - история и блокировки фоновых задач.
+type JobSqlite struct {
+	db *sql.DB
+}
+
+// NewJobSqlite - создает репозиторий задач и таблицы для него.
+func NewJobSqlite(db *sql.DB) repository.JobRepository {
+	if err := createJobTables(db); err != nil {
+		fmt.Printf("Error creating job tables: %v\n", err)
+	}
+	return &JobSqlite{db: db}
+}
+
+// createJobTables - SQL запрос для создания таблиц запусков и блокировок
+func createJobTables(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS job_runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		job TEXT NOT NULL,
+		owner TEXT NOT NULL DEFAULT '',   -- Хост и PID экземпляра бота
+		status TEXT NOT NULL,             -- running, ok, failed
+		details TEXT NOT NULL DEFAULT '',
+		error TEXT NOT NULL DEFAULT '',
+		started_at DATETIME NOT NULL,
+		finished_at DATETIME              -- NULL, пока задача выполняется
+	);
+	CREATE INDEX IF NOT EXISTS idx_job_runs_job ON job_runs(job, id);
+
+	CREATE TABLE IF NOT EXISTS job_locks (
+		job TEXT PRIMARY KEY,
+		owner TEXT NOT NULL,
+		expires_at INTEGER NOT NULL       -- Unix-время, после которого блокировка не действует
+	);
+	`
+	_, err := db.Exec(query)
+	return err
+}
+
+// AcquireJobLock - берет блокировку, если ее нет, она просрочена или уже принадлежит owner.
+// Все делается одним запросом, поэтому два экземпляра не возьмут ее одновременно.
+func (r *JobSqlite) AcquireJobLock(job, owner string, until time.Time) (bool, error) {
+	query := `
+	INSERT INTO job_locks (job, owner, expires_at) VALUES (?, ?, ?)
+	ON CONFLICT (job) DO UPDATE SET owner = excluded.owner, expires_at = excluded.expires_at
+	WHERE job_locks.expires_at < ? OR job_locks.owner = excluded.owner`
+	res, err := r.db.Exec(query, job, owner, until.Unix(), time.Now().Unix())
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire job lock: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire job lock: %w", err)
+	}
+	return n > 0, nil
+}
+
+// ReleaseJobLock - отпускает блокировку, только если она наша
+func (r *JobSqlite) ReleaseJobLock(job, owner string) error {
+	if _, err := r.db.Exec(`DELETE FROM job_locks WHERE job = ? AND owner = ?`, job, owner); err != nil {
+		return fmt.Errorf("failed to release job lock: %w", err)
+	}
+	return nil
+}
+
+// CreateJobRun - сохраняет начало запуска
+func (r *JobSqlite) CreateJobRun(run *domain.JobRun) error {
+	run.StartedAt = run.StartedAt.UTC().Truncate(time.Second)
+	res, err := r.db.Exec(`INSERT INTO job_runs (job, owner, status, details, error, started_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		run.Job, run.Owner, run.Status, run.Details, run.Error, run.StartedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create job run: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get job run id: %w", err)
+	}
+	run.ID = id
+	return nil
+}
+
+// FinishJobRun - сохраняет статус, итог и время окончания запуска
+func (r *JobSqlite) FinishJobRun(run *domain.JobRun) error {
+	run.FinishedAt = run.FinishedAt.UTC().Truncate(time.Second)
+	_, err := r.db.Exec(`UPDATE job_runs SET status = ?, details = ?, error = ?, finished_at = ? WHERE id = ?`,
+		run.Status, run.Details, run.Error, run.FinishedAt, run.ID)
+	if err != nil {
+		return fmt.Errorf("failed to finish job run: %w", err)
+	}
+	return nil
+}
+
+// jobRunColumns - колонки запуска в порядке scanJobRun
+const jobRunColumns = `id, job, owner, status, details, error, started_at, finished_at`
+
+// scanJobRun - читает запуск из строки результата
+func scanJobRun(row interface{ Scan(...any) error }) (domain.JobRun, error) {
+	var run domain.JobRun
+	var finishedAt sql.NullTime
+	err := row.Scan(&run.ID, &run.Job, &run.Owner, &run.Status, &run.Details, &run.Error, &run.StartedAt, &finishedAt)
+	run.FinishedAt = finishedAt.Time
+	return run, err
+}
+
+// GetLastJobRun - последний запуск задачи
+func (r *JobSqlite) GetLastJobRun(job string) (*domain.JobRun, error) {
+	row := r.db.QueryRow(`SELECT `+jobRunColumns+` FROM job_runs WHERE job = ? ORDER BY id DESC LIMIT 1`, job)
+	run, err := scanJobRun(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last job run: %w", err)
+	}
+	return &run, nil
+}
+
+// GetJobRuns - запуски всех задач постранично, новые первыми
+func (r *JobSqlite) GetJobRuns(limit, offset int) ([]domain.JobRun, error) {
+	rows, err := r.db.Query(`SELECT `+jobRunColumns+` FROM job_runs ORDER BY id DESC LIMIT ? OFFSET ?`, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []domain.JobRun
+	for rows.Next() {
+		run, err := scanJobRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
@@ -0,0 +1,329 @@
+// loyalty.go - Реализация интерфейса LoyaltyRepository для SQLite.
+// Операции с бонусами хранятся в loyalty_entries, а их проводки по счетам - в loyalty_postings
+// (по две на операцию: счет покупателя и счет магазина, в сумме ноль).
+// Начисления расходуются по очереди, от старых к новым: в remaining хранится, сколько от
+// начисления еще осталось, поэтому сгорают именно те бонусы, которые дольше всех не тратили.
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"salle_parfume/internal/domain"
+	"salle_parfume/internal/repository"
+	"time"
+)
+
+// LoyaltySqlite - репозиторий бонусов.
+type LoyaltySqlite struct {
+	db *sql.DB
+}
+
+// NewLoyaltySqlite - создает репозиторий бонусов и таблицы для него.
+func NewLoyaltySqlite(db *sql.DB) repository.LoyaltyRepository {
+	if err := createLoyaltyTables(db); err != nil {
+		fmt.Printf("Error creating loyalty tables: %v\n", err)
+	}
+	return &LoyaltySqlite{db: db}
+}
+
+// createLoyaltyTables - SQL запрос для создания таблиц операций и проводок
+func createLoyaltyTables(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS loyalty_entries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		chat_id INTEGER NOT NULL,
+		kind TEXT NOT NULL,                    -- earn, spend, refund, revoke, expire, adjust
+		points INTEGER NOT NULL,               -- Изменение баланса покупателя
+		remaining INTEGER NOT NULL DEFAULT 0,  -- Для начислений: сколько еще не потрачено и не сгорело
+		order_id INTEGER NOT NULL DEFAULT 0,
+		reason TEXT NOT NULL DEFAULT '',
+		actor TEXT NOT NULL DEFAULT '',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE TABLE IF NOT EXISTS loyalty_postings (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		entry_id INTEGER NOT NULL REFERENCES loyalty_entries(id),
+		account TEXT NOT NULL,                 -- customer:<chat_id> или shop:...
+		points INTEGER NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_loyalty_entries_chat ON loyalty_entries(chat_id, remaining);
+	CREATE INDEX IF NOT EXISTS idx_loyalty_entries_order ON loyalty_entries(order_id);
+	CREATE INDEX IF NOT EXISTS idx_loyalty_postings_account ON loyalty_postings(account);
+	`
+	_, err := db.Exec(query)
+	return err
+}
+
+// addLoyaltyEntry - сохраняет операцию и две ее проводки. Начисление сразу доступно к расходу целиком.
+// Списания сначала должны забрать бонусы из начислений (consumePoints).
+func addLoyaltyEntry(tx *sql.Tx, entry *domain.LoyaltyEntry) error {
+	var remaining int64
+	if entry.Points > 0 {
+		remaining = entry.Points
+	}
+	query := `INSERT INTO loyalty_entries (chat_id, kind, points, remaining, order_id, reason, actor) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	res, err := tx.Exec(query, entry.ChatID, entry.Kind, entry.Points, remaining, entry.OrderID, entry.Reason, entry.Actor)
+	if err != nil {
+		return fmt.Errorf("failed to create loyalty entry: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get loyalty entry id: %w", err)
+	}
+
+	postings := []struct {
+		account string
+		points  int64
+	}{
+		{domain.LoyaltyCustomerAccount(entry.ChatID), entry.Points},
+		{entry.Kind.ShopAccount(), -entry.Points},
+	}
+	for _, p := range postings {
+		if _, err := tx.Exec(`INSERT INTO loyalty_postings (entry_id, account, points) VALUES (?, ?, ?)`, id, p.account, p.points); err != nil {
+			return fmt.Errorf("failed to create loyalty posting: %w", err)
+		}
+	}
+	entry.ID = id
+	return nil
+}
+
+// consumePoints - забирает бонусы из начислений покупателя, начиная со старых.
+// Если бонусов меньше, чем нужно, ничего не меняет и возвращает domain.ErrNotEnoughPoints.
+func consumePoints(tx *sql.Tx, chatID, points int64) error {
+	rows, err := tx.Query(`SELECT id, remaining FROM loyalty_entries WHERE chat_id = ? AND remaining > 0 ORDER BY id`, chatID)
+	if err != nil {
+		return fmt.Errorf("failed to get loyalty credits: %w", err)
+	}
+	type credit struct{ id, remaining int64 }
+	var credits []credit
+	var total int64
+	for rows.Next() {
+		var c credit
+		if err := rows.Scan(&c.id, &c.remaining); err != nil {
+			rows.Close()
+			return err
+		}
+		credits = append(credits, c)
+		total += c.remaining
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if total < points {
+		return domain.ErrNotEnoughPoints
+	}
+
+	for _, c := range credits {
+		if points == 0 {
+			break
+		}
+		take := min(c.remaining, points)
+		if _, err := tx.Exec(`UPDATE loyalty_entries SET remaining = remaining - ? WHERE id = ?`, take, c.id); err != nil {
+			return fmt.Errorf("failed to consume loyalty credit: %w", err)
+		}
+		points -= take
+	}
+	return nil
+}
+
+// spendOrderPoints - списывает бонусы в счет нового заказа
+func spendOrderPoints(tx *sql.Tx, orderID int64, order *domain.Order) error {
+	if order.PointsSpent <= 0 {
+		return nil
+	}
+	if err := consumePoints(tx, order.ChatID, order.PointsSpent); err != nil {
+		return err
+	}
+	return addLoyaltyEntry(tx, &domain.LoyaltyEntry{ChatID: order.ChatID, Kind: domain.LoyaltySpend, Points: -order.PointsSpent, OrderID: orderID})
+}
+
+// earnOrderPoints - начисляет бонусы за оплаченный заказ. Повторная оплата или получение
+// того же заказа второй раз не начисляет.
+func earnOrderPoints(tx *sql.Tx, orderID int64) error {
+	var chatID, points int64
+	err := tx.QueryRow(`SELECT chat_id, points_earned FROM orders WHERE id = ?`, orderID).Scan(&chatID, &points)
+	if err != nil {
+		return fmt.Errorf("failed to get order points: %w", err)
+	}
+	if points <= 0 {
+		return nil
+	}
+	var earned bool
+	err = tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM loyalty_entries WHERE order_id = ? AND kind = ?)`, orderID, domain.LoyaltyEarn).Scan(&earned)
+	if err != nil {
+		return fmt.Errorf("failed to check order points: %w", err)
+	}
+	if earned {
+		return nil
+	}
+	return addLoyaltyEntry(tx, &domain.LoyaltyEntry{ChatID: chatID, Kind: domain.LoyaltyEarn, Points: points, OrderID: orderID})
+}
+
+// cancelOrderPoints - при отмене заказа возвращает списанные бонусы и забирает начисленные.
+// Начисленные забираем только в той части, что покупатель еще не потратил: в минус баланс не уходит.
+// Повторный вызов для того же заказа ничего не меняет: возврат делается один раз, а забирать уже нечего.
+func cancelOrderPoints(tx *sql.Tx, orderID int64) error {
+	var chatID, spent int64
+	err := tx.QueryRow(`SELECT chat_id, points_spent FROM orders WHERE id = ?`, orderID).Scan(&chatID, &spent)
+	if err != nil {
+		return fmt.Errorf("failed to get order points: %w", err)
+	}
+	if spent > 0 {
+		var refunded bool
+		err = tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM loyalty_entries WHERE order_id = ? AND kind = ?)`, orderID, domain.LoyaltyRefund).Scan(&refunded)
+		if err != nil {
+			return fmt.Errorf("failed to check order refund: %w", err)
+		}
+		if !refunded {
+			if err := addLoyaltyEntry(tx, &domain.LoyaltyEntry{ChatID: chatID, Kind: domain.LoyaltyRefund, Points: spent, OrderID: orderID}); err != nil {
+				return err
+			}
+		}
+	}
+
+	var earnID, remaining int64
+	err = tx.QueryRow(`SELECT id, remaining FROM loyalty_entries WHERE order_id = ? AND kind = ?`, orderID, domain.LoyaltyEarn).Scan(&earnID, &remaining)
+	if err == sql.ErrNoRows || (err == nil && remaining == 0) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get order points: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE loyalty_entries SET remaining = 0 WHERE id = ?`, earnID); err != nil {
+		return fmt.Errorf("failed to revoke order points: %w", err)
+	}
+	return addLoyaltyEntry(tx, &domain.LoyaltyEntry{ChatID: chatID, Kind: domain.LoyaltyRevoke, Points: -remaining, OrderID: orderID})
+}
+
+// GetLoyaltyBalance - баланс бонусов покупателя (сумма проводок по его счету)
+func (r *LoyaltySqlite) GetLoyaltyBalance(chatID int64) (int64, error) {
+	var balance int64
+	query := `SELECT COALESCE(SUM(points), 0) FROM loyalty_postings WHERE account = ?`
+	if err := r.db.QueryRow(query, domain.LoyaltyCustomerAccount(chatID)).Scan(&balance); err != nil {
+		return 0, fmt.Errorf("failed to get loyalty balance: %w", err)
+	}
+	return balance, nil
+}
+
+// GetOldestPoints - остаток самого старого начисления, которое еще не потрачено, и когда оно было.
+// Если неистраченных начислений нет, возвращает ноль.
+func (r *LoyaltySqlite) GetOldestPoints(chatID int64) (int64, time.Time, error) {
+	var points int64
+	var createdAt time.Time
+	query := `SELECT remaining, created_at FROM loyalty_entries WHERE chat_id = ? AND remaining > 0 ORDER BY id LIMIT 1`
+	err := r.db.QueryRow(query, chatID).Scan(&points, &createdAt)
+	if err == sql.ErrNoRows {
+		return 0, time.Time{}, nil
+	}
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to get oldest loyalty credit: %w", err)
+	}
+	return points, createdAt, nil
+}
+
+// GetLoyaltyHistory - операции покупателя постранично, новые первыми
+func (r *LoyaltySqlite) GetLoyaltyHistory(chatID int64, limit, offset int) ([]domain.LoyaltyEntry, error) {
+	query := `SELECT id, chat_id, kind, points, order_id, reason, actor, created_at FROM loyalty_entries
+		WHERE chat_id = ? ORDER BY id DESC LIMIT ? OFFSET ?`
+	rows, err := r.db.Query(query, chatID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loyalty history: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []domain.LoyaltyEntry
+	for rows.Next() {
+		var e domain.LoyaltyEntry
+		if err := rows.Scan(&e.ID, &e.ChatID, &e.Kind, &e.Points, &e.OrderID, &e.Reason, &e.Actor, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// AdjustPoints - ручная корректировка. Списать больше, чем есть на счете, нельзя (domain.ErrNotEnoughPoints).
+func (r *LoyaltySqlite) AdjustPoints(entry *domain.LoyaltyEntry) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	entry.Kind = domain.LoyaltyAdjust
+	if entry.Points < 0 {
+		if err := consumePoints(tx, entry.ChatID, -entry.Points); err != nil {
+			return err
+		}
+	}
+	if err := addLoyaltyEntry(tx, entry); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ExpirePoints - сжигает остатки начислений, сделанных раньше before. Возвращает,
+// сколько начислений сгорело. За раз обрабатывается не больше limit начислений.
+// Время в loyalty_entries пишется через CURRENT_TIMESTAMP (UTC без зоны), поэтому границу передаем в том же виде.
+func (r *LoyaltySqlite) ExpirePoints(before time.Time, limit int) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `SELECT id, chat_id, remaining FROM loyalty_entries WHERE remaining > 0 AND created_at < ? ORDER BY id LIMIT ?`
+	rows, err := tx.Query(query, before.UTC().Format(time.DateTime), limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get expired loyalty credits: %w", err)
+	}
+	type credit struct{ id, chatID, remaining int64 }
+	var credits []credit
+	for rows.Next() {
+		var c credit
+		if err := rows.Scan(&c.id, &c.chatID, &c.remaining); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		credits = append(credits, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, c := range credits {
+		if _, err := tx.Exec(`UPDATE loyalty_entries SET remaining = 0 WHERE id = ?`, c.id); err != nil {
+			return 0, fmt.Errorf("failed to expire loyalty credit: %w", err)
+		}
+		if err := addLoyaltyEntry(tx, &domain.LoyaltyEntry{ChatID: c.chatID, Kind: domain.LoyaltyExpire, Points: -c.remaining}); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit expired points: %w", err)
+	}
+	return len(credits), nil
+}
+
+// GetLoyaltyTotals - обороты по счетам магазина: сколько бонусов выдано кешбэком,
+// потрачено, сгорело и начислено вручную (со знаком счета магазина: выдача - минус).
+func (r *LoyaltySqlite) GetLoyaltyTotals() (map[string]int64, error) {
+	rows, err := r.db.Query(`SELECT account, SUM(points) FROM loyalty_postings WHERE account LIKE 'shop:%' GROUP BY account`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loyalty totals: %w", err)
+	}
+	defer rows.Close()
+
+	totals := make(map[string]int64)
+	for rows.Next() {
+		var account string
+		var points int64
+		if err := rows.Scan(&account, &points); err != nil {
+			return nil, err
+		}
+		totals[account] = points
+	}
+	return totals, rows.Err()
+}
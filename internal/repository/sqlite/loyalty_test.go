@@ -0,0 +1,104 @@
+package sqlite
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"salle_parfume/internal/domain"
+)
+
+// TestLoyaltyLedger - начисление, списание, возврат, отмена начисления и сгорание бонусов
+// на одном покупателе. После каждого шага проверяем баланс, в конце - что проводки сходятся.
+func TestLoyaltyLedger(t *testing.T) {
+	shop := newTestShop(t, 100, 50)
+
+	wantBalance := func(step string, want int64) {
+		t.Helper()
+		if got := shop.balance(t); got != want {
+			t.Fatalf("%s: бонусов %d, ожидалось %d", step, got, want)
+		}
+	}
+
+	// 1. Оплаченный заказ приносит обещанные бонусы
+	first, err := shop.order(t, 1, 0, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := shop.orders.UpdateOrderStatus(first.ID, domain.OrderStatusPaid); err != nil {
+		t.Fatal(err)
+	}
+	wantBalance("начисление", 70)
+
+	// 2. Списание забирает сначала старые бонусы: 50 ручных и 10 из 20 за первый заказ
+	second, err := shop.order(t, 1, 60, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantBalance("списание", 10)
+
+	// 3. Больше баланса не списать ни заказом, ни вручную
+	if _, err := shop.order(t, 1, 20, 0); !errors.Is(err, domain.ErrNotEnoughPoints) {
+		t.Fatalf("заказ с лишними бонусами: ожидалась domain.ErrNotEnoughPoints, получено %v", err)
+	}
+	if err := shop.loyalty.AdjustPoints(&domain.LoyaltyEntry{ChatID: shop.chatID, Points: -11, Reason: "тест"}); !errors.Is(err, domain.ErrNotEnoughPoints) {
+		t.Fatalf("ручное списание: ожидалась domain.ErrNotEnoughPoints, получено %v", err)
+	}
+	wantBalance("отказ в списании", 10)
+
+	// 4. Отмена первого заказа забирает только неистраченные 10 из 20: в минус не уходим
+	if err := shop.orders.UpdateOrderStatus(first.ID, domain.OrderStatusCancelled); err != nil {
+		t.Fatal(err)
+	}
+	wantBalance("отмена начисления", 0)
+
+	// 5. Отмена второго возвращает списанные 60, повторный возврат по тому же заказу ничего не дает
+	if err := shop.orders.UpdateOrderStatus(second.ID, domain.OrderStatusCancelled); err != nil {
+		t.Fatal(err)
+	}
+	wantBalance("возврат", 60)
+	tx, err := shop.db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cancelOrderPoints(tx, second.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	wantBalance("повторный возврат", 60)
+
+	// 6. Сгорают только начисления старше границы
+	if n, err := shop.loyalty.ExpirePoints(time.Now().Add(-time.Hour), 100); err != nil || n != 0 {
+		t.Fatalf("сгорание свежих бонусов: %d, %v", n, err)
+	}
+	if n, err := shop.loyalty.ExpirePoints(time.Now().Add(time.Hour), 100); err != nil || n != 1 {
+		t.Fatalf("сгорело %d начислений (%v), ожидалось 1", n, err)
+	}
+	wantBalance("сгорание", 0)
+
+	// 7. Двойная запись: все проводки в сумме ноль, обороты магазина по счетам
+	var sum int64
+	if err := shop.db.QueryRow(`SELECT COALESCE(SUM(points), 0) FROM loyalty_postings`).Scan(&sum); err != nil {
+		t.Fatal(err)
+	}
+	if sum != 0 {
+		t.Errorf("сумма проводок %d, ожидался 0", sum)
+	}
+	totals, err := shop.loyalty.GetLoyaltyTotals()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int64{
+		domain.LoyaltyAccountCashback:    -10, // выдано 20, отменено 10
+		domain.LoyaltyAccountRedeemed:    0,   // списано 60 и возвращено 60
+		domain.LoyaltyAccountExpired:     60,
+		domain.LoyaltyAccountAdjustments: -50,
+	}
+	for account, points := range want {
+		if totals[account] != points {
+			t.Errorf("счет %s: %d, ожидалось %d", account, totals[account], points)
+		}
+	}
+}
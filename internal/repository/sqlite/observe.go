@@ -0,0 +1,79 @@
+// observe.go - время запросов к базе для метрик. Соединения драйвера оборачиваются так,
+// что каждый Exec и Query (и в транзакциях тоже) сообщает наблюдателю свою длительность.
+package sqlite
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"time"
+)
+
+// QueryObserver - получает длительность каждого запроса. op - select, insert, update, delete или other.
+type QueryObserver interface {
+	ObserveQuery(op string, duration time.Duration)
+}
+
+// observedConnector - открывает соединения драйвера и оборачивает их
+type observedConnector struct {
+	driver   driver.Driver
+	dsn      string
+	observer QueryObserver
+}
+
+func (c *observedConnector) Connect(context.Context) (driver.Conn, error) {
+	conn, err := c.driver.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &observedConn{Conn: conn, observer: c.observer}, nil
+}
+
+func (c *observedConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// observedConn - соединение, которое замеряет запросы. Остальное делает соединение драйвера
+// (SQLite умеет все интерфейсы ниже).
+type observedConn struct {
+	driver.Conn
+	observer QueryObserver
+}
+
+func (c *observedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	defer c.observe(query, time.Now())
+	return c.Conn.(driver.ExecerContext).ExecContext(ctx, query, args)
+}
+
+// QueryContext - время до получения первых строк (чтение остальных сюда не входит)
+func (c *observedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	defer c.observe(query, time.Now())
+	return c.Conn.(driver.QueryerContext).QueryContext(ctx, query, args)
+}
+
+func (c *observedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return c.Conn.(driver.ConnPrepareContext).PrepareContext(ctx, query)
+}
+
+func (c *observedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return c.Conn.(driver.ConnBeginTx).BeginTx(ctx, opts)
+}
+
+func (c *observedConn) Ping(ctx context.Context) error {
+	return c.Conn.(driver.Pinger).Ping(ctx)
+}
+
+// observe - сообщает длительность запроса с начала start
+func (c *observedConn) observe(query string, start time.Time) {
+	c.observer.ObserveQuery(queryOp(query), time.Since(start))
+}
+
+// queryOp - вид запроса по первому слову
+func queryOp(query string) string {
+	word, _, _ := strings.Cut(strings.TrimSpace(query), " ")
+	switch op := strings.ToLower(word); op {
+	case "select", "insert", "update", "delete":
+		return op
+	}
+	return "other"
+}
@@ -0,0 +1,421 @@
+// order.go - Реализация интерфейса OrderRepository для SQLite.
+// Заказ хранится в двух таблицах: orders (шапка) и order_items (позиции).
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"salle_parfume/internal/domain"
+	"salle_parfume/internal/repository"
+)
+
+// OrderSqlite - репозиторий заказов.
+type OrderSqlite struct {
+	db *sql.DB
+}
+
+// NewOrderSqlite - создает репозиторий заказов и таблицы для него.
+func NewOrderSqlite(db *sql.DB) repository.OrderRepository {
+	if err := createOrdersTables(db); err != nil {
+		fmt.Printf("Error creating orders tables: %v\n", err)
+	}
+	return &OrderSqlite{db: db}
+}
+
+// createOrdersTables - SQL запрос для создания таблиц заказов.
+// Все суммы хранятся в копейках (INTEGER), валюта - одна на весь заказ.
+func createOrdersTables(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS orders (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		chat_id INTEGER NOT NULL,                  -- Покупатель
+		status TEXT NOT NULL DEFAULT 'new',        -- new, paid, confirmed, shipped, delivered, cancelled
+		subtotal_minor INTEGER NOT NULL DEFAULT 0, -- Сумма без скидок
+		discount_minor INTEGER NOT NULL DEFAULT 0, -- Сумма скидок
+		total_minor INTEGER NOT NULL DEFAULT 0,    -- Итоговая сумма
+		currency TEXT NOT NULL,
+		promo_code TEXT NOT NULL DEFAULT '',
+		tracking_number TEXT NOT NULL DEFAULT '',  -- Трек-номер отправления
+		delivery_method_id INTEGER NOT NULL DEFAULT 0, -- Способ доставки (0 - не выбирался)
+		delivery_kind TEXT NOT NULL DEFAULT '',
+		delivery_name TEXT NOT NULL DEFAULT '',    -- Название способа на момент покупки
+		delivery_cost_minor INTEGER NOT NULL DEFAULT 0, -- Цена доставки (входит в total_minor)
+		delivery_address TEXT NOT NULL DEFAULT '',
+		delivery_lat REAL,                         -- Геопозиция покупателя (NULL - не присылал)
+		delivery_lon REAL,
+		paid_at DATETIME,                          -- Когда оплачен онлайн или получен (NULL - еще не оплачен)
+		points_spent INTEGER NOT NULL DEFAULT 0,   -- Списано бонусов (уже вычтены из total_minor)
+		points_earned INTEGER NOT NULL DEFAULT 0,  -- Бонусов за заказ, начисляются при оплате
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE TABLE IF NOT EXISTS order_items (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		order_id INTEGER NOT NULL REFERENCES orders(id),
+		product_id INTEGER NOT NULL,
+		name TEXT,                                 -- Название на момент покупки
+		price_minor INTEGER NOT NULL DEFAULT 0,    -- Цена за штуку на момент покупки
+		discount_minor INTEGER NOT NULL DEFAULT 0, -- Скидка на всю позицию
+		quantity INTEGER NOT NULL DEFAULT 1
+	);
+	CREATE INDEX IF NOT EXISTS idx_orders_chat_id ON orders(chat_id);
+	CREATE INDEX IF NOT EXISTS idx_order_items_order_id ON order_items(order_id);
+	`
+	if _, err := db.Exec(query); err != nil {
+		return err
+	}
+
+	// Старые базы: добавляем недостающие поля и переводим суммы из REAL в копейки
+	if err := addColumnIfMissing(db, "orders", "promo_code", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := addColumnIfMissing(db, "orders", "currency", legacyCurrencyColumn); err != nil {
+		return err
+	}
+	if err := addColumnIfMissing(db, "orders", "tracking_number", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	deliveryColumns := []struct{ name, definition string }{
+		{"delivery_method_id", "INTEGER NOT NULL DEFAULT 0"},
+		{"delivery_kind", "TEXT NOT NULL DEFAULT ''"},
+		{"delivery_name", "TEXT NOT NULL DEFAULT ''"},
+		{"delivery_cost_minor", "INTEGER NOT NULL DEFAULT 0"},
+		{"delivery_address", "TEXT NOT NULL DEFAULT ''"},
+		{"delivery_lat", "REAL"},
+		{"delivery_lon", "REAL"},
+	}
+	for _, c := range deliveryColumns {
+		if err := addColumnIfMissing(db, "orders", c.name, c.definition); err != nil {
+			return err
+		}
+	}
+	if err := addColumnIfMissing(db, "orders", "paid_at", "DATETIME"); err != nil {
+		return err
+	}
+	if err := addColumnIfMissing(db, "orders", "points_spent", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := addColumnIfMissing(db, "orders", "points_earned", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	err := migrateToMinorUnits(db, "orders", []moneyColumn{
+		{real: "subtotal", minor: "subtotal_minor"},
+		{real: "discount", minor: "discount_minor"},
+		{real: "total", minor: "total_minor"},
+	})
+	if err != nil {
+		return err
+	}
+	return migrateToMinorUnits(db, "order_items", []moneyColumn{
+		{real: "price", minor: "price_minor"},
+		{real: "discount", minor: "discount_minor"},
+	})
+}
+
+// CreateOrder - сохраняет заказ и его позиции в одной транзакции.
+// В ней же списываются остатки товаров и бонусы: если товара на складе или бонусов
+// уже не хватает (параллельный заказ успел раньше), заказ не сохраняется
+// (domain.ErrOutOfStock, domain.ErrNotEnoughPoints).
+func (r *OrderSqlite) CreateOrder(order *domain.Order) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %w", err)
+	}
+	// Если Commit прошел, Rollback ничего не сделает
+	defer tx.Rollback()
+
+	if order.Status == "" {
+		order.Status = domain.OrderStatusNew
+	}
+
+	d := order.Delivery
+	var lat, lon sql.NullFloat64
+	if d.Location != nil {
+		lat = sql.NullFloat64{Float64: d.Location.Latitude, Valid: true}
+		lon = sql.NullFloat64{Float64: d.Location.Longitude, Valid: true}
+	}
+
+	query := `INSERT INTO orders (chat_id, status, subtotal_minor, discount_minor, total_minor, currency, promo_code,
+		delivery_method_id, delivery_kind, delivery_name, delivery_cost_minor, delivery_address, delivery_lat, delivery_lon,
+		points_spent, points_earned)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	res, err := tx.Exec(query, order.ChatID, order.Status, order.Subtotal.Amount, order.Discount.Amount, order.Total.Amount,
+		order.Total.Currency, order.PromoCode, d.MethodID, d.Kind, d.Name, d.Cost.Amount, d.Address, lat, lon,
+		order.PointsSpent, order.PointsEarned)
+	if err != nil {
+		return fmt.Errorf("failed to create order: %w", err)
+	}
+	orderID, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get order id: %w", err)
+	}
+
+	for _, item := range order.Items {
+		_, err := tx.Exec(`INSERT INTO order_items (order_id, product_id, name, price_minor, quantity, discount_minor) VALUES (?, ?, ?, ?, ?, ?)`,
+			orderID, item.ProductID, item.Name, item.Price.Amount, item.Quantity, item.Discount.Amount)
+		if err != nil {
+			return fmt.Errorf("failed to create order item: %w", err)
+		}
+		if err := takeStock(tx, item.ProductID, item.Quantity); err != nil {
+			return err
+		}
+	}
+	if err := spendOrderPoints(tx, orderID, order); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit order: %w", err)
+	}
+	order.ID = orderID
+	return nil
+}
+
+// takeStock - списывает остаток товара, если он ведется. Сервис проверяет остаток при расчете
+// корзины, но до транзакции, поэтому проверяем еще раз здесь: два заказа одновременно
+// не продадут больше, чем есть (иначе отмена вернула бы на склад несуществующий товар).
+func takeStock(tx *sql.Tx, productID int64, quantity int) error {
+	res, err := tx.Exec(`UPDATE products SET stock = stock - ? WHERE id = ? AND stock IS NOT NULL AND stock >= ?`,
+		quantity, productID, quantity)
+	if err != nil {
+		return fmt.Errorf("failed to update product stock: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update product stock: %w", err)
+	}
+	if n > 0 {
+		return nil
+	}
+	// Ничего не списали: либо остаток не ведется (или товар удален), либо его не хватает
+	var tracked int
+	err = tx.QueryRow(`SELECT COUNT(*) FROM products WHERE id = ? AND stock IS NOT NULL`, productID).Scan(&tracked)
+	if err != nil {
+		return fmt.Errorf("failed to check product stock: %w", err)
+	}
+	if tracked > 0 {
+		return domain.ErrOutOfStock
+	}
+	return nil
+}
+
+// orderColumns - общий список колонок заказа для SELECT
+const orderColumns = `id, chat_id, status, subtotal_minor, discount_minor, total_minor, currency, promo_code, tracking_number, created_at,
+	delivery_method_id, delivery_kind, delivery_name, delivery_cost_minor, delivery_address, delivery_lat, delivery_lon,
+	points_spent, points_earned`
+
+// scanOrder - сканирует строку в структуру заказа (без позиций)
+func scanOrder(row interface{ Scan(...any) error }) (domain.Order, error) {
+	var o domain.Order
+	var currency string
+	var lat, lon sql.NullFloat64
+	d := &o.Delivery
+	err := row.Scan(&o.ID, &o.ChatID, &o.Status, &o.Subtotal.Amount, &o.Discount.Amount, &o.Total.Amount,
+		&currency, &o.PromoCode, &o.TrackingNumber, &o.CreatedAt,
+		&d.MethodID, &d.Kind, &d.Name, &d.Cost.Amount, &d.Address, &lat, &lon,
+		&o.PointsSpent, &o.PointsEarned)
+	o.Subtotal.Currency, o.Discount.Currency, o.Total.Currency, d.Cost.Currency = currency, currency, currency, currency
+	if lat.Valid && lon.Valid {
+		d.Location = &domain.Location{Latitude: lat.Float64, Longitude: lon.Float64}
+	}
+	return o, err
+}
+
+// GetOrderByID - возвращает заказ с позициями. Если заказа нет, возвращает nil без ошибки.
+func (r *OrderSqlite) GetOrderByID(id int64) (*domain.Order, error) {
+	o, err := scanOrder(r.db.QueryRow(`SELECT `+orderColumns+` FROM orders WHERE id = ?`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+
+	items, err := r.getOrderItems(o.ID, o.Total.Currency)
+	if err != nil {
+		return nil, err
+	}
+	o.Items = items
+	return &o, nil
+}
+
+// GetOrdersByStatus - последние заказы в статусе вместе с позициями, новые первыми
+func (r *OrderSqlite) GetOrdersByStatus(status domain.OrderStatus, limit int) ([]domain.Order, error) {
+	return r.getOrders(`SELECT `+orderColumns+` FROM orders WHERE status = ? ORDER BY id DESC LIMIT ?`, status, limit)
+}
+
+// GetUserOrders - заказы покупателя вместе с позициями постранично, новые первыми
+func (r *OrderSqlite) GetUserOrders(chatID int64, limit, offset int) ([]domain.Order, error) {
+	return r.getOrders(`SELECT `+orderColumns+` FROM orders WHERE chat_id = ? ORDER BY id DESC LIMIT ? OFFSET ?`, chatID, limit, offset)
+}
+
+// getOrders - список заказов по запросу и позиции к ним
+func (r *OrderSqlite) getOrders(query string, args ...any) ([]domain.Order, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get orders: %w", err)
+	}
+	defer rows.Close()
+
+	var orders []domain.Order
+	for rows.Next() {
+		o, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// Позиции читаем после закрытия курсора: SQLite с одним соединением не любит вложенные запросы
+	rows.Close()
+
+	for i := range orders {
+		items, err := r.getOrderItems(orders[i].ID, orders[i].Total.Currency)
+		if err != nil {
+			return nil, err
+		}
+		orders[i].Items = items
+	}
+	return orders, nil
+}
+
+// getOrderItems - позиции одного заказа (валюта у позиций та же, что у заказа)
+func (r *OrderSqlite) getOrderItems(orderID int64, currency string) ([]domain.OrderItem, error) {
+	query := `SELECT product_id, name, price_minor, quantity, discount_minor FROM order_items WHERE order_id = ? ORDER BY id`
+	rows, err := r.db.Query(query, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []domain.OrderItem
+	for rows.Next() {
+		var item domain.OrderItem
+		if err := rows.Scan(&item.ProductID, &item.Name, &item.Price.Amount, &item.Quantity, &item.Discount.Amount); err != nil {
+			return nil, err
+		}
+		item.Price.Currency, item.Discount.Currency = currency, currency
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// UpdateOrderStatus - меняет статус заказа. При отмене товары возвращаются на склад,
+// а бонусы - на счет покупателя. При оплате или получении запоминается время первой оплаты
+// и начисляются бонусы за заказ. Переход, который не разрешает CanBecome (в том числе
+// повторная отмена или оживление отмененного заказа), - domain.ErrStatusChange.
+func (r *OrderSqlite) UpdateOrderStatus(id int64, status domain.OrderStatus) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var current domain.OrderStatus
+	if err := tx.QueryRow(`SELECT status FROM orders WHERE id = ?`, id).Scan(&current); err != nil {
+		return fmt.Errorf("failed to get order status: %w", err)
+	}
+	if !current.CanBecome(status) {
+		return domain.ErrStatusChange
+	}
+
+	// Условие на прежний статус - если его только что сменил кто-то другой, переход мог стать недопустимым
+	query := `UPDATE orders SET status = ?, paid_at = CASE WHEN ? THEN COALESCE(paid_at, CURRENT_TIMESTAMP) ELSE paid_at END
+		WHERE id = ? AND status = ?`
+	res, err := tx.Exec(query, status, status.MarksPaid(), id, current)
+	if err != nil {
+		return fmt.Errorf("failed to update order status: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update order status: %w", err)
+	}
+	switch {
+	case n == 0:
+		return domain.ErrStatusChange
+	case status == domain.OrderStatusCancelled:
+		if err := returnOrderStock(tx, id); err != nil {
+			return err
+		}
+		if err := cancelOrderPoints(tx, id); err != nil {
+			return err
+		}
+	case status.MarksPaid():
+		if err := earnOrderPoints(tx, id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// CancelUserOrder - покупатель отменяет свой заказ. Проверка статуса в том же запросе,
+// чтобы не отменить заказ, который админ как раз подтверждает в веб-админке.
+func (r *OrderSqlite) CancelUserOrder(chatID, id int64) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`UPDATE orders SET status = ? WHERE id = ? AND chat_id = ? AND status = ?`,
+		domain.OrderStatusCancelled, id, chatID, domain.OrderStatusNew)
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel order: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel order: %w", err)
+	}
+	if n == 0 {
+		return false, nil
+	}
+	if err := returnOrderStock(tx, id); err != nil {
+		return false, err
+	}
+	if err := cancelOrderPoints(tx, id); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit order cancel: %w", err)
+	}
+	return true, nil
+}
+
+// returnOrderStock - возвращает на склад товары отмененного заказа (у которых ведется остаток)
+func returnOrderStock(tx *sql.Tx, orderID int64) error {
+	query := `
+	UPDATE products SET stock = stock + (
+		SELECT SUM(i.quantity) FROM order_items i WHERE i.order_id = ? AND i.product_id = products.id
+	)
+	WHERE stock IS NOT NULL AND id IN (SELECT product_id FROM order_items WHERE order_id = ?)`
+	if _, err := tx.Exec(query, orderID, orderID); err != nil {
+		return fmt.Errorf("failed to return order stock: %w", err)
+	}
+	return nil
+}
+
+// SetTrackingNumber - сохраняет трек-номер отправления
+func (r *OrderSqlite) SetTrackingNumber(id int64, tracking string) error {
+	_, err := r.db.Exec(`UPDATE orders SET tracking_number = ? WHERE id = ?`, tracking, id)
+	if err != nil {
+		return fmt.Errorf("failed to set tracking number: %w", err)
+	}
+	return nil
+}
+
+// HasDeliveredProduct - есть ли у покупателя полученный заказ с этим товаром
+func (r *OrderSqlite) HasDeliveredProduct(chatID, productID int64) (bool, error) {
+	query := `
+	SELECT EXISTS (
+		SELECT 1 FROM orders o
+		JOIN order_items i ON i.order_id = o.id
+		WHERE o.chat_id = ? AND i.product_id = ? AND o.status = ?
+	)`
+
+	var exists bool
+	if err := r.db.QueryRow(query, chatID, productID, domain.OrderStatusDelivered).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check delivered product: %w", err)
+	}
+	return exists, nil
+}
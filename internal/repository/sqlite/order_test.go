@@ -0,0 +1,158 @@
+package sqlite
+
+import (
+	"errors"
+	"testing"
+
+	"salle_parfume/internal/domain"
+)
+
+// TestUpdateOrderStatus - остатки и бонусы при смене статуса. Заказ на 3 шт. из 10,
+// списано 30 бонусов из 100, за заказ обещано 5. Недопустимые переходы (повторная отмена,
+// оживление отмененного заказа) не должны второй раз возвращать товар и бонусы.
+func TestUpdateOrderStatus(t *testing.T) {
+	type step struct {
+		status  domain.OrderStatus
+		refused bool // ожидаем domain.ErrStatusChange
+	}
+	tests := []struct {
+		name        string
+		steps       []step
+		wantStock   int
+		wantBalance int64
+		wantStatus  domain.OrderStatus
+	}{
+		{name: "только оформлен", wantStock: 7, wantBalance: 70, wantStatus: domain.OrderStatusNew},
+		{
+			name:      "отмена возвращает товар и бонусы",
+			steps:     []step{{status: domain.OrderStatusCancelled}},
+			wantStock: 10, wantBalance: 100, wantStatus: domain.OrderStatusCancelled,
+		},
+		{
+			name:      "повторная отмена ничего не возвращает",
+			steps:     []step{{status: domain.OrderStatusCancelled}, {status: domain.OrderStatusCancelled, refused: true}},
+			wantStock: 10, wantBalance: 100, wantStatus: domain.OrderStatusCancelled,
+		},
+		{
+			name: "отмененный заказ не оживить",
+			steps: []step{
+				{status: domain.OrderStatusCancelled},
+				{status: domain.OrderStatusNew, refused: true},
+				{status: domain.OrderStatusPaid, refused: true},
+				{status: domain.OrderStatusCancelled, refused: true},
+			},
+			wantStock: 10, wantBalance: 100, wantStatus: domain.OrderStatusCancelled,
+		},
+		{
+			name:      "оплата начисляет бонусы",
+			steps:     []step{{status: domain.OrderStatusPaid}},
+			wantStock: 7, wantBalance: 75, wantStatus: domain.OrderStatusPaid,
+		},
+		{
+			name:      "повторная оплата не начисляет еще раз",
+			steps:     []step{{status: domain.OrderStatusPaid}, {status: domain.OrderStatusPaid, refused: true}},
+			wantStock: 7, wantBalance: 75, wantStatus: domain.OrderStatusPaid,
+		},
+		{
+			name:      "отмена оплаченного забирает начисленное",
+			steps:     []step{{status: domain.OrderStatusPaid}, {status: domain.OrderStatusCancelled}},
+			wantStock: 10, wantBalance: 100, wantStatus: domain.OrderStatusCancelled,
+		},
+		{
+			name: "получение при оплате на месте начисляет бонусы",
+			steps: []step{
+				{status: domain.OrderStatusConfirmed},
+				{status: domain.OrderStatusShipped},
+				{status: domain.OrderStatusDelivered},
+			},
+			wantStock: 7, wantBalance: 75, wantStatus: domain.OrderStatusDelivered,
+		},
+		{
+			name: "полученный заказ не отменить",
+			steps: []step{
+				{status: domain.OrderStatusShipped},
+				{status: domain.OrderStatusDelivered},
+				{status: domain.OrderStatusCancelled, refused: true},
+			},
+			wantStock: 7, wantBalance: 75, wantStatus: domain.OrderStatusDelivered,
+		},
+		{
+			name:      "отправленный заказ не подтвердить",
+			steps:     []step{{status: domain.OrderStatusShipped}, {status: domain.OrderStatusConfirmed, refused: true}},
+			wantStock: 7, wantBalance: 70, wantStatus: domain.OrderStatusShipped,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shop := newTestShop(t, 10, 100)
+			order, err := shop.order(t, 3, 30, 5)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, st := range tt.steps {
+				err := shop.orders.UpdateOrderStatus(order.ID, st.status)
+				if st.refused != errors.Is(err, domain.ErrStatusChange) || (!st.refused && err != nil) {
+					t.Fatalf("переход в %s: ошибка %v, ожидался отказ: %v", st.status, err, st.refused)
+				}
+			}
+
+			if got := shop.stock(t); got != tt.wantStock {
+				t.Errorf("остаток %d, ожидался %d", got, tt.wantStock)
+			}
+			if got := shop.balance(t); got != tt.wantBalance {
+				t.Errorf("бонусов %d, ожидалось %d", got, tt.wantBalance)
+			}
+			saved, err := shop.orders.GetOrderByID(order.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if saved.Status != tt.wantStatus {
+				t.Errorf("статус %s, ожидался %s", saved.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+// TestCancelUserOrderAfterAdminCancel - покупатель отменяет заказ, который админ уже отменил:
+// товар и бонусы не возвращаются второй раз
+func TestCancelUserOrderAfterAdminCancel(t *testing.T) {
+	shop := newTestShop(t, 10, 100)
+	order, err := shop.order(t, 3, 30, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := shop.orders.UpdateOrderStatus(order.ID, domain.OrderStatusCancelled); err != nil {
+		t.Fatal(err)
+	}
+	cancelled, err := shop.orders.CancelUserOrder(shop.chatID, order.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cancelled {
+		t.Fatal("отмененный заказ отменился еще раз")
+	}
+	if got := shop.stock(t); got != 10 {
+		t.Errorf("остаток %d, ожидался 10", got)
+	}
+	if got := shop.balance(t); got != 100 {
+		t.Errorf("бонусов %d, ожидалось 100", got)
+	}
+}
+
+// TestCreateOrderOutOfStock - заказ больше остатка не сохраняется и остаток не меняет
+func TestCreateOrderOutOfStock(t *testing.T) {
+	shop := newTestShop(t, 2, 0)
+	if _, err := shop.order(t, 3, 0, 0); !errors.Is(err, domain.ErrOutOfStock) {
+		t.Fatalf("ожидалась domain.ErrOutOfStock, получено %v", err)
+	}
+	if got := shop.stock(t); got != 2 {
+		t.Errorf("остаток %d, ожидался 2", got)
+	}
+	if _, err := shop.order(t, 2, 0, 0); err != nil {
+		t.Fatalf("заказ на весь остаток: %v", err)
+	}
+	if got := shop.stock(t); got != 0 {
+		t.Errorf("остаток %d, ожидался 0", got)
+	}
+}
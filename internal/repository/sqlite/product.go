@@ -31,33 +31,229 @@ func createProductsTable(db *sql.DB) error {
 	query := `
 	CREATE TABLE IF NOT EXISTS products (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		sku TEXT,          -- Артикул (уникальный, по нему работает импорт каталога)
 		type TEXT,         -- Тип (female, male, unisex)
 		name TEXT,         -- Название
 		description TEXT,  -- Описание
-		price REAL,        -- Цена (REAL это float в sqlite)
-		image_id TEXT      -- ID картинки в телеграм
+		price_minor INTEGER NOT NULL DEFAULT 0, -- Цена в копейках (целое число, без ошибок округления)
+		currency TEXT NOT NULL, -- Код валюты (валюта магазина, см. CheckCurrency)
+		image_id TEXT,     -- ID картинки в телеграм
+		weight_grams INTEGER NOT NULL DEFAULT 0, -- Вес с упаковкой для доставки (0 - не указан)
+		stock INTEGER      -- Остаток на складе (NULL - не ведется)
 	);
 	`
-	_, err := db.Exec(query)
+	if _, err := db.Exec(query); err != nil {
+		return err
+	}
+
+	// В старых базах цена хранилась в колонке price REAL (рубли) - переводим в копейки
+	if err := addColumnIfMissing(db, "products", "currency", legacyCurrencyColumn); err != nil {
+		return err
+	}
+	if err := migrateToMinorUnits(db, "products", []moneyColumn{{real: "price", minor: "price_minor"}}); err != nil {
+		return err
+	}
+
+	// Вес для расчета доставки: у старых товаров не указан
+	if err := addColumnIfMissing(db, "products", "weight_grams", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+
+	// Остатки появились позже: у старых товаров они не ведутся, товары остаются в продаже
+	if err := addColumnIfMissing(db, "products", "stock", "INTEGER"); err != nil {
+		return err
+	}
+
+	// Артикулы появились позже: старым товарам выдаем SP-<id>
+	if err := addColumnIfMissing(db, "products", "sku", "TEXT"); err != nil {
+		return err
+	}
+	if _, err := db.Exec(`UPDATE products SET sku = 'SP-' || id WHERE sku IS NULL OR sku = ''`); err != nil {
+		return err
+	}
+	if _, err := db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_products_sku ON products(sku)`); err != nil {
+		return err
+	}
+
+	// Галерея товара: несколько фото по порядку, position 0 - обложка
+	query = `
+	CREATE TABLE IF NOT EXISTS product_images (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		product_id INTEGER NOT NULL,
+		file_id TEXT NOT NULL,   -- ID фото в телеграм
+		position INTEGER NOT NULL,
+		UNIQUE(product_id, position)
+	);
+	`
+	if _, err := db.Exec(query); err != nil {
+		return err
+	}
+
+	// У товаров, созданных до галерей, единственное фото становится первым в галерее
+	_, err := db.Exec(`
+		INSERT INTO product_images (product_id, file_id, position)
+		SELECT id, image_id, 0 FROM products
+		WHERE image_id IS NOT NULL AND image_id != ''
+		  AND id NOT IN (SELECT product_id FROM product_images)`)
 	return err
 }
 
+// productColumns - общий список колонок товара для SELECT
+const productColumns = `id, sku, type, name, description, price_minor, currency, image_id, weight_grams, stock`
+
+// scanProduct - сканирует строку в структуру товара
+func scanProduct(row interface{ Scan(...any) error }) (domain.Product, error) {
+	var p domain.Product
+	err := row.Scan(&p.ID, &p.SKU, &p.Type, &p.Name, &p.Description, &p.Price.Amount, &p.Price.Currency, &p.ImageID, &p.Weight, &p.Stock)
+	return p, err
+}
+
 // CreateProduct - Добавляет товар в базу данных
+// Если артикул не указан, товару выдается SP-<id>.
 func (r *ProductSqlite) CreateProduct(product *domain.Product) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if len(product.Images) > 0 {
+		product.ImageID = product.Images[0]
+	}
+
 	// Используем подготовленные выражения (?) для защиты от SQL-инъекций
-	query := `INSERT INTO products (type, name, description, price, image_id) VALUES (?, ?, ?, ?, ?)`
-	
-	_, err := r.db.Exec(query, product.Type, product.Name, product.Description, product.Price, product.ImageID)
+	query := `INSERT INTO products (sku, type, name, description, price_minor, currency, image_id, weight_grams, stock) VALUES (NULLIF(?, ''), ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	res, err := tx.Exec(query, product.SKU, product.Type, product.Name, product.Description, product.Price.Amount, product.Price.Currency, product.ImageID,
+		product.Weight, product.Stock)
 	if err != nil {
 		return fmt.Errorf("failed to create product: %w", err)
 	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get product id: %w", err)
+	}
+
+	if product.SKU == "" {
+		product.SKU = fmt.Sprintf("SP-%d", id)
+		if _, err := tx.Exec(`UPDATE products SET sku = ? WHERE id = ?`, product.SKU, id); err != nil {
+			return fmt.Errorf("failed to set product sku: %w", err)
+		}
+	}
+
+	if err := saveProductImages(tx, id, product.Gallery()); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit product: %w", err)
+	}
+	product.ID = id
+	return nil
+}
+
+// UpdateProduct - Обновляет все поля товара по его ID.
+// Если product.Images == nil, в галерее заменяется только обложка, остальные фото остаются.
+func (r *ProductSqlite) UpdateProduct(product *domain.Product) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if len(product.Images) > 0 {
+		product.ImageID = product.Images[0]
+	}
+
+	query := `UPDATE products SET sku = ?, type = ?, name = ?, description = ?, price_minor = ?, currency = ?, image_id = ?, weight_grams = ?, stock = ? WHERE id = ?`
+
+	_, err = tx.Exec(query, product.SKU, product.Type, product.Name, product.Description, product.Price.Amount, product.Price.Currency,
+		product.ImageID, product.Weight, product.Stock, product.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update product: %w", err)
+	}
+
+	if product.Images != nil {
+		err = saveProductImages(tx, product.ID, product.Images)
+	} else {
+		_, err = tx.Exec(`INSERT INTO product_images (product_id, file_id, position) VALUES (?, ?, 0)
+			ON CONFLICT(product_id, position) DO UPDATE SET file_id = excluded.file_id`, product.ID, product.ImageID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update product images: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// SetProductImages - Заменяет галерею товара, первое фото становится обложкой
+func (r *ProductSqlite) SetProductImages(productID int64, fileIDs []string) error {
+	if len(fileIDs) == 0 {
+		return fmt.Errorf("product must have at least one image")
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE products SET image_id = ? WHERE id = ?`, fileIDs[0], productID); err != nil {
+		return fmt.Errorf("failed to update product cover: %w", err)
+	}
+	if err := saveProductImages(tx, productID, fileIDs); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// saveProductImages - перезаписывает фото товара внутри транзакции
+func saveProductImages(tx *sql.Tx, productID int64, fileIDs []string) error {
+	if _, err := tx.Exec(`DELETE FROM product_images WHERE product_id = ?`, productID); err != nil {
+		return fmt.Errorf("failed to clear product images: %w", err)
+	}
+	for i, fileID := range fileIDs {
+		_, err := tx.Exec(`INSERT INTO product_images (product_id, file_id, position) VALUES (?, ?, ?)`, productID, fileID, i)
+		if err != nil {
+			return fmt.Errorf("failed to save product image: %w", err)
+		}
+	}
 	return nil
 }
 
+// loadProductImages - подгружает галереи для списка товаров одним запросом
+func (r *ProductSqlite) loadProductImages(products []domain.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+	index := make(map[int64]int, len(products))
+	for i, p := range products {
+		index[p.ID] = i
+	}
+
+	rows, err := r.db.Query(`SELECT product_id, file_id FROM product_images ORDER BY product_id, position`)
+	if err != nil {
+		return fmt.Errorf("failed to get product images: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var productID int64
+		var fileID string
+		if err := rows.Scan(&productID, &fileID); err != nil {
+			return err
+		}
+		if i, ok := index[productID]; ok {
+			products[i].Images = append(products[i].Images, fileID)
+		}
+	}
+	return rows.Err()
+}
+
 // GetAllProducts - Получает список всех товаров из базы
 func (r *ProductSqlite) GetAllProducts() ([]domain.Product, error) {
-	query := `SELECT id, type, name, description, price, image_id FROM products`
-	
+	query := `SELECT ` + productColumns + ` FROM products`
+
 	rows, err := r.db.Query(query)
 	if err != nil {
 		return nil, err
@@ -65,15 +261,86 @@ func (r *ProductSqlite) GetAllProducts() ([]domain.Product, error) {
 	defer rows.Close() // Обязательно закрываем rows, чтобы не текли соединения
 
 	var products []domain.Product
-	
+
 	// Бежим по строкам результата
 	for rows.Next() {
-		var p domain.Product
 		// Сканируем данные из строки в структуру
-		if err := rows.Scan(&p.ID, &p.Type, &p.Name, &p.Description, &p.Price, &p.ImageID); err != nil {
+		p, err := scanProduct(rows)
+		if err != nil {
 			return nil, err
 		}
 		products = append(products, p)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.loadProductImages(products); err != nil {
+		return nil, err
+	}
 	return products, nil
-}
\ No newline at end of file
+}
+
+// GetProductByID - Ищет товар по ID. Если товара нет, возвращает nil без ошибки.
+func (r *ProductSqlite) GetProductByID(id int64) (*domain.Product, error) {
+	p, err := scanProduct(r.db.QueryRow(`SELECT `+productColumns+` FROM products WHERE id = ?`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+	return r.withImages(p)
+}
+
+// GetProductBySKU - Ищет товар по артикулу. Если товара нет, возвращает nil без ошибки.
+func (r *ProductSqlite) GetProductBySKU(sku string) (*domain.Product, error) {
+	p, err := scanProduct(r.db.QueryRow(`SELECT `+productColumns+` FROM products WHERE sku = ?`, sku))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get product by sku: %w", err)
+	}
+	return r.withImages(p)
+}
+
+// withImages - подгружает галерею одного товара
+func (r *ProductSqlite) withImages(p domain.Product) (*domain.Product, error) {
+	rows, err := r.db.Query(`SELECT file_id FROM product_images WHERE product_id = ? ORDER BY position`, p.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product images: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var fileID string
+		if err := rows.Scan(&fileID); err != nil {
+			return nil, err
+		}
+		p.Images = append(p.Images, fileID)
+	}
+	return &p, rows.Err()
+}
+
+// DeleteProduct - удаляет товар вместе с галереей и подписками и убирает его из корзин.
+// Позиции заказов не трогаем: в них сохранены название и цена на момент покупки.
+func (r *ProductSqlite) DeleteProduct(id int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, query := range []string{
+		`DELETE FROM product_images WHERE product_id = ?`,
+		`DELETE FROM cart_items WHERE product_id = ?`,
+		`DELETE FROM product_subscriptions WHERE product_id = ?`,
+		`DELETE FROM products WHERE id = ?`,
+	} {
+		if _, err := tx.Exec(query, id); err != nil {
+			return fmt.Errorf("failed to delete product: %w", err)
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,267 @@
+// promo.go - Реализация интерфейса PromoRepository для SQLite.
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"salle_parfume/internal/domain"
+	"salle_parfume/internal/repository"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PromoSqlite - репозиторий акций и промокодов.
+type PromoSqlite struct {
+	db *sql.DB
+}
+
+// NewPromoSqlite - создает репозиторий акций и таблицы для него.
+func NewPromoSqlite(db *sql.DB) repository.PromoRepository {
+	if err := createPromotionsTables(db); err != nil {
+		fmt.Printf("Error creating promotions tables: %v\n", err)
+	}
+	return &PromoSqlite{db: db}
+}
+
+// createPromotionsTables - SQL запрос для создания таблиц акций и их использований
+func createPromotionsTables(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS promotions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		code TEXT UNIQUE COLLATE NOCASE,      -- Промокод (NULL - автоматическая акция)
+		name TEXT NOT NULL DEFAULT '',
+		kind TEXT NOT NULL,                     -- percent, fixed
+		percent REAL NOT NULL DEFAULT 0,        -- Процент скидки (для percent)
+		amount_minor INTEGER NOT NULL DEFAULT 0, -- Сумма скидки в копейках (для fixed)
+		min_order_minor INTEGER NOT NULL DEFAULT 0,
+		usage_limit INTEGER NOT NULL DEFAULT 0,
+		per_user_limit INTEGER NOT NULL DEFAULT 0,
+		starts_at DATETIME,
+		ends_at DATETIME,
+		product_ids TEXT NOT NULL DEFAULT '',   -- ID товаров через запятую
+		product_types TEXT NOT NULL DEFAULT '', -- Категории через запятую
+		active INTEGER NOT NULL DEFAULT 1,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE TABLE IF NOT EXISTS promotion_uses (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		promotion_id INTEGER NOT NULL REFERENCES promotions(id),
+		chat_id INTEGER NOT NULL,
+		order_id INTEGER NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_promotion_uses_promo_chat ON promotion_uses(promotion_id, chat_id);
+	`
+	if _, err := db.Exec(query); err != nil {
+		return err
+	}
+
+	if err := migratePromotionValue(db); err != nil {
+		return err
+	}
+	return migrateToMinorUnits(db, "promotions", []moneyColumn{{real: "min_order", minor: "min_order_minor"}})
+}
+
+// migratePromotionValue - в старых базах размер скидки хранился в одной колонке value REAL:
+// процент для percent и рубли для fixed. Раскладываем его в percent и amount_minor.
+func migratePromotionValue(db *sql.DB) error {
+	exists, err := hasColumn(db, "promotions", "value")
+	if err != nil || !exists {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := addColumnIfMissing(tx, "promotions", "percent", "REAL NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := addColumnIfMissing(tx, "promotions", "amount_minor", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+
+	query := `
+	UPDATE promotions SET
+		percent = CASE WHEN kind = 'percent' THEN value ELSE 0 END,
+		amount_minor = CASE WHEN kind = 'fixed' THEN CAST(ROUND(value * 100) AS INTEGER) ELSE 0 END`
+	if _, err := tx.Exec(query); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`ALTER TABLE promotions DROP COLUMN value`); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// promoColumns - общий список колонок для SELECT
+const promoColumns = `id, COALESCE(code, ''), name, kind, percent, amount_minor, min_order_minor, usage_limit, per_user_limit, starts_at, ends_at, product_ids, product_types, active`
+
+// scanPromotion - сканирует строку и разворачивает списки товаров и категорий
+func scanPromotion(row interface{ Scan(...any) error }) (domain.Promotion, error) {
+	var p domain.Promotion
+	var startsAt, endsAt sql.NullTime
+	var productIDs, productTypes string
+
+	err := row.Scan(&p.ID, &p.Code, &p.Name, &p.Kind, &p.Percent, &p.Amount.Amount, &p.MinOrder.Amount, &p.UsageLimit, &p.PerUserLimit,
+		&startsAt, &endsAt, &productIDs, &productTypes, &p.Active)
+	if err != nil {
+		return p, err
+	}
+
+	// Суммы акций всегда в валюте магазина
+	p.Amount.Currency = domain.DefaultCurrency
+	p.MinOrder.Currency = domain.DefaultCurrency
+
+	p.StartsAt = startsAt.Time
+	p.EndsAt = endsAt.Time
+	for _, s := range splitList(productIDs) {
+		if id, err := strconv.ParseInt(s, 10, 64); err == nil {
+			p.ProductIDs = append(p.ProductIDs, id)
+		}
+	}
+	for _, s := range splitList(productTypes) {
+		p.ProductTypes = append(p.ProductTypes, domain.ProductType(s))
+	}
+	return p, nil
+}
+
+// splitList - разбирает строку "a,b,c" в срез, пустая строка дает пустой срез
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// nullTime - нулевое время сохраняем как NULL
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+// CreatePromotion - сохраняет новую акцию
+func (r *PromoSqlite) CreatePromotion(promo *domain.Promotion) error {
+	ids := make([]string, 0, len(promo.ProductIDs))
+	for _, id := range promo.ProductIDs {
+		ids = append(ids, strconv.FormatInt(id, 10))
+	}
+	types := make([]string, 0, len(promo.ProductTypes))
+	for _, t := range promo.ProductTypes {
+		types = append(types, string(t))
+	}
+
+	// У автоматической акции кода нет, храним NULL, чтобы не мешать UNIQUE
+	var code sql.NullString
+	if promo.Code != "" {
+		code = sql.NullString{String: promo.Code, Valid: true}
+	}
+
+	query := `
+	INSERT INTO promotions (code, name, kind, percent, amount_minor, min_order_minor, usage_limit, per_user_limit, starts_at, ends_at, product_ids, product_types, active)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	res, err := r.db.Exec(query, code, promo.Name, promo.Kind, promo.Percent, promo.Amount.Amount, promo.MinOrder.Amount, promo.UsageLimit, promo.PerUserLimit,
+		nullTime(promo.StartsAt), nullTime(promo.EndsAt), strings.Join(ids, ","), strings.Join(types, ","), promo.Active)
+	if err != nil {
+		return fmt.Errorf("failed to create promotion: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get promotion id: %w", err)
+	}
+	promo.ID = id
+	return nil
+}
+
+// GetPromotionByCode - ищет акцию по промокоду. Если нет, возвращает nil без ошибки.
+func (r *PromoSqlite) GetPromotionByCode(code string) (*domain.Promotion, error) {
+	p, err := scanPromotion(r.db.QueryRow(`SELECT `+promoColumns+` FROM promotions WHERE code = ?`, code))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get promotion: %w", err)
+	}
+	return &p, nil
+}
+
+// GetPromotionByID - ищет акцию по ID. Если нет, возвращает nil без ошибки.
+func (r *PromoSqlite) GetPromotionByID(id int64) (*domain.Promotion, error) {
+	p, err := scanPromotion(r.db.QueryRow(`SELECT `+promoColumns+` FROM promotions WHERE id = ?`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get promotion: %w", err)
+	}
+	return &p, nil
+}
+
+// GetAllPromotions - все акции, новые первыми
+func (r *PromoSqlite) GetAllPromotions() ([]domain.Promotion, error) {
+	return r.queryPromotions(`SELECT ` + promoColumns + ` FROM promotions ORDER BY id DESC`)
+}
+
+// GetAutoPromotions - включенные акции без промокода.
+// Сроки действия проверяет уже сервис, так как ему известно текущее время.
+func (r *PromoSqlite) GetAutoPromotions() ([]domain.Promotion, error) {
+	return r.queryPromotions(`SELECT ` + promoColumns + ` FROM promotions WHERE code IS NULL AND active = 1 ORDER BY id`)
+}
+
+// SetPromotionActive - включает или выключает акцию
+func (r *PromoSqlite) SetPromotionActive(id int64, active bool) error {
+	if _, err := r.db.Exec(`UPDATE promotions SET active = ? WHERE id = ?`, active, id); err != nil {
+		return fmt.Errorf("failed to update promotion: %w", err)
+	}
+	return nil
+}
+
+// CountPromotionUses - сколько раз промокод использован всеми покупателями
+func (r *PromoSqlite) CountPromotionUses(promoID int64) (int, error) {
+	var count int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM promotion_uses WHERE promotion_id = ?`, promoID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count promotion uses: %w", err)
+	}
+	return count, nil
+}
+
+// CountUserPromotionUses - сколько раз промокод использован одним покупателем
+func (r *PromoSqlite) CountUserPromotionUses(promoID, chatID int64) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM promotion_uses WHERE promotion_id = ? AND chat_id = ?`
+	if err := r.db.QueryRow(query, promoID, chatID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count user promotion uses: %w", err)
+	}
+	return count, nil
+}
+
+// RecordPromotionUse - записывает использование промокода в заказе
+func (r *PromoSqlite) RecordPromotionUse(promoID, chatID, orderID int64) error {
+	query := `INSERT INTO promotion_uses (promotion_id, chat_id, order_id) VALUES (?, ?, ?)`
+	if _, err := r.db.Exec(query, promoID, chatID, orderID); err != nil {
+		return fmt.Errorf("failed to record promotion use: %w", err)
+	}
+	return nil
+}
+
+// queryPromotions - выполняет запрос и собирает список акций
+func (r *PromoSqlite) queryPromotions(query string, args ...any) ([]domain.Promotion, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query promotions: %w", err)
+	}
+	defer rows.Close()
+
+	var promos []domain.Promotion
+	for rows.Next() {
+		p, err := scanPromotion(rows)
+		if err != nil {
+			return nil, err
+		}
+		promos = append(promos, p)
+	}
+	return promos, rows.Err()
+}
@@ -0,0 +1,128 @@
+// referral.go - Реализация интерфейса ReferralRepository для SQLite.
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"salle_parfume/internal/domain"
+	"salle_parfume/internal/repository"
+)
+
+// ReferralSqlite - репозиторий реферальной программы.
+type ReferralSqlite struct {
+	db *sql.DB
+}
+
+// NewReferralSqlite - создает репозиторий приглашений и таблицу для него.
+func NewReferralSqlite(db *sql.DB) repository.ReferralRepository {
+	if err := createReferralsTable(db); err != nil {
+		fmt.Printf("Error creating referrals table: %v\n", err)
+	}
+	return &ReferralSqlite{db: db}
+}
+
+// createReferralsTable - SQL запрос для создания таблицы приглашений.
+// Покупателя приглашают только один раз, поэтому invitee_id уникален.
+func createReferralsTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS referrals (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		referrer_id INTEGER NOT NULL,           -- Кто пригласил
+		invitee_id INTEGER NOT NULL UNIQUE,     -- Кого пригласили
+		order_id INTEGER NOT NULL DEFAULT 0,    -- Первый оплаченный заказ друга (0 - еще нет)
+		reward_code TEXT NOT NULL DEFAULT '',   -- Промокод, выданный пригласившему (пусто - еще не выдан)
+		rewarded_at DATETIME,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_referrals_referrer ON referrals(referrer_id);
+	`
+	_, err := db.Exec(query)
+	return err
+}
+
+// CreateReferral - сохраняет приглашение, если покупателя еще никто не приглашал
+func (r *ReferralSqlite) CreateReferral(ref *domain.Referral) (bool, error) {
+	query := `INSERT INTO referrals (referrer_id, invitee_id) VALUES (?, ?) ON CONFLICT (invitee_id) DO NOTHING`
+	res, err := r.db.Exec(query, ref.ReferrerID, ref.InviteeID)
+	if err != nil {
+		return false, fmt.Errorf("failed to create referral: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to create referral: %w", err)
+	}
+	if n == 0 {
+		return false, nil
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return false, fmt.Errorf("failed to get referral id: %w", err)
+	}
+	ref.ID = id
+	return true, nil
+}
+
+// GetReferralStats - число приглашенных и выданные промокоды (использован ли каждый)
+func (r *ReferralSqlite) GetReferralStats(chatID int64) (domain.ReferralStats, error) {
+	var stats domain.ReferralStats
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM referrals WHERE referrer_id = ?`, chatID).Scan(&stats.Invited); err != nil {
+		return stats, fmt.Errorf("failed to count referrals: %w", err)
+	}
+
+	query := `
+	SELECT p.code, p.percent, p.ends_at, EXISTS (SELECT 1 FROM promotion_uses u WHERE u.promotion_id = p.id)
+	FROM referrals r JOIN promotions p ON p.code = r.reward_code
+	WHERE r.referrer_id = ? AND r.reward_code != ''
+	ORDER BY r.rewarded_at DESC, r.id DESC`
+	rows, err := r.db.Query(query, chatID)
+	if err != nil {
+		return stats, fmt.Errorf("failed to get referral rewards: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var reward domain.ReferralReward
+		var endsAt sql.NullTime
+		if err := rows.Scan(&reward.Code, &reward.Percent, &endsAt, &reward.Used); err != nil {
+			return stats, err
+		}
+		reward.EndsAt = endsAt.Time
+		stats.Rewards = append(stats.Rewards, reward)
+	}
+	return stats, rows.Err()
+}
+
+// GetDueReferrals - приглашения без награды, у которых друг уже оплатил или получил заказ.
+// Отмененные заказы не считаются. OrderID - первый такой заказ.
+func (r *ReferralSqlite) GetDueReferrals(limit int) ([]domain.Referral, error) {
+	query := `
+	SELECT r.id, r.referrer_id, r.invitee_id, r.created_at, MIN(o.id)
+	FROM referrals r JOIN orders o ON o.chat_id = r.invitee_id
+	WHERE r.reward_code = '' AND o.paid_at IS NOT NULL AND o.status != ?
+	GROUP BY r.id
+	ORDER BY r.id LIMIT ?`
+	rows, err := r.db.Query(query, domain.OrderStatusCancelled, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due referrals: %w", err)
+	}
+	defer rows.Close()
+
+	var refs []domain.Referral
+	for rows.Next() {
+		var ref domain.Referral
+		if err := rows.Scan(&ref.ID, &ref.ReferrerID, &ref.InviteeID, &ref.CreatedAt, &ref.OrderID); err != nil {
+			return nil, err
+		}
+		refs = append(refs, ref)
+	}
+	return refs, rows.Err()
+}
+
+// SetReferralReward - запоминает заказ друга и промокод, выданный пригласившему
+func (r *ReferralSqlite) SetReferralReward(id, orderID int64, code string) error {
+	query := `UPDATE referrals SET order_id = ?, reward_code = ?, rewarded_at = CURRENT_TIMESTAMP WHERE id = ?`
+	if _, err := r.db.Exec(query, orderID, code, id); err != nil {
+		return fmt.Errorf("failed to set referral reward: %w", err)
+	}
+	return nil
+}
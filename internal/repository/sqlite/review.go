@@ -0,0 +1,147 @@
+// review.go - Реализация интерфейса ReviewRepository для SQLite.
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"salle_parfume/internal/domain"
+	"salle_parfume/internal/repository"
+)
+
+// ReviewSqlite - репозиторий отзывов.
+type ReviewSqlite struct {
+	db *sql.DB
+}
+
+// NewReviewSqlite - создает репозиторий отзывов и таблицу для него.
+func NewReviewSqlite(db *sql.DB) repository.ReviewRepository {
+	if err := createReviewsTable(db); err != nil {
+		fmt.Printf("Error creating reviews table: %v\n", err)
+	}
+	return &ReviewSqlite{db: db}
+}
+
+// createReviewsTable - SQL запрос для создания таблицы отзывов
+func createReviewsTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS reviews (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		product_id INTEGER NOT NULL,
+		chat_id INTEGER NOT NULL,
+		author_name TEXT,
+		rating INTEGER NOT NULL CHECK (rating BETWEEN 1 AND 5),
+		text TEXT,
+		photo_id TEXT,                            -- ID фото в телеграм
+		status TEXT NOT NULL DEFAULT 'pending',   -- pending, approved, rejected
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_reviews_product_status ON reviews(product_id, status);
+	`
+	_, err := db.Exec(query)
+	return err
+}
+
+// reviewColumns - общий список колонок, чтобы не дублировать его в каждом SELECT
+const reviewColumns = `id, product_id, chat_id, author_name, rating, text, photo_id, status, created_at`
+
+// scanReview - сканирует одну строку в структуру отзыва
+func scanReview(row interface{ Scan(...any) error }) (domain.Review, error) {
+	var rv domain.Review
+	err := row.Scan(&rv.ID, &rv.ProductID, &rv.ChatID, &rv.AuthorName, &rv.Rating, &rv.Text, &rv.PhotoID, &rv.Status, &rv.CreatedAt)
+	return rv, err
+}
+
+// CreateReview - сохраняет отзыв в статусе "на модерации"
+func (r *ReviewSqlite) CreateReview(review *domain.Review) error {
+	if review.Status == "" {
+		review.Status = domain.ReviewStatusPending
+	}
+
+	query := `INSERT INTO reviews (product_id, chat_id, author_name, rating, text, photo_id, status) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	res, err := r.db.Exec(query, review.ProductID, review.ChatID, review.AuthorName, review.Rating, review.Text, review.PhotoID, review.Status)
+	if err != nil {
+		return fmt.Errorf("failed to create review: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get review id: %w", err)
+	}
+	review.ID = id
+	return nil
+}
+
+// GetReviewByID - ищет отзыв по ID. Если отзыва нет, возвращает nil без ошибки.
+func (r *ReviewSqlite) GetReviewByID(id int64) (*domain.Review, error) {
+	rv, err := scanReview(r.db.QueryRow(`SELECT `+reviewColumns+` FROM reviews WHERE id = ?`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get review: %w", err)
+	}
+	return &rv, nil
+}
+
+// GetUserReview - последний отзыв покупателя о товаре. Если отзыва нет, возвращает nil без ошибки.
+func (r *ReviewSqlite) GetUserReview(chatID, productID int64) (*domain.Review, error) {
+	query := `SELECT ` + reviewColumns + ` FROM reviews WHERE chat_id = ? AND product_id = ? ORDER BY id DESC LIMIT 1`
+	rv, err := scanReview(r.db.QueryRow(query, chatID, productID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user review: %w", err)
+	}
+	return &rv, nil
+}
+
+// UpdateReviewStatus - меняет статус модерации
+func (r *ReviewSqlite) UpdateReviewStatus(id int64, status domain.ReviewStatus) error {
+	_, err := r.db.Exec(`UPDATE reviews SET status = ? WHERE id = ?`, status, id)
+	if err != nil {
+		return fmt.Errorf("failed to update review status: %w", err)
+	}
+	return nil
+}
+
+// GetPendingReviews - все отзывы, которые ждут модерации (старые первыми)
+func (r *ReviewSqlite) GetPendingReviews() ([]domain.Review, error) {
+	return r.queryReviews(`SELECT `+reviewColumns+` FROM reviews WHERE status = ? ORDER BY id`, domain.ReviewStatusPending)
+}
+
+// GetApprovedReviews - опубликованные отзывы о товаре (новые первыми)
+func (r *ReviewSqlite) GetApprovedReviews(productID int64, limit, offset int) ([]domain.Review, error) {
+	query := `SELECT ` + reviewColumns + ` FROM reviews WHERE product_id = ? AND status = ? ORDER BY id DESC LIMIT ? OFFSET ?`
+	return r.queryReviews(query, productID, domain.ReviewStatusApproved, limit, offset)
+}
+
+// GetProductRating - средняя оценка и количество опубликованных отзывов
+func (r *ReviewSqlite) GetProductRating(productID int64) (domain.ProductRating, error) {
+	query := `SELECT COALESCE(AVG(rating), 0), COUNT(*) FROM reviews WHERE product_id = ? AND status = ?`
+
+	var rating domain.ProductRating
+	if err := r.db.QueryRow(query, productID, domain.ReviewStatusApproved).Scan(&rating.Average, &rating.Count); err != nil {
+		return rating, fmt.Errorf("failed to get product rating: %w", err)
+	}
+	return rating, nil
+}
+
+// queryReviews - выполняет запрос и собирает список отзывов
+func (r *ReviewSqlite) queryReviews(query string, args ...any) ([]domain.Review, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reviews: %w", err)
+	}
+	defer rows.Close()
+
+	var reviews []domain.Review
+	for rows.Next() {
+		rv, err := scanReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, rv)
+	}
+	return reviews, rows.Err()
+}
@@ -3,6 +3,7 @@ package sqlite
 
 import (
 	"database/sql"
+	"fmt"
 
 	_ "github.com/mattn/go-sqlite3" // Драйвер для SQLite
 )
@@ -10,6 +11,7 @@ import (
 type Config struct {
 	DriverName string
 	Path       string
+	Observer   QueryObserver // Кому сообщать время запросов (nil - не замеряем)
 }
 
 func NewSqliteDB(cfg Config) (*sql.DB, error) {
@@ -17,6 +19,12 @@ func NewSqliteDB(cfg Config) (*sql.DB, error) {
 	if err != nil {
 		return nil, err
 	}
+	if cfg.Observer != nil {
+		// Тот же драйвер, но соединения открываем сами, чтобы замерять запросы
+		connector := &observedConnector{driver: db.Driver(), dsn: cfg.Path, observer: cfg.Observer}
+		db.Close()
+		db = sql.OpenDB(connector)
+	}
 
 	if err := db.Ping(); err != nil {
 		return nil, err
@@ -24,3 +32,82 @@ func NewSqliteDB(cfg Config) (*sql.DB, error) {
 
 	return db, nil
 }
+
+// execQuerier - общее у *sql.DB и *sql.Tx, чтобы миграции работали и внутри транзакции
+type execQuerier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// hasColumn - есть ли колонка в таблице
+func hasColumn(db execQuerier, table, column string) (bool, error) {
+	rows, err := db.Query(`SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
+
+// addColumnIfMissing - добавляет колонку в существующую таблицу, если ее еще нет.
+// CREATE TABLE IF NOT EXISTS не меняет уже созданные таблицы, поэтому новые поля
+// в старых базах появляются через эту функцию.
+func addColumnIfMissing(db execQuerier, table, column, definition string) error {
+	exists, err := hasColumn(db, table, column)
+	if err != nil || exists {
+		return err
+	}
+	_, err = db.Exec(`ALTER TABLE ` + table + ` ADD COLUMN ` + column + ` ` + definition)
+	return err
+}
+
+// moneyColumn - пара колонок для миграции денег: старая REAL в рублях и новая INTEGER в копейках
+type moneyColumn struct {
+	real  string // старая колонка, например price
+	minor string // новая колонка, например price_minor
+}
+
+// migrateToMinorUnits - переводит денежные колонки из REAL (рубли) в INTEGER (копейки).
+// Для каждой пары создается новая колонка; если старая есть - значения переносятся
+// с округлением до копейки, а старая колонка удаляется. Все в одной транзакции.
+func migrateToMinorUnits(db *sql.DB, table string, columns []moneyColumn) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, c := range columns {
+		if err := addColumnIfMissing(tx, table, c.minor, "INTEGER NOT NULL DEFAULT 0"); err != nil {
+			return err
+		}
+
+		exists, err := hasColumn(tx, table, c.real)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+
+		update := `UPDATE ` + table + ` SET ` + c.minor + ` = CAST(ROUND(COALESCE(` + c.real + `, 0) * 100) AS INTEGER)`
+		if _, err := tx.Exec(update); err != nil {
+			return fmt.Errorf("migrate %s.%s: %w", table, c.real, err)
+		}
+		if _, err := tx.Exec(`ALTER TABLE ` + table + ` DROP COLUMN ` + c.real); err != nil {
+			return fmt.Errorf("drop %s.%s: %w", table, c.real, err)
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,89 @@
+package sqlite
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"salle_parfume/internal/domain"
+	"salle_parfume/internal/repository"
+)
+
+// newTestDB - пустая база в файле во временном каталоге теста
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := NewSqliteDB(Config{DriverName: "sqlite3", Path: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// testShop - база с товаром и покупателем, у которого есть бонусы
+type testShop struct {
+	db       *sql.DB
+	products repository.ProductRepository
+	orders   repository.OrderRepository
+	loyalty  repository.LoyaltyRepository
+	product  *domain.Product
+	chatID   int64
+}
+
+// newTestShop - товар с остатком stock и покупатель с points бонусами
+func newTestShop(t *testing.T, stock int, points int64) *testShop {
+	t.Helper()
+	db := newTestDB(t)
+	s := &testShop{
+		db:       db,
+		products: NewProductSqlite(db),
+		orders:   NewOrderSqlite(db),
+		loyalty:  NewLoyaltySqlite(db),
+		chatID:   1001,
+	}
+	s.product = &domain.Product{SKU: "A-1", Type: "unisex", Name: "Aqua", Price: domain.NewMoney(100_00, domain.DefaultCurrency), Stock: &stock}
+	if err := s.products.CreateProduct(s.product); err != nil {
+		t.Fatal(err)
+	}
+	if points > 0 {
+		if err := s.loyalty.AdjustPoints(&domain.LoyaltyEntry{ChatID: s.chatID, Points: points, Reason: "тест", Actor: "test"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s
+}
+
+// order - оформляет заказ на quantity штук товара со списанием spent и обещанием earned бонусов
+func (s *testShop) order(t *testing.T, quantity int, spent, earned int64) (*domain.Order, error) {
+	t.Helper()
+	price := s.product.Price
+	order := &domain.Order{
+		ChatID:       s.chatID,
+		Subtotal:     price.Mul(quantity),
+		Total:        price.Mul(quantity).Sub(domain.PointsValue(spent)),
+		Items:        []domain.OrderItem{{ProductID: s.product.ID, Name: s.product.Name, Price: price, Quantity: quantity}},
+		PointsSpent:  spent,
+		PointsEarned: earned,
+	}
+	return order, s.orders.CreateOrder(order)
+}
+
+// stock - остаток товара сейчас
+func (s *testShop) stock(t *testing.T) int {
+	t.Helper()
+	p, err := s.products.GetProductByID(s.product.ID)
+	if err != nil || p == nil || p.Stock == nil {
+		t.Fatalf("товар не найден: %v", err)
+	}
+	return *p.Stock
+}
+
+// balance - бонусы покупателя сейчас
+func (s *testShop) balance(t *testing.T) int64 {
+	t.Helper()
+	balance, err := s.loyalty.GetLoyaltyBalance(s.chatID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return balance
+}
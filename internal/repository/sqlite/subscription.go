@@ -0,0 +1,143 @@
+// subscription.go - Реализация интерфейса SubscriptionRepository для SQLite.
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"salle_parfume/internal/domain"
+	"salle_parfume/internal/repository"
+)
+
+// SubscriptionSqlite - репозиторий подписок на товары.
+type SubscriptionSqlite struct {
+	db *sql.DB
+}
+
+// NewSubscriptionSqlite - создает репозиторий подписок и таблицу для него.
+func NewSubscriptionSqlite(db *sql.DB) repository.SubscriptionRepository {
+	if err := createSubscriptionsTable(db); err != nil {
+		fmt.Printf("Error creating subscriptions table: %v\n", err)
+	}
+	return &SubscriptionSqlite{db: db}
+}
+
+// createSubscriptionsTable - SQL запрос для создания таблицы подписок.
+// На один товар у покупателя не больше одной подписки каждого вида.
+func createSubscriptionsTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS product_subscriptions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		chat_id INTEGER NOT NULL,
+		product_id INTEGER NOT NULL,
+		kind TEXT NOT NULL,                   -- restock, price_drop
+		price_minor INTEGER NOT NULL,         -- Цена при подписке или при последнем уведомлении
+		currency TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(chat_id, product_id, kind)
+	);
+	CREATE INDEX IF NOT EXISTS idx_product_subscriptions_product ON product_subscriptions(product_id);
+	`
+	_, err := db.Exec(query)
+	return err
+}
+
+// subscriptionColumns - общий список колонок подписки для SELECT (с названием товара из products p)
+const subscriptionColumns = `s.id, s.chat_id, s.product_id, s.kind, s.price_minor, s.currency, s.created_at, p.name`
+
+// scanSubscription - сканирует строку в структуру подписки
+func scanSubscription(row interface{ Scan(...any) error }) (domain.Subscription, error) {
+	var sub domain.Subscription
+	err := row.Scan(&sub.ID, &sub.ChatID, &sub.ProductID, &sub.Kind, &sub.Price.Amount, &sub.Price.Currency, &sub.CreatedAt, &sub.ProductName)
+	return sub, err
+}
+
+// Subscribe - сохраняет подписку, если такой еще нет
+func (r *SubscriptionSqlite) Subscribe(sub *domain.Subscription) (bool, error) {
+	query := `INSERT INTO product_subscriptions (chat_id, product_id, kind, price_minor, currency) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (chat_id, product_id, kind) DO NOTHING`
+	res, err := r.db.Exec(query, sub.ChatID, sub.ProductID, sub.Kind, sub.Price.Amount, sub.Price.Currency)
+	if err != nil {
+		return false, fmt.Errorf("failed to create subscription: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to create subscription: %w", err)
+	}
+	if n == 0 {
+		return false, nil
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return false, fmt.Errorf("failed to get subscription id: %w", err)
+	}
+	sub.ID = id
+	return true, nil
+}
+
+// Unsubscribe - удаляет подписку покупателя. Чужую подписку не тронет.
+func (r *SubscriptionSqlite) Unsubscribe(chatID, id int64) (bool, error) {
+	res, err := r.db.Exec(`DELETE FROM product_subscriptions WHERE id = ? AND chat_id = ?`, id, chatID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete subscription: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete subscription: %w", err)
+	}
+	return n > 0, nil
+}
+
+// GetUserSubscriptions - подписки покупателя на товары, которые еще есть в каталоге
+func (r *SubscriptionSqlite) GetUserSubscriptions(chatID int64) ([]domain.Subscription, error) {
+	query := `SELECT ` + subscriptionColumns + ` FROM product_subscriptions s
+		JOIN products p ON p.id = s.product_id
+		WHERE s.chat_id = ? ORDER BY s.id DESC`
+	return r.getSubscriptions(query, chatID)
+}
+
+// GetDueSubscriptions - подписки, по которым пора уведомить: товар снова в наличии
+// или его цена в той же валюте стала ниже запомненной. Условия совпадают с domain.Subscription.Due.
+func (r *SubscriptionSqlite) GetDueSubscriptions(limit int) ([]domain.Subscription, error) {
+	query := `SELECT ` + subscriptionColumns + ` FROM product_subscriptions s
+		JOIN products p ON p.id = s.product_id
+		WHERE (s.kind = ? AND (p.stock IS NULL OR p.stock > 0))
+		   OR (s.kind = ? AND p.currency = s.currency AND p.price_minor < s.price_minor)
+		ORDER BY s.id LIMIT ?`
+	return r.getSubscriptions(query, domain.SubscriptionRestock, domain.SubscriptionPriceDrop, limit)
+}
+
+// getSubscriptions - выполняет запрос и собирает подписки
+func (r *SubscriptionSqlite) getSubscriptions(query string, args ...any) ([]domain.Subscription, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []domain.Subscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+// DeleteSubscription - удаляет подписку по ID
+func (r *SubscriptionSqlite) DeleteSubscription(id int64) error {
+	if _, err := r.db.Exec(`DELETE FROM product_subscriptions WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete subscription: %w", err)
+	}
+	return nil
+}
+
+// SetSubscriptionPrice - запоминает цену, о которой уже сообщили покупателю
+func (r *SubscriptionSqlite) SetSubscriptionPrice(id int64, price domain.Money) error {
+	_, err := r.db.Exec(`UPDATE product_subscriptions SET price_minor = ?, currency = ? WHERE id = ?`, price.Amount, price.Currency, id)
+	if err != nil {
+		return fmt.Errorf("failed to update subscription price: %w", err)
+	}
+	return nil
+}
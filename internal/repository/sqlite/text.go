@@ -0,0 +1,135 @@
+// text.go - Реализация интерфейса TextRepository для SQLite.
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"salle_parfume/internal/domain"
+	"salle_parfume/internal/repository"
+)
+
+// TextSqlite - репозиторий текстов магазина.
+type TextSqlite struct {
+	db *sql.DB
+}
+
+// NewTextSqlite - создает репозиторий текстов и таблицу для него.
+func NewTextSqlite(db *sql.DB) repository.TextRepository {
+	if err := createShopTextsTable(db); err != nil {
+		fmt.Printf("Error creating shop texts table: %v\n", err)
+	}
+	return &TextSqlite{db: db}
+}
+
+// createShopTextsTable - SQL запрос для создания таблицы версий текстов
+func createShopTextsTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS shop_texts (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		key TEXT NOT NULL,                  -- welcome, about, delivery, contacts, faq
+		lang TEXT NOT NULL,
+		version INTEGER NOT NULL,           -- 1, 2, 3... для каждой пары key+lang
+		body TEXT NOT NULL,                 -- Шаблон с подстановками {first_name}
+		author_id INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(key, lang, version)
+	);
+	`
+	_, err := db.Exec(query)
+	return err
+}
+
+// shopTextColumns - общий список колонок для SELECT
+const shopTextColumns = `id, key, lang, version, body, author_id, created_at`
+
+// scanShopText - сканирует одну строку в структуру текста
+func scanShopText(row interface{ Scan(...any) error }) (domain.ShopText, error) {
+	var t domain.ShopText
+	err := row.Scan(&t.ID, &t.Key, &t.Lang, &t.Version, &t.Body, &t.AuthorID, &t.CreatedAt)
+	return t, err
+}
+
+// GetShopText - последняя версия текста. Если текст ни разу не меняли, возвращает nil без ошибки.
+func (r *TextSqlite) GetShopText(key, lang string) (*domain.ShopText, error) {
+	query := `SELECT ` + shopTextColumns + ` FROM shop_texts WHERE key = ? AND lang = ? ORDER BY version DESC LIMIT 1`
+	t, err := scanShopText(r.db.QueryRow(query, key, lang))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get shop text: %w", err)
+	}
+	return &t, nil
+}
+
+// GetShopTextVersion - конкретная версия текста. Если такой нет, возвращает nil без ошибки.
+func (r *TextSqlite) GetShopTextVersion(key, lang string, version int) (*domain.ShopText, error) {
+	query := `SELECT ` + shopTextColumns + ` FROM shop_texts WHERE key = ? AND lang = ? AND version = ?`
+	t, err := scanShopText(r.db.QueryRow(query, key, lang, version))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get shop text version: %w", err)
+	}
+	return &t, nil
+}
+
+// GetShopTextHistory - последние версии текста, новые первыми
+func (r *TextSqlite) GetShopTextHistory(key, lang string, limit int) ([]domain.ShopText, error) {
+	query := `SELECT ` + shopTextColumns + ` FROM shop_texts WHERE key = ? AND lang = ? ORDER BY version DESC LIMIT ?`
+	rows, err := r.db.Query(query, key, lang, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query shop texts: %w", err)
+	}
+	defer rows.Close()
+
+	var texts []domain.ShopText
+	for rows.Next() {
+		t, err := scanShopText(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan shop text: %w", err)
+		}
+		texts = append(texts, t)
+	}
+	return texts, rows.Err()
+}
+
+// SaveShopText - сохраняет текст следующей версией.
+// Номер версии считается внутри транзакции, чтобы две правки подряд не получили один номер.
+func (r *TextSqlite) SaveShopText(text *domain.ShopText) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var version int
+	query := `SELECT COALESCE(MAX(version), 0) + 1 FROM shop_texts WHERE key = ? AND lang = ?`
+	if err := tx.QueryRow(query, text.Key, text.Lang).Scan(&version); err != nil {
+		return fmt.Errorf("failed to get next shop text version: %w", err)
+	}
+
+	res, err := tx.Exec(`INSERT INTO shop_texts (key, lang, version, body, author_id) VALUES (?, ?, ?, ?, ?)`,
+		text.Key, text.Lang, version, text.Body, text.AuthorID)
+	if err != nil {
+		return fmt.Errorf("failed to save shop text: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get shop text id: %w", err)
+	}
+
+	var createdAt sql.NullTime
+	if err := tx.QueryRow(`SELECT created_at FROM shop_texts WHERE id = ?`, id).Scan(&createdAt); err != nil {
+		return fmt.Errorf("failed to get shop text date: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit shop text: %w", err)
+	}
+
+	text.ID = id
+	text.Version = version
+	text.CreatedAt = createdAt.Time
+	return nil
+}
@@ -0,0 +1,158 @@
+// schedule.go — расписание задач в формате cron: "минуты часы дни месяцы дни_недели".
+// Поддерживаются *, числа, диапазоны 1-5, шаги */15 и 1-30/2, списки через запятую,
+// а также сокращения @hourly, @daily и @every <длительность> ("@every 30m").
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule - когда задача должна выполниться в следующий раз после момента t
+type Schedule interface {
+	Next(t time.Time) time.Time
+}
+
+// ParseSchedule - разбирает строку расписания
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	switch spec {
+	case "@hourly":
+		spec = "0 * * * *"
+	case "@daily", "@midnight":
+		spec = "0 0 * * *"
+	case "@weekly":
+		spec = "0 0 * * 0"
+	}
+
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil || d < time.Minute {
+			return nil, fmt.Errorf("расписание %q: нужна длительность от минуты, например @every 15m", spec)
+		}
+		return every(d), nil
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("расписание %q: нужно 5 полей (минуты часы дни месяцы дни_недели) или @every 15m", spec)
+	}
+	var c cron
+	var err error
+	ranges := []struct {
+		dst      *uint64
+		min, max int
+		name     string
+	}{
+		{&c.minutes, 0, 59, "минуты"},
+		{&c.hours, 0, 23, "часы"},
+		{&c.days, 1, 31, "дни"},
+		{&c.months, 1, 12, "месяцы"},
+		{&c.weekdays, 0, 7, "дни недели"},
+	}
+	for i, r := range ranges {
+		if *r.dst, err = parseField(fields[i], r.min, r.max); err != nil {
+			return nil, fmt.Errorf("расписание %q, %s: %w", spec, r.name, err)
+		}
+	}
+	// Воскресенье можно писать и 0, и 7
+	if c.weekdays&(1<<7) != 0 {
+		c.weekdays |= 1
+	}
+	c.anyDay = fields[2] == "*"
+	c.anyWeekday = fields[4] == "*"
+	return c, nil
+}
+
+// every - "@every 15m": через равные промежутки от прошлого запуска
+type every time.Duration
+
+func (e every) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e))
+}
+
+// cron - расписание по полям, каждое поле - набор битов разрешенных значений
+type cron struct {
+	minutes, hours, days, months, weekdays uint64
+	// Как в обычном cron: если заданы и дни месяца, и дни недели, подходит любой из них
+	anyDay, anyWeekday bool
+}
+
+// maxSearch - дальше этого следующий запуск не ищем (например, для 31 февраля)
+const maxSearch = 5 * 366 * 24 * time.Hour
+
+func (c cron) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxSearch)
+
+	for t.Before(limit) {
+		switch {
+		case !has(c.months, int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case !has(c.hours, t.Hour()):
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case !has(c.minutes, t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches - подходит ли день по дням месяца и дням недели
+func (c cron) dayMatches(t time.Time) bool {
+	day := has(c.days, t.Day())
+	weekday := has(c.weekdays, int(t.Weekday()))
+	if c.anyDay || c.anyWeekday {
+		return day && weekday
+	}
+	return day || weekday
+}
+
+func has(set uint64, n int) bool {
+	return set&(1<<uint(n)) != 0
+}
+
+// parseField - одно поле cron: список через запятую из *, чисел и диапазонов с шагом
+func parseField(field string, min, max int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		expr, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepStr); err != nil || step <= 0 {
+				return 0, fmt.Errorf("неверный шаг %q", stepStr)
+			}
+		}
+
+		lo, hi := min, max
+		if expr != "*" {
+			from, to, isRange := strings.Cut(expr, "-")
+			var err error
+			if lo, err = strconv.Atoi(from); err != nil {
+				return 0, fmt.Errorf("неверное значение %q", part)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(to); err != nil {
+					return 0, fmt.Errorf("неверное значение %q", part)
+				}
+			} else if hasStep {
+				hi = max // "5/15" - с 5 до конца с шагом 15
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q вне диапазона %d-%d", part, min, max)
+		}
+		for n := lo; n <= hi; n += step {
+			set |= 1 << uint(n)
+		}
+	}
+	return set, nil
+}
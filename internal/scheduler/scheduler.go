@@ -0,0 +1,216 @@
+// scheduler.go — фоновые задачи по расписанию (напоминания, обслуживание базы и т.п.).
+// Каждый запуск записывается в базу: после перезапуска бота расписание продолжается
+// от последнего запуска, а не начинается заново. Перед запуском задача берет блокировку
+// в той же базе, поэтому при нескольких экземплярах бота она выполняется только одним из них.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"salle_parfume/internal/domain"
+	"salle_parfume/internal/repository"
+)
+
+// JobFunc - тело задачи. Возвращает итог одной строкой для истории запусков.
+// Задача должна прерываться, когда ctx отменен (остановка бота или таймаут).
+type JobFunc func(ctx context.Context) (string, error)
+
+// DefaultTimeout - сколько задача может выполняться, если таймаут не указан
+const DefaultTimeout = 10 * time.Minute
+
+// job - задача и ее состояние в планировщике
+type job struct {
+	name     string
+	schedule Schedule
+	timeout  time.Duration
+	run      JobFunc
+
+	next    time.Time // Когда запускать
+	running bool      // Сейчас выполняется (второй раз одновременно не запускаем)
+}
+
+// Scheduler - планировщик фоновых задач
+type Scheduler struct {
+	repo  repository.JobRepository
+	owner string // Кто держит блокировки: хост и PID этого экземпляра
+
+	mu     sync.Mutex
+	jobs   []*job
+	wake   chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New - создает планировщик. Задачи добавляются через Add до Start.
+func New(repo repository.JobRepository) *Scheduler {
+	host, _ := os.Hostname()
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		repo:   repo,
+		owner:  fmt.Sprintf("%s:%d", host, os.Getpid()),
+		wake:   make(chan struct{}, 1),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Add - добавляет задачу с расписанием в формате cron или "@every 15m".
+// timeout == 0 - DefaultTimeout.
+func (s *Scheduler) Add(name, spec string, timeout time.Duration, run JobFunc) error {
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		return fmt.Errorf("задача %s: %w", name, err)
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, j := range s.jobs {
+		if j.name == name {
+			return fmt.Errorf("задача %s уже добавлена", name)
+		}
+	}
+	s.jobs = append(s.jobs, &job{name: name, schedule: schedule, timeout: timeout, run: run})
+	return nil
+}
+
+// Start - запускает планировщик в фоне. Первый запуск каждой задачи считается
+// от ее последнего запуска в базе: пропущенный за время простоя запуск выполнится сразу.
+func (s *Scheduler) Start() {
+	now := time.Now()
+	s.mu.Lock()
+	for _, j := range s.jobs {
+		j.next = j.schedule.Next(now)
+		last, err := s.repo.GetLastJobRun(j.name)
+		if err != nil {
+			log.Printf("Error getting last run of job %s: %v", j.name, err)
+		}
+		if last != nil {
+			j.next = j.schedule.Next(last.StartedAt.Local())
+		}
+		log.Printf("Scheduler: %s next run at %s", j.name, j.next.Format(time.DateTime))
+	}
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go s.loop()
+}
+
+// Stop - останавливает планировщик и ждет, пока выполняющиеся задачи прервутся
+func (s *Scheduler) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// loop - ждет ближайшей задачи и запускает все, чье время пришло
+func (s *Scheduler) loop() {
+	defer s.wg.Done()
+	for {
+		s.mu.Lock()
+		now := time.Now()
+		wait := time.Minute // Раз в минуту просыпаемся в любом случае: часы могли перевести
+		for _, j := range s.jobs {
+			if j.running || j.next.IsZero() {
+				continue
+			}
+			if !j.next.After(now) {
+				j.running = true
+				s.wg.Add(1)
+				go s.execute(j, j.next)
+				continue
+			}
+			wait = min(wait, j.next.Sub(now))
+		}
+		s.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		case <-s.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// execute - один запуск задачи: блокировка, запись в историю, выполнение с таймаутом.
+// due - на какое время запуск был запланирован.
+func (s *Scheduler) execute(j *job, due time.Time) {
+	defer s.wg.Done()
+	started := time.Now()
+
+	defer func() {
+		s.mu.Lock()
+		j.running = false
+		// Следующий запуск считаем от начала этого, чтобы долгая задача не сдвигала расписание
+		j.next = j.schedule.Next(started)
+		s.mu.Unlock()
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}()
+
+	// Блокировку держим с запасом на таймаут: если процесс упадет, ее подхватят после срока
+	locked, err := s.repo.AcquireJobLock(j.name, s.owner, started.Add(j.timeout+time.Minute))
+	if err != nil {
+		log.Printf("Error locking job %s: %v", j.name, err)
+		return
+	}
+	if !locked {
+		log.Printf("Scheduler: %s is running in another instance, skipping", j.name)
+		return
+	}
+	defer func() {
+		if err := s.repo.ReleaseJobLock(j.name, s.owner); err != nil {
+			log.Printf("Error unlocking job %s: %v", j.name, err)
+		}
+	}()
+
+	// Другой экземпляр мог уже выполнить этот запуск, пока мы ждали блокировку
+	if last, err := s.repo.GetLastJobRun(j.name); err == nil && last != nil && last.Owner != s.owner && !last.StartedAt.Before(due.Truncate(time.Second)) {
+		return
+	}
+
+	run := &domain.JobRun{Job: j.name, Owner: s.owner, Status: domain.JobRunning, StartedAt: started}
+	if err := s.repo.CreateJobRun(run); err != nil {
+		log.Printf("Error saving run of job %s: %v", j.name, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, j.timeout)
+	details, err := safeRun(ctx, j.run)
+	cancel()
+
+	run.Details = details
+	run.Status = domain.JobOK
+	run.FinishedAt = time.Now()
+	if err != nil {
+		run.Status = domain.JobFailed
+		run.Error = err.Error()
+		log.Printf("Error running job %s: %v", j.name, err)
+	}
+	if err := s.repo.FinishJobRun(run); err != nil {
+		log.Printf("Error saving result of job %s: %v", j.name, err)
+	}
+}
+
+// safeRun - выполняет задачу, превращая панику в ошибку, чтобы она не уронила бота
+func safeRun(ctx context.Context, run JobFunc) (details string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return run(ctx)
+}
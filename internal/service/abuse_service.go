@@ -0,0 +1,207 @@
+// abuse_service.go — защита бота от флуда и блокировки пользователей.
+// Кто присылает больше limit обновлений за окно или раз за разом жмет одну и ту же кнопку,
+// получает паузу: бот его не слушает. Каждое следующее превышение подряд удваивает паузу
+// (до maxCooldown), а после спокойного периода счет начинается заново.
+// Превышения сохраняются в базе, чтобы админ видел, кого стоит заблокировать насовсем.
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"salle_parfume/internal/domain"
+	"salle_parfume/internal/repository"
+)
+
+const (
+	// repeatCallbackWindow - повтор той же кнопки быстрее этого считаем лишним нажатием
+	repeatCallbackWindow = 2 * time.Second
+	// repeatCallbackLimit - после стольких лишних нажатий подряд даем паузу. Пока их меньше,
+	// нажатия обрабатываются: повтор бывает и нарочным ("+" в корзине несколько раз).
+	repeatCallbackLimit = 5
+	// floodStatePrune - при скольких пользователях в памяти (счетчики или проверка бана)
+	// чистим тех, кто давно успокоился
+	floodStatePrune = 10000
+)
+
+// FloodVerdict - что делать с обновлением
+type FloodVerdict struct {
+	Allow    bool          // Обрабатываем
+	Cooldown time.Duration // Только что началась пауза такой длины (о ней стоит предупредить)
+}
+
+// floodState - счетчики одного пользователя
+type floodState struct {
+	windowStart time.Time // Начало текущего окна
+	count       int       // Обновлений в окне
+
+	lastCallback   string    // Данные последней нажатой кнопки
+	lastCallbackAt time.Time // Когда ее нажали
+	repeats        int       // Лишних нажатий подряд
+
+	strikes      int       // Превышений подряд
+	lastStrikeAt time.Time // Когда было последнее превышение
+	blockedUntil time.Time // До какого времени не слушаем
+}
+
+// AbuseService - сервис защиты от флуда и блокировок
+type AbuseService struct {
+	repo        repository.AbuseRepository
+	limit       int           // Сколько обновлений принимаем за window (0 - без ограничения)
+	window      time.Duration // Окно подсчета
+	cooldown    time.Duration // Пауза за первое превышение
+	maxCooldown time.Duration // Больше этого пауза не растет
+
+	mu     sync.Mutex
+	states map[int64]*floodState
+	banned map[int64]bool // Кого уже проверяли в базе: заблокирован или нет. Незаблокированных забывает prune.
+}
+
+// NewAbuseService - создает сервис. limit обновлений за window, пауза от cooldown до maxCooldown.
+func NewAbuseService(repo repository.AbuseRepository, limit int, window, cooldown, maxCooldown time.Duration) *AbuseService {
+	return &AbuseService{
+		repo:        repo,
+		limit:       limit,
+		window:      window,
+		cooldown:    cooldown,
+		maxCooldown: max(maxCooldown, cooldown),
+		states:      make(map[int64]*floodState),
+		banned:      make(map[int64]bool),
+	}
+}
+
+// IsBanned - заблокирован ли пользователь. Ответ базы запоминаем: проверка идет на каждое обновление.
+func (s *AbuseService) IsBanned(userID int64) (bool, error) {
+	s.mu.Lock()
+	banned, ok := s.banned[userID]
+	s.mu.Unlock()
+	if ok {
+		return banned, nil
+	}
+
+	ban, err := s.repo.GetBan(userID)
+	if err != nil {
+		return false, err
+	}
+	s.mu.Lock()
+	s.banned[userID] = ban != nil
+	s.mu.Unlock()
+	return ban != nil, nil
+}
+
+// Ban - блокирует пользователя
+func (s *AbuseService) Ban(ban *domain.Ban) error {
+	if err := s.repo.BanUser(ban); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.banned[ban.UserID] = true
+	s.mu.Unlock()
+	return nil
+}
+
+// Unban - снимает блокировку и забывает прошлые превышения (false - не был заблокирован)
+func (s *AbuseService) Unban(userID int64) (bool, error) {
+	ok, err := s.repo.UnbanUser(userID)
+	if err != nil {
+		return false, err
+	}
+	s.mu.Lock()
+	s.banned[userID] = false
+	delete(s.states, userID)
+	s.mu.Unlock()
+	return ok, nil
+}
+
+// Bans - заблокированные, новые первыми
+func (s *AbuseService) Bans(limit, offset int) ([]domain.Ban, error) {
+	return s.repo.GetBans(limit, offset)
+}
+
+// Check - учитывает обновление пользователя. callback - данные нажатой кнопки (пусто для сообщений).
+// Ошибка - только о том, что превышение не удалось записать; решение в FloodVerdict верное и тогда.
+func (s *AbuseService) Check(userID int64, callback string, now time.Time) (FloodVerdict, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.states) > floodStatePrune || len(s.banned) > floodStatePrune {
+		s.prune(now)
+	}
+	state, ok := s.states[userID]
+	if !ok {
+		state = &floodState{windowStart: now}
+		s.states[userID] = state
+	}
+	if now.Before(state.blockedUntil) {
+		return FloodVerdict{}, nil
+	}
+
+	// Та же кнопка сразу еще раз: нажатие обрабатываем как обычно, но repeatCallbackLimit
+	// таких повторов подряд - флуд
+	if callback != "" {
+		repeated := callback == state.lastCallback && now.Sub(state.lastCallbackAt) < repeatCallbackWindow
+		state.lastCallback, state.lastCallbackAt = callback, now
+		if repeated {
+			state.repeats++
+		} else {
+			state.repeats = 0
+		}
+		if state.repeats >= repeatCallbackLimit {
+			state.repeats = 0
+			return s.strike(userID, state, domain.FloodRepeatCallback, fmt.Sprintf("%d нажатий %q подряд", repeatCallbackLimit+1, callback), now)
+		}
+	}
+
+	if s.limit <= 0 {
+		return FloodVerdict{Allow: true}, nil
+	}
+	if now.Sub(state.windowStart) >= s.window {
+		state.windowStart, state.count = now, 0
+	}
+	state.count++
+	if state.count <= s.limit {
+		return FloodVerdict{Allow: true}, nil
+	}
+	details := fmt.Sprintf("больше %d обновлений за %s", s.limit, s.window)
+	state.windowStart, state.count = now, 0
+	return s.strike(userID, state, domain.FloodRateLimit, details, now)
+}
+
+// strike - превышение: пауза удваивается с каждым превышением подряд, после maxCooldown
+// без превышений счет начинается заново
+func (s *AbuseService) strike(userID int64, state *floodState, kind domain.FloodKind, details string, now time.Time) (FloodVerdict, error) {
+	if now.Sub(state.lastStrikeAt) > s.maxCooldown {
+		state.strikes = 0
+	}
+	cooldown := s.cooldown
+	for i := 0; i < state.strikes && cooldown < s.maxCooldown; i++ {
+		cooldown *= 2
+	}
+	cooldown = min(cooldown, s.maxCooldown)
+	state.strikes++
+	state.lastStrikeAt = now
+	state.blockedUntil = now.Add(cooldown)
+
+	event := &domain.FloodEvent{UserID: userID, Kind: kind, Details: details, Strike: state.strikes, Cooldown: cooldown}
+	if err := s.repo.CreateFloodEvent(event); err != nil {
+		return FloodVerdict{Cooldown: cooldown}, err
+	}
+	return FloodVerdict{Cooldown: cooldown}, nil
+}
+
+// prune - забывает пользователей без паузы, окна и недавних превышений, а вместе с ними
+// и то, что они не заблокированы (при следующем обновлении проверим в базе заново).
+// Заблокированных помним: их немного, и каждое их обновление иначе шло бы в базу.
+func (s *AbuseService) prune(now time.Time) {
+	for id, state := range s.states {
+		if now.After(state.blockedUntil) && now.Sub(state.windowStart) >= s.window && now.Sub(state.lastStrikeAt) > s.maxCooldown {
+			delete(s.states, id)
+		}
+	}
+	for id, banned := range s.banned {
+		if _, ok := s.states[id]; !ok && !banned {
+			delete(s.banned, id)
+		}
+	}
+}
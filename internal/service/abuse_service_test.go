@@ -0,0 +1,193 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"salle_parfume/internal/domain"
+)
+
+// memAbuse - блокировки и превышения в памяти вместо базы
+type memAbuse struct {
+	bans    map[int64]*domain.Ban
+	events  []domain.FloodEvent
+	lookups int // Сколько раз спрашивали блокировку
+}
+
+func newMemAbuse() *memAbuse { return &memAbuse{bans: make(map[int64]*domain.Ban)} }
+
+func (m *memAbuse) BanUser(ban *domain.Ban) error { m.bans[ban.UserID] = ban; return nil }
+
+func (m *memAbuse) UnbanUser(userID int64) (bool, error) {
+	_, ok := m.bans[userID]
+	delete(m.bans, userID)
+	return ok, nil
+}
+
+func (m *memAbuse) GetBan(userID int64) (*domain.Ban, error) {
+	m.lookups++
+	return m.bans[userID], nil
+}
+
+func (m *memAbuse) GetBans(limit, offset int) ([]domain.Ban, error) { return nil, nil }
+
+func (m *memAbuse) CreateFloodEvent(event *domain.FloodEvent) error {
+	m.events = append(m.events, *event)
+	return nil
+}
+
+func (m *memAbuse) GetFloodEvents(limit, offset int) ([]domain.FloodEvent, error) { return nil, nil }
+
+// update - одно обновление от пользователя: через сколько после начала, какая кнопка и что ожидаем
+type update struct {
+	at       time.Duration
+	callback string
+	allow    bool
+	cooldown time.Duration // Началась пауза такой длины
+}
+
+// TestAbuseCheck - лимит обновлений, повторы кнопки и рост паузы
+func TestAbuseCheck(t *testing.T) {
+	// Повторы одной кнопки: 6 нажатий подряд - превышение, первые 5 обрабатываются
+	repeats := func(callback string, step time.Duration, n int) []update {
+		var list []update
+		for i := 0; i < n; i++ {
+			list = append(list, update{at: time.Duration(i) * step, callback: callback, allow: true})
+		}
+		return list
+	}
+
+	tests := []struct {
+		name    string
+		limit   int
+		updates []update
+		strikes []domain.FloodKind
+	}{
+		{
+			name:  "лимит за окно",
+			limit: 3,
+			updates: []update{
+				{at: 0, allow: true},
+				{at: time.Second, allow: true},
+				{at: 2 * time.Second, allow: true},
+				{at: 3 * time.Second, cooldown: time.Minute},
+				{at: 30 * time.Second},              // пауза идет
+				{at: 64 * time.Second, allow: true}, // пауза кончилась, новое окно
+				{at: 65 * time.Second, allow: true},
+				{at: 66 * time.Second, allow: true},
+				{at: 67 * time.Second, cooldown: 2 * time.Minute}, // второе превышение подряд - пауза вдвое
+			},
+			strikes: []domain.FloodKind{domain.FloodRateLimit, domain.FloodRateLimit},
+		},
+		{
+			name:    "нарочные повторы плюса в корзине обрабатываются",
+			updates: repeats("1:cinc:a", 300*time.Millisecond, 5),
+		},
+		{
+			name: "шестой повтор подряд - флуд",
+			updates: append(repeats("1:cinc:a", 300*time.Millisecond, 5),
+				update{at: 1500 * time.Millisecond, callback: "1:cinc:a", cooldown: time.Minute}),
+			strikes: []domain.FloodKind{domain.FloodRepeatCallback},
+		},
+		{
+			name:    "редкие нажатия той же кнопки не повторы",
+			updates: repeats("1:cinc:a", 3*time.Second, 10),
+		},
+		{
+			name: "другая кнопка сбрасывает счет повторов",
+			updates: append(append(repeats("1:cinc:a", 100*time.Millisecond, 5),
+				update{at: 500 * time.Millisecond, callback: "1:cdec:a", allow: true}),
+				update{at: 600 * time.Millisecond, callback: "1:cinc:a", allow: true}),
+		},
+	}
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMemAbuse()
+			s := NewAbuseService(repo, tt.limit, time.Minute, time.Minute, 10*time.Minute)
+			for i, u := range tt.updates {
+				verdict, err := s.Check(7, u.callback, start.Add(u.at))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if verdict.Allow != u.allow || verdict.Cooldown != u.cooldown {
+					t.Fatalf("обновление %d (%s): %+v, ожидалось Allow=%v Cooldown=%s", i+1, u.at, verdict, u.allow, u.cooldown)
+				}
+			}
+			if len(repo.events) != len(tt.strikes) {
+				t.Fatalf("записано превышений %d, ожидалось %d", len(repo.events), len(tt.strikes))
+			}
+			for i, kind := range tt.strikes {
+				if repo.events[i].Kind != kind || repo.events[i].Strike != i+1 {
+					t.Errorf("превышение %d: %+v, ожидался вид %s", i+1, repo.events[i], kind)
+				}
+			}
+		})
+	}
+}
+
+// TestAbuseCooldownCap - пауза удваивается до maxCooldown, после спокойного периода счет сначала
+func TestAbuseCooldownCap(t *testing.T) {
+	s := NewAbuseService(newMemAbuse(), 1, time.Minute, time.Minute, 3*time.Minute)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	var got []time.Duration
+	for i := 0; i < 4; i++ {
+		s.Check(7, "", now)
+		verdict, _ := s.Check(7, "", now)
+		got = append(got, verdict.Cooldown)
+		now = now.Add(verdict.Cooldown) // сразу после паузы
+	}
+	// Спокойный период дольше maxCooldown - снова с минуты
+	now = now.Add(time.Hour)
+	s.Check(7, "", now)
+	verdict, _ := s.Check(7, "", now)
+	got = append(got, verdict.Cooldown)
+
+	want := []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 3 * time.Minute, time.Minute}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("паузы %v, ожидались %v", got, want)
+		}
+	}
+}
+
+// TestAbuseBans - блокировка запоминается, а память о незаблокированных чистится вместе со счетчиками
+func TestAbuseBans(t *testing.T) {
+	repo := newMemAbuse()
+	s := NewAbuseService(repo, 30, time.Minute, time.Minute, time.Hour)
+
+	if err := s.Ban(&domain.Ban{UserID: 1, Actor: "admin"}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if banned, err := s.IsBanned(1); err != nil || !banned {
+			t.Fatalf("заблокированный не заблокирован: %v", err)
+		}
+	}
+	if repo.lookups != 0 {
+		t.Fatalf("блокировку спрашивали в базе %d раз, ожидалось 0", repo.lookups)
+	}
+	if ok, err := s.Unban(1); err != nil || !ok {
+		t.Fatalf("разблокировка: %v, %v", ok, err)
+	}
+	if banned, _ := s.IsBanned(1); banned {
+		t.Fatal("разблокированный все еще заблокирован")
+	}
+	s.Ban(&domain.Ban{UserID: 2})
+
+	// Много разных пользователей: их "не заблокирован" не должно копиться вечно
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for id := int64(100); id < 100+floodStatePrune+1; id++ {
+		s.IsBanned(id)
+		s.Check(id, "", now)
+	}
+	s.Check(99, "", now.Add(2*time.Hour))
+
+	if len(s.banned) > 3 {
+		t.Fatalf("в памяти %d проверок блокировки, ожидалось не больше 3", len(s.banned))
+	}
+	if banned, _ := s.IsBanned(2); !banned {
+		t.Fatal("после чистки забыли блокировку")
+	}
+}
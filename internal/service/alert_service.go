@@ -0,0 +1,166 @@
+// alert_service.go — сообщения об ошибках в чат админов (ALERTS_CHAT_ID).
+// Одинаковые ошибки склеиваются: повтор в течение alertDedupWindow не отправляется,
+// а в следующем сообщении о ней указывается, сколько раз она повторилась.
+// Всего не больше alertsPerMinute сообщений в минуту, чтобы лавина ошибок не забила чат.
+// Кроме паник бота (их с контекстом сообщает Recover) сюда попадают строки журнала,
+// которые начинаются с "Error": так в проекте пишут ошибки бота, API, админки и задач по расписанию,
+// поэтому отдельно вызывать Report в каждом месте не нужно.
+package service
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"salle_parfume/internal/logger"
+)
+
+const (
+	// alertDedupWindow - одинаковую ошибку повторно сообщаем не раньше, чем через это время
+	alertDedupWindow = 10 * time.Minute
+	// alertsPerMinute - больше сообщений в минуту не отправляем
+	alertsPerMinute = 10
+	// alertMaxLength - длина сообщения (у Телеграма ограничение 4096 символов)
+	alertMaxLength = 3500
+	// alertKeysPrune - при скольких запомненных ошибках забываем старые
+	alertKeysPrune = 1000
+)
+
+// AlertSender - отправляет сообщение в чат (реализует бот). Вызывается не из цикла бота.
+type AlertSender interface {
+	SendAlert(chatID int64, text string) error
+}
+
+// Alert - ошибка для админов
+type Alert struct {
+	Title   string   // Что случилось одной строкой (по ней склеиваем одинаковые)
+	Context []string // Где: обновление, чат, команда, состояние
+	Details string   // Подробности, например стек
+}
+
+// alertSeen - когда ошибку последний раз отправляли и сколько раз с тех пор она повторилась
+type alertSeen struct {
+	sentAt     time.Time
+	suppressed int
+}
+
+// AlertService - сервис сообщений об ошибках
+type AlertService struct {
+	chatID int64 // Куда отправлять (0 - никуда, только журнал)
+	sender AlertSender
+
+	mu          sync.Mutex
+	seen        map[string]*alertSeen
+	minuteStart time.Time // Начало текущей минуты для лимита
+	sentInMin   int       // Сколько отправлено в текущую минуту
+	dropped     int       // Сколько не отправили из-за лимита (сообщим в следующем)
+}
+
+// NewAlertService - создает сервис. chatID - чат или группа для ошибок (0 - не отправлять).
+// Отправитель задается через SetSender, когда бот уже создан.
+func NewAlertService(chatID int64) *AlertService {
+	return &AlertService{chatID: chatID, seen: make(map[string]*alertSeen)}
+}
+
+// SetSender - кто отправляет сообщения
+func (s *AlertService) SetSender(sender AlertSender) {
+	s.sender = sender
+}
+
+// alertNumbers - числа в тексте ошибки (ID заказов, чатов...): при склейке их не различаем
+var alertNumbers = regexp.MustCompile(`\d+`)
+
+// Report - сообщает об ошибке, если такую недавно не сообщали и лимит не исчерпан.
+// Не ждет отправки: вызывается и из цикла бота, и из записи в журнал.
+func (s *AlertService) Report(alert Alert) {
+	if s.chatID == 0 || s.sender == nil {
+		return
+	}
+	text, ok := s.admit(alert, time.Now())
+	if !ok {
+		return
+	}
+	go func() {
+		// Не "Error...", чтобы неудачная отправка не попала обратно в Write
+		if err := s.sender.SendAlert(s.chatID, text); err != nil {
+			log.Printf("Alerts: send failed: %v", err)
+		}
+	}()
+}
+
+// admit - решает, отправлять ли ошибку, и собирает текст сообщения
+func (s *AlertService) admit(alert Alert, now time.Time) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := alertNumbers.ReplaceAllString(alert.Title, "#")
+	seen, ok := s.seen[key]
+	if ok && now.Sub(seen.sentAt) < alertDedupWindow {
+		seen.suppressed++
+		return "", false
+	}
+
+	if now.Sub(s.minuteStart) >= time.Minute {
+		s.minuteStart, s.sentInMin = now, 0
+	}
+	if s.sentInMin >= alertsPerMinute {
+		s.dropped++
+		return "", false
+	}
+	s.sentInMin++
+
+	if len(s.seen) > alertKeysPrune {
+		for k, v := range s.seen {
+			if now.Sub(v.sentAt) >= alertDedupWindow {
+				delete(s.seen, k)
+			}
+		}
+	}
+	var repeated int
+	if ok {
+		repeated = seen.suppressed
+	}
+	s.seen[key] = &alertSeen{sentAt: now}
+	dropped := s.dropped
+	s.dropped = 0
+
+	return formatAlert(alert, repeated, dropped), true
+}
+
+// formatAlert - текст сообщения: что, где, подробности и сколько было повторов
+func formatAlert(alert Alert, repeated, dropped int) string {
+	var sb strings.Builder
+	sb.WriteString("⚠️ " + alert.Title + "\n")
+	for _, line := range alert.Context {
+		sb.WriteString(line + "\n")
+	}
+	if repeated > 0 {
+		sb.WriteString(fmt.Sprintf("С прошлого сообщения повторилась еще %d раз.\n", repeated))
+	}
+	if dropped > 0 {
+		sb.WriteString(fmt.Sprintf("Не отправлено из-за лимита других ошибок: %d.\n", dropped))
+	}
+	if alert.Details != "" {
+		sb.WriteString("\n" + alert.Details)
+	}
+	text := sb.String()
+	if len(text) > alertMaxLength {
+		text = strings.ToValidUTF8(text[:alertMaxLength], "") + "\n…"
+	}
+	return text
+}
+
+// Write - строки журнала: те, что начинаются с "Error", отправляем админам.
+// Подключается через log.SetOutput вместе с обычным выводом.
+func (s *AlertService) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		line = logger.TrimTime(line)
+		if strings.HasPrefix(line, "Error") {
+			s.Report(Alert{Title: line})
+		}
+	}
+	return len(p), nil
+}
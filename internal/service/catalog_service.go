@@ -0,0 +1,293 @@
+// catalog_service.go — массовый импорт и экспорт каталога таблицей (CSV/XLSX).
+// Сервис работает с уже прочитанной таблицей [][]string: формат файла разбирает пакет spreadsheet.
+package service
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"salle_parfume/internal/domain"
+	"salle_parfume/internal/repository"
+)
+
+// Колонки таблицы каталога. Экспорт пишет их в этом порядке, импорт ищет по заголовку.
+const (
+	ColumnSKU         = "sku"
+	ColumnType        = "type"
+	ColumnName        = "name"
+	ColumnDescription = "description"
+	ColumnPrice       = "price"
+	ColumnCurrency    = "currency"
+	ColumnWeight      = "weight" // Граммы, необязательная: пусто - вес не меняется
+	ColumnStock       = "stock"  // Штуки, необязательная: пусто - остаток не меняется (у новых товаров не ведется)
+	ColumnImageID     = "image_id"
+	ColumnImageURL    = "image_url"
+	ColumnImageFile   = "image_file"
+)
+
+// catalogColumns - заголовок выгрузки
+var catalogColumns = []string{ColumnSKU, ColumnType, ColumnName, ColumnDescription, ColumnPrice, ColumnCurrency, ColumnWeight, ColumnStock, ColumnImageID, ColumnImageURL, ColumnImageFile}
+
+// requiredColumns - без этих колонок импорт не начинается
+var requiredColumns = []string{ColumnSKU, ColumnType, ColumnName, ColumnPrice}
+
+// ImageUploader - загружает фото в Телеграм и возвращает его file_id.
+// Каталог хранит только file_id, поэтому картинки по ссылке и из архива сначала надо загрузить.
+type ImageUploader interface {
+	UploadPhotoURL(url string) (string, error)
+	UploadPhotoBytes(name string, data []byte) (string, error)
+}
+
+// CatalogService - сервис импорта и экспорта каталога
+type CatalogService struct {
+	products repository.ProductRepository
+	uploader ImageUploader
+}
+
+// NewCatalogService - создает сервис каталога
+func NewCatalogService(products repository.ProductRepository, uploader ImageUploader) *CatalogService {
+	return &CatalogService{
+		products: products,
+		uploader: uploader,
+	}
+}
+
+// Import - проверяет таблицу построчно и сохраняет товары по артикулу:
+// если артикул уже есть - товар обновляется, иначе создается новый.
+// Строки с ошибками пропускаются и попадают в отчет, остальные сохраняются.
+// images - фото из ZIP-архива (имя файла -> содержимое), может быть nil.
+func (s *CatalogService) Import(table [][]string, images map[string][]byte) (*domain.ImportResult, error) {
+	if len(table) == 0 {
+		return nil, fmt.Errorf("файл пустой")
+	}
+
+	// Находим колонки по заголовку, регистр и пробелы не важны
+	columns := make(map[string]int)
+	for i, name := range table[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	var missing []string
+	for _, name := range requiredColumns {
+		if _, ok := columns[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("в заголовке нет колонок: %s (нужны %s)", strings.Join(missing, ", "), strings.Join(catalogColumns, ", "))
+	}
+
+	// Файлы из архива ищем по имени без папок и без учета регистра
+	byName := make(map[string][]byte, len(images))
+	for name, data := range images {
+		byName[strings.ToLower(path.Base(name))] = data
+	}
+
+	result := &domain.ImportResult{}
+	seen := make(map[string]int) // артикул -> строка, где он уже встречался
+
+	for i, row := range table[1:] {
+		rowNum := i + 2
+		cell := func(name string) string {
+			idx, ok := columns[name]
+			if !ok || idx >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[idx])
+		}
+
+		// Полностью пустые строки (часто в конце файла) просто пропускаем
+		if strings.TrimSpace(strings.Join(row, "")) == "" {
+			continue
+		}
+
+		fail := func(format string, args ...any) {
+			result.Errors = append(result.Errors, domain.ImportError{Row: rowNum, Message: fmt.Sprintf(format, args...)})
+		}
+
+		sku := cell(ColumnSKU)
+		if sku == "" {
+			fail("не указан артикул")
+			continue
+		}
+		if prev, ok := seen[sku]; ok {
+			fail("артикул %s уже встречался в строке %d", sku, prev)
+			continue
+		}
+		seen[sku] = rowNum
+
+		productType, err := domain.ParseProductType(cell(ColumnType))
+		if err != nil {
+			fail("%v", err)
+			continue
+		}
+
+		name := cell(ColumnName)
+		if name == "" {
+			fail("не указано название")
+			continue
+		}
+
+		currency := strings.ToUpper(cell(ColumnCurrency))
+		if currency == "" {
+			currency = domain.DefaultCurrency
+		}
+		price, err := domain.ParseMoney(cell(ColumnPrice), currency)
+		if err != nil {
+			fail("цена: %v", err)
+			continue
+		}
+		if err := domain.ValidatePrice(price); err != nil {
+			fail("цена: %v", err)
+			continue
+		}
+
+		var weight int
+		if v := cell(ColumnWeight); v != "" {
+			if weight, err = strconv.Atoi(v); err != nil {
+				fail("вес: нужно целое число граммов, а не %q", v)
+				continue
+			}
+			if err := domain.ValidateWeight(weight); err != nil {
+				fail("вес: %v", err)
+				continue
+			}
+		}
+
+		var stock *int
+		if v := cell(ColumnStock); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				fail("остаток: нужно целое число штук, а не %q", v)
+				continue
+			}
+			stock = &n
+			if err := domain.ValidateStock(stock); err != nil {
+				fail("остаток: %v", err)
+				continue
+			}
+		}
+
+		existing, err := s.products.GetProductBySKU(sku)
+		if err != nil {
+			return result, err
+		}
+		if cell(ColumnWeight) == "" && existing != nil {
+			weight = existing.Weight // старые таблицы без колонки веса не сбрасывают его
+		}
+		if cell(ColumnStock) == "" && existing != nil {
+			stock = existing.Stock
+		}
+
+		// Фото: готовый file_id, ссылка, файл из архива (по имени из таблицы или по артикулу)
+		imageID := cell(ColumnImageID)
+		if imageID == "" {
+			imageID, err = s.resolveImage(cell(ColumnImageURL), cell(ColumnImageFile), sku, byName)
+			if err != nil {
+				fail("фото: %v", err)
+				continue
+			}
+		}
+		if imageID == "" && existing != nil {
+			imageID = existing.ImageID // при обновлении без фото оставляем старое
+		}
+		if imageID == "" {
+			fail("нет фото: укажите image_url, image_file или приложите архив с файлом %s.jpg", sku)
+			continue
+		}
+
+		product := domain.Product{
+			SKU:         sku,
+			Type:        productType,
+			Name:        name,
+			Description: cell(ColumnDescription),
+			Price:       price,
+			ImageID:     imageID,
+			Weight:      weight,
+			Stock:       stock,
+		}
+
+		if existing != nil {
+			product.ID = existing.ID
+			if err := s.products.UpdateProduct(&product); err != nil {
+				return result, err
+			}
+			result.Updated++
+			continue
+		}
+		if err := s.products.CreateProduct(&product); err != nil {
+			return result, err
+		}
+		result.Created++
+	}
+
+	return result, nil
+}
+
+// resolveImage - загружает фото по ссылке или из архива и возвращает file_id.
+// Пустая строка без ошибки - фото в строке не указано.
+func (s *CatalogService) resolveImage(url, file, sku string, images map[string][]byte) (string, error) {
+	if url != "" {
+		return s.uploader.UploadPhotoURL(url)
+	}
+
+	if file != "" {
+		data, ok := images[strings.ToLower(path.Base(file))]
+		if !ok {
+			return "", fmt.Errorf("файла %s нет в архиве (сначала отправьте ZIP с фото)", file)
+		}
+		return s.uploader.UploadPhotoBytes(file, data)
+	}
+
+	// Имя не указано - ищем в архиве файл, названный по артикулу
+	for _, ext := range []string{".jpg", ".jpeg", ".png", ".webp"} {
+		name := strings.ToLower(sku) + ext
+		if data, ok := images[name]; ok {
+			return s.uploader.UploadPhotoBytes(name, data)
+		}
+	}
+	return "", nil
+}
+
+// Export - возвращает текущий каталог таблицей в том же формате, что понимает Import
+func (s *CatalogService) Export() ([][]string, error) {
+	products, err := s.products.GetAllProducts()
+	if err != nil {
+		return nil, err
+	}
+
+	table := [][]string{catalogColumns}
+	for _, p := range products {
+		table = append(table, []string{
+			p.SKU,
+			string(p.Type),
+			p.Name,
+			p.Description,
+			p.Price.Decimal(),
+			p.Price.Currency,
+			weightCell(p.Weight),
+			stockCell(p.Stock),
+			p.ImageID,
+			"",
+			"",
+		})
+	}
+	return table, nil
+}
+
+// weightCell - вес для выгрузки. Не указанный вес остается пустой ячейкой, а не нулем.
+func weightCell(grams int) string {
+	if grams == 0 {
+		return ""
+	}
+	return strconv.Itoa(grams)
+}
+
+// stockCell - остаток для выгрузки. Если остаток не ведется, ячейка пустая.
+func stockCell(stock *int) string {
+	if stock == nil {
+		return ""
+	}
+	return strconv.Itoa(*stock)
+}
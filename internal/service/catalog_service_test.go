@@ -0,0 +1,66 @@
+package service
+
+import (
+	"testing"
+
+	"salle_parfume/internal/domain"
+)
+
+// memProducts - товары в памяти вместо базы
+type memProducts struct {
+	bySKU map[string]*domain.Product
+}
+
+func (m *memProducts) CreateProduct(p *domain.Product) error {
+	p.ID = int64(len(m.bySKU) + 1)
+	m.bySKU[p.SKU] = p
+	return nil
+}
+
+func (m *memProducts) GetAllProducts() ([]domain.Product, error) { return nil, nil }
+
+func (m *memProducts) GetProductByID(id int64) (*domain.Product, error) { return nil, nil }
+
+func (m *memProducts) GetProductBySKU(sku string) (*domain.Product, error) {
+	return m.bySKU[sku], nil
+}
+
+func (m *memProducts) UpdateProduct(p *domain.Product) error {
+	m.bySKU[p.SKU] = p
+	return nil
+}
+
+func (m *memProducts) SetProductImages(productID int64, fileIDs []string) error { return nil }
+
+func (m *memProducts) DeleteProduct(id int64) error { return nil }
+
+// TestImportWithShopCurrency - при валюте магазина не RUB товар сохраняется в ней,
+// а строка с чужой валютой попадает в отчет об ошибках (раньше сохранение падало с паникой).
+func TestImportWithShopCurrency(t *testing.T) {
+	prev := domain.DefaultCurrency
+	domain.DefaultCurrency = "USD"
+	t.Cleanup(func() { domain.DefaultCurrency = prev })
+
+	repo := &memProducts{bySKU: make(map[string]*domain.Product)}
+	table := [][]string{
+		{ColumnSKU, ColumnType, ColumnName, ColumnPrice, ColumnCurrency, ColumnImageID},
+		{"A-1", "unisex", "Aqua", "49.90", "", "file-1"},
+		{"A-2", "male", "Bois", "100", "RUB", "file-2"},
+		{"A-3", "female", "Rose", "200000000", "", "file-3"},
+	}
+	result, err := NewCatalogService(repo, nil).Import(table, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Created != 1 {
+		t.Fatalf("создано %d товаров, ожидался 1 (ошибки: %+v)", result.Created, result.Errors)
+	}
+	saved := repo.bySKU["A-1"]
+	if saved == nil || saved.Price != domain.NewMoney(4990, "USD") {
+		t.Fatalf("сохранен товар %+v, ожидалась цена 49.90 USD", saved)
+	}
+	if len(result.Errors) != 2 || result.Errors[0].Row != 3 || result.Errors[1].Row != 4 {
+		t.Fatalf("ожидались ошибки в строках 3 (чужая валюта) и 4 (слишком дорого), получено %+v", result.Errors)
+	}
+}
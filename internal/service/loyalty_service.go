@@ -0,0 +1,122 @@
+// loyalty_service.go — бонусная программа: сколько бонусов начислить за заказ и сколько можно
+// списать при оформлении, баланс с ближайшим сгоранием, ручные корректировки админом.
+// Само списание и начисление по заказам делает репозиторий заказов в тех же транзакциях,
+// что оформление, оплата и отмена заказа. Сгорание запускает планировщик.
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"salle_parfume/internal/domain"
+	"salle_parfume/internal/repository"
+)
+
+// loyaltyExpireBatch - сколько начислений сжигать в одной транзакции
+const loyaltyExpireBatch = 500
+
+// LoyaltyService - сервис бонусной программы
+type LoyaltyService struct {
+	loyalty  repository.LoyaltyRepository
+	earn     float64       // Сколько процентов от оплаченной суммы товаров возвращается бонусами
+	maxSpend float64       // Какую долю суммы товаров (в процентах) можно оплатить бонусами
+	ttl      time.Duration // Через сколько бонусы сгорают (0 - не сгорают)
+}
+
+// NewLoyaltyService - создает сервис бонусов
+func NewLoyaltyService(loyalty repository.LoyaltyRepository, earnPercent, maxSpendPercent float64, ttl time.Duration) *LoyaltyService {
+	return &LoyaltyService{
+		loyalty:  loyalty,
+		earn:     earnPercent,
+		maxSpend: maxSpendPercent,
+		ttl:      ttl,
+	}
+}
+
+// EarnPercent - процент кешбэка (для описания программы покупателю)
+func (s *LoyaltyService) EarnPercent() float64 {
+	return s.earn
+}
+
+// MaxSpendPercent - какую долю заказа можно оплатить бонусами
+func (s *LoyaltyService) MaxSpendPercent() float64 {
+	return s.maxSpend
+}
+
+// Balance - баланс покупателя и сколько бонусов сгорит первыми
+func (s *LoyaltyService) Balance(chatID int64) (domain.LoyaltyBalance, error) {
+	points, err := s.loyalty.GetLoyaltyBalance(chatID)
+	if err != nil || s.ttl == 0 {
+		return domain.LoyaltyBalance{Points: points}, err
+	}
+	oldest, since, err := s.loyalty.GetOldestPoints(chatID)
+	if err != nil {
+		return domain.LoyaltyBalance{}, err
+	}
+	balance := domain.LoyaltyBalance{Points: points}
+	if oldest > 0 {
+		balance.Expiring = oldest
+		balance.ExpiresAt = since.Add(s.ttl)
+	}
+	return balance, nil
+}
+
+// History - операции с бонусами покупателя, новые первыми
+func (s *LoyaltyService) History(chatID int64, limit, offset int) ([]domain.LoyaltyEntry, error) {
+	return s.loyalty.GetLoyaltyHistory(chatID, limit, offset)
+}
+
+// Spendable - сколько бонусов покупатель может списать в этом заказе:
+// не больше баланса и не больше доли суммы товаров со скидками (доставку бонусами не оплатить).
+func (s *LoyaltyService) Spendable(chatID int64, quote *domain.Quote) (int64, error) {
+	limit := domain.MoneyToPoints(quote.Total.Percent(s.maxSpend))
+	if limit == 0 {
+		return 0, nil
+	}
+	balance, err := s.loyalty.GetLoyaltyBalance(chatID)
+	if err != nil {
+		return 0, err
+	}
+	return max(min(balance, limit), 0), nil
+}
+
+// EarnFor - сколько бонусов начислить за товары на эту сумму (без доставки и без оплаченного бонусами)
+func (s *LoyaltyService) EarnFor(paid domain.Money) int64 {
+	return domain.MoneyToPoints(paid.Percent(s.earn))
+}
+
+// Adjust - ручное начисление (points > 0) или списание (points < 0) админом с причиной.
+// Списать больше, чем есть на счете, нельзя: вернется domain.ErrNotEnoughPoints.
+func (s *LoyaltyService) Adjust(chatID, points int64, reason, actor string) (*domain.LoyaltyEntry, error) {
+	reason = strings.TrimSpace(reason)
+	if err := domain.ValidateLoyaltyAdjust(points, reason); err != nil {
+		return nil, err
+	}
+	entry := &domain.LoyaltyEntry{ChatID: chatID, Points: points, Reason: reason, Actor: actor}
+	if err := s.loyalty.AdjustPoints(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// Run - сжигает бонусы, начисленные раньше, чем ttl назад (задача планировщика)
+func (s *LoyaltyService) Run(ctx context.Context) (string, error) {
+	if s.ttl == 0 {
+		return "бонусы не сгорают", nil
+	}
+	before := time.Now().Add(-s.ttl)
+	var expired int
+	for ctx.Err() == nil {
+		n, err := s.loyalty.ExpirePoints(before, loyaltyExpireBatch)
+		expired += n
+		if err != nil {
+			return fmt.Sprintf("сгорело начислений: %d", expired), err
+		}
+		if n < loyaltyExpireBatch {
+			break
+		}
+	}
+	return fmt.Sprintf("сгорело начислений: %d", expired), ctx.Err()
+}
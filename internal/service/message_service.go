@@ -2,17 +2,50 @@
 // без разницы куда послать сообщение, телеграм бот, сайт, приложение
 package service
 
-// MessageService - сервис для генерации сообщений
-type MessageService struct{}
+import (
+	"salle_parfume/internal/domain"
+	"salle_parfume/internal/i18n"
+)
 
-func NewMessageService() *MessageService {
-	return &MessageService{}
+// MessageService - сервис для генерации сообщений на языке пользователя
+type MessageService struct {
+	catalog *i18n.Catalog
 }
 
-func (s *MessageService) GetWelcomeMessage() string {
-	return "Добро пожаловать в Salle Parfume! Я помогу тебе найти идеальный парфюм."
+func NewMessageService(catalog *i18n.Catalog) *MessageService {
+	return &MessageService{catalog: catalog}
 }
 
-func (s *MessageService) GetAboutMessage() string {
-	return "Мы - Salle Parfume, ваш проводник в мир ароматов."
+// Text - любой текст по ключу каталога (см. internal/i18n/locales)
+func (s *MessageService) Text(lang, key string, args ...any) string {
+	return s.catalog.Text(lang, key, args...)
+}
+
+// Plural - текст с правильной формой множественного числа для n
+func (s *MessageService) Plural(lang, key string, n int, args ...any) string {
+	return s.catalog.Plural(lang, key, n, args...)
+}
+
+// FormatMoney - сумма с валютой так, как принято в языке: "1499.90 руб." или "RUB 1499.90".
+// Формат валюты берется из ключа money.<код валюты>, для неизвестных валют - "сумма КОД".
+func (s *MessageService) FormatMoney(lang string, m domain.Money) string {
+	currency := m.Currency
+	if currency == "" {
+		currency = domain.DefaultCurrency
+	}
+	key := "money." + currency
+	if !s.catalog.Has(key) {
+		return m.Decimal() + " " + currency
+	}
+	return s.catalog.Text(lang, key, m.Decimal())
+}
+
+// Languages - коды доступных языков
+func (s *MessageService) Languages() []string {
+	return s.catalog.Languages()
+}
+
+// MatchLanguage - язык каталога по коду из Телеграма ("en-US" -> "en"), пустая строка - не поддерживается
+func (s *MessageService) MatchLanguage(code string) string {
+	return s.catalog.Match(code)
 }
@@ -0,0 +1,222 @@
+// order_service.go — оформление заказа из корзины. Общий код для бота и HTTP API,
+// чтобы заказ с сайта и из Телеграма считался и сохранялся одинаково.
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"salle_parfume/internal/domain"
+	"salle_parfume/internal/repository"
+)
+
+// Ошибки оформления заказа, которые показываются покупателю.
+var (
+	ErrCartEmpty              = errors.New("корзина пуста")
+	ErrDeliveryRequired       = errors.New("выберите способ доставки")
+	ErrDeliveryMethod         = errors.New("такого способа доставки нет")
+	ErrDeliveryAddress        = errors.New("укажите адрес доставки или отправьте геопозицию")
+	ErrDeliveryAddressTooLong = errors.New("адрес доставки слишком длинный")
+	ErrOutOfStock             = domain.ErrOutOfStock // Его же возвращает база, если товар раскупили во время оформления
+)
+
+// OrderNotifier - получает каждый новый заказ (например, чтобы сообщить о нем админам).
+// Вызывается сразу после сохранения заказа и не должен надолго блокировать оформление.
+type OrderNotifier interface {
+	NotifyNewOrder(order *domain.Order)
+}
+
+// OrderMetrics - считает оформленные заказы
+type OrderMetrics interface {
+	OrderCreated()
+}
+
+// OrderService - сервис оформления заказов
+type OrderService struct {
+	orders     repository.OrderRepository
+	deliveries repository.DeliveryRepository
+	carts      repository.CartRepository
+	promos     repository.PromoRepository
+	pricing    *PricingService
+	loyalty    *LoyaltyService
+	notifier   OrderNotifier // nil - никого не уведомляем
+	metrics    OrderMetrics  // nil - не считаем
+}
+
+// NewOrderService - создает сервис заказов
+func NewOrderService(orders repository.OrderRepository, deliveries repository.DeliveryRepository, carts repository.CartRepository, promos repository.PromoRepository, pricing *PricingService, loyalty *LoyaltyService) *OrderService {
+	return &OrderService{
+		orders:     orders,
+		deliveries: deliveries,
+		carts:      carts,
+		promos:     promos,
+		pricing:    pricing,
+		loyalty:    loyalty,
+	}
+}
+
+// SetNotifier - кого уведомлять о новых заказах. Задается после создания,
+// потому что уведомления отправляет бот, а бот сам зависит от этого сервиса.
+func (s *OrderService) SetNotifier(notifier OrderNotifier) {
+	s.notifier = notifier
+}
+
+// SetMetrics - куда сообщать об оформленных заказах
+func (s *OrderService) SetMetrics(metrics OrderMetrics) {
+	s.metrics = metrics
+}
+
+// Quote - расчет корзины покупателя. Пустой code - без промокода,
+// иначе промокод проверяется и при ошибке возвращается одна из ErrPromo*.
+func (s *OrderService) Quote(chatID int64, code string) (*domain.Quote, error) {
+	lines, err := s.carts.GetCart(chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	var promo *domain.Promotion
+	if code != "" {
+		promo, err = s.pricing.CheckPromoCode(chatID, code, lines)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s.pricing.Calculate(lines, promo)
+}
+
+// DeliveryOptions - включенные способы доставки с ценой для посчитанной корзины.
+// Пустой список - магазин доставку не настраивал, заказ оформляется без нее.
+func (s *OrderService) DeliveryOptions(quote *domain.Quote) ([]domain.DeliveryOption, error) {
+	methods, err := s.deliveries.GetDeliveryMethods(true)
+	if err != nil {
+		return nil, err
+	}
+	options := make([]domain.DeliveryOption, 0, len(methods))
+	for _, m := range methods {
+		options = append(options, domain.DeliveryOption{Method: m, Cost: m.Cost(quote.Total, quote.Weight)})
+	}
+	return options, nil
+}
+
+// Delivery - проверяет выбор покупателя и считает цену доставки для корзины.
+// Курьеру нужен адрес или геопозиция, для самовывоза адресом заказа становится адрес пункта.
+func (s *OrderService) Delivery(quote *domain.Quote, choice domain.DeliveryChoice) (domain.OrderDelivery, error) {
+	if choice.MethodID == 0 {
+		// Без выбора можно оформить только в магазине, где способов доставки нет
+		methods, err := s.deliveries.GetDeliveryMethods(true)
+		if err != nil {
+			return domain.OrderDelivery{}, err
+		}
+		if len(methods) > 0 {
+			return domain.OrderDelivery{}, ErrDeliveryRequired
+		}
+		return domain.OrderDelivery{}, nil
+	}
+
+	method, err := s.deliveries.GetDeliveryMethodByID(choice.MethodID)
+	if err != nil {
+		return domain.OrderDelivery{}, err
+	}
+	if method == nil || !method.Active {
+		return domain.OrderDelivery{}, ErrDeliveryMethod
+	}
+
+	delivery := domain.OrderDelivery{
+		MethodID: method.ID,
+		Kind:     method.Kind,
+		Name:     method.Name,
+		Cost:     method.Cost(quote.Total, quote.Weight),
+		Address:  method.Address,
+	}
+	if method.NeedsAddress() {
+		address := strings.TrimSpace(choice.Address)
+		if address == "" && choice.Location == nil {
+			return domain.OrderDelivery{}, ErrDeliveryAddress
+		}
+		if len([]rune(address)) > domain.MaxDeliveryAddressLength {
+			return domain.OrderDelivery{}, ErrDeliveryAddressTooLong
+		}
+		delivery.Address = address
+		delivery.Location = choice.Location
+	}
+	return delivery, nil
+}
+
+// Checkout - считает корзину с доставкой и оформляет заказ
+func (s *OrderService) Checkout(chatID int64, code string, choice domain.DeliveryChoice, usePoints bool) (*domain.Order, error) {
+	quote, err := s.Quote(chatID, code)
+	if err != nil {
+		return nil, err
+	}
+	if len(quote.Lines) == 0 {
+		return nil, ErrCartEmpty
+	}
+	delivery, err := s.Delivery(quote, choice)
+	if err != nil {
+		return nil, err
+	}
+	return s.PlaceOrder(chatID, quote, delivery, usePoints)
+}
+
+// PlaceOrder - сохраняет заказ по уже посчитанной корзине и доставке, отмечает использование
+// промокода и очищает корзину. Ошибки после сохранения заказа не отменяют его,
+// поэтому заказ возвращается вместе с такой ошибкой.
+// Если каких-то товаров на складе меньше, чем в корзине, заказ не создается (ErrOutOfStock).
+// usePoints - списать бонусы, сколько можно (Spendable). Если баланс успел уменьшиться,
+// заказ не создается (domain.ErrNotEnoughPoints). Бонусы за заказ считаются с суммы товаров
+// без оплаченного бонусами и начисляются, когда заказ оплачен.
+func (s *OrderService) PlaceOrder(chatID int64, quote *domain.Quote, delivery domain.OrderDelivery, usePoints bool) (*domain.Order, error) {
+	if quote.OutOfStock() {
+		return nil, ErrOutOfStock
+	}
+	var points int64
+	if usePoints {
+		var err error
+		if points, err = s.loyalty.Spendable(chatID, quote); err != nil {
+			return nil, err
+		}
+	}
+	paid := quote.Total.Sub(domain.PointsValue(points))
+	order := &domain.Order{
+		ChatID:       chatID,
+		Subtotal:     quote.Subtotal,
+		Discount:     quote.Discount(),
+		Total:        paid.Add(delivery.Cost),
+		PromoCode:    quote.PromoCode,
+		Delivery:     delivery,
+		PointsSpent:  points,
+		PointsEarned: s.loyalty.EarnFor(paid),
+	}
+	for _, line := range quote.Lines {
+		order.Items = append(order.Items, domain.OrderItem{
+			ProductID: line.ProductID,
+			Name:      line.Name,
+			Price:     line.UnitPrice,
+			Quantity:  line.Quantity,
+			Discount:  line.Discount,
+		})
+	}
+
+	if err := s.orders.CreateOrder(order); err != nil {
+		return nil, err
+	}
+	if s.metrics != nil {
+		s.metrics.OrderCreated()
+	}
+	if s.notifier != nil {
+		s.notifier.NotifyNewOrder(order)
+	}
+
+	// Отмечаем использование промокода, чтобы работали лимиты
+	var errs []error
+	if order.PromoCode != "" {
+		promo, err := s.promos.GetPromotionByCode(order.PromoCode)
+		if err == nil && promo != nil {
+			err = s.promos.RecordPromotionUse(promo.ID, chatID, order.ID)
+		}
+		errs = append(errs, err)
+	}
+	errs = append(errs, s.carts.ClearCart(chatID))
+
+	return order, errors.Join(errs...)
+}
@@ -0,0 +1,205 @@
+// pricing_service.go — расчет стоимости корзины: автоматические акции и промокоды.
+// Один расчет используется и в корзине, и при оформлении заказа, и в счете на оплату.
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"salle_parfume/internal/domain"
+	"salle_parfume/internal/repository"
+)
+
+// Ошибки применения промокода. Текст ошибок можно показывать покупателю.
+var (
+	ErrPromoNotFound      = errors.New("промокод не найден")
+	ErrPromoInactive      = errors.New("промокод сейчас не действует")
+	ErrPromoNotApplicable = errors.New("промокод не подходит к товарам в корзине")
+	ErrPromoMinOrder      = errors.New("сумма заказа меньше минимальной для промокода")
+	ErrPromoUsageLimit    = errors.New("промокод больше недоступен")
+	ErrPromoUserLimit     = errors.New("вы уже использовали этот промокод")
+)
+
+// PricingService - сервис расчета цен со скидками
+type PricingService struct {
+	promos repository.PromoRepository
+	now    func() time.Time // текущее время (подменяется, если нужно считать на другой момент)
+}
+
+// NewPricingService - создает сервис расчета цен
+func NewPricingService(promos repository.PromoRepository) *PricingService {
+	return &PricingService{
+		promos: promos,
+		now:    time.Now,
+	}
+}
+
+// CheckPromoCode - проверяет, можно ли применить промокод к корзине покупателя.
+// Возвращает найденную акцию или одну из ошибок ErrPromo*.
+func (s *PricingService) CheckPromoCode(chatID int64, code string, lines []domain.CartLine) (*domain.Promotion, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return nil, ErrPromoNotFound
+	}
+
+	promo, err := s.promos.GetPromotionByCode(code)
+	if err != nil {
+		return nil, err
+	}
+	if promo == nil {
+		return nil, ErrPromoNotFound
+	}
+	if !promo.IsActiveAt(s.now()) {
+		return nil, ErrPromoInactive
+	}
+
+	// Лимиты использования
+	if promo.UsageLimit > 0 {
+		used, err := s.promos.CountPromotionUses(promo.ID)
+		if err != nil {
+			return nil, err
+		}
+		if used >= promo.UsageLimit {
+			return nil, ErrPromoUsageLimit
+		}
+	}
+	if promo.PerUserLimit > 0 {
+		used, err := s.promos.CountUserPromotionUses(promo.ID, chatID)
+		if err != nil {
+			return nil, err
+		}
+		if used >= promo.PerUserLimit {
+			return nil, ErrPromoUserLimit
+		}
+	}
+
+	// Промокод должен подходить хотя бы к одному товару
+	applicable := false
+	for _, line := range lines {
+		if promo.Matches(line.Product) {
+			applicable = true
+			break
+		}
+	}
+	if !applicable {
+		return nil, ErrPromoNotApplicable
+	}
+
+	// Минимальная сумма считается после автоматических скидок
+	if promo.MinOrder.IsPositive() {
+		quote, err := s.Calculate(lines, nil)
+		if err != nil {
+			return nil, err
+		}
+		if quote.Total.Less(promo.MinOrder) {
+			return nil, fmt.Errorf("%w (от %s)", ErrPromoMinOrder, promo.MinOrder)
+		}
+	}
+
+	return promo, nil
+}
+
+// Calculate - считает стоимость корзины.
+// Сначала к каждой строке применяется самая выгодная автоматическая акция,
+// затем промокод (если передан) - к оставшейся сумме подходящих строк.
+func (s *PricingService) Calculate(lines []domain.CartLine, promo *domain.Promotion) (*domain.Quote, error) {
+	autos, err := s.promos.GetAutoPromotions()
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+
+	quote := &domain.Quote{}
+	for _, line := range lines {
+		base := line.Product.Price.Mul(line.Quantity)
+		quoteLine := domain.QuoteLine{
+			ProductID: line.Product.ID,
+			Name:      line.Product.Name,
+			Quantity:  line.Quantity,
+			UnitPrice: line.Product.Price,
+			Total:     base,
+		}
+		if !line.Product.HasStock(line.Quantity) {
+			quoteLine.Available = line.Product.Stock
+		}
+		quote.Lines = append(quote.Lines, quoteLine)
+		quote.Subtotal = quote.Subtotal.Add(base)
+		quote.Weight += line.Product.ShippingWeight() * line.Quantity
+	}
+
+	// 1. автоматические акции: на строку действует одна, самая выгодная
+	for i, line := range lines {
+		var best domain.Money
+		for _, auto := range autos {
+			if !auto.IsActiveAt(now) || !auto.Matches(line.Product) {
+				continue
+			}
+			if quote.Subtotal.Less(auto.MinOrder) {
+				continue
+			}
+			best = best.Max(autoDiscount(&auto, line))
+		}
+		best = best.Min(quote.Lines[i].Total)
+		quote.Lines[i].Discount = best
+		quote.Lines[i].Total = quote.Lines[i].Total.Sub(best)
+		quote.AutoDiscount = quote.AutoDiscount.Add(best)
+	}
+
+	// 2. промокод
+	if promo != nil {
+		quote.CodeDiscount = applyCode(quote, lines, promo)
+		if quote.CodeDiscount.IsPositive() {
+			quote.PromoCode = promo.Code
+		}
+	}
+
+	quote.Total = quote.Subtotal.Sub(quote.Discount())
+	return quote, nil
+}
+
+// autoDiscount - скидка автоматической акции на строку корзины.
+// Фиксированная сумма в автоматической акции действует на каждую штуку товара.
+func autoDiscount(promo *domain.Promotion, line domain.CartLine) domain.Money {
+	if promo.Kind == domain.PromoPercent {
+		return line.Product.Price.Mul(line.Quantity).Percent(promo.Percent)
+	}
+	return promo.Amount.Min(line.Product.Price).Mul(line.Quantity)
+}
+
+// applyCode - применяет промокод к строкам расчета и возвращает сумму скидки.
+// Фиксированная сумма промокода делится между подходящими строками пропорционально их стоимости.
+func applyCode(quote *domain.Quote, lines []domain.CartLine, promo *domain.Promotion) domain.Money {
+	// Вес строки - ее сумма после автоматических скидок; неподходящие строки имеют нулевой вес
+	weights := make([]domain.Money, len(lines))
+	var eligibleSum domain.Money
+	for i, line := range lines {
+		if promo.Matches(line.Product) {
+			weights[i] = quote.Lines[i].Total
+			eligibleSum = eligibleSum.Add(weights[i])
+		}
+	}
+	if !eligibleSum.IsPositive() {
+		return domain.Money{}
+	}
+
+	var discounts []domain.Money
+	if promo.Kind == domain.PromoPercent {
+		discounts = make([]domain.Money, len(lines))
+		for i, w := range weights {
+			discounts[i] = w.Percent(promo.Percent)
+		}
+	} else {
+		discounts = promo.Amount.Min(eligibleSum).Allocate(weights)
+	}
+
+	var total domain.Money
+	for i, d := range discounts {
+		d = d.Min(quote.Lines[i].Total)
+		quote.Lines[i].Discount = quote.Lines[i].Discount.Add(d)
+		quote.Lines[i].Total = quote.Lines[i].Total.Sub(d)
+		total = total.Add(d)
+	}
+	return total
+}
@@ -0,0 +1,162 @@
+// referral_service.go — реферальная программа. Покупатель делится ссылкой на бота,
+// друг, который впервые открыл бота по ней, записывается за пригласившим.
+// Когда первый заказ друга оплачен (или получен при оплате на месте), задача планировщика
+// выдает пригласившему одноразовый промокод и просит бота сообщить ему об этом.
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"time"
+
+	"salle_parfume/internal/domain"
+	"salle_parfume/internal/repository"
+)
+
+// ReferralNotifier - сообщает пригласившему, что он получил промокод за друга (реализует бот)
+type ReferralNotifier interface {
+	NotifyReferralReward(ctx context.Context, ref domain.Referral, promo *domain.Promotion) error
+}
+
+// referralBatch - больше наград за один запуск не выдаем, остальные выдадим в следующий
+const referralBatch = 200
+
+// referralCodeAlphabet - символы промокода без похожих друг на друга (0 и O, 1 и I)
+const referralCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// ReferralService - сервис реферальной программы
+type ReferralService struct {
+	referrals repository.ReferralRepository
+	users     repository.Authorization
+	orders    repository.OrderRepository
+	promos    repository.PromoRepository
+	sender    ReferralNotifier
+	percent   float64       // Скидка по промокоду за друга, в процентах
+	validity  time.Duration // Сколько действует промокод (0 - бессрочно)
+}
+
+// NewReferralService - создает сервис приглашений.
+// Отправитель уведомлений задается через SetSender, когда бот уже создан.
+func NewReferralService(referrals repository.ReferralRepository, users repository.Authorization, orders repository.OrderRepository, promos repository.PromoRepository, percent float64, validity time.Duration) *ReferralService {
+	return &ReferralService{
+		referrals: referrals,
+		users:     users,
+		orders:    orders,
+		promos:    promos,
+		percent:   percent,
+		validity:  validity,
+	}
+}
+
+// SetSender - кто сообщает о выданных промокодах
+func (s *ReferralService) SetSender(sender ReferralNotifier) {
+	s.sender = sender
+}
+
+// RewardPercent - размер скидки за друга (показывается на экране приглашений)
+func (s *ReferralService) RewardPercent() float64 {
+	return s.percent
+}
+
+// Invite - записывает нового покупателя за пригласившим. Вызывается только для тех,
+// кто впервые открыл бота. Себя пригласить нельзя, пригласивший должен быть знаком боту,
+// а у приглашенного не должно быть заказов (например, с сайта до прихода в бота).
+// false - приглашение не засчитано.
+func (s *ReferralService) Invite(referrerID, inviteeID int64) (bool, error) {
+	if referrerID == inviteeID {
+		return false, nil
+	}
+	referrer, err := s.users.GetUserByChatID(referrerID)
+	if err != nil || referrer == nil {
+		return false, err
+	}
+	orders, err := s.orders.GetUserOrders(inviteeID, 1, 0)
+	if err != nil || len(orders) > 0 {
+		return false, err
+	}
+	return s.referrals.CreateReferral(&domain.Referral{ReferrerID: referrerID, InviteeID: inviteeID})
+}
+
+// Stats - сколько друзей пригласил покупатель и какие промокоды получил
+func (s *ReferralService) Stats(chatID int64) (domain.ReferralStats, error) {
+	return s.referrals.GetReferralStats(chatID)
+}
+
+// Run - один проход: выдает промокоды за друзей, оплативших первый заказ (задача планировщика).
+// Промокод сохраняется до отправки сообщения, поэтому не пропадает,
+// даже если пригласивший заблокировал бота: он увидит его на экране приглашений.
+func (s *ReferralService) Run(ctx context.Context) (string, error) {
+	if s.sender == nil {
+		return "", fmt.Errorf("не задан отправитель уведомлений")
+	}
+	refs, err := s.referrals.GetDueReferrals(referralBatch)
+	if err != nil {
+		return "", err
+	}
+
+	var rewarded int
+	var lastErr error
+	for _, ref := range refs {
+		if ctx.Err() != nil {
+			break
+		}
+		promo, err := s.createReward()
+		if err != nil {
+			return fmt.Sprintf("выдано %d из %d", rewarded, len(refs)), err
+		}
+		if err := s.referrals.SetReferralReward(ref.ID, ref.OrderID, promo.Code); err != nil {
+			return fmt.Sprintf("выдано %d из %d", rewarded, len(refs)), err
+		}
+		rewarded++
+
+		ref.RewardCode = promo.Code
+		if err := s.sender.NotifyReferralReward(ctx, ref, promo); err != nil {
+			lastErr = fmt.Errorf("покупатель %d: %w", ref.ReferrerID, err)
+		}
+	}
+
+	details := fmt.Sprintf("выдано %d из %d", rewarded, len(refs))
+	if lastErr != nil {
+		details += ", не доставлено: " + lastErr.Error()
+	}
+	return details, ctx.Err()
+}
+
+// createReward - одноразовый промокод со скидкой за друга
+func (s *ReferralService) createReward() (*domain.Promotion, error) {
+	code, err := newReferralCode()
+	if err != nil {
+		return nil, err
+	}
+	promo := &domain.Promotion{
+		Code:       code,
+		Name:       "Скидка за друга",
+		Kind:       domain.PromoPercent,
+		Percent:    s.percent,
+		UsageLimit: 1,
+		Active:     true,
+	}
+	if s.validity > 0 {
+		promo.EndsAt = time.Now().Add(s.validity)
+	}
+	if err := promo.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.promos.CreatePromotion(promo); err != nil {
+		return nil, err
+	}
+	return promo, nil
+}
+
+// newReferralCode - случайный промокод вида REF-7KQ2MX
+func newReferralCode() (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate promo code: %w", err)
+	}
+	for i := range b {
+		b[i] = referralCodeAlphabet[int(b[i])%len(referralCodeAlphabet)]
+	}
+	return "REF-" + string(b), nil
+}
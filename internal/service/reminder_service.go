@@ -0,0 +1,82 @@
+// reminder_service.go — напоминания о брошенных корзинах. Запускается планировщиком:
+// находит корзины, которые давно не меняли, и просит бота напомнить покупателю.
+// Напоминаем не чаще раза на одно состояние корзины и не чаще interval одному покупателю.
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"salle_parfume/internal/domain"
+	"salle_parfume/internal/repository"
+)
+
+// CartReminder - отправляет покупателю напоминание о корзине (реализует бот)
+type CartReminder interface {
+	SendCartReminder(ctx context.Context, cart domain.AbandonedCart) error
+}
+
+// cartReminderBatch - больше напоминаний за один запуск не отправляем,
+// остальные уйдут в следующий (Телеграм ограничивает частоту сообщений)
+const cartReminderBatch = 200
+
+// CartReminderService - сервис напоминаний о корзине
+type CartReminderService struct {
+	carts    repository.CartRepository
+	sender   CartReminder
+	after    time.Duration // Сколько корзина должна пролежать без изменений
+	interval time.Duration // Не чаще одного напоминания за это время одному покупателю
+}
+
+// NewCartReminderService - создает сервис напоминаний.
+// Отправитель задается через SetSender, когда бот уже создан.
+func NewCartReminderService(carts repository.CartRepository, after, interval time.Duration) *CartReminderService {
+	return &CartReminderService{
+		carts:    carts,
+		after:    after,
+		interval: interval,
+	}
+}
+
+// SetSender - кто отправляет напоминания
+func (s *CartReminderService) SetSender(sender CartReminder) {
+	s.sender = sender
+}
+
+// Run - один проход: напоминает по всем брошенным корзинам (задача планировщика).
+// Напоминание отмечается отправленным, даже если покупатель заблокировал бота,
+// чтобы не пытаться снова при каждом запуске.
+func (s *CartReminderService) Run(ctx context.Context) (string, error) {
+	if s.sender == nil {
+		return "", fmt.Errorf("не задан отправитель напоминаний")
+	}
+	now := time.Now()
+	carts, err := s.carts.GetAbandonedCarts(now.Add(-s.after), now.Add(-s.interval), cartReminderBatch)
+	if err != nil {
+		return "", err
+	}
+
+	var sent int
+	var lastErr error
+	for _, cart := range carts {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := s.sender.SendCartReminder(ctx, cart); err != nil {
+			lastErr = fmt.Errorf("покупатель %d: %w", cart.ChatID, err)
+		} else {
+			sent++
+		}
+		if err := s.carts.MarkCartReminded(cart.ChatID); err != nil {
+			return fmt.Sprintf("отправлено %d из %d", sent, len(carts)), err
+		}
+	}
+
+	details := fmt.Sprintf("отправлено %d из %d", sent, len(carts))
+	// Задача считается упавшей, только если не ушло ни одного напоминания
+	if sent == 0 && lastErr != nil {
+		return details, lastErr
+	}
+	return details, ctx.Err()
+}
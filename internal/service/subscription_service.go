@@ -0,0 +1,98 @@
+// subscription_service.go — уведомления по подпискам "Сообщить о поступлении" и "Сообщить о снижении цены".
+// Запускается планировщиком: находит подписки, по которым товар снова в наличии или подешевел,
+// и просит бота написать покупателю. Одноразовая подписка после этого удаляется,
+// у постоянной запоминается новая цена, чтобы следующее уведомление было только при новом снижении.
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"salle_parfume/internal/domain"
+	"salle_parfume/internal/repository"
+)
+
+// SubscriptionNotifier - сообщает покупателю, что товар из подписки появился или подешевел (реализует бот)
+type SubscriptionNotifier interface {
+	NotifySubscription(ctx context.Context, sub domain.Subscription, product *domain.Product) error
+}
+
+// subscriptionBatch - больше уведомлений за один запуск не отправляем,
+// остальные уйдут в следующий (Телеграм ограничивает частоту сообщений)
+const subscriptionBatch = 200
+
+// SubscriptionService - сервис уведомлений по подпискам на товары
+type SubscriptionService struct {
+	subs     repository.SubscriptionRepository
+	products repository.ProductRepository
+	sender   SubscriptionNotifier
+}
+
+// NewSubscriptionService - создает сервис уведомлений.
+// Отправитель задается через SetSender, когда бот уже создан.
+func NewSubscriptionService(subs repository.SubscriptionRepository, products repository.ProductRepository) *SubscriptionService {
+	return &SubscriptionService{
+		subs:     subs,
+		products: products,
+	}
+}
+
+// SetSender - кто отправляет уведомления
+func (s *SubscriptionService) SetSender(sender SubscriptionNotifier) {
+	s.sender = sender
+}
+
+// Run - один проход по подпискам, которые пора отработать (задача планировщика).
+// Подписка отрабатывается, даже если сообщение не ушло (покупатель заблокировал бота),
+// чтобы не пытаться снова при каждом запуске.
+func (s *SubscriptionService) Run(ctx context.Context) (string, error) {
+	if s.sender == nil {
+		return "", fmt.Errorf("не задан отправитель уведомлений")
+	}
+	subs, err := s.subs.GetDueSubscriptions(subscriptionBatch)
+	if err != nil {
+		return "", err
+	}
+
+	products := make(map[int64]*domain.Product)
+	var sent int
+	var lastErr error
+	for _, sub := range subs {
+		if ctx.Err() != nil {
+			break
+		}
+		product, ok := products[sub.ProductID]
+		if !ok {
+			if product, err = s.products.GetProductByID(sub.ProductID); err != nil {
+				return fmt.Sprintf("отправлено %d из %d", sent, len(subs)), err
+			}
+			products[sub.ProductID] = product
+		}
+		// Товар могли удалить или снова изменить, пока шла рассылка
+		if product == nil || !sub.Due(product) {
+			continue
+		}
+
+		if err := s.sender.NotifySubscription(ctx, sub, product); err != nil {
+			lastErr = fmt.Errorf("покупатель %d: %w", sub.ChatID, err)
+		} else {
+			sent++
+		}
+
+		if sub.Kind.OneShot() {
+			err = s.subs.DeleteSubscription(sub.ID)
+		} else {
+			err = s.subs.SetSubscriptionPrice(sub.ID, product.Price)
+		}
+		if err != nil {
+			return fmt.Sprintf("отправлено %d из %d", sent, len(subs)), err
+		}
+	}
+
+	details := fmt.Sprintf("отправлено %d из %d", sent, len(subs))
+	// Задача считается упавшей, только если не ушло ни одного уведомления
+	if sent == 0 && lastErr != nil {
+		return details, lastErr
+	}
+	return details, ctx.Err()
+}
@@ -0,0 +1,102 @@
+// text_service.go — тексты магазина, которые админ редактирует из бота:
+// приветствие, "О нас", доставка, контакты, FAQ. Пока текст не меняли, берется
+// текст по умолчанию из каталога переводов (ключи texts.*).
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"salle_parfume/internal/domain"
+	"salle_parfume/internal/i18n"
+	"salle_parfume/internal/repository"
+)
+
+// Ошибки редактирования текстов.
+var (
+	ErrUnknownShopText         = errors.New("такого текста нет")
+	ErrShopTextVersionNotFound = errors.New("такой версии текста нет")
+)
+
+// TextService - сервис редактируемых текстов магазина
+type TextService struct {
+	texts   repository.TextRepository
+	catalog *i18n.Catalog
+}
+
+// NewTextService - создает сервис текстов
+func NewTextService(texts repository.TextRepository, catalog *i18n.Catalog) *TextService {
+	return &TextService{texts: texts, catalog: catalog}
+}
+
+// Current - текущий шаблон текста: последняя версия из базы или текст по умолчанию.
+// У текста по умолчанию Version == 0.
+func (s *TextService) Current(key, lang string) (*domain.ShopText, error) {
+	if !domain.IsShopTextKey(key) {
+		return nil, ErrUnknownShopText
+	}
+	text, err := s.texts.GetShopText(key, lang)
+	if err != nil {
+		return nil, err
+	}
+	if text == nil {
+		text = &domain.ShopText{Key: key, Lang: lang, Body: s.catalog.Text(lang, "texts."+key)}
+	}
+	return text, nil
+}
+
+// Render - готовый текст для покупателя с подставленными значениями.
+// Если база недоступна, возвращает текст по умолчанию вместе с ошибкой,
+// чтобы покупатель все равно получил ответ.
+func (s *TextService) Render(key, lang string, vars map[string]string) (string, error) {
+	text, err := s.Current(key, lang)
+	if err != nil {
+		return domain.RenderText(s.catalog.Text(lang, "texts."+key), vars), err
+	}
+	return domain.RenderText(text.Body, vars), nil
+}
+
+// Save - проверяет шаблон и сохраняет его новой версией
+func (s *TextService) Save(key, lang, body string, authorID int64) (*domain.ShopText, error) {
+	if !domain.IsShopTextKey(key) {
+		return nil, ErrUnknownShopText
+	}
+	if err := domain.ValidateTextTemplate(body); err != nil {
+		return nil, err
+	}
+
+	text := &domain.ShopText{Key: key, Lang: lang, Body: body, AuthorID: authorID}
+	if err := s.texts.SaveShopText(text); err != nil {
+		return nil, err
+	}
+	return text, nil
+}
+
+// History - последние версии текста, новые первыми
+func (s *TextService) History(key, lang string, limit int) ([]domain.ShopText, error) {
+	if !domain.IsShopTextKey(key) {
+		return nil, ErrUnknownShopText
+	}
+	return s.texts.GetShopTextHistory(key, lang, limit)
+}
+
+// Rollback - возвращает старую версию текста. История не переписывается:
+// старый текст сохраняется еще одной, новой версией.
+func (s *TextService) Rollback(key, lang string, version int, authorID int64) (*domain.ShopText, error) {
+	if !domain.IsShopTextKey(key) {
+		return nil, ErrUnknownShopText
+	}
+	old, err := s.texts.GetShopTextVersion(key, lang, version)
+	if err != nil {
+		return nil, err
+	}
+	if old == nil {
+		return nil, fmt.Errorf("%w: %d", ErrShopTextVersionNotFound, version)
+	}
+
+	text := &domain.ShopText{Key: key, Lang: lang, Body: old.Body, AuthorID: authorID}
+	if err := s.texts.SaveShopText(text); err != nil {
+		return nil, err
+	}
+	return text, nil
+}
@@ -0,0 +1,45 @@
+// csv.go - чтение и запись таблиц в CSV.
+// Таблица - это просто [][]string: первая строка заголовок, дальше данные.
+package spreadsheet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+)
+
+// utf8BOM - метка в начале файла, по которой Excel понимает, что CSV в UTF-8
+const utf8BOM = "\uFEFF"
+
+// ReadCSV - читает CSV. Разделитель (запятая или точка с запятой) определяется
+// по первой строке: русский Excel сохраняет CSV через точку с запятой.
+func ReadCSV(data []byte) ([][]string, error) {
+	text := strings.TrimPrefix(string(data), utf8BOM)
+
+	firstLine, _, _ := strings.Cut(text, "\n")
+	r := csv.NewReader(strings.NewReader(text))
+	if strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+		r.Comma = ';'
+	}
+	r.FieldsPerRecord = -1 // строки могут быть разной длины
+	r.LazyQuotes = true
+
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения CSV: %w", err)
+	}
+	return rows, nil
+}
+
+// WriteCSV - записывает таблицу в CSV (UTF-8 с BOM, разделитель - запятая).
+func WriteCSV(rows [][]string) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(utf8BOM)
+
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(rows); err != nil {
+		return nil, fmt.Errorf("ошибка записи CSV: %w", err)
+	}
+	return buf.Bytes(), nil
+}